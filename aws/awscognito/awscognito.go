@@ -3,7 +3,7 @@ package awscognito
 import (
 	"context"
 	"fmt"
-	"sync/atomic"
+	"log/slog"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
@@ -11,34 +11,39 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentity"
 
 	"github.com/88labs/go-utils/aws/awsconfig"
+	"github.com/88labs/go-utils/aws/awscredentials"
+	"github.com/88labs/go-utils/aws/awstransport"
 	"github.com/88labs/go-utils/aws/ctxawslocal"
+	"github.com/88labs/go-utils/aws/internal/awsmetrics"
 	"github.com/88labs/go-utils/aws/internal/awstrace"
 )
 
-var cognitoidentityClientAtomic atomic.Pointer[cognitoidentity.Client]
+// GlobalLogger is used by the package-level GetCredentialsForIdentity, and by the clients created without WithLogger.
+// It is nil by default, which disables logging.
+var GlobalLogger *slog.Logger
 
 // Client is a Cognito Identity client that manages its own SDK client instance.
-// Unlike the package-level functions that use a singleton, each Client holds
+// Unlike the package-level functions that share the clients of DefaultRegistry, each Client holds
 // its own *cognitoidentity.Client, enabling external lifecycle management.
 type Client struct {
 	client *cognitoidentity.Client
+	logger *slog.Logger
 }
 
 // NewClient creates a new Client for the given region.
 // Using ctxawslocal.WithContext, you can redirect requests to a local mock
 // (e.g. LocalStack) by setting the Cognito endpoint and credentials in the context.
+// Use a Registry to share the clients of several regions and options.
 func NewClient(ctx context.Context, region awsconfig.Region, opts ...ClientOption) (*Client, error) {
-	cfg := clientConfig{}
-	for _, opt := range opts {
-		if opt != nil {
-			opt.apply(&cfg)
-		}
-	}
+	return newClientWithConfig(ctx, region, newClientConfig(opts...))
+}
+
+func newClientWithConfig(ctx context.Context, region awsconfig.Region, cfg clientConfig) (*Client, error) {
 	sdkClient, err := newCognitoClient(ctx, region, cfg)
 	if err != nil {
 		return nil, err
 	}
-	return &Client{client: sdkClient}, nil
+	return &Client{client: sdkClient, logger: cfg.logger}, nil
 }
 
 // CognitoClient returns the underlying *cognitoidentity.Client for advanced usage.
@@ -50,8 +55,13 @@ func (c *Client) CognitoClient() *cognitoidentity.Client {
 // The region and endpoint are those the Client was created with via NewClient.
 func (c *Client) GetCredentialsForIdentity(
 	ctx context.Context, identityId string, logins map[string]string,
-) (*cognitoidentity.GetCredentialsForIdentityOutput, error) {
-	res, err := c.client.GetCredentialsForIdentity(
+) (res *cognitoidentity.GetCredentialsForIdentityOutput, err error) {
+	done := c.logOperation(ctx, "GetCredentialsForIdentity", slog.String("identity_id", identityId))
+	defer func() {
+		done(err)
+	}()
+
+	res, err = c.client.GetCredentialsForIdentity(
 		ctx,
 		&cognitoidentity.GetCredentialsForIdentityInput{
 			IdentityId: aws.String(identityId),
@@ -65,7 +75,7 @@ func (c *Client) GetCredentialsForIdentity(
 }
 
 // GetCredentialsForIdentity calls the Cognito GetCredentialsForIdentity API
-// using the package-level singleton client.
+// using the client of region and opts in DefaultRegistry.
 //
 // Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
 func GetCredentialsForIdentity(
@@ -75,39 +85,32 @@ func GetCredentialsForIdentity(
 	logins map[string]string,
 	opts ...ClientOption,
 ) (*cognitoidentity.GetCredentialsForIdentityOutput, error) {
-	var sdkClient *cognitoidentity.Client
-	if v := cognitoidentityClientAtomic.Load(); v != nil {
-		sdkClient = v
-	} else {
-		var err error
-		cfg := clientConfig{}
-		for _, opt := range opts {
-			if opt != nil {
-				opt.apply(&cfg)
-			}
-		}
-		sdkClient, err = newCognitoClient(ctx, region, cfg)
-		if err != nil {
-			return nil, err
-		}
-		cognitoidentityClientAtomic.Store(sdkClient)
+	c, err := DefaultRegistry.Client(ctx, region, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return (&Client{client: sdkClient}).GetCredentialsForIdentity(ctx, identityId, logins)
+	return c.GetCredentialsForIdentity(ctx, identityId, logins)
 }
 
-// newCognitoClient creates a fresh *cognitoidentity.Client without touching the singleton.
+// newCognitoClient creates a fresh *cognitoidentity.Client without touching the registry.
 func newCognitoClient(ctx context.Context, region awsconfig.Region, cfg clientConfig) (*cognitoidentity.Client, error) {
 	if localProfile, ok := getLocalEndpoint(ctx); ok {
 		return getClientLocal(ctx, region, *localProfile, cfg)
 	}
-	awsCfg, err := awsConfig.LoadDefaultConfig(ctx, awsConfig.WithRegion(region.String()))
+	awsCfg, err := awscredentials.LoadDefaultConfig(ctx, cfg.credentialsOptions, awsConfig.WithRegion(region.String()))
 	if err != nil {
 		return nil, fmt.Errorf("unable to load SDK config, %w", err)
 	}
 	return cognitoidentity.NewFromConfig(awsCfg, func(o *cognitoidentity.Options) {
+		if len(cfg.transportOptions) > 0 {
+			o.HTTPClient = awstransport.ConfigureHTTPClient(o.HTTPClient, cfg.transportOptions...)
+		}
 		if cfg.traceEnabled {
 			awstrace.AppendMiddlewares(&o.APIOptions, cfg.traceProvider)
 		}
+		if cfg.metricsEnabled {
+			awsmetrics.AppendMiddlewares(&o.APIOptions, cfg.meterProvider)
+		}
 	}), nil
 }
 
@@ -129,9 +132,15 @@ func getClientLocal(ctx context.Context, region awsconfig.Region, localProfile L
 	}
 	return cognitoidentity.NewFromConfig(awsCfg, func(o *cognitoidentity.Options) {
 		o.BaseEndpoint = aws.String(localProfile.Endpoint)
+		if len(cfg.transportOptions) > 0 {
+			o.HTTPClient = awstransport.ConfigureHTTPClient(o.HTTPClient, cfg.transportOptions...)
+		}
 		if cfg.traceEnabled {
 			awstrace.AppendMiddlewares(&o.APIOptions, cfg.traceProvider)
 		}
+		if cfg.metricsEnabled {
+			awsmetrics.AppendMiddlewares(&o.APIOptions, cfg.meterProvider)
+		}
 	}), nil
 }
 
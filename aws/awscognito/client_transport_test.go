@@ -0,0 +1,33 @@
+package awscognito_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+
+	"github.com/88labs/go-utils/aws/awscognito"
+	"github.com/88labs/go-utils/aws/awsconfig"
+	"github.com/88labs/go-utils/aws/awstransport"
+)
+
+func TestNewClient_WithTransport(t *testing.T) {
+	client, err := awscognito.NewClient(context.Background(), awsconfig.RegionTokyo, awscognito.WithTransport(
+		awstransport.WithDialTimeout(3*time.Second),
+		awstransport.WithMaxIdleConnsPerHost(64),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpClient, ok := client.CognitoClient().Options().HTTPClient.(*awshttp.BuildableClient)
+	if !ok {
+		t.Fatalf("unexpected HTTP client %T", client.CognitoClient().Options().HTTPClient)
+	}
+	if got := httpClient.GetDialer().Timeout; got != 3*time.Second {
+		t.Fatalf("unexpected dial timeout %s", got)
+	}
+	if got := httpClient.GetTransport().MaxIdleConnsPerHost; got != 64 {
+		t.Fatalf("unexpected max idle conns per host %d", got)
+	}
+}
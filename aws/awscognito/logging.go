@@ -0,0 +1,15 @@
+package awscognito
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/88labs/go-utils/aws/internal/awslog"
+)
+
+func (c *Client) logOperation(ctx context.Context, operation string, attrs ...slog.Attr) func(err error, extra ...slog.Attr) {
+	if c == nil {
+		return func(error, ...slog.Attr) {}
+	}
+	return awslog.LogOperation(ctx, c.logger, "awscognito", operation, attrs...)
+}
@@ -0,0 +1,117 @@
+package awscognito_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/88labs/go-utils/aws/awscognito"
+	"github.com/88labs/go-utils/aws/awsconfig"
+	"github.com/88labs/go-utils/aws/ctxawslocal"
+)
+
+const testIdentityID = "ap-northeast-1:00000000-0000-0000-0000-000000000000"
+
+// newLoggingTestContext returns a context whose Cognito endpoint knows only testIdentityID.
+func newLoggingTestContext(t *testing.T) context.Context {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		var input struct{ IdentityId string }
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		if input.IdentityId != testIdentityID {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"identity not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"IdentityId":"` + testIdentityID + `","Credentials":{"AccessKeyId":"AKID"}}`))
+	}))
+	t.Cleanup(server.Close)
+	return ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithCognitoEndpoint(server.URL),
+		ctxawslocal.WithAccessKey("test"),
+		ctxawslocal.WithSecretAccessKey("test"),
+	)
+}
+
+func TestNewClient_WithLogger_logsGetCredentialsForIdentity(t *testing.T) {
+	ctx := newLoggingTestContext(t)
+	var buf bytes.Buffer
+	client, err := awscognito.NewClient(ctx, awsconfig.RegionTokyo,
+		awscognito.WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetCredentialsForIdentity(ctx, testIdentityID, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &entry); err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range map[string]any{
+		"level":       "INFO",
+		"msg":         "awscognito operation completed",
+		"component":   "awscognito",
+		"operation":   "GetCredentialsForIdentity",
+		"identity_id": testIdentityID,
+	} {
+		if entry[k] != want {
+			t.Errorf("%s = %v, want %v", k, entry[k], want)
+		}
+	}
+	if _, ok := entry["duration"]; !ok {
+		t.Error("duration is not logged")
+	}
+}
+
+func TestNewClient_WithZapLogger_logsFailure(t *testing.T) {
+	ctx := newLoggingTestContext(t)
+	core, observedLogs := observer.New(zap.InfoLevel)
+	client, err := awscognito.NewClient(ctx, awsconfig.RegionTokyo, awscognito.WithZapLogger(zap.New(core)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetCredentialsForIdentity(ctx, "unknown", nil); err == nil {
+		t.Fatal("GetCredentialsForIdentity of an unknown identity succeeded")
+	}
+
+	entries := observedLogs.All()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(entries))
+	}
+	if entries[0].Level != zap.ErrorLevel || entries[0].Message != "awscognito operation failed" {
+		t.Fatalf("logged %v %q, want error %q", entries[0].Level, entries[0].Message, "awscognito operation failed")
+	}
+	fields := entries[0].ContextMap()
+	if fields["identity_id"] != "unknown" {
+		t.Errorf("identity_id = %v, want %q", fields["identity_id"], "unknown")
+	}
+	if err, ok := fields["error"].(string); !ok || !strings.Contains(err, "ResourceNotFoundException") {
+		t.Errorf("error = %v", fields["error"])
+	}
+}
+
+func TestGlobalLogger_logsGetCredentialsForIdentity(t *testing.T) {
+	ctx := newLoggingTestContext(t)
+	var buf bytes.Buffer
+	awscognito.GlobalLogger = slog.New(slog.NewJSONHandler(&buf, nil))
+	t.Cleanup(func() { awscognito.GlobalLogger = nil })
+
+	if _, err := awscognito.GetCredentialsForIdentity(ctx, awsconfig.RegionTokyo, testIdentityID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"operation":"GetCredentialsForIdentity"`) {
+		t.Fatalf("logs = %q", buf.String())
+	}
+}
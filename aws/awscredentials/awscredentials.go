@@ -0,0 +1,208 @@
+// Package awscredentials configures the credentials of the clients of awss3, awssqs, awsdynamo and awscognito.
+//
+// The same options are passed to the WithCredentials option of each package:
+//
+//	creds := []awscredentials.OptionCredentials{
+//		awscredentials.WithAssumeRole("arn:aws:iam::123456789012:role/reader"),
+//		awscredentials.WithExternalID("external-id"),
+//	}
+//	s3Client, err := awss3.NewClient(ctx, region, awss3.WithCredentials(creds...))
+//	sqsClient, err := awssqs.NewClient(ctx, region, awssqs.WithCredentials(creds...))
+//
+// Without options, the default credential chain of the AWS SDK is used.
+package awscredentials
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// ErrRoleARNRequired is returned when a web identity token file is set without the role to assume.
+var ErrRoleARNRequired = errors.New("RoleARNRequired")
+
+type OptionCredentials interface {
+	Apply(*confCredentials)
+}
+
+type confCredentials struct {
+	// Profile is the name of the shared config profile.
+	Profile string
+	// Provider is the provider of the credentials, used instead of the default credential chain.
+	Provider aws.CredentialsProvider
+	// RoleARN is the ARN of the role assumed with the base credentials, or with the web identity token.
+	RoleARN string
+	// ExternalID is the external ID of the assumed role.
+	ExternalID *string
+	// RoleSessionName is the session name of the assumed role.
+	RoleSessionName string
+	// Duration is the duration of the credentials of the assumed role.
+	Duration time.Duration
+	// WebIdentityTokenFile is the path of the OIDC token used to assume the role.
+	WebIdentityTokenFile string
+}
+
+// nolint:revive
+func GetCredentialsConf(opts ...OptionCredentials) confCredentials {
+	c := confCredentials{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt.Apply(&c)
+		}
+	}
+	return c
+}
+
+type OptionProfile string
+
+func (o OptionProfile) Apply(c *confCredentials) {
+	c.Profile = string(o)
+}
+
+// WithProfile
+// Loads the base credentials and the settings of the named profile of the shared config and credentials files.
+func WithProfile(name string) OptionProfile {
+	return OptionProfile(name)
+}
+
+type optionProvider struct {
+	provider aws.CredentialsProvider
+}
+
+func (o optionProvider) Apply(c *confCredentials) {
+	c.Provider = o.provider
+}
+
+// WithProvider
+// Uses provider for the base credentials instead of the default credential chain.
+// The credentials are cached until they expire; a provider that is already an aws.CredentialsCache is kept.
+func WithProvider(provider aws.CredentialsProvider) OptionCredentials {
+	return optionProvider{provider: provider}
+}
+
+type OptionAssumeRole string
+
+func (o OptionAssumeRole) Apply(c *confCredentials) {
+	c.RoleARN = string(o)
+}
+
+// WithAssumeRole
+// Assumes the role with STS AssumeRole using the base credentials, or with AssumeRoleWithWebIdentity
+// when WithWebIdentityTokenFile is set. The credentials are cached and refreshed before they expire.
+func WithAssumeRole(roleARN string) OptionAssumeRole {
+	return OptionAssumeRole(roleARN)
+}
+
+type OptionExternalID string
+
+func (o OptionExternalID) Apply(c *confCredentials) {
+	c.ExternalID = aws.String(string(o))
+}
+
+// WithExternalID
+// Sets the external ID of the role assumed with WithAssumeRole.
+func WithExternalID(externalID string) OptionExternalID {
+	return OptionExternalID(externalID)
+}
+
+type OptionRoleSessionName string
+
+func (o OptionRoleSessionName) Apply(c *confCredentials) {
+	c.RoleSessionName = string(o)
+}
+
+// WithRoleSessionName
+// Sets the session name of the role assumed with WithAssumeRole. Default is generated by the AWS SDK.
+func WithRoleSessionName(name string) OptionRoleSessionName {
+	return OptionRoleSessionName(name)
+}
+
+type OptionDuration time.Duration
+
+func (o OptionDuration) Apply(c *confCredentials) {
+	c.Duration = time.Duration(o)
+}
+
+// WithDuration
+// Sets the duration of the credentials of the role assumed with WithAssumeRole. Default is 15 minutes.
+func WithDuration(duration time.Duration) OptionDuration {
+	return OptionDuration(duration)
+}
+
+type OptionWebIdentityTokenFile string
+
+func (o OptionWebIdentityTokenFile) Apply(c *confCredentials) {
+	c.WebIdentityTokenFile = string(o)
+}
+
+// WithWebIdentityTokenFile
+// Assumes the role of WithAssumeRole with the OIDC token of the file, e.g. the token of an EKS service account.
+// The file is read again every time the credentials are refreshed.
+func WithWebIdentityTokenFile(path string) OptionWebIdentityTokenFile {
+	return OptionWebIdentityTokenFile(path)
+}
+
+// LoadDefaultConfig loads the SDK config with config.LoadDefaultConfig and optFns, using the credentials of opts.
+func LoadDefaultConfig(
+	ctx context.Context, opts []OptionCredentials, optFns ...func(*awsConfig.LoadOptions) error,
+) (aws.Config, error) {
+	c := GetCredentialsConf(opts...)
+	if c.WebIdentityTokenFile != "" && c.RoleARN == "" {
+		return aws.Config{}, ErrRoleARNRequired
+	}
+	if c.Profile != "" {
+		optFns = append(optFns, awsConfig.WithSharedConfigProfile(c.Profile))
+	}
+	if c.Provider != nil {
+		optFns = append(optFns, awsConfig.WithCredentialsProvider(newCredentialsCache(c.Provider)))
+	}
+	cfg, err := awsConfig.LoadDefaultConfig(ctx, optFns...)
+	if err != nil {
+		return aws.Config{}, err
+	}
+	if c.RoleARN == "" {
+		return cfg, nil
+	}
+
+	stsClient := sts.NewFromConfig(cfg)
+	if c.WebIdentityTokenFile != "" {
+		cfg.Credentials = newCredentialsCache(stscreds.NewWebIdentityRoleProvider(
+			stsClient, c.RoleARN, stscreds.IdentityTokenFile(c.WebIdentityTokenFile),
+			func(o *stscreds.WebIdentityRoleOptions) {
+				o.RoleSessionName = c.RoleSessionName
+				o.Duration = c.Duration
+			},
+		))
+		return cfg, nil
+	}
+	cfg.Credentials = newCredentialsCache(stscreds.NewAssumeRoleProvider(
+		stsClient, c.RoleARN,
+		func(o *stscreds.AssumeRoleOptions) {
+			o.ExternalID = c.ExternalID
+			if c.RoleSessionName != "" {
+				o.RoleSessionName = c.RoleSessionName
+			}
+			if c.Duration > 0 {
+				o.Duration = c.Duration
+			}
+		},
+	))
+	return cfg, nil
+}
+
+// RoleARN returns the ARN of the role assumed with opts, empty when no role is assumed.
+func RoleARN(opts ...OptionCredentials) string {
+	return GetCredentialsConf(opts...).RoleARN
+}
+
+func newCredentialsCache(provider aws.CredentialsProvider) aws.CredentialsProvider {
+	if aws.IsCredentialsProvider(provider, (*aws.CredentialsCache)(nil)) {
+		return provider
+	}
+	return aws.NewCredentialsCache(provider)
+}
@@ -0,0 +1,188 @@
+package awscredentials_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"gotest.tools/v3/assert"
+
+	"github.com/88labs/go-utils/aws/awsconfig"
+	"github.com/88labs/go-utils/aws/awscredentials"
+)
+
+const testRoleARN = "arn:aws:iam::123456789012:role/reader"
+
+// stsServer is a fake STS endpoint answering AssumeRole and AssumeRoleWithWebIdentity.
+type stsServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []url.Values
+}
+
+func newSTSServer(t *testing.T) *stsServer {
+	t.Helper()
+	s := &stsServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, r.PostForm)
+		s.mu.Unlock()
+		action := r.PostForm.Get("Action")
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = fmt.Fprintf(w, `<%[1]sResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+<%[1]sResult>
+<Credentials>
+<AccessKeyId>ASSUMEDACCESSKEY</AccessKeyId>
+<SecretAccessKey>ASSUMEDSECRETKEY</SecretAccessKey>
+<SessionToken>ASSUMEDSESSIONTOKEN</SessionToken>
+<Expiration>%[2]s</Expiration>
+</Credentials>
+</%[1]sResult>
+</%[1]sResponse>`, action, time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *stsServer) Requests() []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]url.Values(nil), s.requests...)
+}
+
+func TestLoadDefaultConfig(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	baseProvider := credentials.NewStaticCredentialsProvider("BASEACCESSKEY", "BASESECRETKEY", "")
+
+	t.Run("WithProvider caches the credentials", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		provider := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			calls.Add(1)
+			return aws.Credentials{
+				AccessKeyID: "CUSTOMACCESSKEY", SecretAccessKey: "CUSTOMSECRETKEY",
+				CanExpire: true, Expires: time.Now().Add(time.Hour),
+			}, nil
+		})
+		cfg, err := awscredentials.LoadDefaultConfig(ctx,
+			[]awscredentials.OptionCredentials{awscredentials.WithProvider(provider)},
+			awsConfig.WithRegion(awsconfig.RegionTokyo.String()),
+		)
+		assert.NilError(t, err)
+		for range 3 {
+			creds, err := cfg.Credentials.Retrieve(ctx)
+			assert.NilError(t, err)
+			assert.Equal(t, "CUSTOMACCESSKEY", creds.AccessKeyID)
+		}
+		assert.Equal(t, int32(1), calls.Load())
+	})
+	t.Run("WithAssumeRole", func(t *testing.T) {
+		t.Parallel()
+		server := newSTSServer(t)
+		cfg, err := awscredentials.LoadDefaultConfig(ctx,
+			[]awscredentials.OptionCredentials{
+				awscredentials.WithProvider(baseProvider),
+				awscredentials.WithAssumeRole(testRoleARN),
+				awscredentials.WithExternalID("external-id"),
+				awscredentials.WithRoleSessionName("session"),
+				awscredentials.WithDuration(time.Hour),
+			},
+			awsConfig.WithRegion(awsconfig.RegionTokyo.String()),
+			awsConfig.WithBaseEndpoint(server.URL),
+		)
+		assert.NilError(t, err)
+		for range 2 {
+			creds, err := cfg.Credentials.Retrieve(ctx)
+			assert.NilError(t, err)
+			assert.Equal(t, "ASSUMEDACCESSKEY", creds.AccessKeyID)
+			assert.Equal(t, "ASSUMEDSESSIONTOKEN", creds.SessionToken)
+		}
+		requests := server.Requests()
+		assert.Equal(t, 1, len(requests))
+		assert.Equal(t, "AssumeRole", requests[0].Get("Action"))
+		assert.Equal(t, testRoleARN, requests[0].Get("RoleArn"))
+		assert.Equal(t, "external-id", requests[0].Get("ExternalId"))
+		assert.Equal(t, "session", requests[0].Get("RoleSessionName"))
+		assert.Equal(t, "3600", requests[0].Get("DurationSeconds"))
+	})
+	t.Run("WithWebIdentityTokenFile", func(t *testing.T) {
+		t.Parallel()
+		server := newSTSServer(t)
+		tokenFile := filepath.Join(t.TempDir(), "token")
+		assert.NilError(t, os.WriteFile(tokenFile, []byte("oidc-token"), 0o600))
+		cfg, err := awscredentials.LoadDefaultConfig(ctx,
+			[]awscredentials.OptionCredentials{
+				awscredentials.WithProvider(baseProvider),
+				awscredentials.WithAssumeRole(testRoleARN),
+				awscredentials.WithWebIdentityTokenFile(tokenFile),
+				awscredentials.WithRoleSessionName("session"),
+			},
+			awsConfig.WithRegion(awsconfig.RegionTokyo.String()),
+			awsConfig.WithBaseEndpoint(server.URL),
+		)
+		assert.NilError(t, err)
+		creds, err := cfg.Credentials.Retrieve(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, "ASSUMEDACCESSKEY", creds.AccessKeyID)
+		requests := server.Requests()
+		assert.Equal(t, 1, len(requests))
+		assert.Equal(t, "AssumeRoleWithWebIdentity", requests[0].Get("Action"))
+		assert.Equal(t, testRoleARN, requests[0].Get("RoleArn"))
+		assert.Equal(t, "oidc-token", requests[0].Get("WebIdentityToken"))
+		assert.Equal(t, "session", requests[0].Get("RoleSessionName"))
+	})
+	t.Run("WithWebIdentityTokenFile requires WithAssumeRole", func(t *testing.T) {
+		t.Parallel()
+		_, err := awscredentials.LoadDefaultConfig(ctx,
+			[]awscredentials.OptionCredentials{awscredentials.WithWebIdentityTokenFile("token")},
+		)
+		assert.ErrorIs(t, err, awscredentials.ErrRoleARNRequired)
+	})
+	t.Run("WithProfile", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		credentialsFile := filepath.Join(dir, "credentials")
+		assert.NilError(t, os.WriteFile(credentialsFile, []byte(
+			"[default]\naws_access_key_id = DEFAULTACCESSKEY\naws_secret_access_key = DEFAULTSECRETKEY\n"+
+				"[reader]\naws_access_key_id = PROFILEACCESSKEY\naws_secret_access_key = PROFILESECRETKEY\n",
+		), 0o600))
+		configFile := filepath.Join(dir, "config")
+		assert.NilError(t, os.WriteFile(configFile, []byte("[profile reader]\nregion = ap-northeast-3\n"), 0o600))
+
+		cfg, err := awscredentials.LoadDefaultConfig(ctx,
+			[]awscredentials.OptionCredentials{awscredentials.WithProfile("reader")},
+			awsConfig.WithSharedCredentialsFiles([]string{credentialsFile}),
+			awsConfig.WithSharedConfigFiles([]string{configFile}),
+		)
+		assert.NilError(t, err)
+		assert.Equal(t, awsconfig.RegionOsaka.String(), cfg.Region)
+		creds, err := cfg.Credentials.Retrieve(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, "PROFILEACCESSKEY", creds.AccessKeyID)
+	})
+}
+
+func TestRoleARN(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "", awscredentials.RoleARN())
+	assert.Equal(t, testRoleARN, awscredentials.RoleARN(
+		awscredentials.WithAssumeRole("arn:aws:iam::123456789012:role/other"),
+		awscredentials.WithAssumeRole(testRoleARN),
+	))
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -32,16 +33,16 @@ func PutItem[T any](
 	tableName TableName,
 	item T,
 	opts ...dynamooptions.OptionDynamo,
-) error {
-	c := dynamooptions.GetDynamoConf(opts...)
-	client, err := getClientWithConfig(
-		ctx,
-		region,
-		c.MaxAttempts,
-		c.MaxBackoffDelay,
-		c.TraceProvider(),
-		c.TraceEnabled(),
+) (err error) {
+	cfg := newClientConfig(opts...)
+	done := logOperation(ctx, cfg.logger, "PutItem",
+		slog.String("table_name", tableName.String()),
 	)
+	defer func() {
+		done(err)
+	}()
+
+	client, err := getClientWithConfig(ctx, region, cfg)
 	if err != nil {
 		return err
 	}
@@ -74,16 +75,17 @@ func UpdateItem[T any, K ~string](
 	key K,
 	update expression.UpdateBuilder,
 	opts ...dynamooptions.OptionDynamo,
-) (*T, error) {
-	c := dynamooptions.GetDynamoConf(opts...)
-	client, err := getClientWithConfig(
-		ctx,
-		region,
-		c.MaxAttempts,
-		c.MaxBackoffDelay,
-		c.TraceProvider(),
-		c.TraceEnabled(),
+) (_ *T, err error) {
+	cfg := newClientConfig(opts...)
+	done := logOperation(ctx, cfg.logger, "UpdateItem",
+		slog.String("table_name", tableName.String()),
+		slog.String("key", string(key)),
 	)
+	defer func() {
+		done(err)
+	}()
+
+	client, err := getClientWithConfig(ctx, region, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -131,16 +133,17 @@ func DeleteItem[T any, K ~string](
 	keyAttributeName KeyAttributeName,
 	key K,
 	opts ...dynamooptions.OptionDynamo,
-) (*T, error) {
-	c := dynamooptions.GetDynamoConf(opts...)
-	client, err := getClientWithConfig(
-		ctx,
-		region,
-		c.MaxAttempts,
-		c.MaxBackoffDelay,
-		c.TraceProvider(),
-		c.TraceEnabled(),
+) (_ *T, err error) {
+	cfg := newClientConfig(opts...)
+	done := logOperation(ctx, cfg.logger, "DeleteItem",
+		slog.String("table_name", tableName.String()),
+		slog.String("key", string(key)),
 	)
+	defer func() {
+		done(err)
+	}()
+
+	client, err := getClientWithConfig(ctx, region, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -181,16 +184,17 @@ func GetItem[T any, K ~string](
 	keyAttributeName KeyAttributeName,
 	key K,
 	opts ...dynamooptions.OptionDynamo,
-) (*T, error) {
-	c := dynamooptions.GetDynamoConf(opts...)
-	client, err := getClientWithConfig(
-		ctx,
-		region,
-		c.MaxAttempts,
-		c.MaxBackoffDelay,
-		c.TraceProvider(),
-		c.TraceEnabled(),
+) (_ *T, err error) {
+	cfg := newClientConfig(opts...)
+	done := logOperation(ctx, cfg.logger, "GetItem",
+		slog.String("table_name", tableName.String()),
+		slog.String("key", string(key)),
 	)
+	defer func() {
+		done(err)
+	}()
+
+	client, err := getClientWithConfig(ctx, region, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -229,20 +233,21 @@ func BatchGetItem[T any, K ~string](
 	keyAttributeName KeyAttributeName,
 	keys []K,
 	opts ...dynamooptions.OptionDynamo,
-) ([]*T, error) {
+) (_ []*T, err error) {
 	// DynamoDB allows a maximum batch size of 100 items.
 	// https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_BatchGetItem.html
 	const MaxBatchSize = 100
 
-	c := dynamooptions.GetDynamoConf(opts...)
-	client, err := getClientWithConfig(
-		ctx,
-		region,
-		c.MaxAttempts,
-		c.MaxBackoffDelay,
-		c.TraceProvider(),
-		c.TraceEnabled(),
+	cfg := newClientConfig(opts...)
+	done := logOperation(ctx, cfg.logger, "BatchGetItem",
+		slog.String("table_name", tableName.String()),
+		slog.Int("key_count", len(keys)),
 	)
+	defer func() {
+		done(err)
+	}()
+
+	client, err := getClientWithConfig(ctx, region, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -298,7 +303,7 @@ func BatchWriteItem[T any](
 	tableName TableName,
 	items []T,
 	opts ...dynamooptions.OptionDynamo,
-) error {
+) (err error) {
 	const (
 		// MaxBatchSize DynamoDB allows a maximum batch size of 25 items.
 		// https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_BatchWriteItem.html
@@ -307,15 +312,16 @@ func BatchWriteItem[T any](
 		WriteWaitTime = 10 * time.Millisecond
 	)
 
-	c := dynamooptions.GetDynamoConf(opts...)
-	client, err := getClientWithConfig(
-		ctx,
-		region,
-		c.MaxAttempts,
-		c.MaxBackoffDelay,
-		c.TraceProvider(),
-		c.TraceEnabled(),
+	cfg := newClientConfig(opts...)
+	done := logOperation(ctx, cfg.logger, "BatchWriteItem",
+		slog.String("table_name", tableName.String()),
+		slog.Int("item_count", len(items)),
 	)
+	defer func() {
+		done(err)
+	}()
+
+	client, err := getClientWithConfig(ctx, region, cfg)
 	if err != nil {
 		return err
 	}
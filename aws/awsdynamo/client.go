@@ -3,7 +3,7 @@ package awsdynamo
 import (
 	"context"
 	"fmt"
-	"sync/atomic"
+	"log/slog"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -12,18 +12,26 @@ import (
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"go.opentelemetry.io/otel/metric"
 	oteltrace "go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 
 	"github.com/88labs/go-utils/aws/awsconfig"
+	"github.com/88labs/go-utils/aws/awscredentials"
 	"github.com/88labs/go-utils/aws/awsdynamo/dynamooptions"
+	"github.com/88labs/go-utils/aws/awstransport"
 	"github.com/88labs/go-utils/aws/ctxawslocal"
+	"github.com/88labs/go-utils/aws/internal/awslog"
+	"github.com/88labs/go-utils/aws/internal/awsmetrics"
 	"github.com/88labs/go-utils/aws/internal/awstrace"
 )
 
-var dynamoDBClientAtomic atomic.Pointer[dynamodb.Client]
+// GlobalLogger is used by package-level functions such as PutItem and GetItem without WithLogger.
+// It is nil by default, which disables logging.
+var GlobalLogger *slog.Logger
 
 // Client is a DynamoDB client that manages its own SDK client instance.
-// Unlike the package-level functions that use a singleton, each Client holds
+// Unlike the package-level functions that share the clients of DefaultRegistry, each Client holds
 // its own *dynamodb.Client, enabling external lifecycle management.
 //
 // Note: Generic package-level functions (PutItem, GetItem, etc.) remain at the
@@ -32,13 +40,39 @@ type Client struct {
 	client *dynamodb.Client
 }
 
+// clientConfig is the configuration of a *dynamodb.Client resolved from the dynamooptions.
+type clientConfig struct {
+	maxAttempts        int
+	maxBackoffDelay    time.Duration
+	traceProvider      oteltrace.TracerProvider
+	traceEnabled       bool
+	meterProvider      metric.MeterProvider
+	metricsEnabled     bool
+	transportOptions   []awstransport.OptionTransport
+	credentialsOptions []awscredentials.OptionCredentials
+	logger             *slog.Logger
+}
+
+func newClientConfig(opts ...dynamooptions.OptionDynamo) clientConfig {
+	c := dynamooptions.GetDynamoConf(opts...)
+	return clientConfig{
+		maxAttempts:        c.MaxAttempts,
+		maxBackoffDelay:    c.MaxBackoffDelay,
+		traceProvider:      c.TraceProvider(),
+		traceEnabled:       c.TraceEnabled(),
+		meterProvider:      c.MeterProvider(),
+		metricsEnabled:     c.MetricsEnabled(),
+		transportOptions:   c.TransportOptions(),
+		credentialsOptions: c.CredentialsOptions(),
+		logger:             c.Logger(),
+	}
+}
+
 // NewClient creates a new Client for the given region.
 // Using ctxawslocal.WithContext, you can make requests for local mocks.
+// Use a Registry to share the clients of several regions and options.
 func NewClient(ctx context.Context, region awsconfig.Region, opts ...dynamooptions.OptionDynamo) (*Client, error) {
-	c := dynamooptions.GetDynamoConf(opts...)
-	sdkClient, err := newDynamoDBClient(
-		ctx, region, c.MaxAttempts, c.MaxBackoffDelay, c.TraceProvider(), c.TraceEnabled(),
-	)
+	sdkClient, err := newDynamoDBClient(ctx, region, newClientConfig(opts...))
 	if err != nil {
 		return nil, err
 	}
@@ -52,11 +86,49 @@ func WithTrace(provider oteltrace.TracerProvider) dynamooptions.OptionDynamo {
 	return dynamooptions.WithTrace(provider)
 }
 
+// WithMetrics records OpenTelemetry metrics of the requests of an independently created DynamoDB client.
+// It is the same as dynamooptions.WithMetrics.
+func WithMetrics(provider metric.MeterProvider) dynamooptions.OptionDynamo {
+	return dynamooptions.WithMetrics(provider)
+}
+
+// WithTransport configures the HTTP transport of an independently created DynamoDB client.
+// It is the same as dynamooptions.WithTransport.
+func WithTransport(opts ...awstransport.OptionTransport) dynamooptions.OptionDynamo {
+	return dynamooptions.WithTransport(opts...)
+}
+
+// WithCredentials configures the credentials of an independently created DynamoDB client.
+// It is the same as dynamooptions.WithCredentials.
+func WithCredentials(opts ...awscredentials.OptionCredentials) dynamooptions.OptionDynamo {
+	return dynamooptions.WithCredentials(opts...)
+}
+
+// WithLogger emits structured logs of the operations of the package-level functions via slog.
+// It is the same as dynamooptions.WithLogger.
+func WithLogger(logger *slog.Logger) dynamooptions.OptionDynamo {
+	return dynamooptions.WithLogger(logger)
+}
+
+// WithZapLogger emits structured logs of the operations of the package-level functions via a zap logger.
+// It is the same as dynamooptions.WithZapLogger.
+func WithZapLogger(logger *zap.Logger) dynamooptions.OptionDynamo {
+	return dynamooptions.WithZapLogger(logger)
+}
+
+// NewLoggerFromZap bridges a zap logger into slog so it can be used with awsdynamo.
+// When logger is nil, a no-op logger is returned.
+func NewLoggerFromZap(logger *zap.Logger) *slog.Logger {
+	return awslog.NewLoggerFromZap(logger)
+}
+
 // DynamoDBClient returns the underlying *dynamodb.Client for advanced usage.
 func (c *Client) DynamoDBClient() *dynamodb.Client {
 	return c.client
 }
 
+// GetClient returns the client of region and the options in DefaultRegistry, creating it on the first call.
+// Using ctxawslocal.WithContext, you can make requests for local mocks.
 func GetClient(
 	ctx context.Context,
 	region awsconfig.Region,
@@ -64,54 +136,29 @@ func GetClient(
 	limitBackOffDelay time.Duration,
 	opts ...dynamooptions.OptionDynamo,
 ) (*dynamodb.Client, error) {
-	c := dynamooptions.GetDynamoConf(opts...)
-	return getClientWithConfig(
-		ctx,
-		region,
-		limitAttempts,
-		limitBackOffDelay,
-		c.TraceProvider(),
-		c.TraceEnabled(),
-	)
+	cfg := newClientConfig(opts...)
+	cfg.maxAttempts, cfg.maxBackoffDelay = limitAttempts, limitBackOffDelay
+	return getClientWithConfig(ctx, region, cfg)
 }
 
-func getClientWithConfig(
-	ctx context.Context,
-	region awsconfig.Region,
-	limitAttempts int,
-	limitBackOffDelay time.Duration,
-	traceProvider oteltrace.TracerProvider,
-	traceEnabled bool,
-) (*dynamodb.Client, error) {
-	if v := dynamoDBClientAtomic.Load(); v != nil {
-		return v, nil
-	}
-	sdkClient, err := newDynamoDBClient(
-		ctx, region, limitAttempts, limitBackOffDelay, traceProvider, traceEnabled,
-	)
+func getClientWithConfig(ctx context.Context, region awsconfig.Region, cfg clientConfig) (*dynamodb.Client, error) {
+	c, err := DefaultRegistry.client(ctx, region, cfg)
 	if err != nil {
 		return nil, err
 	}
-	dynamoDBClientAtomic.Store(sdkClient)
-	return sdkClient, nil
+	return c.client, nil
 }
 
-// newDynamoDBClient creates a fresh *dynamodb.Client without touching the singleton.
-func newDynamoDBClient(
-	ctx context.Context,
-	region awsconfig.Region,
-	limitAttempts int,
-	limitBackOffDelay time.Duration,
-	traceProvider oteltrace.TracerProvider,
-	traceEnabled bool,
-) (*dynamodb.Client, error) {
+// newDynamoDBClient creates a fresh *dynamodb.Client without touching the registry.
+func newDynamoDBClient(ctx context.Context, region awsconfig.Region, cfg clientConfig) (*dynamodb.Client, error) {
 	if localProfile, ok := getLocalEndpoint(ctx); ok {
-		return getClientLocal(ctx, *localProfile, traceProvider, traceEnabled)
+		return getClientLocal(ctx, *localProfile, cfg)
 	}
-	awsCfg, err := awsConfig.LoadDefaultConfig(ctx, awsConfig.WithRegion(region.String()),
+	awsCfg, err := awscredentials.LoadDefaultConfig(ctx, cfg.credentialsOptions,
+		awsConfig.WithRegion(region.String()),
 		awsConfig.WithRetryer(func() aws.Retryer {
-			r := retry.AddWithMaxAttempts(retry.NewStandard(), limitAttempts)
-			r = retry.AddWithMaxBackoffDelay(r, limitBackOffDelay)
+			r := retry.AddWithMaxAttempts(retry.NewStandard(), cfg.maxAttempts)
+			r = retry.AddWithMaxBackoffDelay(r, cfg.maxBackoffDelay)
 			r = retry.AddWithErrorCodes(r,
 				string(types.BatchStatementErrorCodeEnumItemCollectionSizeLimitExceeded),
 				string(types.BatchStatementErrorCodeEnumRequestLimitExceeded),
@@ -126,18 +173,19 @@ func newDynamoDBClient(
 		return nil, fmt.Errorf("unable to load SDK config, %w", err)
 	}
 	return dynamodb.NewFromConfig(awsCfg, func(o *dynamodb.Options) {
-		if traceEnabled {
-			awstrace.AppendMiddlewares(&o.APIOptions, traceProvider)
+		if len(cfg.transportOptions) > 0 {
+			o.HTTPClient = awstransport.ConfigureHTTPClient(o.HTTPClient, cfg.transportOptions...)
+		}
+		if cfg.traceEnabled {
+			awstrace.AppendMiddlewares(&o.APIOptions, cfg.traceProvider)
+		}
+		if cfg.metricsEnabled {
+			awsmetrics.AppendMiddlewares(&o.APIOptions, cfg.meterProvider)
 		}
 	}), nil
 }
 
-func getClientLocal(
-	ctx context.Context,
-	localProfile LocalProfile,
-	traceProvider oteltrace.TracerProvider,
-	traceEnabled bool,
-) (*dynamodb.Client, error) {
+func getClientLocal(ctx context.Context, localProfile LocalProfile, cfg clientConfig) (*dynamodb.Client, error) {
 	awsCfg, err := awsConfig.LoadDefaultConfig(ctx,
 		awsConfig.WithCredentialsProvider(credentials.StaticCredentialsProvider{
 			Value: aws.Credentials{
@@ -153,8 +201,14 @@ func getClientLocal(
 	}
 	return dynamodb.NewFromConfig(awsCfg, func(o *dynamodb.Options) {
 		o.BaseEndpoint = aws.String(localProfile.Endpoint)
-		if traceEnabled {
-			awstrace.AppendMiddlewares(&o.APIOptions, traceProvider)
+		if len(cfg.transportOptions) > 0 {
+			o.HTTPClient = awstransport.ConfigureHTTPClient(o.HTTPClient, cfg.transportOptions...)
+		}
+		if cfg.traceEnabled {
+			awstrace.AppendMiddlewares(&o.APIOptions, cfg.traceProvider)
+		}
+		if cfg.metricsEnabled {
+			awsmetrics.AppendMiddlewares(&o.APIOptions, cfg.meterProvider)
 		}
 	}), nil
 }
@@ -0,0 +1,26 @@
+package awsdynamo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/88labs/go-utils/aws/awsdynamo"
+	"github.com/88labs/go-utils/aws/awstransport"
+)
+
+func TestNewClient_WithTransport(t *testing.T) {
+	client, err := awsdynamo.NewClient(context.Background(), TestRegion, awsdynamo.WithTransport(
+		awstransport.WithDialTimeout(3*time.Second),
+		awstransport.WithMaxIdleConnsPerHost(64),
+	))
+	assert.NoError(t, err)
+	httpClient, ok := client.DynamoDBClient().Options().HTTPClient.(*awshttp.BuildableClient)
+	if assert.True(t, ok) {
+		assert.Equal(t, 3*time.Second, httpClient.GetDialer().Timeout)
+		assert.Equal(t, 64, httpClient.GetTransport().MaxIdleConnsPerHost)
+	}
+}
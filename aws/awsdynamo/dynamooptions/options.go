@@ -1,9 +1,16 @@
 package dynamooptions
 
 import (
+	"log/slog"
 	"time"
 
+	"go.opentelemetry.io/otel/metric"
 	oteltrace "go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/88labs/go-utils/aws/awscredentials"
+	"github.com/88labs/go-utils/aws/awstransport"
+	"github.com/88labs/go-utils/aws/internal/awslog"
 )
 
 type OptionDynamo interface {
@@ -15,6 +22,11 @@ type confDynamo struct {
 	MaxBackoffDelay time.Duration
 	traceProvider   oteltrace.TracerProvider
 	traceEnabled    bool
+	meterProvider   metric.MeterProvider
+	metricsEnabled  bool
+	transport       []awstransport.OptionTransport
+	credentials     []awscredentials.OptionCredentials
+	logger          *slog.Logger
 }
 
 type OptionMaxAttempts int
@@ -63,6 +75,92 @@ func (c confDynamo) TraceEnabled() bool {
 	return c.traceEnabled
 }
 
+type optionMetrics struct {
+	provider metric.MeterProvider
+}
+
+func (o optionMetrics) Apply(c *confDynamo) {
+	c.meterProvider = o.provider
+	c.metricsEnabled = true
+}
+
+// WithMetrics records OpenTelemetry metrics of the requests of the DynamoDB client: the number and the latency
+// of the operations, the retries, the throttling errors and the bytes transferred, labelled by service,
+// operation and status. A nil provider uses the globally configured provider.
+func WithMetrics(provider metric.MeterProvider) OptionDynamo {
+	return optionMetrics{provider: provider}
+}
+
+// MeterProvider returns the meter provider configured for the DynamoDB client.
+func (c confDynamo) MeterProvider() metric.MeterProvider {
+	return c.meterProvider
+}
+
+// MetricsEnabled reports whether metrics were explicitly enabled.
+func (c confDynamo) MetricsEnabled() bool {
+	return c.metricsEnabled
+}
+
+type optionTransport []awstransport.OptionTransport
+
+func (o optionTransport) Apply(c *confDynamo) {
+	c.transport = append(c.transport, o...)
+}
+
+// WithTransport configures the HTTP transport of the DynamoDB client: dialer, TLS, proxy,
+// connection pool, timeouts, HTTP/2 and RoundTripper hooks.
+// Settings that are not set keep the defaults of the AWS SDK.
+func WithTransport(opts ...awstransport.OptionTransport) OptionDynamo {
+	return optionTransport(opts)
+}
+
+// TransportOptions returns the transport options configured for the DynamoDB client.
+func (c confDynamo) TransportOptions() []awstransport.OptionTransport {
+	return c.transport
+}
+
+type optionCredentials []awscredentials.OptionCredentials
+
+func (o optionCredentials) Apply(c *confDynamo) {
+	c.credentials = append(c.credentials, o...)
+}
+
+// WithCredentials configures the credentials of the DynamoDB client: STS AssumeRole, web identity token files,
+// shared config profiles and custom credential providers. They are ignored for local endpoints.
+func WithCredentials(opts ...awscredentials.OptionCredentials) OptionDynamo {
+	return optionCredentials(opts)
+}
+
+// CredentialsOptions returns the credentials options configured for the DynamoDB client.
+func (c confDynamo) CredentialsOptions() []awscredentials.OptionCredentials {
+	return c.credentials
+}
+
+type optionLogger struct {
+	logger *slog.Logger
+}
+
+func (o optionLogger) Apply(c *confDynamo) {
+	c.logger = awslog.Normalize(o.logger)
+}
+
+// WithLogger emits structured logs of the DynamoDB operations via slog, instead of awsdynamo.GlobalLogger.
+// When logger is nil, a no-op logger is used.
+func WithLogger(logger *slog.Logger) OptionDynamo {
+	return optionLogger{logger: logger}
+}
+
+// WithZapLogger emits structured logs of the DynamoDB operations via a zap logger.
+// When logger is nil, a no-op logger is used.
+func WithZapLogger(logger *zap.Logger) OptionDynamo {
+	return optionLogger{logger: awslog.NewLoggerFromZap(logger)}
+}
+
+// Logger returns the logger configured for the DynamoDB operations, nil when none was configured.
+func (c confDynamo) Logger() *slog.Logger {
+	return c.logger
+}
+
 // nolint:revive
 func GetDynamoConf(opts ...OptionDynamo) confDynamo {
 	// default
@@ -0,0 +1,18 @@
+package awsdynamo
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/88labs/go-utils/aws/internal/awslog"
+)
+
+// logOperation logs an operation with logger, or with GlobalLogger when no logger was configured.
+func logOperation(
+	ctx context.Context, logger *slog.Logger, operation string, attrs ...slog.Attr,
+) func(err error, extra ...slog.Attr) {
+	if logger == nil {
+		logger = GlobalLogger
+	}
+	return awslog.LogOperation(ctx, logger, "awsdynamo", operation, attrs...)
+}
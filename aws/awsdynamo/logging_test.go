@@ -0,0 +1,125 @@
+package awsdynamo_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/88labs/go-utils/aws/awsconfig"
+	"github.com/88labs/go-utils/aws/awsdynamo"
+	"github.com/88labs/go-utils/aws/ctxawslocal"
+)
+
+type loggingTestItem struct {
+	ID string `dynamodbav:"id"`
+}
+
+// newLoggingTestContext returns a context whose DynamoDB endpoint returns an item and fails to put items.
+func newLoggingTestContext(t *testing.T) context.Context {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		if strings.HasSuffix(r.Header.Get("X-Amz-Target"), ".PutItem") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"table not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"Item":{"id":{"S":"1"}}}`))
+	}))
+	t.Cleanup(server.Close)
+	return ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithDynamoEndpoint(server.URL),
+		ctxawslocal.WithAccessKey("test"),
+		ctxawslocal.WithSecretAccessKey("test"),
+	)
+}
+
+func TestGetItem_WithLogger(t *testing.T) {
+	ctx := newLoggingTestContext(t)
+	var buf bytes.Buffer
+	item, err := awsdynamo.GetItem[loggingTestItem](ctx, awsconfig.RegionTokyo, "table", "id", "1",
+		awsdynamo.WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != "1" {
+		t.Fatalf("id = %q, want %q", item.ID, "1")
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &entry); err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range map[string]any{
+		"level":      "INFO",
+		"msg":        "awsdynamo operation completed",
+		"component":  "awsdynamo",
+		"operation":  "GetItem",
+		"table_name": "table",
+		"key":        "1",
+	} {
+		if entry[k] != want {
+			t.Errorf("%s = %v, want %v", k, entry[k], want)
+		}
+	}
+	if _, ok := entry["duration"]; !ok {
+		t.Error("duration is not logged")
+	}
+}
+
+func TestPutItem_WithZapLogger_logsFailure(t *testing.T) {
+	ctx := newLoggingTestContext(t)
+	core, observedLogs := observer.New(zap.InfoLevel)
+	if err := awsdynamo.PutItem(ctx, awsconfig.RegionTokyo, "missing", loggingTestItem{ID: "1"},
+		awsdynamo.WithZapLogger(zap.New(core))); err == nil {
+		t.Fatal("PutItem to a missing table succeeded")
+	}
+
+	entries := observedLogs.All()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(entries))
+	}
+	if entries[0].Level != zap.ErrorLevel || entries[0].Message != "awsdynamo operation failed" {
+		t.Fatalf("logged %v %q, want error %q", entries[0].Level, entries[0].Message, "awsdynamo operation failed")
+	}
+	fields := entries[0].ContextMap()
+	if fields["operation"] != "PutItem" || fields["table_name"] != "missing" {
+		t.Errorf("fields = %v", fields)
+	}
+	if _, ok := fields["error"]; !ok {
+		t.Error("error is not logged")
+	}
+}
+
+func TestGlobalLogger(t *testing.T) {
+	ctx := newLoggingTestContext(t)
+	var global bytes.Buffer
+	awsdynamo.GlobalLogger = slog.New(slog.NewJSONHandler(&global, nil))
+	t.Cleanup(func() { awsdynamo.GlobalLogger = nil })
+
+	if _, err := awsdynamo.GetItem[loggingTestItem](ctx, awsconfig.RegionTokyo, "table", "id", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(global.String(), `"operation":"GetItem"`) {
+		t.Fatalf("logs = %q", global.String())
+	}
+
+	// WithLogger takes precedence, and a nil logger disables logging.
+	global.Reset()
+	if _, err := awsdynamo.GetItem[loggingTestItem](ctx, awsconfig.RegionTokyo, "table", "id", "1",
+		awsdynamo.WithLogger(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if global.Len() != 0 {
+		t.Fatalf("a nil logger logged to GlobalLogger: %q", global.String())
+	}
+}
@@ -0,0 +1,33 @@
+package awsdynamo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/88labs/go-utils/aws/awsconfig"
+	"github.com/88labs/go-utils/aws/awsdynamo"
+	"github.com/88labs/go-utils/aws/awsdynamo/dynamooptions"
+)
+
+func TestRegistry_Client(t *testing.T) {
+	ctx := context.Background()
+	registry := awsdynamo.NewRegistry()
+
+	base, err := registry.Client(ctx, awsconfig.RegionTokyo)
+	assert.NoError(t, err)
+	for name, opt := range map[string]dynamooptions.OptionDynamo{
+		"WithMaxAttempts":     dynamooptions.WithMaxAttempts(5),
+		"WithMaxBackoffDelay": dynamooptions.WithMaxBackoffDelay(time.Minute),
+	} {
+		client, err := registry.Client(ctx, awsconfig.RegionTokyo, opt)
+		assert.NoError(t, err, name)
+		assert.NotSame(t, base, client, name)
+		again, err := registry.Client(ctx, awsconfig.RegionTokyo, opt)
+		assert.NoError(t, err, name)
+		assert.Same(t, client, again, name)
+	}
+	assert.Equal(t, 3, registry.Len())
+}
@@ -4,22 +4,37 @@ import (
 	"context"
 	"errors"
 	"io"
+	"iter"
 
 	"github.com/aws/aws-sdk-go-v2/feature/s3/transfermanager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 
 	"github.com/88labs/go-utils/aws/awsconfig"
+	"github.com/88labs/go-utils/aws/awss3/options/s3copy"
+	"github.com/88labs/go-utils/aws/awss3/options/s3delete"
 	"github.com/88labs/go-utils/aws/awss3/options/s3download"
 	"github.com/88labs/go-utils/aws/awss3/options/s3head"
 	"github.com/88labs/go-utils/aws/awss3/options/s3list"
+	"github.com/88labs/go-utils/aws/awss3/options/s3multipart"
 	"github.com/88labs/go-utils/aws/awss3/options/s3presigned"
+	"github.com/88labs/go-utils/aws/awss3/options/s3presignedpost"
+	"github.com/88labs/go-utils/aws/awss3/options/s3select"
 	"github.com/88labs/go-utils/aws/awss3/options/s3selectcsv"
+	"github.com/88labs/go-utils/aws/awss3/options/s3sync"
 	"github.com/88labs/go-utils/aws/awss3/options/s3upload"
+	"github.com/88labs/go-utils/aws/awss3/options/s3zip"
 )
 
 var ErrNotFound = errors.New("NotFound")
 
+// ErrPreconditionFailed is returned when a precondition of a request fails (HTTP 412),
+// e.g. an If-Match ETag does not match or If-None-Match: * finds an existing object.
+var ErrPreconditionFailed = errors.New("PreconditionFailed")
+
+// ErrNotModified is returned when a conditional read finds the object not modified (HTTP 304).
+var ErrNotModified = errors.New("NotModified")
+
 // PutObject
 // aws-sdk-go v2 PutObject
 // If there is no particular reason to use PutObject, please use UploadManager
@@ -71,6 +86,64 @@ func HeadObject(
 	return packageClientFromSDK(c).HeadObject(ctx, bucketName, key, opts...)
 }
 
+// WaitUntilObjectExists
+// Polls HeadObject until the object exists, with the Timeout, MinDelay, MaxDelay and LogWaitAttempts s3head options.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func WaitUntilObjectExists(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key, opts ...s3head.OptionS3Head,
+) (*s3.HeadObjectOutput, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).WaitUntilObjectExists(ctx, bucketName, key, opts...)
+}
+
+// WaitUntilObjectNotExists
+// Polls HeadObject until the object does not exist, with the same s3head options as WaitUntilObjectExists.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func WaitUntilObjectNotExists(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key, opts ...s3head.OptionS3Head,
+) error {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return err
+	}
+	return packageClientFromSDK(c).WaitUntilObjectNotExists(ctx, bucketName, key, opts...)
+}
+
+// WaitUntilObjectsExist
+// Polls HeadObject until all the objects exist, with the same s3head options as WaitUntilObjectExists.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func WaitUntilObjectsExist(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, keys Keys, opts ...s3head.OptionS3Head,
+) error {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return err
+	}
+	return packageClientFromSDK(c).WaitUntilObjectsExist(ctx, bucketName, keys, opts...)
+}
+
+// WaitUntilPrefixCount
+// Polls ListObjects until at least count objects exist under prefix, with the same s3head options as
+// WaitUntilObjectExists.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func WaitUntilPrefixCount(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, prefix string, count int,
+	opts ...s3head.OptionS3Head,
+) (Objects, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).WaitUntilPrefixCount(ctx, bucketName, prefix, count, opts...)
+}
+
 // ListObjects
 // aws-sdk-go v2 ListObjectsV2
 //
@@ -85,29 +158,115 @@ func ListObjects(
 	return packageClientFromSDK(c).ListObjects(ctx, bucketName, opts...)
 }
 
+// ListObjectsIter
+// aws-sdk-go v2 ListObjectsV2, streamed page by page as an iterator
+// A listing error is yielded once as the final element.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func ListObjectsIter(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, opts ...s3list.OptionS3List,
+) iter.Seq2[ListEntry, error] {
+	return func(yield func(ListEntry, error) bool) {
+		c, err := GetClient(ctx, region) // nolint:typecheck
+		if err != nil {
+			yield(ListEntry{}, err)
+			return
+		}
+		for entry, err := range packageClientFromSDK(c).ListObjectsIter(ctx, bucketName, opts...) {
+			if !yield(entry, err) {
+				return
+			}
+		}
+	}
+}
+
 // GetObjectWriter
 // aws-sdk-go v2 GetObject output io.Writer
 //
 // Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
-func GetObjectWriter(ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key, w io.Writer) error {
+func GetObjectWriter(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key, w io.Writer,
+	opts ...s3download.OptionS3Download,
+) error {
 	c, err := GetClient(ctx, region) // nolint:typecheck
 	if err != nil {
 		return err
 	}
-	return packageClientFromSDK(c).GetObjectWriter(ctx, bucketName, key, w)
+	return packageClientFromSDK(c).GetObjectWriter(ctx, bucketName, key, w, opts...)
+}
+
+// GetObjectRange retrieves length bytes of an object starting at offset with a ranged GET.
+// If length is 0 or negative, the object is read to the end. The caller must close the Body of the output.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func GetObjectRange(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key, offset, length int64,
+	opts ...s3download.OptionS3Download,
+) (*s3.GetObjectOutput, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).GetObjectRange(ctx, bucketName, key, offset, length, opts...)
+}
+
+// OpenObject opens an object as an io.ReadSeekCloser and io.ReaderAt backed by ranged GETs.
+// Reads fail with ErrObjectChanged if the object is overwritten after it is opened.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func OpenObject(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key,
+	opts ...s3download.OptionS3Download,
+) (*ObjectReader, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).OpenObject(ctx, bucketName, key, opts...)
 }
 
 // DeleteObject
 // aws-sdk-go v2 DeleteObject
 // Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
-func DeleteObject(ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key) (
-	*s3.DeleteObjectOutput, error,
-) {
+func DeleteObject(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key, opts ...s3delete.OptionS3Delete,
+) (*s3.DeleteObjectOutput, error) {
 	c, err := GetClient(ctx, region) // nolint:typecheck
 	if err != nil {
 		return nil, err
 	}
-	return packageClientFromSDK(c).DeleteObject(ctx, bucketName, key)
+	return packageClientFromSDK(c).DeleteObject(ctx, bucketName, key, opts...)
+}
+
+// DeleteObjects
+// aws-sdk-go v2 DeleteObjects
+// Delete keys in batches of 1,000 and report the result of each key
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func DeleteObjects(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, keys Keys,
+	opts ...s3delete.OptionS3Delete,
+) (DeleteResults, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).DeleteObjects(ctx, bucketName, keys, opts...)
+}
+
+// DeletePrefix
+// Delete all objects under the prefix by combining ListObjectsV2 and DeleteObjects
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func DeletePrefix(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, prefix string,
+	opts ...s3delete.OptionS3Delete,
+) (DeleteResults, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).DeletePrefix(ctx, bucketName, prefix, opts...)
 }
 
 // DownloadFiles
@@ -142,6 +301,100 @@ func DownloadFilesParallel(
 	return packageClientFromSDK(c).DownloadFilesParallel(ctx, bucketName, keys, outputDir, opts...)
 }
 
+// DownloadFilesParallelWithResults
+// Batch download objects on s3 and save to directory, returning a result per key
+// The files of the keys that succeeded are kept when other keys fail
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func DownloadFilesParallelWithResults(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, keys Keys, outputDir string,
+	opts ...s3download.OptionS3Download,
+) (DownloadResults, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).DownloadFilesParallelWithResults(ctx, bucketName, keys, outputDir, opts...)
+}
+
+// WriteZip
+// Stream objects on s3 into a zip archive written to w, with parallel prefetch and bounded memory
+// If the file name is duplicated, add a sequential number to the suffix
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func WriteZip(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, keys Keys, w io.Writer,
+	opts ...s3zip.OptionS3Zip,
+) ([]string, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).WriteZip(ctx, bucketName, keys, w, opts...)
+}
+
+// UploadZip
+// Stream objects on s3 into a zip archive uploaded to s3 with a multipart upload
+// If the file name is duplicated, add a sequential number to the suffix
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func UploadZip(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, keys Keys,
+	destBucketName BucketName, destKey Key, opts ...s3zip.OptionS3Zip,
+) ([]string, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).UploadZip(ctx, bucketName, keys, destBucketName, destKey, opts...)
+}
+
+// UploadDirectory
+// Upload every file under a local directory, keeping the relative paths as keys under the prefix
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func UploadDirectory(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, prefix string, localDir string,
+	opts ...s3sync.OptionS3Sync,
+) (SyncResults, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).UploadDirectory(ctx, bucketName, prefix, localDir, opts...)
+}
+
+// DownloadPrefix
+// Download every object under the prefix to a local directory, keeping the key hierarchy
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func DownloadPrefix(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, prefix string, localDir string,
+	opts ...s3sync.OptionS3Sync,
+) (SyncResults, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).DownloadPrefix(ctx, bucketName, prefix, localDir, opts...)
+}
+
+// Sync
+// Transfer only the new and changed files between a local directory and a prefix
+// Upload by default, use s3sync.WithDirection(s3sync.DirectionDownload) to download
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func Sync(
+	ctx context.Context, region awsconfig.Region, localDir string, bucketName BucketName, prefix string,
+	opts ...s3sync.OptionS3Sync,
+) (SyncResults, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).Sync(ctx, localDir, bucketName, prefix, opts...)
+}
+
 // Presign
 // aws-sdk-go v2 Presign
 // default expires is 15 minutes
@@ -172,11 +425,158 @@ func Copy(
 	return packageClientFromSDK(c).Copy(ctx, bucketName, srcKey, destKey, opts...)
 }
 
+// CopyObject copies an Amazon S3 object to another key, possibly in another bucket.
+// Objects larger than 5 GiB are copied with parallel UploadPartCopy requests.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func CopyObject(
+	ctx context.Context, region awsconfig.Region, srcBucketName BucketName, srcKey Key,
+	destBucketName BucketName, destKey Key, opts ...s3copy.OptionS3Copy,
+) (*CopyObjectResult, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).CopyObject(ctx, srcBucketName, srcKey, destBucketName, destKey, opts...)
+}
+
+// GetObjectTags returns the tags of an object.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func GetObjectTags(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key,
+) (map[string]string, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).GetObjectTags(ctx, bucketName, key)
+}
+
+// PutObjectTags replaces all tags of an object with tags.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func PutObjectTags(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key, tags map[string]string,
+) error {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return err
+	}
+	return packageClientFromSDK(c).PutObjectTags(ctx, bucketName, key, tags)
+}
+
+// DeleteObjectTags removes all tags of an object.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func DeleteObjectTags(ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key) error {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return err
+	}
+	return packageClientFromSDK(c).DeleteObjectTags(ctx, bucketName, key)
+}
+
+// ReplaceMetadata replaces the user metadata of an object in place by copying the object onto itself.
+// Content headers, tags, the storage class and the server-side encryption of the object are kept.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func ReplaceMetadata(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key, metadata map[string]string,
+) (*CopyObjectResult, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).ReplaceMetadata(ctx, bucketName, key, metadata)
+}
+
+// GetLifecycleRules returns the lifecycle rules of a bucket.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func GetLifecycleRules(ctx context.Context, region awsconfig.Region, bucketName BucketName) ([]LifecycleRule, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).GetLifecycleRules(ctx, bucketName)
+}
+
+// PutLifecycleRule adds rule to the lifecycle configuration of a bucket, or replaces the rule with the same ID.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func PutLifecycleRule(ctx context.Context, region awsconfig.Region, bucketName BucketName, rule LifecycleRule) error {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return err
+	}
+	return packageClientFromSDK(c).PutLifecycleRule(ctx, bucketName, rule)
+}
+
+// DeleteLifecycleRule removes the rule with id from the lifecycle configuration of a bucket.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func DeleteLifecycleRule(ctx context.Context, region awsconfig.Region, bucketName BucketName, id string) error {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return err
+	}
+	return packageClientFromSDK(c).DeleteLifecycleRule(ctx, bucketName, id)
+}
+
+// GetCORSRules returns the CORS rules of a bucket.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func GetCORSRules(ctx context.Context, region awsconfig.Region, bucketName BucketName) ([]types.CORSRule, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).GetCORSRules(ctx, bucketName)
+}
+
+// PutCORSRules replaces the CORS rules of a bucket with rules. Passing no rules deletes the CORS configuration.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func PutCORSRules(ctx context.Context, region awsconfig.Region, bucketName BucketName, rules []types.CORSRule) error {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return err
+	}
+	return packageClientFromSDK(c).PutCORSRules(ctx, bucketName, rules)
+}
+
 const (
 	SelectCSVAllQuery    = "SELECT * FROM S3Object"
 	SelectCSVLimit1Query = "SELECT * FROM S3Object LIMIT 1"
 )
 
+// SelectObject runs an S3 Select query on an object and yields the records decoded from JSON into T.
+// The object is read as JSON Lines by default, and an error is yielded once as the final element.
+// Records that cannot be decoded into T are yielded as errors.
+// SQL Reference : https://docs.aws.amazon.com/AmazonS3/latest/userguide/s3-glacier-select-sql-reference-select.html
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func SelectObject[T any](
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key, query string,
+	opts ...s3select.OptionS3Select,
+) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		c, err := GetClient(ctx, region) // nolint:typecheck
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		records := packageClientFromSDK(c).SelectObject(ctx, bucketName, key, query, opts...)
+		for v, err := range DecodeRecords[T](records) {
+			if !yield(v, err) {
+				return
+			}
+		}
+	}
+}
+
 // SelectCSVAll
 // SQL Reference : https://docs.aws.amazon.com/AmazonS3/latest/userguide/s3-glacier-select-sql-reference-select.html
 func SelectCSVAll(
@@ -215,6 +615,66 @@ func PresignPutObject(
 	return packageClientFromSDK(c).PresignPutObject(ctx, bucketName, key, opts...)
 }
 
+// PresignPutObjectRequest
+// Generate a presigned PUT request and the signed headers the uploader must send
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func PresignPutObjectRequest(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key,
+	opts ...s3presigned.OptionS3Presigned,
+) (*PresignedRequest, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).PresignPutObjectRequest(ctx, bucketName, key, opts...)
+}
+
+// PresignPostObject
+// Generate a presigned POST policy (URL and form fields) for uploading an object directly from a browser
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func PresignPostObject(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key,
+	opts ...s3presignedpost.OptionS3PresignedPost,
+) (*PresignedPost, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).PresignPostObject(ctx, bucketName, key, opts...)
+}
+
+// NewMultipartUpload initiates a managed multipart upload and returns an io.WriteCloser session.
+// The caller must call Close to complete the upload.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func NewMultipartUpload(
+	ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key,
+	opts ...s3multipart.OptionS3Multipart,
+) (*MultipartUpload, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).NewMultipartUpload(ctx, bucketName, key, opts...)
+}
+
+// ResumeMultipartUpload resumes a managed multipart upload from a state returned by MultipartUpload.State.
+// The caller must skip State().Offset() bytes of the source before writing the rest of the object.
+//
+// Mocks: Using ctxawslocal.WithContext, you can make requests for local mocks.
+func ResumeMultipartUpload(
+	ctx context.Context, region awsconfig.Region, state MultipartUploadState,
+	opts ...s3multipart.OptionS3Multipart,
+) (*MultipartUpload, error) {
+	c, err := GetClient(ctx, region) // nolint:typecheck
+	if err != nil {
+		return nil, err
+	}
+	return packageClientFromSDK(c).ResumeMultipartUpload(ctx, state, opts...)
+}
+
 // ref: https://docs.aws.amazon.com/AmazonS3/latest/API/API_CreateMultipartUpload.html
 func CreateMultipartUpload(
 	ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key, opts ...s3upload.OptionS3Upload,
@@ -223,32 +683,31 @@ func CreateMultipartUpload(
 	if err != nil {
 		return "", err
 	}
-	_ = opts // opts not used by the SDK call; kept for API compatibility
-	return packageClientFromSDK(c).CreateMultipartUpload(ctx, bucketName, key)
+	return packageClientFromSDK(c).CreateMultipartUpload(ctx, bucketName, key, opts...)
 }
 
 // ref: https://docs.aws.amazon.com/AmazonS3/latest/API/API_UploadPart.html
 func UploadPart(
 	ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key, uploadID string, partNumber int32,
-	body io.Reader,
+	body io.Reader, opts ...s3upload.OptionS3Upload,
 ) (*s3.UploadPartOutput, error) {
 	c, err := GetClient(ctx, region) // nolint:typecheck
 	if err != nil {
 		return nil, err
 	}
-	return packageClientFromSDK(c).UploadPart(ctx, bucketName, key, uploadID, partNumber, body)
+	return packageClientFromSDK(c).UploadPart(ctx, bucketName, key, uploadID, partNumber, body, opts...)
 }
 
 // ref: https://docs.aws.amazon.com/AmazonS3/latest/API/API_CompleteMultipartUpload.html
 func CompleteMultipartUpload(
 	ctx context.Context, region awsconfig.Region, bucketName BucketName, key Key, uploadID string,
-	completedParts []types.CompletedPart,
+	completedParts []types.CompletedPart, opts ...s3upload.OptionS3Upload,
 ) (*s3.CompleteMultipartUploadOutput, error) {
 	c, err := GetClient(ctx, region) // nolint:typecheck
 	if err != nil {
 		return nil, err
 	}
-	return packageClientFromSDK(c).CompleteMultipartUpload(ctx, bucketName, key, uploadID, completedParts)
+	return packageClientFromSDK(c).CompleteMultipartUpload(ctx, bucketName, key, uploadID, completedParts, opts...)
 }
 
 // ref: https://docs.aws.amazon.com/AmazonS3/latest/API/API_AbortMultipartUpload.html
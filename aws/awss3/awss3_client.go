@@ -7,11 +7,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"log/slog"
+	"maps"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,15 +26,17 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/smithy-go"
 	awshttp "github.com/aws/smithy-go/transport/http"
-	"github.com/cenkalti/backoff/v4"
 	"github.com/tomtwinkle/utfbomremover"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/text/transform"
 
+	"github.com/88labs/go-utils/aws/awss3/options/s3copy"
+	"github.com/88labs/go-utils/aws/awss3/options/s3delete"
 	"github.com/88labs/go-utils/aws/awss3/options/s3download"
 	"github.com/88labs/go-utils/aws/awss3/options/s3head"
 	"github.com/88labs/go-utils/aws/awss3/options/s3list"
 	"github.com/88labs/go-utils/aws/awss3/options/s3presigned"
+	"github.com/88labs/go-utils/aws/awss3/options/s3presignedpost"
 	"github.com/88labs/go-utils/aws/awss3/options/s3selectcsv"
 	"github.com/88labs/go-utils/aws/awss3/options/s3upload"
 )
@@ -54,17 +61,17 @@ func (c *Client) PutObject(
 		done(err)
 	}()
 
-	conf := s3upload.GetS3UploadConf(opts...)
 	input := &s3.PutObjectInput{
 		Body:   body,
 		Bucket: bucketName.AWSString(),
 		Key:    key.AWSString(),
 	}
-	if conf.S3Expires != nil {
-		input.Expires = aws.Time(time.Now().Add(*conf.S3Expires))
-	}
+	newUploadAttributes(opts...).applyPutObjectInput(input)
 	res, err = c.client.PutObject(ctx, input)
-	return res, err
+	if err != nil {
+		return nil, objectWriteError(err)
+	}
+	return res, nil
 }
 
 // UploadManager uploads an object using the transfer manager.
@@ -76,22 +83,27 @@ func (c *Client) UploadManager(
 		slog.String("bucket", bucketName.String()),
 		slog.String("key", key.String()),
 	)
+	progress := newTransferProgress(s3upload.GetS3UploadConf(opts...).ProgressListener, 1)
 	defer func() {
-		done(err)
+		done(err, progress.logAttrs()...)
 	}()
 
-	conf := s3upload.GetS3UploadConf(opts...)
-	uploader := transfermanager.New(c.client)
+	attrs := newUploadAttributes(opts...)
+	uploader := transfermanager.New(attrs.uploadClient(c.client))
 	input := &transfermanager.UploadObjectInput{
 		Body:   body,
 		Bucket: bucketName.AWSString(),
 		Key:    key.AWSString(),
 	}
-	if conf.S3Expires != nil {
-		input.Expires = aws.Time(time.Now().Add(*conf.S3Expires))
+	attrs.applyUploadObjectInput(input)
+	if err = c.encryptUploadObjectInput(ctx, input); err != nil {
+		return nil, err
 	}
-	res, err = uploader.UploadObject(ctx, input)
-	return res, err
+	res, err = uploader.UploadObject(ctx, input, progress.object(key))
+	if err != nil {
+		return nil, objectWriteError(err)
+	}
+	return res, nil
 }
 
 // HeadObject retrieves metadata from an object without returning the object itself.
@@ -114,72 +126,177 @@ func (c *Client) headObject(
 	ctx context.Context, bucketName BucketName, key Key, opts ...s3head.OptionS3Head,
 ) (*s3.HeadObjectOutput, error) {
 	conf := s3head.GetS3HeadConf(opts...)
+	input := newHeadObjectInput(bucketName, key, opts...)
 	if conf.Timeout > 0 {
 		waiter := s3.NewObjectExistsWaiter(c.client, func(options *s3.ObjectExistsWaiterOptions) {
 			options.MinDelay = conf.MinDelay
 			options.MaxDelay = conf.MaxDelay
 			options.LogWaitAttempts = conf.LogWaitAttempts
 		})
-		err := waiter.Wait(ctx, &s3.HeadObjectInput{
-			Bucket: bucketName.AWSString(),
-			Key:    key.AWSString(),
-		}, conf.Timeout)
+		err := waiter.Wait(ctx, input, conf.Timeout)
 		if err != nil {
 			return nil, fmt.Errorf("%w:%v", ErrNotFound, err)
 		}
 	}
-	res, err := c.client.HeadObject(
-		ctx,
-		&s3.HeadObjectInput{
-			Bucket: bucketName.AWSString(),
-			Key:    key.AWSString(),
-		})
+	return c.headObjectInput(ctx, input)
+}
+
+// newHeadObjectInput returns a HeadObject request with the conditions of opts.
+func newHeadObjectInput(bucketName BucketName, key Key, opts ...s3head.OptionS3Head) *s3.HeadObjectInput {
+	conf := s3head.GetS3HeadConf(opts...)
+	return &s3.HeadObjectInput{
+		Bucket:          bucketName.AWSString(),
+		Key:             key.AWSString(),
+		IfMatch:         conf.IfMatch,
+		IfNoneMatch:     conf.IfNoneMatch,
+		IfModifiedSince: conf.IfModifiedSince,
+		VersionId:       conf.VersionID,
+	}
+}
+
+func (c *Client) headObjectInput(ctx context.Context, input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	res, err := c.client.HeadObject(ctx, input)
 	if err != nil {
-		var nond *types.NotFound
-		if errors.As(err, &nond) {
-			return nil, ErrNotFound
-		}
-		return nil, err
+		return nil, objectReadError(err)
 	}
 	return res, nil
 }
 
 // ListObjects lists objects in a bucket.
+// All pages are read before returning. Use ListObjectsIter for large prefixes.
 func (c *Client) ListObjects(
 	ctx context.Context, bucketName BucketName, opts ...s3list.OptionS3List,
 ) (objects Objects, err error) {
-	conf := s3list.GetS3ListConf(opts...)
-	attrs := []slog.Attr{
-		slog.String("bucket", bucketName.String()),
-	}
-	if conf.Prefix != nil {
-		attrs = append(attrs, slog.String("prefix", *conf.Prefix))
-	}
-	done := c.logOperation(ctx, "ListObjects", attrs...)
+	input, pageCallback := newListObjectsV2Input(bucketName, opts...)
+	done := c.logOperation(ctx, "ListObjects", listLogAttrs(input)...)
 	defer func() {
 		done(err, slog.Int("object_count", len(objects)))
 	}()
 
-	input := &s3.ListObjectsV2Input{
-		Bucket: bucketName.AWSString(),
+	objects = make(Objects, 0)
+	err = c.listObjectsPages(ctx, input, pageCallback, func(output *s3.ListObjectsV2Output) bool {
+		objects = append(objects, output.Contents...)
+		return true
+	})
+	if err != nil {
+		return nil, err
 	}
-	if conf.Prefix != nil {
-		input.Prefix = conf.Prefix
+	return objects, nil
+}
+
+// ListObjectsIter lists objects in a bucket page by page and yields them one at a time.
+// Only a single page is held in memory, and breaking out of the loop stops further requests.
+// With s3list.WithDelimiter, common prefixes are yielded in key order alongside the objects.
+// A listing error is yielded once as the final element.
+func (c *Client) ListObjectsIter(
+	ctx context.Context, bucketName BucketName, opts ...s3list.OptionS3List,
+) iter.Seq2[ListEntry, error] {
+	return func(yield func(ListEntry, error) bool) {
+		input, pageCallback := newListObjectsV2Input(bucketName, opts...)
+		done := c.logOperation(ctx, "ListObjectsIter", listLogAttrs(input)...)
+		var (
+			err         error
+			objectCount int
+			prefixCount int
+		)
+		defer func() {
+			done(err, slog.Int("object_count", objectCount), slog.Int("common_prefix_count", prefixCount))
+		}()
+
+		err = c.listObjectsPages(ctx, input, pageCallback, func(output *s3.ListObjectsV2Output) bool {
+			for _, entry := range mergeListEntries(output.Contents, output.CommonPrefixes) {
+				if entry.IsCommonPrefix() {
+					prefixCount++
+				} else {
+					objectCount++
+				}
+				if !yield(entry, nil) {
+					return false
+				}
+			}
+			return true
+		})
+		if err != nil {
+			yield(ListEntry{}, err)
+		}
 	}
-	objects = make(Objects, 0)
+}
+
+func newListObjectsV2Input(
+	bucketName BucketName, opts ...s3list.OptionS3List,
+) (*s3.ListObjectsV2Input, s3list.PageCallbackFunc) {
+	conf := s3list.GetS3ListConf(opts...)
+	input := &s3.ListObjectsV2Input{
+		Bucket:     bucketName.AWSString(),
+		Prefix:     conf.Prefix,
+		Delimiter:  conf.Delimiter,
+		StartAfter: conf.StartAfter,
+		MaxKeys:    conf.MaxKeys,
+	}
+	return input, conf.PageCallback
+}
+
+// listObjectsPages calls fn with every ListObjectsV2 page until fn returns false.
+func (c *Client) listObjectsPages(
+	ctx context.Context, input *s3.ListObjectsV2Input, pageCallback s3list.PageCallbackFunc,
+	fn func(output *s3.ListObjectsV2Output) bool,
+) error {
 	paginator := s3.NewListObjectsV2Paginator(c.client, input)
 	for paginator.HasMorePages() {
 		output, err := paginator.NextPage(ctx)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if pageCallback != nil {
+			if err := pageCallback(output); err != nil {
+				return err
+			}
+		}
+		if !fn(output) {
+			return nil
 		}
-		objects = append(objects, output.Contents...)
 	}
-	return objects, nil
+	return nil
+}
+
+// mergeListEntries merges the objects and common prefixes of a page in key order.
+// S3 returns both lists sorted, so a single merge pass is enough.
+func mergeListEntries(objects []types.Object, prefixes []types.CommonPrefix) []ListEntry {
+	entries := make([]ListEntry, 0, len(objects)+len(prefixes))
+	i, j := 0, 0
+	for i < len(objects) || j < len(prefixes) {
+		if j >= len(prefixes) ||
+			(i < len(objects) && aws.ToString(objects[i].Key) < aws.ToString(prefixes[j].Prefix)) {
+			entries = append(entries, ListEntry{Object: objects[i]})
+			i++
+			continue
+		}
+		entries = append(entries, ListEntry{CommonPrefix: prefixes[j].Prefix})
+		j++
+	}
+	return entries
+}
+
+func listLogAttrs(input *s3.ListObjectsV2Input) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("bucket", aws.ToString(input.Bucket)),
+	}
+	if input.Prefix != nil {
+		attrs = append(attrs, slog.String("prefix", *input.Prefix))
+	}
+	if input.Delimiter != nil {
+		attrs = append(attrs, slog.String("delimiter", *input.Delimiter))
+	}
+	if input.StartAfter != nil {
+		attrs = append(attrs, slog.String("start_after", *input.StartAfter))
+	}
+	return attrs
 }
 
 // GetObjectWriter downloads an object and writes its content to w.
-func (c *Client) GetObjectWriter(ctx context.Context, bucketName BucketName, key Key, w io.Writer) (err error) {
+func (c *Client) GetObjectWriter(
+	ctx context.Context, bucketName BucketName, key Key, w io.Writer, opts ...s3download.OptionS3Download,
+) (err error) {
 	done := c.logOperation(ctx, "GetObjectWriter",
 		slog.String("bucket", bucketName.String()),
 		slog.String("key", key.String()),
@@ -188,19 +305,27 @@ func (c *Client) GetObjectWriter(ctx context.Context, bucketName BucketName, key
 		done(err)
 	}()
 
-	if _, err = c.headObject(ctx, bucketName, key); err != nil {
+	conf := s3download.GetS3DownloadConf(opts...)
+	if _, err = c.headObjectInput(ctx, &s3.HeadObjectInput{
+		Bucket:          bucketName.AWSString(),
+		Key:             key.AWSString(),
+		IfMatch:         conf.IfMatch,
+		IfNoneMatch:     conf.IfNoneMatch,
+		IfModifiedSince: conf.IfModifiedSince,
+		VersionId:       conf.VersionID,
+	}); err != nil {
 		return err
 	}
 	resp, err := c.client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: bucketName.AWSString(),
-		Key:    key.AWSString(),
+		Bucket:          bucketName.AWSString(),
+		Key:             key.AWSString(),
+		IfMatch:         conf.IfMatch,
+		IfNoneMatch:     conf.IfNoneMatch,
+		IfModifiedSince: conf.IfModifiedSince,
+		VersionId:       conf.VersionID,
 	})
 	if err != nil {
-		var nond *types.NotFound
-		if errors.As(err, &nond) {
-			return ErrNotFound
-		}
-		return err
+		return objectReadError(err)
 	}
 	defer func() {
 		if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
@@ -211,8 +336,57 @@ func (c *Client) GetObjectWriter(ctx context.Context, bucketName BucketName, key
 	return err
 }
 
+// GetObjectRange retrieves length bytes of an object starting at offset with a ranged GET.
+// If length is 0 or negative, the object is read to the end.
+// The caller must close the Body of the output; ContentRange holds the range actually returned.
+func (c *Client) GetObjectRange(
+	ctx context.Context, bucketName BucketName, key Key, offset, length int64,
+	opts ...s3download.OptionS3Download,
+) (*s3.GetObjectOutput, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid range offset: %d", offset)
+	}
+	conf := s3download.GetS3DownloadConf(opts...)
+	return c.getObjectRange(ctx, &s3.GetObjectInput{
+		Bucket:          bucketName.AWSString(),
+		Key:             key.AWSString(),
+		Range:           aws.String(httpRange(offset, length)),
+		IfMatch:         conf.IfMatch,
+		IfNoneMatch:     conf.IfNoneMatch,
+		IfModifiedSince: conf.IfModifiedSince,
+		VersionId:       conf.VersionID,
+	})
+}
+
+func (c *Client) getObjectRange(ctx context.Context, input *s3.GetObjectInput) (res *s3.GetObjectOutput, err error) {
+	done := c.logOperation(ctx, "GetObjectRange",
+		slog.String("bucket", aws.ToString(input.Bucket)),
+		slog.String("key", aws.ToString(input.Key)),
+		slog.String("range", aws.ToString(input.Range)),
+	)
+	defer func() {
+		done(err)
+	}()
+
+	res, err = c.client.GetObject(ctx, input)
+	if err != nil {
+		return nil, objectReadError(err)
+	}
+	return res, nil
+}
+
+// httpRange returns the HTTP Range header value for length bytes from offset.
+func httpRange(offset, length int64) string {
+	if length <= 0 {
+		return fmt.Sprintf("bytes=%d-", offset)
+	}
+	return fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+}
+
 // DeleteObject deletes an object from a bucket.
-func (c *Client) DeleteObject(ctx context.Context, bucketName BucketName, key Key) (
+func (c *Client) DeleteObject(
+	ctx context.Context, bucketName BucketName, key Key, opts ...s3delete.OptionS3Delete,
+) (
 	res *s3.DeleteObjectOutput, err error,
 ) {
 	done := c.logOperation(ctx, "DeleteObject",
@@ -223,15 +397,173 @@ func (c *Client) DeleteObject(ctx context.Context, bucketName BucketName, key Ke
 		done(err)
 	}()
 
-	if _, err = c.headObject(ctx, bucketName, key); err != nil {
+	conf := s3delete.GetS3DeleteConf(opts...)
+	if _, err = c.headObjectInput(ctx, &s3.HeadObjectInput{
+		Bucket:    bucketName.AWSString(),
+		Key:       key.AWSString(),
+		IfMatch:   conf.IfMatch,
+		VersionId: conf.VersionID,
+	}); err != nil {
 		return nil, err
 	}
 	input := &s3.DeleteObjectInput{
-		Bucket: bucketName.AWSString(),
-		Key:    key.AWSString(),
+		Bucket:    bucketName.AWSString(),
+		Key:       key.AWSString(),
+		IfMatch:   conf.IfMatch,
+		VersionId: conf.VersionID,
 	}
 	res, err = c.client.DeleteObject(ctx, input)
-	return res, err
+	if err != nil {
+		return nil, objectWriteError(err)
+	}
+	return res, nil
+}
+
+// maxDeleteObjectsKeys is the maximum number of keys accepted by a single DeleteObjects request.
+const maxDeleteObjectsKeys = 1000
+
+// DeleteObjects deletes multiple objects from a bucket.
+// Keys are split into DeleteObjects requests of up to 1,000 keys, which are sent concurrently
+// up to s3delete.WithConcurrency. Unlike DeleteObject, keys are not checked with HeadObject first,
+// and deleting a key that does not exist succeeds.
+//
+// The results are returned in the order of the unique keys, one per key.
+// err joins the errors of all failed keys and is nil when every key was deleted.
+func (c *Client) DeleteObjects(
+	ctx context.Context, bucketName BucketName, keys Keys, opts ...s3delete.OptionS3Delete,
+) (results DeleteResults, err error) {
+	conf := s3delete.GetS3DeleteConf(opts...)
+	done := c.logOperation(ctx, "DeleteObjects",
+		slog.String("bucket", bucketName.String()),
+		slog.Int("key_count", len(keys)),
+		slog.Bool("dry_run", conf.DryRun),
+	)
+	defer func() {
+		done(err, slog.Int("deleted_count", len(results.Deleted())), slog.Int("failed_count", len(results.Failed())))
+	}()
+
+	batcher := c.newDeleteBatcher(ctx, bucketName, conf.Concurrency, conf.DryRun)
+	for chunk := range slices.Chunk(keys.Unique(), maxDeleteObjectsKeys) {
+		batcher.add(chunk)
+	}
+	results = batcher.wait()
+	return results, results.Err()
+}
+
+// DeletePrefix deletes every object whose key begins with prefix.
+// Objects are listed page by page and each page is deleted with DeleteObjects while
+// listing continues, so the whole prefix is never held in memory at once.
+// With s3delete.WithDryRun, the matching keys are returned without being deleted.
+//
+// An empty prefix is rejected to avoid emptying the whole bucket by mistake.
+// err joins the listing error and the errors of all failed keys.
+func (c *Client) DeletePrefix(
+	ctx context.Context, bucketName BucketName, prefix string, opts ...s3delete.OptionS3Delete,
+) (results DeleteResults, err error) {
+	conf := s3delete.GetS3DeleteConf(opts...)
+	done := c.logOperation(ctx, "DeletePrefix",
+		slog.String("bucket", bucketName.String()),
+		slog.String("prefix", prefix),
+		slog.Bool("dry_run", conf.DryRun),
+	)
+	defer func() {
+		done(err, slog.Int("deleted_count", len(results.Deleted())), slog.Int("failed_count", len(results.Failed())))
+	}()
+
+	if prefix == "" {
+		return nil, errors.New("awss3: DeletePrefix requires a non-empty prefix")
+	}
+	batcher := c.newDeleteBatcher(ctx, bucketName, conf.Concurrency, conf.DryRun)
+	input, _ := newListObjectsV2Input(bucketName, s3list.WithPrefix(prefix))
+	listErr := c.listObjectsPages(ctx, input, nil, func(output *s3.ListObjectsV2Output) bool {
+		keys := make(Keys, 0, len(output.Contents))
+		for _, v := range output.Contents {
+			keys = append(keys, Key(aws.ToString(v.Key)))
+		}
+		if len(keys) > 0 {
+			batcher.add(keys)
+		}
+		return true
+	})
+	results = batcher.wait()
+	return results, errors.Join(listErr, results.Err())
+}
+
+// deleteBatcher sends DeleteObjects requests concurrently and collects per-key results.
+type deleteBatcher struct {
+	ctx        context.Context
+	client     *Client
+	bucketName BucketName
+	dryRun     bool
+	eg         errgroup.Group
+	chunks     []DeleteResults
+}
+
+func (c *Client) newDeleteBatcher(
+	ctx context.Context, bucketName BucketName, concurrency int, dryRun bool,
+) *deleteBatcher {
+	b := &deleteBatcher{
+		ctx:        ctx,
+		client:     c,
+		bucketName: bucketName,
+		dryRun:     dryRun,
+	}
+	b.eg.SetLimit(concurrency)
+	return b
+}
+
+// add schedules the deletion of up to 1,000 keys. It blocks while the concurrency limit is reached.
+func (b *deleteBatcher) add(keys Keys) {
+	results := make(DeleteResults, len(keys))
+	for i, k := range keys {
+		results[i] = DeleteResult{Key: k}
+	}
+	b.chunks = append(b.chunks, results)
+	if b.dryRun {
+		return
+	}
+	b.eg.Go(func() error {
+		b.client.deleteObjectsChunk(b.ctx, b.bucketName, results)
+		return nil
+	})
+}
+
+// wait waits for all scheduled requests and returns the results in the order they were added.
+func (b *deleteBatcher) wait() DeleteResults {
+	_ = b.eg.Wait()
+	return slices.Concat(b.chunks...)
+}
+
+// deleteObjectsChunk deletes the keys of results with a single DeleteObjects request
+// and records the outcome of each key in results.
+func (c *Client) deleteObjectsChunk(ctx context.Context, bucketName BucketName, results DeleteResults) {
+	objects := make([]types.ObjectIdentifier, len(results))
+	for i, v := range results {
+		objects[i] = types.ObjectIdentifier{Key: v.Key.AWSString()}
+	}
+	res, err := c.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+		Bucket: bucketName.AWSString(),
+		Delete: &types.Delete{
+			Objects: objects,
+			Quiet:   aws.Bool(true),
+		},
+	})
+	if err != nil {
+		for i := range results {
+			results[i].Err = err
+		}
+		return
+	}
+	failed := make(map[Key]error, len(res.Errors))
+	for _, v := range res.Errors {
+		failed[Key(aws.ToString(v.Key))] = &DeleteObjectError{
+			Code:    aws.ToString(v.Code),
+			Message: aws.ToString(v.Message),
+		}
+	}
+	for i, v := range results {
+		results[i].Err = failed[v.Key]
+	}
 }
 
 // DownloadFiles downloads multiple objects and saves them to a directory.
@@ -245,14 +577,15 @@ func (c *Client) DownloadFiles(
 		slog.Int("key_count", len(keys)),
 		slog.String("output_dir", outputDir),
 	)
+	conf := s3download.GetS3DownloadConf(opts...)
+	uniqKeys := keys.Unique()
+	progress := newTransferProgress(conf.ProgressListener, len(uniqKeys))
 	downloadedCount := 0
 	defer func() {
-		done(err, slog.Int("downloaded_file_count", downloadedCount))
+		done(err, append(progress.logAttrs(), slog.Int("downloaded_file_count", downloadedCount))...)
 	}()
 
-	conf := s3download.GetS3DownloadConf(opts...)
-	uniqKeys := keys.Unique()
-	downloader := transfermanager.New(c.client, func(o *transfermanager.Options) {
+	downloader := transfermanager.New(c.client, c.downloadOptions, func(o *transfermanager.Options) {
 		o.GetObjectBufferSize = 5 * 1024 * 1024
 	})
 	paths = make([]string, len(uniqKeys))
@@ -291,20 +624,14 @@ func (c *Client) DownloadFiles(
 			Bucket:   bucketName.AWSString(),
 			Key:      s3Key.AWSString(),
 			WriterAt: f,
-		})
+		}, progress.object(s3Key))
 		if closeErr := f.Close(); closeErr != nil && dlErr == nil {
 			dlErr = closeErr
 		}
 		if dlErr != nil {
 			_ = os.Remove(filePath)
-			var oe *smithy.OperationError
-			if errors.As(dlErr, &oe) {
-				var resErr *awshttp.ResponseError
-				if errors.As(oe.Err, &resErr) {
-					if resErr.Response.StatusCode == http.StatusNotFound {
-						return nil, ErrNotFound
-					}
-				}
+			if isNotFoundError(dlErr) {
+				return nil, ErrNotFound
 			}
 			return nil, dlErr
 		}
@@ -315,6 +642,9 @@ func (c *Client) DownloadFiles(
 
 // DownloadFilesParallel downloads multiple objects in parallel and saves them to a directory.
 // If the file name is duplicated, a sequential number is added to the suffix.
+// Up to s3download.WithConcurrency objects are downloaded at the same time.
+// When a key fails, the remaining downloads are canceled and only the error is returned.
+// Use DownloadFilesParallelWithResults to get a result per key instead.
 func (c *Client) DownloadFilesParallel(
 	ctx context.Context, bucketName BucketName, keys Keys, outputDir string,
 	opts ...s3download.OptionS3Download,
@@ -324,99 +654,40 @@ func (c *Client) DownloadFilesParallel(
 		slog.Int("key_count", len(keys)),
 		slog.String("output_dir", outputDir),
 	)
+	conf := s3download.GetS3DownloadConf(opts...)
+	uniqKeys := keys.Unique()
+	progress := newTransferProgress(conf.ProgressListener, len(uniqKeys))
 	downloadedCount := 0
 	defer func() {
-		done(err, slog.Int("downloaded_file_count", downloadedCount))
+		done(err, append(progress.logAttrs(), slog.Int("downloaded_file_count", downloadedCount))...)
 	}()
 
-	conf := s3download.GetS3DownloadConf(opts...)
-	uniqKeys := keys.Unique()
-	downloader := transfermanager.New(c.client, func(o *transfermanager.Options) {
+	// the downloads are retried as a whole, so their requests are not retried again
+	downloader := transfermanager.New(singleRetryClient{c.client}, c.downloadOptions, func(o *transfermanager.Options) {
 		o.GetObjectBufferSize = 5 * 1024 * 1024
 	})
-	paths = make([]string, len(uniqKeys))
-
-	resolveFilePath := func(s3Key string) string {
-		fileName := filepath.Base(s3Key)
-		if conf.FileNameReplacer != nil {
-			fileName = conf.FileNameReplacer(s3Key, fileName)
-		}
-		filePath := path.Join(outputDir, fileName)
-		var existsFileCount int
-		for {
-			if existsFileCount > 0 {
-				ext := filepath.Ext(fileName)
-				newFileName := fmt.Sprintf("%s_%d%s", strings.TrimSuffix(fileName, ext), existsFileCount+1, ext)
-				filePath = path.Join(outputDir, newFileName)
-			}
-			if _, err := os.Stat(filePath); err != nil {
-				break
-			}
-			existsFileCount++
-		}
-		return filePath
-	}
-
-	files := make([]*os.File, len(uniqKeys))
-	for i, s3Key := range uniqKeys {
-		filePath := resolveFilePath(s3Key.String())
-		paths[i] = filePath
-		f, err := os.Create(filePath)
-		if err != nil {
-			for j := 0; j < i; j++ {
-				_ = files[j].Close()
-				_ = os.Remove(paths[j])
-			}
-			return nil, err
-		}
-		files[i] = f
-	}
+	// the paths are reserved up front, while the files are created by the downloads,
+	// so that at most Concurrency files are open at a time
+	paths = downloadFilePaths(uniqKeys, outputDir, conf.FileNameReplacer, true)
 
 	var eg errgroup.Group
+	eg.SetLimit(conf.Concurrency)
 	egCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
-	for i := range uniqKeys {
-		i := i
-		s3Key := uniqKeys[i]
-		f := files[i]
+	for i, s3Key := range uniqKeys {
 		filePath := paths[i]
 		eg.Go(func() error {
-			var gErr error
-			b := backoff.WithContext(backoff.NewExponentialBackOff(), egCtx)
-			dlErr := backoff.Retry(func() error {
-				if _, err := downloader.DownloadObject(egCtx, &transfermanager.DownloadObjectInput{
-					Bucket:   bucketName.AWSString(),
-					Key:      s3Key.AWSString(),
-					WriterAt: f,
-				}); err != nil {
-					var oe *smithy.OperationError
-					if errors.As(err, &oe) {
-						var resErr *awshttp.ResponseError
-						if errors.As(oe.Err, &resErr) {
-							switch resErr.Response.StatusCode {
-							case http.StatusNotFound:
-								gErr = ErrNotFound
-								cancel()
-							case http.StatusBadRequest, http.StatusUnauthorized, http.StatusPaymentRequired,
-								http.StatusForbidden, http.StatusMethodNotAllowed, http.StatusUnprocessableEntity,
-								http.StatusMisdirectedRequest, http.StatusRequestURITooLong, http.StatusRequestEntityTooLarge,
-								http.StatusPreconditionFailed:
-								gErr = resErr.Err
-								cancel()
-							}
-						}
-					}
-					return err
-				}
-				return nil
-			}, b)
-			if closeErr := f.Close(); closeErr != nil && dlErr == nil {
-				dlErr = closeErr
+			if err := egCtx.Err(); err != nil {
+				return err
 			}
+			objectProgress := progress.object(s3Key)
+			dlErr := c.retryer.do(egCtx, "DownloadFilesParallel", func(ctx context.Context) error {
+				return downloadFile(ctx, downloader, bucketName, s3Key, filePath, time.Time{}, objectProgress)
+			})
 			if dlErr != nil {
-				_ = os.Remove(filePath)
-				if gErr != nil {
-					return gErr
+				cancel()
+				if errors.Is(dlErr, ErrNotFound) || isNotFoundError(dlErr) {
+					return ErrNotFound
 				}
 				return dlErr
 			}
@@ -445,12 +716,17 @@ func (c *Client) Presign(
 		done(err, slog.Duration("expires", conf.PresignExpires))
 	}()
 
-	if _, err = c.headObject(ctx, bucketName, key); err != nil {
+	if _, err = c.headObjectInput(ctx, &s3.HeadObjectInput{
+		Bucket:    bucketName.AWSString(),
+		Key:       key.AWSString(),
+		VersionId: conf.VersionID,
+	}); err != nil {
 		return "", err
 	}
 	input := &s3.GetObjectInput{
-		Bucket: bucketName.AWSString(),
-		Key:    key.AWSString(),
+		Bucket:    bucketName.AWSString(),
+		Key:       key.AWSString(),
+		VersionId: conf.VersionID,
 	}
 	if conf.PresignFileName != "" {
 		input.ResponseContentDisposition = aws.String(ResponseContentDisposition(conf.ContentDispositionType,
@@ -459,7 +735,7 @@ func (c *Client) Presign(
 	if key.Ext() == ".pdf" {
 		input.ResponseContentType = aws.String("application/pdf")
 	}
-	ps := s3.NewPresignClient(c.client)
+	ps := s3.NewPresignClient(c.client, s3.WithPresignClientFromClientOptions(withoutEncryption))
 	resp, err := ps.PresignGetObject(ctx, input, func(o *s3.PresignOptions) {
 		o.Expires = conf.PresignExpires
 	})
@@ -471,6 +747,7 @@ func (c *Client) Presign(
 }
 
 // Copy copies an Amazon S3 object within the same bucket.
+// Use CopyObject to copy between buckets or to copy objects larger than 5 GiB.
 func (c *Client) Copy(
 	ctx context.Context, bucketName BucketName, srcKey, destKey Key,
 	opts ...s3upload.OptionS3Upload,
@@ -484,29 +761,266 @@ func (c *Client) Copy(
 		done(err)
 	}()
 
-	conf := s3upload.GetS3UploadConf(opts...)
 	req := &s3.CopyObjectInput{
 		Bucket:            bucketName.AWSString(),
 		CopySource:        srcKey.bucketJoinEscapedAWSString(bucketName),
 		Key:               destKey.AWSString(),
 		MetadataDirective: types.MetadataDirectiveReplace,
 	}
-	if conf.S3Expires != nil {
-		req.Expires = aws.Time(time.Now().Add(*conf.S3Expires))
+	newUploadAttributes(opts...).applyCopyObjectInput(req)
+	if c.encryption != nil {
+		// the metadata is replaced, so the envelope of an encrypted object is copied explicitly
+		head, err := c.headObject(ctx, bucketName, srcKey)
+		if err != nil {
+			return err
+		}
+		req.Metadata = withEncryptionEnvelope(req.Metadata, head.Metadata)
 	}
 	if _, err := c.client.CopyObject(ctx, req); err != nil {
-		var oe *smithy.OperationError
-		if errors.As(err, &oe) {
-			var resErr *awshttp.ResponseError
-			if errors.As(oe.Err, &resErr) {
-				if resErr.Response.StatusCode == http.StatusNotFound {
-					return ErrNotFound
-				}
+		if isNotFoundError(err) {
+			return ErrNotFound
+		}
+		return objectWriteError(err)
+	}
+	return nil
+}
+
+// maxUploadParts is the maximum number of parts in a multipart upload.
+const maxUploadParts = 10000
+
+// CopyObject copies an object to another key, possibly in another bucket.
+// Objects larger than s3copy.WithMultipartThreshold (5 GiB by default, the CopyObject limit)
+// are copied with parallel UploadPartCopy requests.
+//
+// By default, metadata, content headers, tags and the storage class are copied from the source object.
+// Use s3copy.WithMetadata, s3copy.WithContentType, s3copy.WithTags and s3copy.WithStorageClass to replace them.
+// Every request is conditioned on the source ETag, so the copy fails if the source
+// object changes while it is being copied.
+func (c *Client) CopyObject(
+	ctx context.Context, srcBucketName BucketName, srcKey Key, destBucketName BucketName, destKey Key,
+	opts ...s3copy.OptionS3Copy,
+) (res *CopyObjectResult, err error) {
+	done := c.logOperation(ctx, "CopyObject",
+		slog.String("src_bucket", srcBucketName.String()),
+		slog.String("src_key", srcKey.String()),
+		slog.String("dest_bucket", destBucketName.String()),
+		slog.String("dest_key", destKey.String()),
+	)
+	defer func() {
+		if res != nil {
+			done(err, slog.Int("part_count", res.PartCount))
+			return
+		}
+		done(err)
+	}()
+
+	conf := s3copy.GetS3CopyConf(opts...)
+	head, err := c.headObject(ctx, srcBucketName, srcKey)
+	if err != nil {
+		return nil, err
+	}
+	copySource := srcKey.bucketJoinEscapedAWSString(srcBucketName)
+	var tagging *string
+	if conf.TaggingDirective == types.TaggingDirectiveReplace && len(conf.Tags) > 0 {
+		tagging = aws.String(encodeTags(conf.Tags))
+	}
+
+	storageClass := conf.StorageClass
+	if storageClass == "" {
+		storageClass = head.StorageClass
+	}
+
+	size := c.storedSize(head)
+	if size <= conf.MultipartThreshold {
+		input := &s3.CopyObjectInput{
+			Bucket:            destBucketName.AWSString(),
+			Key:               destKey.AWSString(),
+			CopySource:        copySource,
+			CopySourceIfMatch: head.ETag,
+			MetadataDirective: conf.MetadataDirective,
+			TaggingDirective:  conf.TaggingDirective,
+			Tagging:           tagging,
+			StorageClass:      storageClass,
+		}
+		if conf.MetadataDirective == types.MetadataDirectiveReplace {
+			input.Metadata = withEncryptionEnvelope(conf.Metadata, head.Metadata)
+			input.ContentType = conf.ContentType
+		}
+		out, err := c.client.CopyObject(ctx, input)
+		if err != nil {
+			if isNotFoundError(err) {
+				return nil, ErrNotFound
 			}
+			return nil, err
 		}
+		res = &CopyObjectResult{VersionID: aws.ToString(out.VersionId)}
+		if out.CopyObjectResult != nil {
+			res.ETag = aws.ToString(out.CopyObjectResult.ETag)
+		}
+		return res, nil
+	}
+
+	// UploadPartCopy does not carry metadata or tags, so the multipart upload
+	// is created with the values that CopyObject would have copied.
+	input := &s3.CreateMultipartUploadInput{
+		Bucket:       destBucketName.AWSString(),
+		Key:          destKey.AWSString(),
+		StorageClass: storageClass,
+		Tagging:      tagging,
+	}
+	if conf.MetadataDirective == types.MetadataDirectiveReplace {
+		input.Metadata = withEncryptionEnvelope(conf.Metadata, head.Metadata)
+		input.ContentType = conf.ContentType
+	} else {
+		input.Metadata = head.Metadata
+		input.ContentType = head.ContentType
+		input.CacheControl = head.CacheControl
+		input.ContentDisposition = head.ContentDisposition
+		input.ContentEncoding = head.ContentEncoding
+		input.ContentLanguage = head.ContentLanguage
+		input.Expires = head.Expires
+	}
+	if conf.TaggingDirective != types.TaggingDirectiveReplace && aws.ToInt32(head.TagCount) > 0 {
+		tags, err := c.client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+			Bucket: srcBucketName.AWSString(),
+			Key:    srcKey.AWSString(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		input.Tagging = aws.String(encodeTagSet(tags.TagSet))
+	}
+	res, err = c.multipartCopy(ctx, input, copySource, head.ETag, size, conf.PartSize, conf.Concurrency)
+	return res, err
+}
+
+// multipartCopy copies size bytes from copySource with parallel UploadPartCopy requests.
+// The multipart upload is aborted if any part fails.
+func (c *Client) multipartCopy(
+	ctx context.Context, input *s3.CreateMultipartUploadInput, copySource, etag *string,
+	size, partSize int64, concurrency int,
+) (*CopyObjectResult, error) {
+	partSize = max(partSize, (size+maxUploadParts-1)/maxUploadParts)
+	partCount := int((size + partSize - 1) / partSize)
+
+	created, err := c.client.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	abort := func(err error) error {
+		_, abortErr := c.client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+			Bucket:   input.Bucket,
+			Key:      input.Key,
+			UploadId: created.UploadId,
+		})
+		return errors.Join(err, abortErr)
+	}
+
+	parts := make([]types.CompletedPart, partCount)
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(concurrency)
+	for i := range partCount {
+		start := int64(i) * partSize
+		end := min(start+partSize, size) - 1
+		partNumber := aws.Int32(int32(i + 1))
+		eg.Go(func() error {
+			out, err := c.client.UploadPartCopy(egCtx, &s3.UploadPartCopyInput{
+				Bucket:            input.Bucket,
+				Key:               input.Key,
+				UploadId:          created.UploadId,
+				PartNumber:        partNumber,
+				CopySource:        copySource,
+				CopySourceIfMatch: etag,
+				CopySourceRange:   aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+			})
+			if err != nil {
+				return err
+			}
+			parts[i] = types.CompletedPart{
+				PartNumber: partNumber,
+				ETag:       out.CopyPartResult.ETag,
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, abort(err)
+	}
+	out, err := c.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          input.Bucket,
+		Key:             input.Key,
+		UploadId:        created.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		return nil, abort(err)
+	}
+	return &CopyObjectResult{
+		ETag:      aws.ToString(out.ETag),
+		VersionID: aws.ToString(out.VersionId),
+		PartCount: partCount,
+	}, nil
+}
+
+// encodeTags encodes tags as the URL query string expected by the x-amz-tagging header.
+func encodeTags(tags map[string]string) string {
+	values := make(url.Values, len(tags))
+	for k, v := range tags {
+		values.Set(k, v)
+	}
+	return values.Encode()
+}
+
+func encodeTagSet(tagSet []types.Tag) string {
+	tags := make(map[string]string, len(tagSet))
+	for _, t := range tagSet {
+		tags[aws.ToString(t.Key)] = aws.ToString(t.Value)
+	}
+	return encodeTags(tags)
+}
+
+// isNotFoundError reports whether err is an S3 response with status 404.
+func isNotFoundError(err error) bool {
+	return hasStatusCode(err, http.StatusNotFound)
+}
+
+// objectReadError maps a failed read or head request to ErrNotFound, ErrNotModified or ErrPreconditionFailed.
+func objectReadError(err error) error {
+	var nond *types.NotFound
+	switch {
+	case errors.As(err, &nond), isNotFoundError(err):
+		return ErrNotFound
+	case hasStatusCode(err, http.StatusNotModified):
+		return ErrNotModified
+	case isPreconditionFailedError(err):
+		return ErrPreconditionFailed
+	default:
 		return err
 	}
-	return nil
+}
+
+// objectWriteError maps a failed conditional write to ErrPreconditionFailed.
+func objectWriteError(err error) error {
+	if isPreconditionFailedError(err) {
+		return ErrPreconditionFailed
+	}
+	return err
+}
+
+// isPreconditionFailedError reports whether err is an S3 response with status 412.
+func isPreconditionFailedError(err error) bool {
+	return hasStatusCode(err, http.StatusPreconditionFailed)
+}
+
+func hasStatusCode(err error, statusCode int) bool {
+	var oe *smithy.OperationError
+	if errors.As(err, &oe) {
+		var resErr *awshttp.ResponseError
+		if errors.As(oe.Err, &resErr) {
+			return resErr.Response.StatusCode == statusCode
+		}
+	}
+	return false
 }
 
 // SelectCSVAll executes a SQL expression against S3 Select and writes results to w.
@@ -623,10 +1137,27 @@ func (c *Client) SelectCSVHeaders(
 }
 
 // PresignPutObject generates a pre-signed URL for uploading an object.
+// Use PresignPutObjectRequest to get the headers the uploader must send when the URL is constrained
+// with s3presigned options such as WithContentType, WithContentLength or WithMetadata.
 func (c *Client) PresignPutObject(
 	ctx context.Context, bucketName BucketName, key Key,
 	opts ...s3presigned.OptionS3Presigned,
-) (url string, err error) {
+) (string, error) {
+	req, err := c.PresignPutObjectRequest(ctx, bucketName, key, opts...)
+	if err != nil {
+		return "", err
+	}
+	return req.URL, nil
+}
+
+// PresignPutObjectRequest generates a pre-signed PUT request for uploading an object.
+// The Content-Type, Content-Length, Content-MD5, x-amz-checksum-sha256, user metadata, SSE and
+// Content-Disposition set by s3presigned options are signed, so S3 rejects uploads that do not send
+// exactly the returned headers.
+func (c *Client) PresignPutObjectRequest(
+	ctx context.Context, bucketName BucketName, key Key,
+	opts ...s3presigned.OptionS3Presigned,
+) (req *PresignedRequest, err error) {
 	conf := s3presigned.GetS3PresignedConf(opts...)
 	done := c.logOperation(ctx, "PresignPutObject",
 		slog.String("bucket", bucketName.String()),
@@ -637,37 +1168,131 @@ func (c *Client) PresignPutObject(
 	}()
 
 	input := &s3.PutObjectInput{
-		Bucket: bucketName.AWSString(),
-		Key:    key.AWSString(),
+		Bucket:               bucketName.AWSString(),
+		Key:                  key.AWSString(),
+		IfMatch:              conf.IfMatch,
+		IfNoneMatch:          conf.IfNoneMatch,
+		ContentType:          conf.ContentType,
+		ContentLength:        conf.ContentLength,
+		ContentMD5:           conf.ContentMD5,
+		ChecksumSHA256:       conf.ChecksumSHA256,
+		Metadata:             conf.Metadata,
+		ServerSideEncryption: conf.ServerSideEncryption,
+		SSEKMSKeyId:          conf.SSEKMSKeyID,
+	}
+	if conf.ChecksumSHA256 != nil {
+		input.ChecksumAlgorithm = types.ChecksumAlgorithmSha256
 	}
-	ps := s3.NewPresignClient(c.client)
+	if conf.PresignFileName != "" {
+		input.ContentDisposition = aws.String(ResponseContentDisposition(conf.ContentDispositionType,
+			conf.PresignFileName))
+	}
+	ps := s3.NewPresignClient(c.client, s3.WithPresignClientFromClientOptions(withoutEncryption))
 	resp, err := ps.PresignPutObject(ctx, input, func(o *s3.PresignOptions) {
 		o.Expires = conf.PresignExpires
 	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	url = resp.URL
-	return url, nil
+	headers := resp.SignedHeader.Clone()
+	headers.Del("Host")
+	return &PresignedRequest{
+		URL:     resp.URL,
+		Method:  resp.Method,
+		Headers: headers,
+	}, nil
 }
 
-// CreateMultipartUpload initiates a multipart upload.
-// ref: https://docs.aws.amazon.com/AmazonS3/latest/API/API_CreateMultipartUpload.html
-func (c *Client) CreateMultipartUpload(
+// PresignPostObject generates a presigned POST policy for uploading an object directly from a browser.
+// The browser must send every returned form field together with the file (as the last field)
+// in a multipart/form-data POST to the returned URL. S3 rejects uploads that violate the policy.
+// ref: https://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-HTTPPOSTConstructPolicy.html
+func (c *Client) PresignPostObject(
 	ctx context.Context, bucketName BucketName, key Key,
-) (uploadID string, err error) {
-	done := c.logOperation(ctx, "CreateMultipartUpload",
+	opts ...s3presignedpost.OptionS3PresignedPost,
+) (res *PresignedPost, err error) {
+	conf := s3presignedpost.GetS3PresignedPostConf(opts...)
+	done := c.logOperation(ctx, "PresignPostObject",
 		slog.String("bucket", bucketName.String()),
 		slog.String("key", key.String()),
 	)
 	defer func() {
-		done(err)
+		done(err, slog.Duration("expires", conf.PresignExpires))
 	}()
 
+	var conditions []any
+	fields := make(map[string]string)
+	if r := conf.ContentLengthRange; r != nil {
+		conditions = append(conditions, []any{"content-length-range", r.Min, r.Max})
+	}
+	if conf.KeyStartsWith != nil {
+		conditions = append(conditions, []any{"starts-with", "$key", *conf.KeyStartsWith})
+	}
+	if conf.ContentType != nil {
+		conditions = append(conditions, map[string]string{"Content-Type": *conf.ContentType})
+		fields["Content-Type"] = *conf.ContentType
+	}
+	if conf.ContentTypeStartsWith != nil {
+		conditions = append(conditions, []any{"starts-with", "$Content-Type", *conf.ContentTypeStartsWith})
+	}
+	for k, v := range conf.Metadata {
+		name := "x-amz-meta-" + strings.ToLower(k)
+		conditions = append(conditions, map[string]string{name: v})
+		fields[name] = v
+	}
+	if conf.SuccessActionStatus != 0 {
+		status := strconv.Itoa(conf.SuccessActionStatus)
+		conditions = append(conditions, map[string]string{"success_action_status": status})
+		fields["success_action_status"] = status
+	}
+
+	ps := s3.NewPresignClient(c.client, s3.WithPresignClientFromClientOptions(withoutEncryption))
+	resp, err := ps.PresignPostObject(ctx, &s3.PutObjectInput{
+		Bucket: bucketName.AWSString(),
+		Key:    key.AWSString(),
+	}, func(o *s3.PresignPostOptions) {
+		o.Expires = conf.PresignExpires
+		o.Conditions = conditions
+	})
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(fields, resp.Values)
+	return &PresignedPost{URL: resp.URL, Fields: fields}, nil
+}
+
+// CreateMultipartUpload initiates a multipart upload.
+// The object attributes (content type, metadata, tags, storage class, encryption, checksum algorithm)
+// are taken from opts. When SSE-C or a checksum algorithm is used, pass the same opts to UploadPart.
+// It returns ErrEncryptionNotSupported on a client with WithEncryption, since the parts uploaded with UploadPart
+// would not be encrypted; use NewMultipartUpload or UploadManager instead.
+// ref: https://docs.aws.amazon.com/AmazonS3/latest/API/API_CreateMultipartUpload.html
+func (c *Client) CreateMultipartUpload(
+	ctx context.Context, bucketName BucketName, key Key,
+	opts ...s3upload.OptionS3Upload,
+) (uploadID string, err error) {
+	if c.encryption != nil {
+		return "", ErrEncryptionNotSupported
+	}
 	input := &s3.CreateMultipartUploadInput{
 		Bucket: bucketName.AWSString(),
 		Key:    key.AWSString(),
 	}
+	newUploadAttributes(opts...).applyCreateMultipartUploadInput(input)
+	return c.createMultipartUpload(ctx, input)
+}
+
+func (c *Client) createMultipartUpload(
+	ctx context.Context, input *s3.CreateMultipartUploadInput,
+) (uploadID string, err error) {
+	done := c.logOperation(ctx, "CreateMultipartUpload",
+		slog.String("bucket", aws.ToString(input.Bucket)),
+		slog.String("key", aws.ToString(input.Key)),
+	)
+	defer func() {
+		done(err)
+	}()
+
 	resp, err := c.client.CreateMultipartUpload(ctx, input)
 	if err != nil {
 		return "", err
@@ -677,20 +1302,12 @@ func (c *Client) CreateMultipartUpload(
 }
 
 // UploadPart uploads a part in a multipart upload.
+// Only the SSE-C key and the checksum algorithm of opts are used; they must match CreateMultipartUpload.
 // ref: https://docs.aws.amazon.com/AmazonS3/latest/API/API_UploadPart.html
 func (c *Client) UploadPart(
 	ctx context.Context, bucketName BucketName, key Key, uploadID string, partNumber int32,
-	body io.Reader,
-) (res *s3.UploadPartOutput, err error) {
-	done := c.logOperation(ctx, "UploadPart",
-		slog.String("bucket", bucketName.String()),
-		slog.String("key", key.String()),
-		slog.Int64("part_number", int64(partNumber)),
-	)
-	defer func() {
-		done(err)
-	}()
-
+	body io.Reader, opts ...s3upload.OptionS3Upload,
+) (*s3.UploadPartOutput, error) {
 	input := &s3.UploadPartInput{
 		Bucket:     bucketName.AWSString(),
 		Key:        key.AWSString(),
@@ -698,6 +1315,20 @@ func (c *Client) UploadPart(
 		UploadId:   aws.String(uploadID),
 		Body:       body,
 	}
+	newUploadAttributes(opts...).applyUploadPartInput(input)
+	return c.uploadPart(ctx, input)
+}
+
+func (c *Client) uploadPart(ctx context.Context, input *s3.UploadPartInput) (res *s3.UploadPartOutput, err error) {
+	done := c.logOperation(ctx, "UploadPart",
+		slog.String("bucket", aws.ToString(input.Bucket)),
+		slog.String("key", aws.ToString(input.Key)),
+		slog.Int64("part_number", int64(aws.ToInt32(input.PartNumber))),
+	)
+	defer func() {
+		done(err)
+	}()
+
 	resp, err := c.client.UploadPart(ctx, input)
 	if err != nil {
 		return nil, err
@@ -707,20 +1338,13 @@ func (c *Client) UploadPart(
 }
 
 // CompleteMultipartUpload completes a multipart upload.
+// The write preconditions of opts (s3upload.WithIfMatch, s3upload.WithIfNoneMatch) are checked here,
+// and ErrPreconditionFailed is returned if they fail.
 // ref: https://docs.aws.amazon.com/AmazonS3/latest/API/API_CompleteMultipartUpload.html
 func (c *Client) CompleteMultipartUpload(
 	ctx context.Context, bucketName BucketName, key Key, uploadID string,
-	completedParts []types.CompletedPart,
-) (res *s3.CompleteMultipartUploadOutput, err error) {
-	done := c.logOperation(ctx, "CompleteMultipartUpload",
-		slog.String("bucket", bucketName.String()),
-		slog.String("key", key.String()),
-		slog.Int("part_count", len(completedParts)),
-	)
-	defer func() {
-		done(err)
-	}()
-
+	completedParts []types.CompletedPart, opts ...s3upload.OptionS3Upload,
+) (*s3.CompleteMultipartUploadOutput, error) {
 	input := &s3.CompleteMultipartUploadInput{
 		Bucket:   bucketName.AWSString(),
 		Key:      key.AWSString(),
@@ -729,11 +1353,26 @@ func (c *Client) CompleteMultipartUpload(
 			Parts: completedParts,
 		},
 	}
-	resp, err := c.client.CompleteMultipartUpload(ctx, input)
+	newUploadAttributes(opts...).applyCompleteMultipartUploadInput(input)
+	return c.completeMultipartUpload(ctx, input)
+}
+
+func (c *Client) completeMultipartUpload(
+	ctx context.Context, input *s3.CompleteMultipartUploadInput,
+) (res *s3.CompleteMultipartUploadOutput, err error) {
+	done := c.logOperation(ctx, "CompleteMultipartUpload",
+		slog.String("bucket", aws.ToString(input.Bucket)),
+		slog.String("key", aws.ToString(input.Key)),
+		slog.Int("part_count", len(input.MultipartUpload.Parts)),
+	)
+	defer func() {
+		done(err)
+	}()
+
+	res, err = c.client.CompleteMultipartUpload(ctx, input)
 	if err != nil {
-		return nil, err
+		return nil, objectWriteError(err)
 	}
-	res = resp
 	return res, nil
 }
 
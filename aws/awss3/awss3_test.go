@@ -1,16 +1,30 @@
 package awss3_test
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
 	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -27,12 +41,20 @@ import (
 
 	"github.com/88labs/go-utils/aws/awsconfig"
 	"github.com/88labs/go-utils/aws/awss3"
+	"github.com/88labs/go-utils/aws/awss3/options/s3copy"
+	"github.com/88labs/go-utils/aws/awss3/options/s3delete"
 	"github.com/88labs/go-utils/aws/awss3/options/s3download"
 	"github.com/88labs/go-utils/aws/awss3/options/s3head"
 	"github.com/88labs/go-utils/aws/awss3/options/s3list"
+	"github.com/88labs/go-utils/aws/awss3/options/s3multipart"
 	"github.com/88labs/go-utils/aws/awss3/options/s3presigned"
+	"github.com/88labs/go-utils/aws/awss3/options/s3presignedpost"
+	"github.com/88labs/go-utils/aws/awss3/options/s3progress"
+	"github.com/88labs/go-utils/aws/awss3/options/s3select"
 	"github.com/88labs/go-utils/aws/awss3/options/s3selectcsv"
+	"github.com/88labs/go-utils/aws/awss3/options/s3sync"
 	"github.com/88labs/go-utils/aws/awss3/options/s3upload"
+	"github.com/88labs/go-utils/aws/awss3/options/s3zip"
 	"github.com/88labs/go-utils/aws/ctxawslocal"
 )
 
@@ -100,6 +122,140 @@ func TestHeadObject(t *testing.T) {
 	})
 }
 
+func TestWaitUntilObjectExists(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+	client, err := awss3.NewClient(ctx, TestRegion)
+	assert.NilError(t, err)
+
+	waitOpts := []s3head.OptionS3Head{
+		s3head.WithTimeout(10 * time.Second),
+		s3head.WithMinDelay(20 * time.Millisecond),
+		s3head.WithMaxDelay(100 * time.Millisecond),
+	}
+	putLater := func(t *testing.T, key awss3.Key, body string) {
+		t.Helper()
+		time.AfterFunc(200*time.Millisecond, func() {
+			_, err := client.PutObject(ctx, TestBucket, key, strings.NewReader(body))
+			assert.Check(t, err)
+		})
+	}
+
+	t.Run("WaitUntilObjectExists", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/wait/%s.txt", ulid.MustNew()))
+		putLater(t, key, "created")
+		res, err := awss3.WaitUntilObjectExists(ctx, TestRegion, TestBucket, key, waitOpts...)
+		assert.NilError(t, err)
+		assert.Equal(t, int64(len("created")), aws.ToInt64(res.ContentLength))
+	})
+	t.Run("WaitUntilObjectExists:timeout", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/wait/%s.txt", ulid.MustNew()))
+		_, err := client.WaitUntilObjectExists(ctx, TestBucket, key,
+			s3head.WithTimeout(200*time.Millisecond),
+			s3head.WithMinDelay(20*time.Millisecond),
+			s3head.WithMaxDelay(50*time.Millisecond),
+		)
+		assert.ErrorIs(t, err, awss3.ErrWaitTimeout)
+	})
+	t.Run("WaitUntilObjectExists:default delays", func(t *testing.T) {
+		t.Parallel()
+		for name, opts := range map[string][]s3head.OptionS3Head{
+			"unset":    nil,
+			"zero":     {s3head.WithMinDelay(0), s3head.WithMaxDelay(0)},
+			"max only": {s3head.WithMaxDelay(0)},
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				var logs bytes.Buffer
+				client, err := awss3.NewClient(ctx, TestRegion, awss3.WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))))
+				assert.NilError(t, err)
+				key := awss3.Key(fmt.Sprintf("awstest/wait/%s.txt", ulid.MustNew()))
+				_, err = client.WaitUntilObjectExists(ctx, TestBucket, key,
+					append(opts, s3head.WithTimeout(300*time.Millisecond), s3head.WithLogWaitAttempts(true))...)
+				assert.ErrorIs(t, err, awss3.ErrWaitTimeout)
+
+				// a single HeadObject, then a wait of at least the default minimum delay of 5 seconds
+				var waits []map[string]any
+				for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+					var entry map[string]any
+					assert.NilError(t, json.Unmarshal([]byte(line), &entry))
+					if entry["msg"] == "awss3 waiting" {
+						waits = append(waits, entry)
+					}
+				}
+				assert.Equal(t, 1, len(waits))
+				assert.Assert(t, waits[0]["delay"].(float64) >= float64(5*time.Second), waits[0]["delay"])
+			})
+		}
+	})
+	t.Run("WaitUntilObjectExists:context canceled", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/wait/%s.txt", ulid.MustNew()))
+		cancelCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancel()
+		_, err := client.WaitUntilObjectExists(cancelCtx, TestBucket, key,
+			s3head.WithMinDelay(20*time.Millisecond),
+			s3head.WithMaxDelay(50*time.Millisecond),
+		)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+	t.Run("WaitUntilObjectExists:IfNoneMatch waits for a change", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/wait/%s.txt", ulid.MustNew()))
+		put, err := client.PutObject(ctx, TestBucket, key, strings.NewReader("v1"))
+		assert.NilError(t, err)
+		putLater(t, key, "version 2")
+		res, err := client.WaitUntilObjectExists(ctx, TestBucket, key,
+			append(waitOpts, s3head.WithIfNoneMatch(aws.ToString(put.ETag)))...)
+		assert.NilError(t, err)
+		assert.Equal(t, int64(len("version 2")), aws.ToInt64(res.ContentLength))
+	})
+	t.Run("WaitUntilObjectNotExists", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/wait/%s.txt", ulid.MustNew()))
+		_, err := client.PutObject(ctx, TestBucket, key, strings.NewReader("deleted"))
+		assert.NilError(t, err)
+		time.AfterFunc(200*time.Millisecond, func() {
+			_, err := client.DeleteObject(ctx, TestBucket, key)
+			assert.Check(t, err)
+		})
+		assert.NilError(t, awss3.WaitUntilObjectNotExists(ctx, TestRegion, TestBucket, key, waitOpts...))
+		_, err = client.HeadObject(ctx, TestBucket, key)
+		assert.ErrorIs(t, err, awss3.ErrNotFound)
+	})
+	t.Run("WaitUntilObjectsExist", func(t *testing.T) {
+		t.Parallel()
+		keys := make(awss3.Keys, 0, 3)
+		for i := range 3 {
+			key := awss3.Key(fmt.Sprintf("awstest/wait/%s-%d.txt", ulid.MustNew(), i))
+			keys = append(keys, key)
+			putLater(t, key, "stage output")
+		}
+		assert.NilError(t, awss3.WaitUntilObjectsExist(ctx, TestRegion, TestBucket, keys, waitOpts...))
+		for _, key := range keys {
+			_, err := client.HeadObject(ctx, TestBucket, key)
+			assert.NilError(t, err)
+		}
+	})
+	t.Run("WaitUntilPrefixCount", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("awstest/wait/%s/", ulid.MustNew())
+		for i := range 3 {
+			putLater(t, awss3.Key(fmt.Sprintf("%s%d.txt", prefix, i)), "stage output")
+		}
+		objects, err := awss3.WaitUntilPrefixCount(ctx, TestRegion, TestBucket, prefix, 3, waitOpts...)
+		assert.NilError(t, err)
+		assert.Equal(t, 3, len(objects))
+	})
+}
+
 func TestListObjects(t *testing.T) {
 	t.Parallel()
 	ctx := ctxawslocal.WithContext(
@@ -183,6 +339,137 @@ func TestListObjects(t *testing.T) {
 	})
 }
 
+func TestListObjectsIter(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+	s3Client, err := awss3.GetClient(ctx, TestRegion)
+	assert.NilError(t, err)
+
+	createFixture := func(key string) awss3.Key {
+		uploader := transfermanager.New(s3Client)
+		input := &transfermanager.UploadObjectInput{
+			Body:    strings.NewReader("test"),
+			Bucket:  aws.String(TestBucket),
+			Key:     aws.String(key),
+			Expires: aws.Time(time.Now().Add(10 * time.Minute)),
+		}
+		if _, err := uploader.UploadObject(ctx, input); err != nil {
+			assert.NilError(t, err)
+		}
+		return awss3.Key(key)
+	}
+
+	t.Run("ListObjectsIter", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("iter/%s/", ulid.MustNew())
+		keys := awss3.Keys{
+			createFixture(prefix + "a.txt"),
+			createFixture(prefix + "b.txt"),
+			createFixture(prefix + "c.txt"),
+		}
+		var got awss3.Keys
+		for entry, err := range awss3.ListObjectsIter(ctx, TestRegion, TestBucket, s3list.WithPrefix(prefix)) {
+			assert.NilError(t, err)
+			assert.Assert(t, !entry.IsCommonPrefix())
+			got = append(got, entry.Key())
+		}
+		assert.DeepEqual(t, keys, got)
+	})
+	t.Run("ListObjectsIter stop early", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("iter/%s/", ulid.MustNew())
+		for i := 0; i < 5; i++ {
+			createFixture(fmt.Sprintf("%s%d.txt", prefix, i))
+		}
+		var pages, count int
+		for _, err := range awss3.ListObjectsIter(ctx, TestRegion, TestBucket,
+			s3list.WithPrefix(prefix),
+			s3list.WithMaxKeys(2),
+			s3list.WithPageCallback(func(*s3.ListObjectsV2Output) error {
+				pages++
+				return nil
+			}),
+		) {
+			assert.NilError(t, err)
+			count++
+			if count == 3 {
+				break
+			}
+		}
+		assert.Equal(t, 3, count)
+		assert.Equal(t, 2, pages)
+	})
+	t.Run("ListObjectsIter Delimiter", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("iter/%s/", ulid.MustNew())
+		createFixture(prefix + "dir1/a.txt")
+		createFixture(prefix + "dir1/b.txt")
+		createFixture(prefix + "dir2/a.txt")
+		file := createFixture(prefix + "file.txt")
+		var prefixes, objects awss3.Keys
+		for entry, err := range awss3.ListObjectsIter(ctx, TestRegion, TestBucket,
+			s3list.WithPrefix(prefix),
+			s3list.WithDelimiter("/"),
+		) {
+			assert.NilError(t, err)
+			if entry.IsCommonPrefix() {
+				prefixes = append(prefixes, entry.Key())
+				continue
+			}
+			objects = append(objects, entry.Key())
+		}
+		assert.DeepEqual(t, awss3.Keys{awss3.Key(prefix + "dir1/"), awss3.Key(prefix + "dir2/")}, prefixes)
+		assert.DeepEqual(t, awss3.Keys{file}, objects)
+	})
+	t.Run("ListObjectsIter StartAfter", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("iter/%s/", ulid.MustNew())
+		createFixture(prefix + "a.txt")
+		keyB := createFixture(prefix + "b.txt")
+		keyC := createFixture(prefix + "c.txt")
+		client, err := awss3.NewClient(ctx, TestRegion)
+		assert.NilError(t, err)
+		var got awss3.Keys
+		for entry, err := range client.ListObjectsIter(ctx, TestBucket,
+			s3list.WithPrefix(prefix),
+			s3list.WithStartAfter(prefix+"a.txt"),
+		) {
+			assert.NilError(t, err)
+			got = append(got, entry.Key())
+		}
+		assert.DeepEqual(t, awss3.Keys{keyB, keyC}, got)
+	})
+	t.Run("ListObjectsIter PageCallback error", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("iter/%s/", ulid.MustNew())
+		createFixture(prefix + "a.txt")
+		errStop := errors.New("stop")
+		var gotErr error
+		for _, err := range awss3.ListObjectsIter(ctx, TestRegion, TestBucket,
+			s3list.WithPrefix(prefix),
+			s3list.WithPageCallback(func(*s3.ListObjectsV2Output) error {
+				return errStop
+			}),
+		) {
+			gotErr = err
+		}
+		assert.ErrorIs(t, gotErr, errStop)
+	})
+	t.Run("ListObjectsIter error: non-existent bucket", func(t *testing.T) {
+		t.Parallel()
+		var gotErr error
+		for _, err := range awss3.ListObjectsIter(ctx, TestRegion, NonExistentBucket) {
+			gotErr = err
+		}
+		assert.Assert(t, gotErr != nil)
+	})
+}
+
 func TestGetObject(t *testing.T) {
 	t.Parallel()
 	ctx := ctxawslocal.WithContext(
@@ -227,6 +514,153 @@ func TestGetObject(t *testing.T) {
 	})
 }
 
+func TestGetObjectRange(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+	key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+	_, err := awss3.PutObject(ctx, TestRegion, TestBucket, key, strings.NewReader("0123456789"))
+	assert.NilError(t, err)
+	readRange := func(t *testing.T, offset, length int64) string {
+		t.Helper()
+		res, err := awss3.GetObjectRange(ctx, TestRegion, TestBucket, key, offset, length)
+		assert.NilError(t, err)
+		defer res.Body.Close()
+		b, err := io.ReadAll(res.Body)
+		assert.NilError(t, err)
+		return string(b)
+	}
+
+	t.Run("GetObjectRange", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, "2345", readRange(t, 2, 4))
+	})
+	t.Run("GetObjectRange:To the end", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, "789", readRange(t, 7, 0))
+	})
+	t.Run("GetObjectRange:NotFound", func(t *testing.T) {
+		t.Parallel()
+		_, err := awss3.GetObjectRange(ctx, TestRegion, TestBucket, "NOT_FOUND", 0, 1)
+		assert.ErrorIs(t, err, awss3.ErrNotFound)
+	})
+	t.Run("GetObjectRange:Negative offset", func(t *testing.T) {
+		t.Parallel()
+		_, err := awss3.GetObjectRange(ctx, TestRegion, TestBucket, key, -1, 1)
+		assert.Assert(t, err != nil)
+	})
+}
+
+func TestOpenObject(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+	createFixture := func(body []byte) awss3.Key {
+		key := awss3.Key(fmt.Sprintf("awstest/%s", ulid.MustNew()))
+		_, err := awss3.PutObject(ctx, TestRegion, TestBucket, key, bytes.NewReader(body))
+		assert.NilError(t, err)
+		return key
+	}
+
+	t.Run("OpenObject:Read and Seek", func(t *testing.T) {
+		t.Parallel()
+		body := bytes.Repeat([]byte("0123456789"), 100)
+		key := createFixture(body)
+		r, err := awss3.OpenObject(ctx, TestRegion, TestBucket, key, s3download.WithReadAheadSize(64))
+		assert.NilError(t, err)
+		defer r.Close()
+		assert.Equal(t, int64(len(body)), r.Size())
+		assert.Assert(t, r.ETag() != "")
+
+		b, err := io.ReadAll(r)
+		assert.NilError(t, err)
+		assert.Assert(t, bytes.Equal(body, b))
+
+		pos, err := r.Seek(-5, io.SeekEnd)
+		assert.NilError(t, err)
+		assert.Equal(t, int64(len(body)-5), pos)
+		b, err = io.ReadAll(r)
+		assert.NilError(t, err)
+		assert.Equal(t, "56789", string(b))
+
+		_, err = r.Seek(-1, io.SeekStart)
+		assert.Assert(t, err != nil)
+	})
+	t.Run("OpenObject:ReadAt", func(t *testing.T) {
+		t.Parallel()
+		key := createFixture([]byte("0123456789"))
+		r, err := awss3.OpenObject(ctx, TestRegion, TestBucket, key, s3download.WithReadAheadSize(0))
+		assert.NilError(t, err)
+		defer r.Close()
+		p := make([]byte, 3)
+		n, err := r.ReadAt(p, 4)
+		assert.NilError(t, err)
+		assert.Equal(t, "456", string(p[:n]))
+		n, err = r.ReadAt(p, 8)
+		assert.ErrorIs(t, err, io.EOF)
+		assert.Equal(t, "89", string(p[:n]))
+	})
+	t.Run("OpenObject:zip", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for _, name := range []string{"a.txt", "b.txt"} {
+			w, err := zw.Create(name)
+			assert.NilError(t, err)
+			_, err = w.Write([]byte(name))
+			assert.NilError(t, err)
+		}
+		assert.NilError(t, zw.Close())
+		key := createFixture(buf.Bytes())
+
+		r, err := awss3.OpenObject(ctx, TestRegion, TestBucket, key, s3download.WithReadAheadSize(128))
+		assert.NilError(t, err)
+		defer r.Close()
+		zr, err := zip.NewReader(r, r.Size())
+		assert.NilError(t, err)
+		assert.Equal(t, 2, len(zr.File))
+		f, err := zr.File[1].Open()
+		assert.NilError(t, err)
+		defer f.Close()
+		b, err := io.ReadAll(f)
+		assert.NilError(t, err)
+		assert.Equal(t, "b.txt", string(b))
+	})
+	t.Run("OpenObject:Object changed", func(t *testing.T) {
+		t.Parallel()
+		key := createFixture([]byte("0123456789"))
+		r, err := awss3.OpenObject(ctx, TestRegion, TestBucket, key, s3download.WithReadAheadSize(0))
+		assert.NilError(t, err)
+		defer r.Close()
+		_, err = awss3.PutObject(ctx, TestRegion, TestBucket, key, strings.NewReader("changed"))
+		assert.NilError(t, err)
+		_, err = r.Read(make([]byte, 3))
+		assert.ErrorIs(t, err, awss3.ErrObjectChanged)
+	})
+	t.Run("OpenObject:Closed", func(t *testing.T) {
+		t.Parallel()
+		key := createFixture([]byte("0123456789"))
+		r, err := awss3.OpenObject(ctx, TestRegion, TestBucket, key)
+		assert.NilError(t, err)
+		assert.NilError(t, r.Close())
+		_, err = r.Read(make([]byte, 3))
+		assert.ErrorIs(t, err, os.ErrClosed)
+	})
+	t.Run("OpenObject:NotFound", func(t *testing.T) {
+		t.Parallel()
+		_, err := awss3.OpenObject(ctx, TestRegion, TestBucket, "NOT_FOUND")
+		assert.ErrorIs(t, err, awss3.ErrNotFound)
+	})
+}
+
 func TestDeleteObject(t *testing.T) {
 	t.Parallel()
 	ctx := ctxawslocal.WithContext(
@@ -270,6 +704,114 @@ func TestDeleteObject(t *testing.T) {
 	})
 }
 
+func TestDeleteObjects(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+	s3Client, err := awss3.GetClient(ctx, TestRegion)
+	assert.NilError(t, err)
+
+	createFixture := func(key string) awss3.Key {
+		uploader := transfermanager.New(s3Client)
+		input := &transfermanager.UploadObjectInput{
+			Body:    strings.NewReader("test"),
+			Bucket:  aws.String(TestBucket),
+			Key:     aws.String(key),
+			Expires: aws.Time(time.Now().Add(10 * time.Minute)),
+		}
+		if _, err := uploader.UploadObject(ctx, input); err != nil {
+			assert.NilError(t, err)
+		}
+		return awss3.Key(key)
+	}
+	countObjects := func(prefix string) int {
+		res, err := awss3.ListObjects(ctx, TestRegion, TestBucket, s3list.WithPrefix(prefix))
+		assert.NilError(t, err)
+		return len(res)
+	}
+
+	t.Run("DeleteObjects", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("delete/%s/", ulid.MustNew())
+		keys := awss3.Keys{
+			createFixture(prefix + "a.txt"),
+			createFixture(prefix + "b.txt"),
+			createFixture(prefix + "c.txt"),
+		}
+		res, err := awss3.DeleteObjects(ctx, TestRegion, TestBucket, append(keys, keys[0]))
+		assert.NilError(t, err)
+		assert.DeepEqual(t, keys, res.Deleted())
+		assert.Equal(t, 0, len(res.Failed()))
+		assert.Equal(t, 0, countObjects(prefix))
+	})
+	t.Run("DeleteObjects more than 1000 keys", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("delete/%s/", ulid.MustNew())
+		keys := make(awss3.Keys, 1001)
+		for i := range keys {
+			keys[i] = createFixture(fmt.Sprintf("%s%04d.txt", prefix, i))
+		}
+		client, err := awss3.NewClient(ctx, TestRegion)
+		assert.NilError(t, err)
+		res, err := client.DeleteObjects(ctx, TestBucket, keys, s3delete.WithConcurrency(2))
+		assert.NilError(t, err)
+		assert.Equal(t, 1001, len(res.Deleted()))
+		assert.Equal(t, 0, countObjects(prefix))
+	})
+	t.Run("DeleteObjects error: non-existent bucket", func(t *testing.T) {
+		t.Parallel()
+		res, err := awss3.DeleteObjects(ctx, TestRegion, NonExistentBucket, awss3.NewKeys("a.txt", "b.txt"))
+		assert.Assert(t, err != nil)
+		assert.Equal(t, 2, len(res.Failed()))
+		assert.Equal(t, 0, len(res.Deleted()))
+	})
+	t.Run("DeleteObjects dry run", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("delete/%s/", ulid.MustNew())
+		keys := awss3.Keys{createFixture(prefix + "a.txt")}
+		res, err := awss3.DeleteObjects(ctx, TestRegion, TestBucket, keys, s3delete.WithDryRun(true))
+		assert.NilError(t, err)
+		assert.DeepEqual(t, keys, res.Deleted())
+		assert.Equal(t, 1, countObjects(prefix))
+	})
+	t.Run("DeletePrefix", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("delete/%s/", ulid.MustNew())
+		keys := awss3.Keys{
+			createFixture(prefix + "a.txt"),
+			createFixture(prefix + "dir/b.txt"),
+		}
+		other := createFixture(fmt.Sprintf("delete/%s/a.txt", ulid.MustNew()))
+		res, err := awss3.DeletePrefix(ctx, TestRegion, TestBucket, prefix)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, keys, res.Deleted())
+		assert.Equal(t, 0, countObjects(prefix))
+		_, err = awss3.HeadObject(ctx, TestRegion, TestBucket, other)
+		assert.NilError(t, err)
+	})
+	t.Run("DeletePrefix dry run", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("delete/%s/", ulid.MustNew())
+		keys := awss3.Keys{
+			createFixture(prefix + "a.txt"),
+			createFixture(prefix + "b.txt"),
+		}
+		res, err := awss3.DeletePrefix(ctx, TestRegion, TestBucket, prefix, s3delete.WithDryRun(true))
+		assert.NilError(t, err)
+		assert.DeepEqual(t, keys, res.Deleted())
+		assert.Equal(t, 2, countObjects(prefix))
+	})
+	t.Run("DeletePrefix error: empty prefix", func(t *testing.T) {
+		t.Parallel()
+		_, err := awss3.DeletePrefix(ctx, TestRegion, TestBucket, "")
+		assert.Assert(t, err != nil)
+	})
+}
+
 func TestDownloadFiles(t *testing.T) {
 	t.Parallel()
 	ctx := ctxawslocal.WithContext(
@@ -395,6 +937,35 @@ func TestDownloadFilesParallel(t *testing.T) {
 		keys[i] = awss3.Key(key)
 	}
 
+	t.Run("WithProgressListener", func(t *testing.T) {
+		t.Parallel()
+		var events []s3progress.Event
+		filePaths, err := awss3.DownloadFilesParallel(ctx, TestRegion, TestBucket, keys, t.TempDir(),
+			s3download.WithProgressListener(func(e s3progress.Event) {
+				events = append(events, e)
+			}),
+		)
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), len(filePaths))
+
+		var totalBytes int64
+		completed := make(map[string]bool)
+		for i := range keys {
+			totalBytes += int64(len(getBodyText(i)))
+		}
+		for _, e := range events {
+			assert.Equal(t, len(keys), e.ObjectCount)
+			if e.Type == s3progress.EventComplete {
+				completed[e.Key] = true
+				assert.Equal(t, e.TotalBytes, e.BytesTransferred)
+			}
+		}
+		assert.Equal(t, len(keys), len(completed))
+		last := events[len(events)-1]
+		assert.Equal(t, len(keys), last.CompletedObjects)
+		assert.Equal(t, totalBytes, last.OperationBytesTransferred)
+		assert.Equal(t, totalBytes, last.OperationTotalBytes)
+	})
 	t.Run("no option", func(t *testing.T) {
 		t.Parallel()
 		filePaths, err := awss3.DownloadFilesParallel(ctx, TestRegion, TestBucket, keys, t.TempDir())
@@ -507,7 +1078,7 @@ func TestDownloadFilesParallel(t *testing.T) {
 	})
 }
 
-func TestPutObject(t *testing.T) {
+func TestDownloadFilesParallelWithResults(t *testing.T) {
 	t.Parallel()
 	ctx := ctxawslocal.WithContext(
 		context.Background(),
@@ -515,14 +1086,303 @@ func TestPutObject(t *testing.T) {
 		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
 		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
 	)
+	putObjects := func(t *testing.T, bodies map[string]string) {
+		t.Helper()
+		for key, body := range bodies {
+			_, err := awss3.PutObject(ctx, TestRegion, TestBucket, awss3.Key(key), strings.NewReader(body))
+			assert.NilError(t, err)
+		}
+	}
 
-	t.Run("PutObject", func(t *testing.T) {
+	t.Run("partial failure keeps the other files", func(t *testing.T) {
 		t.Parallel()
-		key := fmt.Sprintf("awstest/%s.txt", ulid.MustNew())
-		body := faker.Sentence()
-		_, err := awss3.PutObject(ctx, TestRegion, TestBucket, awss3.Key(key), strings.NewReader(body))
-		assert.NilError(t, err)
-		filePaths, err := awss3.DownloadFiles(ctx, TestRegion, TestBucket, awss3.NewKeys(key), t.TempDir())
+		prefix := fmt.Sprintf("awstest/%s", ulid.MustNew())
+		putObjects(t, map[string]string{prefix + "/a.txt": "a", prefix + "/b.txt": "bb"})
+		keys := awss3.NewKeys(prefix+"/a.txt", prefix+"/missing.txt", prefix+"/b.txt")
+		outputDir := t.TempDir()
+		results, err := awss3.DownloadFilesParallelWithResults(ctx, TestRegion, TestBucket, keys, outputDir,
+			s3download.WithConcurrency(2),
+		)
+		assert.ErrorIs(t, err, awss3.ErrNotFound)
+		assert.Equal(t, 3, len(results))
+		assert.Equal(t, 2, len(results.Downloaded()))
+
+		failed := results.Failed()
+		assert.Equal(t, 1, len(failed))
+		assert.Equal(t, keys[1], failed[0].Key)
+		assert.ErrorIs(t, failed[0].Err, awss3.ErrNotFound)
+		assert.Equal(t, 1, failed[0].Attempts)
+
+		assert.Equal(t, filepath.Join(outputDir, "b.txt"), results[2].Path)
+		assert.Equal(t, int64(2), results[2].Bytes)
+		assert.Equal(t, 1, results[2].Attempts)
+		assert.DeepEqual(t, []string{filepath.Join(outputDir, "a.txt"), filepath.Join(outputDir, "b.txt")}, results.Paths())
+		assert.DeepEqual(t, map[string]string{"a.txt": "a", "b.txt": "bb"}, readSyncFiles(t, outputDir))
+	})
+	t.Run("duplicated file names", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("awstest/%s", ulid.MustNew())
+		putObjects(t, map[string]string{prefix + "/1/a.txt": "1", prefix + "/2/a.txt": "2"})
+		outputDir := t.TempDir()
+		results, err := awss3.DownloadFilesParallelWithResults(ctx, TestRegion, TestBucket,
+			awss3.NewKeys(prefix+"/1/a.txt", prefix+"/2/a.txt"), outputDir)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, []string{filepath.Join(outputDir, "a.txt"), filepath.Join(outputDir, "a_2.txt")}, results.Paths())
+		assert.DeepEqual(t, map[string]string{"a.txt": "1", "a_2.txt": "2"}, readSyncFiles(t, outputDir))
+	})
+	t.Run("WithSkipExisting", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("awstest/%s", ulid.MustNew())
+		putObjects(t, map[string]string{prefix + "/a.txt": "aaa", prefix + "/b.txt": "bbb", prefix + "/c.txt": "ccc"})
+		keys := awss3.NewKeys(prefix+"/a.txt", prefix+"/b.txt", prefix+"/c.txt")
+		outputDir := t.TempDir()
+		// an interrupted download: a.txt is complete, b.txt has changed
+		writeSyncFiles(t, outputDir, map[string]string{"a.txt": "aaa", "b.txt": "xxx"})
+
+		results, err := awss3.DownloadFilesParallelWithResults(ctx, TestRegion, TestBucket, keys, outputDir,
+			s3download.WithSkipExisting(true),
+		)
+		assert.NilError(t, err)
+		assert.Equal(t, 1, len(results.Skipped()))
+		assert.Equal(t, keys[0], results.Skipped()[0].Key)
+		assert.Equal(t, 0, results.Skipped()[0].Attempts)
+		assert.Equal(t, 2, len(results.Downloaded()))
+		assert.DeepEqual(t, map[string]string{"a.txt": "aaa", "b.txt": "bbb", "c.txt": "ccc"}, readSyncFiles(t, outputDir))
+
+		results, err = awss3.DownloadFilesParallelWithResults(ctx, TestRegion, TestBucket, keys, outputDir,
+			s3download.WithSkipExisting(true),
+		)
+		assert.NilError(t, err)
+		assert.Equal(t, 3, len(results.Skipped()))
+	})
+}
+
+func TestUploadDirectory(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+
+	t.Run("UploadDirectory and DownloadPrefix", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("awstest/%s", ulid.MustNew())
+		srcDir := t.TempDir()
+		files := map[string]string{
+			"a.txt":         "a",
+			"sub/b.csv":     "b,b",
+			"sub/deep/c.js": "c",
+		}
+		writeSyncFiles(t, srcDir, files)
+
+		var progress []s3sync.Progress
+		results, err := awss3.UploadDirectory(ctx, TestRegion, TestBucket, prefix, srcDir,
+			s3sync.WithConcurrency(2),
+			s3sync.WithProgress(func(p s3sync.Progress) {
+				progress = append(progress, p)
+			}),
+		)
+		assert.NilError(t, err)
+		assert.Equal(t, 3, len(results.Transferred()))
+		assert.Equal(t, awss3.Key(prefix+"/a.txt"), results[0].Key)
+		assert.Equal(t, 3, len(progress))
+		assert.Equal(t, 3, progress[2].Completed)
+		assert.Equal(t, 3, progress[2].Total)
+
+		head, err := awss3.HeadObject(ctx, TestRegion, TestBucket, awss3.Key(prefix+"/sub/b.csv"))
+		assert.NilError(t, err)
+		assert.Equal(t, "text/csv; charset=utf-8", aws.ToString(head.ContentType))
+
+		dstDir := t.TempDir()
+		results, err = awss3.DownloadPrefix(ctx, TestRegion, TestBucket, prefix, dstDir)
+		assert.NilError(t, err)
+		assert.Equal(t, 3, len(results.Transferred()))
+		assert.DeepEqual(t, files, readSyncFiles(t, dstDir))
+	})
+	t.Run("DownloadPrefix does not match sibling prefixes", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("awstest/%s", ulid.MustNew())
+		for _, key := range []string{prefix + "/a.txt", prefix + "-other/b.txt"} {
+			_, err := awss3.PutObject(ctx, TestRegion, TestBucket, awss3.Key(key), strings.NewReader("x"))
+			assert.NilError(t, err)
+		}
+		dstDir := t.TempDir()
+		results, err := awss3.DownloadPrefix(ctx, TestRegion, TestBucket, prefix, dstDir)
+		assert.NilError(t, err)
+		assert.Equal(t, 1, len(results))
+		assert.DeepEqual(t, map[string]string{"a.txt": "x"}, readSyncFiles(t, dstDir))
+	})
+}
+
+func TestSync(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+	actions := func(results awss3.SyncResults) map[awss3.Key]s3sync.Action {
+		m := make(map[awss3.Key]s3sync.Action, len(results))
+		for _, v := range results {
+			m[v.Key] = v.Action
+		}
+		return m
+	}
+
+	t.Run("upload", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("awstest/%s/", ulid.MustNew())
+		dir := t.TempDir()
+		writeSyncFiles(t, dir, map[string]string{"keep.txt": "keep", "change.txt": "old", "remove.txt": "remove"})
+		results, err := awss3.Sync(ctx, TestRegion, dir, TestBucket, prefix)
+		assert.NilError(t, err)
+		assert.Equal(t, 3, len(results.Transferred()))
+
+		// unchanged files are skipped
+		results, err = awss3.Sync(ctx, TestRegion, dir, TestBucket, prefix)
+		assert.NilError(t, err)
+		assert.Equal(t, 3, len(results.Skipped()))
+
+		// touched files with the same content are skipped
+		future := time.Now().Add(time.Hour)
+		assert.NilError(t, os.Chtimes(filepath.Join(dir, "keep.txt"), future, future))
+		writeSyncFiles(t, dir, map[string]string{"change.txt": "new", "add/new.txt": "add"})
+		assert.NilError(t, os.Chtimes(filepath.Join(dir, "change.txt"), future, future))
+		assert.NilError(t, os.Remove(filepath.Join(dir, "remove.txt")))
+
+		results, err = awss3.Sync(ctx, TestRegion, dir, TestBucket, prefix, s3sync.WithDelete(true), s3sync.WithDryRun(true))
+		assert.NilError(t, err)
+		want := map[awss3.Key]s3sync.Action{
+			awss3.Key(prefix + "add/new.txt"): s3sync.ActionUpload,
+			awss3.Key(prefix + "change.txt"):  s3sync.ActionUpload,
+			awss3.Key(prefix + "keep.txt"):    s3sync.ActionSkip,
+			awss3.Key(prefix + "remove.txt"):  s3sync.ActionDelete,
+		}
+		assert.DeepEqual(t, want, actions(results))
+		_, err = awss3.HeadObject(ctx, TestRegion, TestBucket, awss3.Key(prefix+"remove.txt"))
+		assert.NilError(t, err)
+
+		results, err = awss3.Sync(ctx, TestRegion, dir, TestBucket, prefix, s3sync.WithDelete(true))
+		assert.NilError(t, err)
+		assert.DeepEqual(t, want, actions(results))
+		_, err = awss3.HeadObject(ctx, TestRegion, TestBucket, awss3.Key(prefix+"remove.txt"))
+		assert.ErrorIs(t, err, awss3.ErrNotFound)
+		var buf bytes.Buffer
+		assert.NilError(t, awss3.GetObjectWriter(ctx, TestRegion, TestBucket, awss3.Key(prefix+"change.txt"), &buf))
+		assert.Equal(t, "new", buf.String())
+	})
+	t.Run("download", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("awstest/%s", ulid.MustNew())
+		for key, body := range map[string]string{"a.txt": "a", "sub/b.txt": "b"} {
+			_, err := awss3.PutObject(ctx, TestRegion, TestBucket, awss3.Key(prefix+"/"+key), strings.NewReader(body))
+			assert.NilError(t, err)
+		}
+		dir := t.TempDir()
+		writeSyncFiles(t, dir, map[string]string{"extra.txt": "extra", "sub/b.txt": "stale content"})
+
+		results, err := awss3.Sync(ctx, TestRegion, dir, TestBucket, prefix,
+			s3sync.WithDirection(s3sync.DirectionDownload),
+			s3sync.WithDelete(true),
+		)
+		assert.NilError(t, err)
+		assert.Equal(t, 2, len(results.Transferred()))
+		assert.Equal(t, 1, len(results.Deleted()))
+		assert.DeepEqual(t, map[string]string{"a.txt": "a", "sub/b.txt": "b"}, readSyncFiles(t, dir))
+
+		results, err = awss3.Sync(ctx, TestRegion, dir, TestBucket, prefix,
+			s3sync.WithDirection(s3sync.DirectionDownload),
+		)
+		assert.NilError(t, err)
+		assert.Equal(t, 2, len(results.Skipped()))
+	})
+	t.Run("NotFound directory", func(t *testing.T) {
+		t.Parallel()
+		_, err := awss3.Sync(ctx, TestRegion, filepath.Join(t.TempDir(), "missing"), TestBucket, "awstest/missing")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+	t.Run("download into a missing directory", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("awstest/%s", ulid.MustNew())
+		_, err := awss3.PutObject(ctx, TestRegion, TestBucket, awss3.Key(prefix+"/sub/a.txt"), strings.NewReader("a"))
+		assert.NilError(t, err)
+		dir := filepath.Join(t.TempDir(), "missing", "dir")
+		download := s3sync.WithDirection(s3sync.DirectionDownload)
+
+		results, err := awss3.Sync(ctx, TestRegion, dir, TestBucket, prefix, download, s3sync.WithDryRun(true))
+		assert.NilError(t, err)
+		assert.Equal(t, 1, len(results.Transferred()))
+		_, err = os.Stat(dir)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+
+		results, err = awss3.Sync(ctx, TestRegion, dir, TestBucket, prefix, download)
+		assert.NilError(t, err)
+		assert.Equal(t, 1, len(results.Transferred()))
+		assert.DeepEqual(t, map[string]string{"sub/a.txt": "a"}, readSyncFiles(t, dir))
+	})
+}
+
+func writeSyncFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, body := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NilError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		assert.NilError(t, os.WriteFile(p, []byte(body), 0o644))
+	}
+}
+
+func readSyncFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(b)
+		return nil
+	})
+	assert.NilError(t, err)
+	return files
+}
+
+func TestPutObject(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+
+	getTags := func(key awss3.Key) []types.Tag {
+		s3Client, err := awss3.GetClient(ctx, TestRegion)
+		assert.NilError(t, err)
+		res, err := s3Client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+			Bucket: aws.String(TestBucket),
+			Key:    key.AWSString(),
+		})
+		assert.NilError(t, err)
+		return res.TagSet
+	}
+
+	t.Run("PutObject", func(t *testing.T) {
+		t.Parallel()
+		key := fmt.Sprintf("awstest/%s.txt", ulid.MustNew())
+		body := faker.Sentence()
+		_, err := awss3.PutObject(ctx, TestRegion, TestBucket, awss3.Key(key), strings.NewReader(body))
+		assert.NilError(t, err)
+		filePaths, err := awss3.DownloadFiles(ctx, TestRegion, TestBucket, awss3.NewKeys(key), t.TempDir())
 		assert.NilError(t, err)
 		assert.Equal(t, 1, len(filePaths))
 		fileBody, err := os.ReadFile(filePaths[0])
@@ -544,6 +1404,30 @@ func TestPutObject(t *testing.T) {
 		assert.NilError(t, err)
 		assert.Equal(t, body, string(fileBody))
 	})
+	t.Run("PutObject with object attribute options", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		_, err := awss3.PutObject(ctx, TestRegion, TestBucket, key, strings.NewReader("test"),
+			s3upload.WithContentType("text/plain"),
+			s3upload.WithContentDisposition(awss3.ResponseContentDisposition(s3presigned.ContentDispositionTypeAttachment, "test.txt")),
+			s3upload.WithCacheControl("max-age=60"),
+			s3upload.WithMetadata(map[string]string{"owner": "awstest"}),
+			s3upload.WithTags(map[string]string{"env": "test"}),
+			s3upload.WithStorageClass(types.StorageClassStandard),
+			s3upload.WithChecksumAlgorithm(types.ChecksumAlgorithmSha256),
+		)
+		assert.NilError(t, err)
+		head, err := awss3.HeadObject(ctx, TestRegion, TestBucket, key)
+		assert.NilError(t, err)
+		assert.Equal(t, "text/plain", aws.ToString(head.ContentType))
+		assert.Equal(t, `attachment; filename*=UTF-8''test.txt`, aws.ToString(head.ContentDisposition))
+		assert.Equal(t, "max-age=60", aws.ToString(head.CacheControl))
+		assert.DeepEqual(t, map[string]string{"owner": "awstest"}, head.Metadata)
+		tags := getTags(key)
+		assert.Equal(t, 1, len(tags))
+		assert.Equal(t, "env", aws.ToString(tags[0].Key))
+		assert.Equal(t, "test", aws.ToString(tags[0].Value))
+	})
 	t.Run("PutObject error: non-existent bucket", func(t *testing.T) {
 		t.Parallel()
 		key := fmt.Sprintf("awstest/%s.txt", ulid.MustNew())
@@ -561,6 +1445,41 @@ func TestUploadManager(t *testing.T) {
 		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
 	)
 
+	getTags := func(key awss3.Key) []types.Tag {
+		s3Client, err := awss3.GetClient(ctx, TestRegion)
+		assert.NilError(t, err)
+		res, err := s3Client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+			Bucket: aws.String(TestBucket),
+			Key:    key.AWSString(),
+		})
+		assert.NilError(t, err)
+		return res.TagSet
+	}
+
+	t.Run("WithProgressListener", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/%s.bin", ulid.MustNew()))
+		body := bytes.Repeat([]byte("a"), 12*1024*1024)
+		var events []s3progress.Event
+		_, err := awss3.UploadManager(ctx, TestRegion, TestBucket, key, bytes.NewReader(body),
+			s3upload.WithProgressListener(func(e s3progress.Event) {
+				events = append(events, e)
+			}),
+		)
+		assert.NilError(t, err)
+		assert.Assert(t, len(events) > 2)
+		assert.Equal(t, s3progress.EventStart, events[0].Type)
+		assert.Equal(t, int64(len(body)), events[0].TotalBytes)
+		for i := 1; i < len(events); i++ {
+			assert.Assert(t, events[i].BytesTransferred >= events[i-1].BytesTransferred)
+		}
+		last := events[len(events)-1]
+		assert.Equal(t, s3progress.EventComplete, last.Type)
+		assert.Equal(t, key.String(), last.Key)
+		assert.Equal(t, int64(len(body)), last.BytesTransferred)
+		assert.Equal(t, 1, last.CompletedObjects)
+		assert.Equal(t, 1, last.ObjectCount)
+	})
 	t.Run("UploadManager", func(t *testing.T) {
 		t.Parallel()
 		key := fmt.Sprintf("awstest/%s.txt", ulid.MustNew())
@@ -589,6 +1508,30 @@ func TestUploadManager(t *testing.T) {
 		assert.NilError(t, err)
 		assert.Equal(t, body, string(fileBody))
 	})
+	t.Run("UploadManager with object attribute options", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		_, err := awss3.UploadManager(ctx, TestRegion, TestBucket, key, strings.NewReader("test"),
+			s3upload.WithContentType("text/plain"),
+			s3upload.WithContentDisposition(awss3.ResponseContentDisposition(s3presigned.ContentDispositionTypeAttachment, "test.txt")),
+			s3upload.WithCacheControl("max-age=60"),
+			s3upload.WithMetadata(map[string]string{"owner": "awstest"}),
+			s3upload.WithTags(map[string]string{"env": "test"}),
+			s3upload.WithStorageClass(types.StorageClassStandard),
+			s3upload.WithChecksumAlgorithm(types.ChecksumAlgorithmSha256),
+		)
+		assert.NilError(t, err)
+		head, err := awss3.HeadObject(ctx, TestRegion, TestBucket, key)
+		assert.NilError(t, err)
+		assert.Equal(t, "text/plain", aws.ToString(head.ContentType))
+		assert.Equal(t, `attachment; filename*=UTF-8''test.txt`, aws.ToString(head.ContentDisposition))
+		assert.Equal(t, "max-age=60", aws.ToString(head.CacheControl))
+		assert.DeepEqual(t, map[string]string{"owner": "awstest"}, head.Metadata)
+		tags := getTags(key)
+		assert.Equal(t, 1, len(tags))
+		assert.Equal(t, "env", aws.ToString(tags[0].Key))
+		assert.Equal(t, "test", aws.ToString(tags[0].Value))
+	})
 	t.Run("UploadManager error: non-existent bucket", func(t *testing.T) {
 		t.Parallel()
 		key := fmt.Sprintf("awstest/%s.txt", ulid.MustNew())
@@ -699,102 +1642,633 @@ func TestPresign(t *testing.T) {
 	})
 }
 
-func TestResponseContentDisposition(t *testing.T) {
+func TestResponseContentDisposition(t *testing.T) {
+	t.Parallel()
+	const fileName = ",あいうえお　牡蠣喰家来 サシスセソ@+$_-^|+{}"
+	t.Run("attachment", func(t *testing.T) {
+		t.Parallel()
+		actual := awss3.ResponseContentDisposition(s3presigned.ContentDispositionTypeAttachment, fileName)
+		assert.Assert(t, actual != "")
+	})
+	t.Run("inline", func(t *testing.T) {
+		t.Parallel()
+		actual := awss3.ResponseContentDisposition(s3presigned.ContentDispositionTypeInline, fileName)
+		assert.Assert(t, actual != "")
+		assert.Assert(t, strings.Contains(actual, "inline"))
+	})
+	t.Run("ascii filename", func(t *testing.T) {
+		t.Parallel()
+		actual := awss3.ResponseContentDisposition(s3presigned.ContentDispositionTypeAttachment, "simple.txt")
+		assert.Equal(t, `attachment; filename*=UTF-8''simple.txt`, actual)
+	})
+}
+
+func TestCopy(t *testing.T) {
+	t.Parallel()
+	createFixture := func(t testing.TB, ctx context.Context) awss3.Key {
+		t.Helper()
+		s3Client, err := awss3.GetClient(ctx, TestRegion)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key := fmt.Sprintf("awstest/%s.txt", ulid.MustNew())
+		uploader := transfermanager.New(s3Client)
+		input := &transfermanager.UploadObjectInput{
+			Body:    strings.NewReader("test"),
+			Bucket:  aws.String(TestBucket),
+			Key:     aws.String(key),
+			Expires: aws.Time(time.Now().Add(10 * time.Minute)),
+		}
+		if _, err := uploader.UploadObject(ctx, input); err != nil {
+			t.Fatal(err)
+		}
+		waiter := s3.NewObjectExistsWaiter(s3Client)
+		if err := waiter.Wait(ctx,
+			&s3.HeadObjectInput{Bucket: aws.String(TestBucket), Key: aws.String(key)},
+			time.Second,
+		); err != nil {
+			t.Fatal(err)
+		}
+		return awss3.Key(key)
+	}
+
+	t.Run("Copy:Same Bucket and Other Key", func(t *testing.T) {
+		t.Parallel()
+		ctx := ctxawslocal.WithContext(
+			context.Background(),
+			ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+			ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+			ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+		)
+		key := createFixture(t, ctx)
+		key2 := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		assert.NilError(t, awss3.Copy(ctx, TestRegion, TestBucket, key, key2))
+	})
+	t.Run("Copy:Same Item", func(t *testing.T) {
+		t.Parallel()
+		ctx := ctxawslocal.WithContext(
+			context.Background(),
+			ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+			ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+			ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+		)
+		key := createFixture(t, ctx)
+		assert.NilError(t, awss3.Copy(ctx, TestRegion, TestBucket, key, key))
+	})
+	t.Run("Copy with WithExpires option", func(t *testing.T) {
+		t.Parallel()
+		ctx := ctxawslocal.WithContext(
+			context.Background(),
+			ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+			ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+			ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+		)
+		key := createFixture(t, ctx)
+		key2 := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		assert.NilError(t, awss3.Copy(ctx, TestRegion, TestBucket, key, key2, s3upload.WithS3Expires(10*time.Minute)))
+	})
+	t.Run("Copy with object attribute options", func(t *testing.T) {
+		t.Parallel()
+		ctx := ctxawslocal.WithContext(
+			context.Background(),
+			ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+			ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+			ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+		)
+		key := createFixture(t, ctx)
+		key2 := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		assert.NilError(t, awss3.Copy(ctx, TestRegion, TestBucket, key, key2,
+			s3upload.WithContentType("application/json"),
+			s3upload.WithCacheControl("no-cache"),
+			s3upload.WithMetadata(map[string]string{"owner": "copy"}),
+		))
+		head, err := awss3.HeadObject(ctx, TestRegion, TestBucket, key2)
+		assert.NilError(t, err)
+		assert.Equal(t, "application/json", aws.ToString(head.ContentType))
+		assert.Equal(t, "no-cache", aws.ToString(head.CacheControl))
+		assert.DeepEqual(t, map[string]string{"owner": "copy"}, head.Metadata)
+	})
+	t.Run("Copy:NotFound", func(t *testing.T) {
+		t.Parallel()
+		ctx := ctxawslocal.WithContext(
+			context.Background(),
+			ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+			ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+			ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+		)
+		err := awss3.Copy(ctx, TestRegion, TestBucket, "NOT_FOUND", "NOT_FOUND")
+		assert.Assert(t, err != nil)
+		assert.ErrorIs(t, err, awss3.ErrNotFound)
+	})
+}
+
+func TestCopyObject(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+	s3Client, err := awss3.GetClient(ctx, TestRegion)
+	assert.NilError(t, err)
+
+	// The destination bucket is created on demand because the local MinIO only provisions TestBucket.
+	const destBucket = "test-copy"
+	_, err = s3Client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(destBucket)})
+	if err != nil {
+		var owned *types.BucketAlreadyOwnedByYou
+		assert.Assert(t, errors.As(err, &owned), err)
+	}
+
+	createFixture := func(body []byte) awss3.Key {
+		key := fmt.Sprintf("awstest/%s.txt", ulid.MustNew())
+		_, err := s3Client.PutObject(ctx, &s3.PutObjectInput{
+			Body:        bytes.NewReader(body),
+			Bucket:      aws.String(TestBucket),
+			Key:         aws.String(key),
+			ContentType: aws.String("text/plain"),
+			Metadata:    map[string]string{"owner": "test"},
+			Tagging:     aws.String("team=a"),
+		})
+		assert.NilError(t, err)
+		return awss3.Key(key)
+	}
+	getBody := func(bucket awss3.BucketName, key awss3.Key) []byte {
+		var buf bytes.Buffer
+		assert.NilError(t, awss3.GetObjectWriter(ctx, TestRegion, bucket, key, &buf))
+		return buf.Bytes()
+	}
+	getTags := func(bucket awss3.BucketName, key awss3.Key) []types.Tag {
+		res, err := s3Client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+			Bucket: bucket.AWSString(),
+			Key:    key.AWSString(),
+		})
+		assert.NilError(t, err)
+		return res.TagSet
+	}
+
+	t.Run("CopyObject:Other Bucket", func(t *testing.T) {
+		t.Parallel()
+		key := createFixture([]byte("test"))
+		res, err := awss3.CopyObject(ctx, TestRegion, TestBucket, key, destBucket, key)
+		assert.NilError(t, err)
+		assert.Equal(t, 0, res.PartCount)
+		assert.DeepEqual(t, []byte("test"), getBody(destBucket, key))
+
+		head, err := awss3.HeadObject(ctx, TestRegion, destBucket, key)
+		assert.NilError(t, err)
+		assert.Equal(t, "text/plain", aws.ToString(head.ContentType))
+		assert.DeepEqual(t, map[string]string{"owner": "test"}, head.Metadata)
+	})
+	t.Run("CopyObject:Replace metadata and tags", func(t *testing.T) {
+		t.Parallel()
+		key := createFixture([]byte("test"))
+		destKey := awss3.Key(fmt.Sprintf("awstest/%s.json", ulid.MustNew()))
+		_, err := awss3.CopyObject(ctx, TestRegion, TestBucket, key, TestBucket, destKey,
+			s3copy.WithContentType("application/json"),
+			s3copy.WithMetadata(map[string]string{"owner": "copy"}),
+			s3copy.WithTags(map[string]string{"team": "b"}),
+		)
+		assert.NilError(t, err)
+
+		head, err := awss3.HeadObject(ctx, TestRegion, TestBucket, destKey)
+		assert.NilError(t, err)
+		assert.Equal(t, "application/json", aws.ToString(head.ContentType))
+		assert.DeepEqual(t, map[string]string{"owner": "copy"}, head.Metadata)
+		tags := getTags(TestBucket, destKey)
+		assert.Equal(t, 1, len(tags))
+		assert.Equal(t, "b", aws.ToString(tags[0].Value))
+	})
+	t.Run("CopyObject:Multipart", func(t *testing.T) {
+		t.Parallel()
+		body := bytes.Repeat([]byte("0123456789"), 1200*1024) // about 11.7 MiB, 3 parts
+		key := createFixture(body)
+		client, err := awss3.NewClient(ctx, TestRegion)
+		assert.NilError(t, err)
+		res, err := client.CopyObject(ctx, TestBucket, key, destBucket, key,
+			s3copy.WithMultipartThreshold(s3copy.MinPartSize),
+			s3copy.WithPartSize(s3copy.MinPartSize),
+			s3copy.WithConcurrency(2),
+		)
+		assert.NilError(t, err)
+		assert.Equal(t, 3, res.PartCount)
+		assert.Assert(t, bytes.Equal(body, getBody(destBucket, key)))
+
+		head, err := awss3.HeadObject(ctx, TestRegion, destBucket, key)
+		assert.NilError(t, err)
+		assert.Equal(t, "text/plain", aws.ToString(head.ContentType))
+		assert.DeepEqual(t, map[string]string{"owner": "test"}, head.Metadata)
+		tags := getTags(destBucket, key)
+		assert.Equal(t, 1, len(tags))
+		assert.Equal(t, "a", aws.ToString(tags[0].Value))
+	})
+	t.Run("CopyObject:Storage class", func(t *testing.T) {
+		t.Parallel()
+		body := bytes.Repeat([]byte("0123456789"), 600*1024) // about 5.9 MiB, 2 parts
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		_, err := s3Client.PutObject(ctx, &s3.PutObjectInput{
+			Body:         bytes.NewReader(body),
+			Bucket:       aws.String(TestBucket),
+			Key:          key.AWSString(),
+			StorageClass: types.StorageClassReducedRedundancy,
+		})
+		assert.NilError(t, err)
+		multipart := []s3copy.OptionS3Copy{
+			s3copy.WithMultipartThreshold(s3copy.MinPartSize),
+			s3copy.WithPartSize(s3copy.MinPartSize),
+		}
+
+		tests := []struct {
+			name string
+			opts []s3copy.OptionS3Copy
+			want types.StorageClass
+		}{
+			{name: "single", want: types.StorageClassReducedRedundancy},
+			{name: "multipart", opts: multipart, want: types.StorageClassReducedRedundancy},
+			{
+				name: "override",
+				opts: append(multipart, s3copy.WithStorageClass(types.StorageClassStandard)),
+				want: types.StorageClassStandard,
+			},
+		}
+		for _, tt := range tests {
+			destKey := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+			_, err := awss3.CopyObject(ctx, TestRegion, TestBucket, key, destBucket, destKey, tt.opts...)
+			assert.NilError(t, err, tt.name)
+
+			head, err := awss3.HeadObject(ctx, TestRegion, destBucket, destKey)
+			assert.NilError(t, err, tt.name)
+			got := head.StorageClass
+			if got == "" {
+				// S3 omits the storage class header for STANDARD objects.
+				got = types.StorageClassStandard
+			}
+			assert.Equal(t, tt.want, got, tt.name)
+		}
+	})
+	t.Run("CopyObject:NotFound", func(t *testing.T) {
+		t.Parallel()
+		_, err := awss3.CopyObject(ctx, TestRegion, TestBucket, "NOT_FOUND", destBucket, "NOT_FOUND")
+		assert.ErrorIs(t, err, awss3.ErrNotFound)
+	})
+}
+
+func TestObjectTags(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+
+	t.Run("Put/Get/Delete", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		_, err := awss3.PutObject(ctx, TestRegion, TestBucket, key, strings.NewReader("test"),
+			s3upload.WithTags(map[string]string{"team": "a"}))
+		assert.NilError(t, err)
+
+		tags, err := awss3.GetObjectTags(ctx, TestRegion, TestBucket, key)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, tags, map[string]string{"team": "a"})
+
+		assert.NilError(t, awss3.PutObjectTags(ctx, TestRegion, TestBucket, key,
+			map[string]string{"retention": "30d", "owner": "batch"}))
+		tags, err = awss3.GetObjectTags(ctx, TestRegion, TestBucket, key)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, tags, map[string]string{"retention": "30d", "owner": "batch"})
+
+		assert.NilError(t, awss3.DeleteObjectTags(ctx, TestRegion, TestBucket, key))
+		tags, err = awss3.GetObjectTags(ctx, TestRegion, TestBucket, key)
+		assert.NilError(t, err)
+		assert.Equal(t, len(tags), 0)
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		_, err := awss3.GetObjectTags(ctx, TestRegion, TestBucket, key)
+		assert.ErrorIs(t, err, awss3.ErrNotFound)
+		err = awss3.PutObjectTags(ctx, TestRegion, TestBucket, key, map[string]string{"a": "b"})
+		assert.ErrorIs(t, err, awss3.ErrNotFound)
+		err = awss3.DeleteObjectTags(ctx, TestRegion, TestBucket, key)
+		assert.ErrorIs(t, err, awss3.ErrNotFound)
+	})
+}
+
+func TestReplaceMetadata(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+
+	t.Run("keeps content headers and tags", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		_, err := awss3.PutObject(ctx, TestRegion, TestBucket, key, strings.NewReader("test"),
+			s3upload.WithContentType("text/plain"),
+			s3upload.WithCacheControl("max-age=60"),
+			s3upload.WithMetadata(map[string]string{"owner": "test"}),
+			s3upload.WithTags(map[string]string{"team": "a"}),
+		)
+		assert.NilError(t, err)
+
+		res, err := awss3.ReplaceMetadata(ctx, TestRegion, TestBucket, key, map[string]string{"state": "archived"})
+		assert.NilError(t, err)
+		assert.Assert(t, res.ETag != "")
+
+		head, err := awss3.HeadObject(ctx, TestRegion, TestBucket, key)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, head.Metadata, map[string]string{"state": "archived"})
+		assert.Equal(t, aws.ToString(head.ContentType), "text/plain")
+		assert.Equal(t, aws.ToString(head.CacheControl), "max-age=60")
+		tags, err := awss3.GetObjectTags(ctx, TestRegion, TestBucket, key)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, tags, map[string]string{"team": "a"})
+
+		var buf bytes.Buffer
+		assert.NilError(t, awss3.GetObjectWriter(ctx, TestRegion, TestBucket, key, &buf))
+		assert.Equal(t, buf.String(), "test")
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		_, err := awss3.ReplaceMetadata(ctx, TestRegion, TestBucket, key, map[string]string{"a": "b"})
+		assert.ErrorIs(t, err, awss3.ErrNotFound)
+	})
+}
+
+func TestLifecycleRules(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+	s3Client, err := awss3.GetClient(ctx, TestRegion)
+	assert.NilError(t, err)
+
+	// A dedicated bucket keeps the rules away from the other tests.
+	bucket := awss3.BucketName(strings.ToLower("test-lifecycle-" + ulid.MustNew().String()))
+	_, err = s3Client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: bucket.AWSString()})
+	assert.NilError(t, err)
+	t.Cleanup(func() {
+		_, _ = s3Client.DeleteBucket(context.WithoutCancel(ctx), &s3.DeleteBucketInput{Bucket: bucket.AWSString()})
+	})
+
+	rules, err := awss3.GetLifecycleRules(ctx, TestRegion, bucket)
+	assert.NilError(t, err)
+	assert.Equal(t, len(rules), 0)
+
+	tmp := awss3.LifecycleRule{ID: "tmp", Prefix: "tmp/", ExpirationDays: 1, NoncurrentVersionExpirationDays: 1}
+	reports := awss3.LifecycleRule{
+		ID:             "reports",
+		Prefix:         "reports/",
+		Tags:           map[string]string{"retention": "short"},
+		ExpirationDays: 30,
+	}
+	assert.NilError(t, awss3.PutLifecycleRule(ctx, TestRegion, bucket, tmp))
+	assert.NilError(t, awss3.PutLifecycleRule(ctx, TestRegion, bucket, reports))
+	// putting the same rule again is idempotent
+	assert.NilError(t, awss3.PutLifecycleRule(ctx, TestRegion, bucket, reports))
+
+	rules, err = awss3.GetLifecycleRules(ctx, TestRegion, bucket)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, rules, []awss3.LifecycleRule{tmp, reports})
+
+	// a rule is replaced by ID and the other rules are kept
+	tmp.ExpirationDays = 3
+	tmp.Disabled = true
+	assert.NilError(t, awss3.PutLifecycleRule(ctx, TestRegion, bucket, tmp))
+	rules, err = awss3.GetLifecycleRules(ctx, TestRegion, bucket)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, rules, []awss3.LifecycleRule{tmp, reports})
+
+	assert.NilError(t, awss3.DeleteLifecycleRule(ctx, TestRegion, bucket, "tmp"))
+	assert.NilError(t, awss3.DeleteLifecycleRule(ctx, TestRegion, bucket, "missing"))
+	rules, err = awss3.GetLifecycleRules(ctx, TestRegion, bucket)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, rules, []awss3.LifecycleRule{reports})
+
+	assert.NilError(t, awss3.DeleteLifecycleRule(ctx, TestRegion, bucket, "reports"))
+	rules, err = awss3.GetLifecycleRules(ctx, TestRegion, bucket)
+	assert.NilError(t, err)
+	assert.Equal(t, len(rules), 0)
+
+	err = awss3.PutLifecycleRule(ctx, TestRegion, bucket, awss3.LifecycleRule{Prefix: "tmp/", ExpirationDays: 1})
+	assert.ErrorContains(t, err, "ID is required")
+}
+
+func TestLifecycleRules_TransitionDefaultMinimumObjectSize(t *testing.T) {
+	t.Parallel()
+	// Minio does not keep the TransitionDefaultMinimumObjectSize of a bucket,
+	// so a proxy reports one for the bucket and records the one that is put.
+	const header = "X-Amz-Transition-Default-Minimum-Object-Size"
+	target, err := url.Parse("http://127.0.0.1:29000")
+	assert.NilError(t, err)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.ModifyResponse = func(resp *http.Response) error {
+		if _, ok := resp.Request.URL.Query()["lifecycle"]; ok && resp.Request.Method == http.MethodGet {
+			resp.Header.Set(header, string(types.TransitionDefaultMinimumObjectSizeVariesByStorageClass))
+		}
+		return nil
+	}
+	var (
+		mu  sync.Mutex
+		put []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["lifecycle"]; ok && r.Method == http.MethodPut {
+			mu.Lock()
+			put = append(put, r.Header.Get(header))
+			mu.Unlock()
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint(server.URL),
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+	client, err := awss3.NewClient(ctx, TestRegion)
+	assert.NilError(t, err)
+	s3Client, err := awss3.GetClient(ctx, TestRegion)
+	assert.NilError(t, err)
+
+	bucket := awss3.BucketName(strings.ToLower("test-lifecycle-" + ulid.MustNew().String()))
+	_, err = s3Client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: bucket.AWSString()})
+	assert.NilError(t, err)
+	t.Cleanup(func() {
+		_, _ = s3Client.DeleteBucket(context.WithoutCancel(ctx), &s3.DeleteBucketInput{Bucket: bucket.AWSString()})
+	})
+
+	keep := awss3.LifecycleRule{ID: "keep", Prefix: "keep/", ExpirationDays: 7}
+	tmp := awss3.LifecycleRule{ID: "tmp", Prefix: "tmp/", ExpirationDays: 1}
+	assert.NilError(t, client.PutLifecycleRule(ctx, bucket, keep))
+	assert.NilError(t, client.PutLifecycleRule(ctx, bucket, tmp))
+	assert.NilError(t, client.DeleteLifecycleRule(ctx, bucket, "tmp"))
+	assert.NilError(t, client.DeleteLifecycleRule(ctx, bucket, "keep"))
+
+	mu.Lock()
+	defer mu.Unlock()
+	// the first rule is put on a bucket without a lifecycle configuration, the last deletion deletes it
+	assert.DeepEqual(t, put, []string{
+		"",
+		string(types.TransitionDefaultMinimumObjectSizeVariesByStorageClass),
+		string(types.TransitionDefaultMinimumObjectSizeVariesByStorageClass),
+	})
+}
+
+func TestGetCORSRules(t *testing.T) {
 	t.Parallel()
-	const fileName = ",あいうえお　牡蠣喰家来 サシスセソ@+$_-^|+{}"
-	t.Run("attachment", func(t *testing.T) {
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+	// MinIO does not implement PutBucketCors, so only a bucket without CORS configuration is covered.
+	rules, err := awss3.GetCORSRules(ctx, TestRegion, TestBucket)
+	assert.NilError(t, err)
+	assert.Equal(t, len(rules), 0)
+}
+
+func TestConditionalRequests(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+	createFixture := func(body string) (awss3.Key, string) {
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		res, err := awss3.PutObject(ctx, TestRegion, TestBucket, key, strings.NewReader(body))
+		assert.NilError(t, err)
+		return key, aws.ToString(res.ETag)
+	}
+
+	t.Run("PutObject:IfNoneMatch", func(t *testing.T) {
 		t.Parallel()
-		actual := awss3.ResponseContentDisposition(s3presigned.ContentDispositionTypeAttachment, fileName)
-		assert.Assert(t, actual != "")
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		_, err := awss3.PutObject(ctx, TestRegion, TestBucket, key, strings.NewReader("first"),
+			s3upload.WithIfNoneMatch("*"))
+		assert.NilError(t, err)
+		_, err = awss3.PutObject(ctx, TestRegion, TestBucket, key, strings.NewReader("second"),
+			s3upload.WithIfNoneMatch("*"))
+		assert.ErrorIs(t, err, awss3.ErrPreconditionFailed)
 	})
-	t.Run("inline", func(t *testing.T) {
+	t.Run("PutObject:IfMatch", func(t *testing.T) {
 		t.Parallel()
-		actual := awss3.ResponseContentDisposition(s3presigned.ContentDispositionTypeInline, fileName)
-		assert.Assert(t, actual != "")
-		assert.Assert(t, strings.Contains(actual, "inline"))
+		key, etag := createFixture("first")
+		_, err := awss3.PutObject(ctx, TestRegion, TestBucket, key, strings.NewReader("second"),
+			s3upload.WithIfMatch(etag))
+		assert.NilError(t, err)
+		_, err = awss3.PutObject(ctx, TestRegion, TestBucket, key, strings.NewReader("third"),
+			s3upload.WithIfMatch(etag))
+		assert.ErrorIs(t, err, awss3.ErrPreconditionFailed)
 	})
-	t.Run("ascii filename", func(t *testing.T) {
+	t.Run("UploadManager:IfNoneMatch", func(t *testing.T) {
 		t.Parallel()
-		actual := awss3.ResponseContentDisposition(s3presigned.ContentDispositionTypeAttachment, "simple.txt")
-		assert.Equal(t, `attachment; filename*=UTF-8''simple.txt`, actual)
+		key, _ := createFixture("first")
+		_, err := awss3.UploadManager(ctx, TestRegion, TestBucket, key, strings.NewReader("second"),
+			s3upload.WithIfNoneMatch("*"))
+		assert.ErrorIs(t, err, awss3.ErrPreconditionFailed)
 	})
-}
-
-func TestCopy(t *testing.T) {
-	t.Parallel()
-	createFixture := func(t testing.TB, ctx context.Context) awss3.Key {
-		t.Helper()
-		s3Client, err := awss3.GetClient(ctx, TestRegion)
-		if err != nil {
-			t.Fatal(err)
-		}
-		key := fmt.Sprintf("awstest/%s.txt", ulid.MustNew())
-		uploader := transfermanager.New(s3Client)
-		input := &transfermanager.UploadObjectInput{
-			Body:    strings.NewReader("test"),
-			Bucket:  aws.String(TestBucket),
-			Key:     aws.String(key),
-			Expires: aws.Time(time.Now().Add(10 * time.Minute)),
-		}
-		if _, err := uploader.UploadObject(ctx, input); err != nil {
-			t.Fatal(err)
-		}
-		waiter := s3.NewObjectExistsWaiter(s3Client)
-		if err := waiter.Wait(ctx,
-			&s3.HeadObjectInput{Bucket: aws.String(TestBucket), Key: aws.String(key)},
-			time.Second,
-		); err != nil {
-			t.Fatal(err)
-		}
-		return awss3.Key(key)
-	}
-
-	t.Run("Copy:Same Bucket and Other Key", func(t *testing.T) {
+	t.Run("CompleteMultipartUpload:IfNoneMatch", func(t *testing.T) {
 		t.Parallel()
-		ctx := ctxawslocal.WithContext(
-			context.Background(),
-			ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
-			ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
-			ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+		key, _ := createFixture("first")
+		uploadID, err := awss3.CreateMultipartUpload(ctx, TestRegion, TestBucket, key)
+		assert.NilError(t, err)
+		part, err := awss3.UploadPart(ctx, TestRegion, TestBucket, key, uploadID, 1, strings.NewReader("second"))
+		assert.NilError(t, err)
+		_, err = awss3.CompleteMultipartUpload(ctx, TestRegion, TestBucket, key, uploadID,
+			[]types.CompletedPart{{ETag: part.ETag, PartNumber: aws.Int32(1)}},
+			s3upload.WithIfNoneMatch("*"),
 		)
-		key := createFixture(t, ctx)
-		key2 := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
-		assert.NilError(t, awss3.Copy(ctx, TestRegion, TestBucket, key, key2))
+		assert.ErrorIs(t, err, awss3.ErrPreconditionFailed)
+		assert.NilError(t, awss3.AbortMultipartUpload(ctx, TestRegion, TestBucket, key, uploadID))
 	})
-	t.Run("Copy:Same Item", func(t *testing.T) {
+	t.Run("HeadObject:Preconditions", func(t *testing.T) {
 		t.Parallel()
-		ctx := ctxawslocal.WithContext(
-			context.Background(),
-			ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
-			ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
-			ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
-		)
-		key := createFixture(t, ctx)
-		assert.NilError(t, awss3.Copy(ctx, TestRegion, TestBucket, key, key))
+		key, etag := createFixture("test")
+		_, err := awss3.HeadObject(ctx, TestRegion, TestBucket, key, s3head.WithIfMatch(etag))
+		assert.NilError(t, err)
+		_, err = awss3.HeadObject(ctx, TestRegion, TestBucket, key, s3head.WithIfMatch(`"0123456789abcdef"`))
+		assert.ErrorIs(t, err, awss3.ErrPreconditionFailed)
+		_, err = awss3.HeadObject(ctx, TestRegion, TestBucket, key, s3head.WithIfNoneMatch(etag))
+		assert.ErrorIs(t, err, awss3.ErrNotModified)
+		_, err = awss3.HeadObject(ctx, TestRegion, TestBucket, key,
+			s3head.WithIfModifiedSince(time.Now().Add(time.Hour)))
+		assert.ErrorIs(t, err, awss3.ErrNotModified)
 	})
-	t.Run("Copy with WithExpires option", func(t *testing.T) {
+	t.Run("GetObjectWriter:Preconditions", func(t *testing.T) {
 		t.Parallel()
-		ctx := ctxawslocal.WithContext(
-			context.Background(),
-			ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
-			ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
-			ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
-		)
-		key := createFixture(t, ctx)
-		key2 := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
-		assert.NilError(t, awss3.Copy(ctx, TestRegion, TestBucket, key, key2, s3upload.WithS3Expires(10*time.Minute)))
+		key, etag := createFixture("test")
+		var buf bytes.Buffer
+		assert.NilError(t, awss3.GetObjectWriter(ctx, TestRegion, TestBucket, key, &buf, s3download.WithIfMatch(etag)))
+		assert.Equal(t, "test", buf.String())
+		err := awss3.GetObjectWriter(ctx, TestRegion, TestBucket, key, io.Discard,
+			s3download.WithIfMatch(`"0123456789abcdef"`))
+		assert.ErrorIs(t, err, awss3.ErrPreconditionFailed)
+		_, err = awss3.GetObjectRange(ctx, TestRegion, TestBucket, key, 0, 1, s3download.WithIfNoneMatch(etag))
+		assert.ErrorIs(t, err, awss3.ErrNotModified)
 	})
-	t.Run("Copy:NotFound", func(t *testing.T) {
+	t.Run("Versions", func(t *testing.T) {
 		t.Parallel()
-		ctx := ctxawslocal.WithContext(
-			context.Background(),
-			ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
-			ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
-			ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
-		)
-		err := awss3.Copy(ctx, TestRegion, TestBucket, "NOT_FOUND", "NOT_FOUND")
+		s3Client, err := awss3.GetClient(ctx, TestRegion)
+		assert.NilError(t, err)
+		// The versioned bucket is created on demand because the local MinIO only provisions TestBucket.
+		const bucket = "test-versioned"
+		_, err = s3Client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(bucket)})
+		if err != nil {
+			var owned *types.BucketAlreadyOwnedByYou
+			assert.Assert(t, errors.As(err, &owned), err)
+		}
+		_, err = s3Client.PutBucketVersioning(ctx, &s3.PutBucketVersioningInput{
+			Bucket:                  aws.String(bucket),
+			VersioningConfiguration: &types.VersioningConfiguration{Status: types.BucketVersioningStatusEnabled},
+		})
+		assert.NilError(t, err)
+
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		first, err := awss3.PutObject(ctx, TestRegion, bucket, key, strings.NewReader("first"))
+		assert.NilError(t, err)
+		versionID := aws.ToString(first.VersionId)
+		assert.Assert(t, versionID != "")
+		_, err = awss3.PutObject(ctx, TestRegion, bucket, key, strings.NewReader("second"))
+		assert.NilError(t, err)
+
+		head, err := awss3.HeadObject(ctx, TestRegion, bucket, key, s3head.WithVersionID(versionID))
+		assert.NilError(t, err)
+		assert.Equal(t, versionID, aws.ToString(head.VersionId))
+		var buf bytes.Buffer
+		assert.NilError(t, awss3.GetObjectWriter(ctx, TestRegion, bucket, key, &buf, s3download.WithVersionID(versionID)))
+		assert.Equal(t, "first", buf.String())
+		r, err := awss3.OpenObject(ctx, TestRegion, bucket, key, s3download.WithVersionID(versionID))
+		assert.NilError(t, err)
+		b, err := io.ReadAll(r)
+		assert.NilError(t, err)
+		assert.Equal(t, "first", string(b))
+		assert.NilError(t, r.Close())
+		presigned, err := awss3.Presign(ctx, TestRegion, bucket, key, s3presigned.WithVersionID(versionID))
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(presigned, "versionId="+versionID))
+
+		_, err = awss3.DeleteObject(ctx, TestRegion, bucket, key, s3delete.WithVersionID(versionID))
+		assert.NilError(t, err)
+		_, err = awss3.HeadObject(ctx, TestRegion, bucket, key, s3head.WithVersionID(versionID))
 		assert.Assert(t, err != nil)
-		assert.ErrorIs(t, err, awss3.ErrNotFound)
+		buf.Reset()
+		assert.NilError(t, awss3.GetObjectWriter(ctx, TestRegion, bucket, key, &buf))
+		assert.Equal(t, "second", buf.String())
 	})
 }
 
@@ -1136,6 +2610,128 @@ func TestSelectCSVAll(t *testing.T) {
 	})
 }
 
+func TestSelectObject(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+	type Row struct {
+		ID     int    `json:"id"`
+		Name   string `json:"name"`
+		Detail string `json:"detail"`
+	}
+	want := []Row{
+		{ID: 1, Name: "hoge", Detail: "あ髙社🍣"},
+		{ID: 2, Name: "fuga", Detail: "い髙社🍣"},
+		{ID: 3, Name: "piyo", Detail: "う髙社🍣"},
+	}
+	createFixture := func(body string) awss3.Key {
+		key := awss3.Key(fmt.Sprintf("awstest/%s.json", ulid.MustNew()))
+		_, err := awss3.PutObject(ctx, TestRegion, TestBucket, key, strings.NewReader(body))
+		assert.NilError(t, err)
+		return key
+	}
+	jsonLines := func(rows []Row) string {
+		var buf bytes.Buffer
+		for _, row := range rows {
+			b, err := json.Marshal(row)
+			assert.NilError(t, err)
+			buf.Write(b)
+			buf.WriteByte('\n')
+		}
+		return buf.String()
+	}
+
+	t.Run("JSON Lines", func(t *testing.T) {
+		t.Parallel()
+		key := createFixture(jsonLines(want))
+		var got []Row
+		for row, err := range awss3.SelectObject[Row](ctx, TestRegion, TestBucket, key, "SELECT * FROM S3Object s") {
+			assert.NilError(t, err)
+			got = append(got, row)
+		}
+		assert.DeepEqual(t, got, want)
+	})
+	t.Run("JSON Document", func(t *testing.T) {
+		t.Parallel()
+		b, err := json.Marshal(map[string][]Row{"rows": want})
+		assert.NilError(t, err)
+		key := createFixture(string(b))
+		var got []Row
+		for row, err := range awss3.SelectObject[Row](ctx, TestRegion, TestBucket, key,
+			"SELECT * FROM S3Object[*].rows[*] s WHERE s.id > 1", s3select.WithJSONDocumentInput()) {
+			assert.NilError(t, err)
+			got = append(got, row)
+		}
+		assert.DeepEqual(t, got, want[1:])
+	})
+	t.Run("Client with stats", func(t *testing.T) {
+		t.Parallel()
+		body := jsonLines(want)
+		key := createFixture(body)
+		client, err := awss3.NewClient(ctx, TestRegion)
+		assert.NilError(t, err)
+
+		var final []s3select.Stats
+		records := client.SelectObject(ctx, TestBucket, key, "SELECT s.name FROM S3Object s",
+			s3select.WithStatsListener(func(s s3select.Stats) {
+				if s.Final {
+					final = append(final, s)
+				}
+			}))
+		var names []string
+		for row, err := range awss3.DecodeRecords[Row](records) {
+			assert.NilError(t, err)
+			names = append(names, row.Name)
+		}
+		assert.DeepEqual(t, names, []string{"hoge", "fuga", "piyo"})
+		assert.Equal(t, len(final), 1)
+		assert.Equal(t, final[0].BytesScanned, int64(len(body)))
+		assert.Assert(t, final[0].BytesReturned > 0)
+	})
+	t.Run("break stops the query", func(t *testing.T) {
+		t.Parallel()
+		key := createFixture(jsonLines(want))
+		var got []Row
+		for row, err := range awss3.SelectObject[Row](ctx, TestRegion, TestBucket, key, "SELECT * FROM S3Object s") {
+			assert.NilError(t, err)
+			got = append(got, row)
+			break
+		}
+		assert.DeepEqual(t, got, want[:1])
+	})
+	t.Run("decode error", func(t *testing.T) {
+		t.Parallel()
+		key := createFixture(`{"id":"one"}` + "\n" + `{"id":2}` + "\n")
+		var (
+			ids  []int
+			errs int
+		)
+		for row, err := range awss3.SelectObject[Row](ctx, TestRegion, TestBucket, key, "SELECT * FROM S3Object s") {
+			if err != nil {
+				errs++
+				continue
+			}
+			ids = append(ids, row.ID)
+		}
+		assert.Equal(t, errs, 1)
+		assert.DeepEqual(t, ids, []int{2})
+	})
+	t.Run("invalid query", func(t *testing.T) {
+		t.Parallel()
+		key := createFixture(jsonLines(want))
+		var errs []error
+		for _, err := range awss3.SelectObject[Row](ctx, TestRegion, TestBucket, key, "SELECT FROM") {
+			errs = append(errs, err)
+		}
+		assert.Equal(t, len(errs), 1)
+		assert.Assert(t, errs[0] != nil)
+	})
+}
+
 func TestSelectCSVHeaders(t *testing.T) {
 	t.Parallel()
 	type TestCSV string
@@ -1216,54 +2812,229 @@ func TestPresignPutObject(t *testing.T) {
 		content := []byte("Hello World")
 		req, err := http.NewRequest(http.MethodPut, presignedURL, bytes.NewReader(content))
 		if err != nil {
-			return err
+			return err
+		}
+
+		req.Header.Set("Content-Type", "text/plain")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("failed to upload file, status code: %d", resp.StatusCode)
+		}
+
+		return nil
+	}
+
+	confirmedUploadedObject := func(ctx context.Context, key awss3.Key) error {
+		_, err := awss3.HeadObject(ctx, TestRegion, TestBucket, key)
+		return err
+	}
+
+	t.Run("Presign", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key("test_presign_put_object_01.txt")
+		pURL, err := awss3.PresignPutObject(ctx, TestRegion, TestBucket, key)
+		assert.NilError(t, err)
+		assert.Assert(t, pURL != "")
+
+		err = uploadTxtByPresignedPutObjectURL(pURL)
+		assert.NilError(t, err)
+		err = confirmedUploadedObject(ctx, key)
+		assert.NilError(t, err)
+	})
+	t.Run("Presign with WithExpires option", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key("test_presign_put_object_expires_01.txt")
+		pURL, err := awss3.PresignPutObject(ctx, TestRegion, TestBucket, key,
+			s3presigned.WithPresignExpires(30*time.Minute),
+		)
+		assert.NilError(t, err)
+		assert.Assert(t, pURL != "")
+
+		err = uploadTxtByPresignedPutObjectURL(pURL)
+		assert.NilError(t, err)
+		err = confirmedUploadedObject(ctx, key)
+		assert.NilError(t, err)
+	})
+	t.Run("PresignPutObjectRequest", func(t *testing.T) {
+		t.Parallel()
+		content := []byte("Hello World")
+		sum := sha256.Sum256(content)
+		checksum := base64.StdEncoding.EncodeToString(sum[:])
+		presign := func(t *testing.T, key awss3.Key) *awss3.PresignedRequest {
+			t.Helper()
+			req, err := awss3.PresignPutObjectRequest(ctx, TestRegion, TestBucket, key,
+				s3presigned.WithContentType("text/plain"),
+				s3presigned.WithContentLength(int64(len(content))),
+				s3presigned.WithChecksumSHA256(checksum),
+				s3presigned.WithMetadata(map[string]string{"owner": "api"}),
+				s3presigned.WithPresignFileName("hello.txt"),
+			)
+			assert.NilError(t, err)
+			assert.Equal(t, http.MethodPut, req.Method)
+			assert.Equal(t, "text/plain", req.Headers.Get("Content-Type"))
+			assert.Equal(t, "api", req.Headers.Get("X-Amz-Meta-Owner"))
+			assert.Equal(t, "", req.Headers.Get("Host"))
+			return req
+		}
+		upload := func(req *awss3.PresignedRequest, header http.Header, body []byte) (int, error) {
+			httpReq, err := http.NewRequest(req.Method, req.URL, bytes.NewReader(body))
+			if err != nil {
+				return 0, err
+			}
+			httpReq.Header = header
+			httpReq.ContentLength = int64(len(body))
+			resp, err := http.DefaultClient.Do(httpReq)
+			if err != nil {
+				return 0, err
+			}
+			defer resp.Body.Close()
+			return resp.StatusCode, nil
+		}
+
+		t.Run("signed headers", func(t *testing.T) {
+			t.Parallel()
+			key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+			req := presign(t, key)
+			status, err := upload(req, req.Headers.Clone(), content)
+			assert.NilError(t, err)
+			assert.Equal(t, http.StatusOK, status)
+
+			head, err := awss3.HeadObject(ctx, TestRegion, TestBucket, key)
+			assert.NilError(t, err)
+			assert.Equal(t, "text/plain", aws.ToString(head.ContentType))
+			assert.Equal(t, `attachment; filename*=UTF-8''hello.txt`, aws.ToString(head.ContentDisposition))
+			assert.DeepEqual(t, map[string]string{"owner": "api"}, head.Metadata)
+		})
+		t.Run("mismatched content type", func(t *testing.T) {
+			t.Parallel()
+			key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+			req := presign(t, key)
+			header := req.Headers.Clone()
+			header.Set("Content-Type", "application/octet-stream")
+			status, err := upload(req, header, content)
+			assert.NilError(t, err)
+			assert.Equal(t, http.StatusForbidden, status)
+		})
+		t.Run("mismatched body", func(t *testing.T) {
+			t.Parallel()
+			key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+			md5sum := md5.Sum(content)
+			req, err := awss3.PresignPutObjectRequest(ctx, TestRegion, TestBucket, key,
+				s3presigned.WithContentMD5(base64.StdEncoding.EncodeToString(md5sum[:])),
+			)
+			assert.NilError(t, err)
+			status, err := upload(req, req.Headers.Clone(), []byte("Hello Worle"))
+			assert.NilError(t, err)
+			assert.Assert(t, status != http.StatusOK)
+			_, err = awss3.HeadObject(ctx, TestRegion, TestBucket, key)
+			assert.ErrorIs(t, err, awss3.ErrNotFound)
+		})
+	})
+}
+
+func TestPresignPostObject(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+
+	// postForm uploads content like a browser form: the fields first, then the file.
+	postForm := func(post *awss3.PresignedPost, fields map[string]string, content []byte) (int, error) {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		for k, v := range post.Fields {
+			if err := w.WriteField(k, v); err != nil {
+				return 0, err
+			}
+		}
+		for k, v := range fields {
+			if err := w.WriteField(k, v); err != nil {
+				return 0, err
+			}
+		}
+		fw, err := w.CreateFormFile("file", "upload.txt")
+		if err != nil {
+			return 0, err
 		}
-
-		req.Header.Set("Content-Type", "text/plain")
-		resp, err := http.DefaultClient.Do(req)
+		if _, err := fw.Write(content); err != nil {
+			return 0, err
+		}
+		if err := w.Close(); err != nil {
+			return 0, err
+		}
+		resp, err := http.Post(post.URL, w.FormDataContentType(), &body)
 		if err != nil {
-			return err
+			return 0, err
 		}
-
 		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("failed to upload file, status code: %d", resp.StatusCode)
-		}
-
-		return nil
-	}
-
-	confirmedUploadedObject := func(ctx context.Context, key awss3.Key) error {
-		_, err := awss3.HeadObject(ctx, TestRegion, TestBucket, key)
-		return err
+		return resp.StatusCode, nil
 	}
 
-	t.Run("Presign", func(t *testing.T) {
+	t.Run("PresignPostObject", func(t *testing.T) {
 		t.Parallel()
-		key := awss3.Key("test_presign_put_object_01.txt")
-		pURL, err := awss3.PresignPutObject(ctx, TestRegion, TestBucket, key)
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		post, err := awss3.PresignPostObject(ctx, TestRegion, TestBucket, key,
+			s3presignedpost.WithContentLengthRange(1, 100),
+			s3presignedpost.WithContentType("text/plain"),
+			s3presignedpost.WithMetadata(map[string]string{"owner": "browser"}),
+			s3presignedpost.WithSuccessActionStatus(http.StatusCreated),
+			s3presignedpost.WithPresignExpires(time.Minute),
+		)
 		assert.NilError(t, err)
-		assert.Assert(t, pURL != "")
+		assert.Equal(t, key.String(), post.Fields["key"])
+		assert.Equal(t, "text/plain", post.Fields["Content-Type"])
+		assert.Equal(t, "browser", post.Fields["x-amz-meta-owner"])
+		assert.Equal(t, "201", post.Fields["success_action_status"])
+		assert.Assert(t, post.Fields["policy"] != "")
 
-		err = uploadTxtByPresignedPutObjectURL(pURL)
+		status, err := postForm(post, nil, []byte("Hello World"))
 		assert.NilError(t, err)
-		err = confirmedUploadedObject(ctx, key)
+		assert.Equal(t, http.StatusCreated, status)
+		head, err := awss3.HeadObject(ctx, TestRegion, TestBucket, key)
 		assert.NilError(t, err)
+		assert.Equal(t, "text/plain", aws.ToString(head.ContentType))
+		assert.DeepEqual(t, map[string]string{"owner": "browser"}, head.Metadata)
 	})
-	t.Run("Presign with WithExpires option", func(t *testing.T) {
+	t.Run("PresignPostObject:Too large", func(t *testing.T) {
 		t.Parallel()
-		key := awss3.Key("test_presign_put_object_expires_01.txt")
-		pURL, err := awss3.PresignPutObject(ctx, TestRegion, TestBucket, key,
-			s3presigned.WithPresignExpires(30*time.Minute),
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		post, err := awss3.PresignPostObject(ctx, TestRegion, TestBucket, key,
+			s3presignedpost.WithContentLengthRange(1, 5),
 		)
 		assert.NilError(t, err)
-		assert.Assert(t, pURL != "")
-
-		err = uploadTxtByPresignedPutObjectURL(pURL)
+		status, err := postForm(post, nil, []byte("Hello World"))
 		assert.NilError(t, err)
-		err = confirmedUploadedObject(ctx, key)
+		assert.Equal(t, http.StatusBadRequest, status)
+		_, err = awss3.HeadObject(ctx, TestRegion, TestBucket, key)
+		assert.ErrorIs(t, err, awss3.ErrNotFound)
+	})
+	t.Run("PresignPostObject:Key and Content-Type starts-with", func(t *testing.T) {
+		t.Parallel()
+		prefix := fmt.Sprintf("awstest/%s/", ulid.MustNew())
+		post, err := awss3.PresignPostObject(ctx, TestRegion, TestBucket, awss3.Key(prefix+"${filename}"),
+			s3presignedpost.WithKeyStartsWith(prefix),
+			s3presignedpost.WithContentTypeStartsWith("text/"),
+		)
+		assert.NilError(t, err)
+		status, err := postForm(post, map[string]string{"Content-Type": "text/csv"}, []byte("a,b"))
 		assert.NilError(t, err)
+		assert.Equal(t, http.StatusNoContent, status)
+		head, err := awss3.HeadObject(ctx, TestRegion, TestBucket, awss3.Key(prefix+"upload.txt"))
+		assert.NilError(t, err)
+		assert.Equal(t, "text/csv", aws.ToString(head.ContentType))
+
+		status, err = postForm(post, map[string]string{"Content-Type": "image/png"}, []byte("a,b"))
+		assert.NilError(t, err)
+		assert.Equal(t, http.StatusForbidden, status)
 	})
 }
 
@@ -1286,6 +3057,30 @@ func TestCreateMultipartUpload(t *testing.T) {
 		defer awss3.AbortMultipartUpload(ctx, TestRegion, TestBucket, key, uploadId) //nolint:errcheck
 	})
 
+	t.Run("Create multipart upload with object attribute options", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		opts := []s3upload.OptionS3Upload{
+			s3upload.WithContentType("text/plain"),
+			s3upload.WithMetadata(map[string]string{"owner": "awstest"}),
+			s3upload.WithChecksumAlgorithm(types.ChecksumAlgorithmSha256),
+		}
+		uploadId, err := awss3.CreateMultipartUpload(ctx, TestRegion, TestBucket, key, opts...)
+		assert.NilError(t, err)
+		partResp, err := awss3.UploadPart(ctx, TestRegion, TestBucket, key, uploadId, 1,
+			strings.NewReader("Hello World"), opts...)
+		assert.NilError(t, err)
+		assert.Assert(t, aws.ToString(partResp.ChecksumSHA256) != "")
+		_, err = awss3.CompleteMultipartUpload(ctx, TestRegion, TestBucket, key, uploadId, []types.CompletedPart{
+			{ETag: partResp.ETag, ChecksumSHA256: partResp.ChecksumSHA256, PartNumber: aws.Int32(1)},
+		})
+		assert.NilError(t, err)
+		head, err := awss3.HeadObject(ctx, TestRegion, TestBucket, key)
+		assert.NilError(t, err)
+		assert.Equal(t, "text/plain", aws.ToString(head.ContentType))
+		assert.DeepEqual(t, map[string]string{"owner": "awstest"}, head.Metadata)
+	})
+
 	t.Run("Create multipart upload with non-existing bucket", func(t *testing.T) {
 		t.Parallel()
 		key := awss3.Key("test_create_multipart_upload_file_b.txt")
@@ -1505,6 +3300,145 @@ func assertNoFDLeak(t *testing.T, outputDir string, fn func()) {
 		"file descriptor leak detected: before=%d after=%d", before, after)
 }
 
+func TestMultipartUpload(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+	partSize := s3multipart.MinPartSize
+	body := bytes.Repeat([]byte("0123456789"), int(partSize*2/10)+1024) // 2 full parts and a small last part
+	getBody := func(key awss3.Key) []byte {
+		var buf bytes.Buffer
+		assert.NilError(t, awss3.GetObjectWriter(ctx, TestRegion, TestBucket, key, &buf))
+		return buf.Bytes()
+	}
+	// writeChunks writes b in small chunks to exercise the part buffering.
+	writeChunks := func(t *testing.T, w io.Writer, b []byte) {
+		t.Helper()
+		for chunk := range slices.Chunk(b, 1000*1000) {
+			n, err := w.Write(chunk)
+			assert.NilError(t, err)
+			assert.Equal(t, len(chunk), n)
+		}
+	}
+
+	t.Run("MultipartUpload", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/%s.bin", ulid.MustNew()))
+		u, err := awss3.NewMultipartUpload(ctx, TestRegion, TestBucket, key,
+			s3multipart.WithPartSize(partSize),
+			s3multipart.WithConcurrency(2),
+			s3multipart.WithUploadOptions(s3upload.WithContentType("application/octet-stream")),
+		)
+		assert.NilError(t, err)
+		writeChunks(t, u, body)
+		assert.NilError(t, u.Close())
+		assert.Assert(t, bytes.Equal(body, getBody(key)))
+		state := u.State()
+		assert.Equal(t, 3, len(state.Parts))
+		assert.Equal(t, types.ChecksumAlgorithmCrc32, state.ChecksumAlgorithm)
+		assert.Assert(t, state.Parts[0].Checksum != "")
+		head, err := awss3.HeadObject(ctx, TestRegion, TestBucket, key)
+		assert.NilError(t, err)
+		assert.Equal(t, "application/octet-stream", aws.ToString(head.ContentType))
+
+		_, err = u.Write([]byte("test"))
+		assert.ErrorIs(t, err, awss3.ErrMultipartUploadClosed)
+	})
+	t.Run("MultipartUpload:SHA256", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		u, err := awss3.NewMultipartUpload(ctx, TestRegion, TestBucket, key,
+			s3multipart.WithUploadOptions(s3upload.WithChecksumAlgorithm(types.ChecksumAlgorithmSha256)),
+		)
+		assert.NilError(t, err)
+		_, err = u.Write([]byte("test"))
+		assert.NilError(t, err)
+		assert.NilError(t, u.Close())
+		assert.DeepEqual(t, []byte("test"), getBody(key))
+		assert.Equal(t, types.ChecksumAlgorithmSha256, u.State().ChecksumAlgorithm)
+	})
+	t.Run("MultipartUpload:Empty", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		u, err := awss3.NewMultipartUpload(ctx, TestRegion, TestBucket, key)
+		assert.NilError(t, err)
+		assert.NilError(t, u.Close())
+		assert.Equal(t, 0, len(getBody(key)))
+	})
+	t.Run("MultipartUpload:Resume", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/%s.bin", ulid.MustNew()))
+		u, err := awss3.NewMultipartUpload(ctx, TestRegion, TestBucket, key,
+			s3multipart.WithPartSize(partSize),
+			s3multipart.WithLeavePartsOnError(),
+		)
+		assert.NilError(t, err)
+		writeChunks(t, u, body[:partSize*2+10])
+		// wait until both full parts are uploaded, then "restart" from the persisted state
+		var state awss3.MultipartUploadState
+		for range 100 {
+			if state = u.State(); len(state.Parts) == 2 {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		b, err := json.Marshal(state)
+		assert.NilError(t, err)
+		var restored awss3.MultipartUploadState
+		assert.NilError(t, json.Unmarshal(b, &restored))
+
+		resumed, err := awss3.ResumeMultipartUpload(ctx, TestRegion, restored)
+		assert.NilError(t, err)
+		offset := resumed.State().Offset()
+		assert.Equal(t, partSize*2, offset)
+		writeChunks(t, resumed, body[offset:])
+		assert.NilError(t, resumed.Close())
+		assert.Assert(t, bytes.Equal(body, getBody(key)))
+	})
+	t.Run("MultipartUpload:Abort", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		u, err := awss3.NewMultipartUpload(ctx, TestRegion, TestBucket, key)
+		assert.NilError(t, err)
+		_, err = u.Write([]byte("test"))
+		assert.NilError(t, err)
+		assert.NilError(t, u.Abort())
+		_, err = u.Write([]byte("test"))
+		assert.ErrorIs(t, err, awss3.ErrMultipartUploadClosed)
+		_, err = awss3.ResumeMultipartUpload(ctx, TestRegion, u.State())
+		assert.Assert(t, err != nil)
+		_, err = awss3.HeadObject(ctx, TestRegion, TestBucket, key)
+		assert.ErrorIs(t, err, awss3.ErrNotFound)
+	})
+	t.Run("MultipartUpload:Context canceled", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/%s.bin", ulid.MustNew()))
+		cancelCtx, cancel := context.WithCancel(ctx)
+		u, err := awss3.NewMultipartUpload(cancelCtx, TestRegion, TestBucket, key,
+			s3multipart.WithPartSize(partSize),
+		)
+		assert.NilError(t, err)
+		writeChunks(t, u, body[:partSize+10])
+		cancel()
+		err = u.Close()
+		assert.ErrorIs(t, err, context.Canceled)
+		_, err = awss3.ResumeMultipartUpload(ctx, TestRegion, u.State())
+		assert.Assert(t, err != nil)
+	})
+	t.Run("MultipartUpload:Unsupported checksum algorithm", func(t *testing.T) {
+		t.Parallel()
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		_, err := awss3.NewMultipartUpload(ctx, TestRegion, TestBucket, key,
+			s3multipart.WithUploadOptions(s3upload.WithChecksumAlgorithm("MD5")),
+		)
+		assert.Assert(t, err != nil)
+	})
+}
+
 // TestDownloadFiles_FilesClosed verifies that every *os.File opened inside
 // DownloadFiles is closed on both the success path and the error path.
 func TestDownloadFiles_FilesClosed(t *testing.T) {
@@ -1642,6 +3576,29 @@ func TestDownloadFilesParallel_FilesClosed(t *testing.T) {
 		})
 	})
 
+	t.Run("open files are bounded by the concurrency", func(t *testing.T) {
+		outDir := t.TempDir()
+		if openFDCount(outDir) < 0 {
+			t.Skip("FD counting not supported on this platform")
+		}
+		var mu sync.Mutex
+		maxOpen := 0
+		paths, err := awss3.DownloadFilesParallel(ctx, TestRegion, TestBucket, keys, outDir,
+			s3download.WithConcurrency(1),
+			s3download.WithProgressListener(func(e s3progress.Event) {
+				if e.Type != s3progress.EventBytes {
+					return
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				maxOpen = max(maxOpen, openFDCount(outDir))
+			}),
+		)
+		assert.NilError(t, err)
+		assert.Equal(t, numFiles, len(paths))
+		assert.Check(t, maxOpen <= 1, "%d files were open at a time with a concurrency of 1", maxOpen)
+	})
+
 	t.Run("mixed: some keys exist, one does not — no FD leak", func(t *testing.T) {
 		mixedKeys := append(
 			awss3.Keys{keys[0], keys[1]},
@@ -1655,6 +3612,130 @@ func TestDownloadFilesParallel_FilesClosed(t *testing.T) {
 	})
 }
 
+func TestWriteZip(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+	client, err := awss3.NewClient(ctx, TestRegion)
+	assert.NilError(t, err)
+
+	getBodyText := func(idx int) string {
+		return fmt.Sprintf("%d-%s", idx, strings.Repeat("test", 10000))
+	}
+	prefix := fmt.Sprintf("awstest/%s/", ulid.MustNew())
+	keys := make(awss3.Keys, 20)
+	for i := range keys {
+		keys[i] = awss3.Key(fmt.Sprintf("%s%d/%s.txt", prefix, i, ulid.MustNew()))
+		_, err := client.PutObject(ctx, TestBucket, keys[i], strings.NewReader(getBodyText(i)))
+		assert.NilError(t, err)
+	}
+	readZip := func(t *testing.T, b []byte) map[string]string {
+		t.Helper()
+		zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+		assert.NilError(t, err)
+		files := make(map[string]string, len(zr.File))
+		for _, f := range zr.File {
+			r, err := f.Open()
+			assert.NilError(t, err)
+			body, err := io.ReadAll(r)
+			assert.NilError(t, err)
+			assert.NilError(t, r.Close())
+			files[f.Name] = string(body)
+		}
+		return files
+	}
+
+	t.Run("no option", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		names, err := awss3.WriteZip(ctx, TestRegion, TestBucket, keys, &buf)
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), len(names))
+		files := readZip(t, buf.Bytes())
+		for i, name := range names {
+			assert.Equal(t, path.Base(keys[i].String()), name)
+			assert.Equal(t, getBodyText(i), files[name])
+		}
+	})
+	t.Run("PrefetchSize:streams larger objects", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		names, err := client.WriteZip(ctx, TestBucket, keys, &buf,
+			s3zip.WithPrefetchSize(1024),
+			s3zip.WithConcurrency(2),
+			s3zip.WithMethod(zip.Store),
+		)
+		assert.NilError(t, err)
+		files := readZip(t, buf.Bytes())
+		for i, name := range names {
+			assert.Equal(t, getBodyText(i), files[name])
+		}
+	})
+	t.Run("FileNameReplacer:duplicate", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		names, err := client.WriteZip(ctx, TestBucket, keys, &buf,
+			s3zip.WithFileNameReplacerFunc(func(S3Key, baseFileName string) string {
+				return "fixname.txt"
+			}),
+		)
+		assert.NilError(t, err)
+		files := readZip(t, buf.Bytes())
+		assert.Equal(t, len(keys), len(files))
+		for i, name := range names {
+			if i == 0 {
+				assert.Equal(t, "fixname.txt", name)
+			} else {
+				assert.Equal(t, fmt.Sprintf("fixname_%d.txt", i+1), name)
+			}
+			assert.Equal(t, getBodyText(i), files[name])
+		}
+	})
+	t.Run("Prefix", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		names, err := client.WriteZip(ctx, TestBucket, awss3.Keys{keys[0]}, &buf, s3zip.WithPrefix(prefix))
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), len(names))
+		assert.Equal(t, len(keys), len(readZip(t, buf.Bytes())))
+	})
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		_, err := client.WriteZip(ctx, TestBucket, append(awss3.Keys{"NOT_FOUND"}, keys...), &buf)
+		assert.ErrorIs(t, err, awss3.ErrNotFound)
+	})
+	t.Run("UploadZip", func(t *testing.T) {
+		t.Parallel()
+		destKey := awss3.Key(fmt.Sprintf("awstest/%s.zip", ulid.MustNew()))
+		names, err := awss3.UploadZip(ctx, TestRegion, TestBucket, keys, TestBucket, destKey,
+			s3zip.WithMultipartOptions(s3multipart.WithPartSize(s3multipart.MinPartSize)),
+		)
+		assert.NilError(t, err)
+		res, err := client.HeadObject(ctx, TestBucket, destKey)
+		assert.NilError(t, err)
+		assert.Equal(t, "application/zip", aws.ToString(res.ContentType))
+		var buf bytes.Buffer
+		assert.NilError(t, client.GetObjectWriter(ctx, TestBucket, destKey, &buf))
+		files := readZip(t, buf.Bytes())
+		for i, name := range names {
+			assert.Equal(t, getBodyText(i), files[name])
+		}
+	})
+	t.Run("UploadZip:not found aborts the upload", func(t *testing.T) {
+		t.Parallel()
+		destKey := awss3.Key(fmt.Sprintf("awstest/%s.zip", ulid.MustNew()))
+		_, err := client.UploadZip(ctx, TestBucket, awss3.Keys{"NOT_FOUND"}, TestBucket, destKey)
+		assert.ErrorIs(t, err, awss3.ErrNotFound)
+		_, err = client.HeadObject(ctx, TestBucket, destKey)
+		assert.ErrorIs(t, err, awss3.ErrNotFound)
+	})
+}
+
 func TestKey(t *testing.T) {
 	t.Parallel()
 
@@ -1776,8 +3857,225 @@ func TestObjects_Find(t *testing.T) {
 	})
 }
 
+func TestListEntry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("object", func(t *testing.T) {
+		t.Parallel()
+		entry := awss3.ListEntry{Object: types.Object{Key: aws.String("path/to/file.txt")}}
+		assert.Equal(t, false, entry.IsCommonPrefix())
+		assert.Equal(t, awss3.Key("path/to/file.txt"), entry.Key())
+	})
+	t.Run("common prefix", func(t *testing.T) {
+		t.Parallel()
+		entry := awss3.ListEntry{CommonPrefix: aws.String("path/to/")}
+		assert.Equal(t, true, entry.IsCommonPrefix())
+		assert.Equal(t, awss3.Key("path/to/"), entry.Key())
+	})
+}
+
+func TestDeleteResults(t *testing.T) {
+	t.Parallel()
+
+	errFailed := &awss3.DeleteObjectError{Code: "AccessDenied", Message: "Access Denied"}
+	results := awss3.DeleteResults{
+		{Key: "a.txt"},
+		{Key: "b.txt", Err: errFailed},
+		{Key: "c.txt"},
+	}
+
+	t.Run("Deleted", func(t *testing.T) {
+		t.Parallel()
+		assert.DeepEqual(t, awss3.Keys{"a.txt", "c.txt"}, results.Deleted())
+	})
+	t.Run("Failed", func(t *testing.T) {
+		t.Parallel()
+		failed := results.Failed()
+		assert.Equal(t, 1, len(failed))
+		assert.Equal(t, awss3.Key("b.txt"), failed[0].Key)
+	})
+	t.Run("Err", func(t *testing.T) {
+		t.Parallel()
+		err := results.Err()
+		assert.ErrorIs(t, err, errFailed)
+		assert.ErrorContains(t, err, "b.txt: AccessDenied: Access Denied")
+	})
+	t.Run("Err all deleted", func(t *testing.T) {
+		t.Parallel()
+		assert.NilError(t, awss3.DeleteResults{{Key: "a.txt"}}.Err())
+	})
+}
+
+func TestObjectRef(t *testing.T) {
+	t.Parallel()
+
+	const apARN = "arn:aws:s3:ap-northeast-1:123456789012:accesspoint/my-ap"
+	tests := []struct {
+		in          string
+		bucket      awss3.BucketName
+		key         awss3.Key
+		region      awsconfig.Region
+		str         string
+		directory   bool
+		accessPoint bool
+	}{
+		{in: "s3://my-bucket/path/to/file.txt", bucket: "my-bucket", key: "path/to/file.txt"},
+		{in: "s3://my-bucket", bucket: "my-bucket", str: "s3://my-bucket"},
+		{in: "s3://" + apARN + "/path/file.txt", bucket: apARN, key: "path/file.txt",
+			region: TestRegion, str: apARN + "/object/path/file.txt", accessPoint: true},
+		{in: "arn:aws:s3:::my-bucket/path/to/file.txt", bucket: "my-bucket", key: "path/to/file.txt",
+			str: "s3://my-bucket/path/to/file.txt"},
+		{in: apARN + "/object/path/file.txt", bucket: apARN, key: "path/file.txt",
+			region: TestRegion, accessPoint: true},
+		{in: "arn:aws:s3-outposts:us-west-2:123456789012:outpost/op-01ac5d28a6a232904/accesspoint/ap/object/a.txt",
+			bucket: "arn:aws:s3-outposts:us-west-2:123456789012:outpost/op-01ac5d28a6a232904/accesspoint/ap",
+			key:    "a.txt", region: awsconfig.RegionOregon, accessPoint: true},
+		{in: "arn:aws:s3express:us-west-2:123456789012:bucket/data--usw2-az1--x-s3", bucket: "data--usw2-az1--x-s3",
+			region: awsconfig.RegionOregon, str: "s3://data--usw2-az1--x-s3", directory: true},
+		{in: "https://my-bucket.s3.ap-northeast-1.amazonaws.com/path/to/file%20name.txt", bucket: "my-bucket",
+			key: "path/to/file name.txt", region: TestRegion, str: "s3://my-bucket/path/to/file name.txt"},
+		{in: "https://s3-backup.s3-ap-northeast-1.amazonaws.com/a.txt", bucket: "s3-backup", key: "a.txt",
+			region: TestRegion, str: "s3://s3-backup/a.txt"},
+		{in: "https://my.dotted.bucket.s3.dualstack.ap-northeast-1.amazonaws.com/a.txt", bucket: "my.dotted.bucket",
+			key: "a.txt", region: TestRegion, str: "s3://my.dotted.bucket/a.txt"},
+		{in: "https://my-bucket.s3.amazonaws.com/a.txt", bucket: "my-bucket", key: "a.txt", str: "s3://my-bucket/a.txt"},
+		{in: "https://s3.ap-northeast-1.amazonaws.com/my-bucket/a.txt", bucket: "my-bucket", key: "a.txt",
+			region: TestRegion, str: "s3://my-bucket/a.txt"},
+		{in: "https://my-ap-123456789012.s3-accesspoint.ap-northeast-1.amazonaws.com/a.txt", bucket: apARN,
+			key: "a.txt", region: TestRegion, str: apARN + "/object/a.txt", accessPoint: true},
+		{in: "https://data--usw2-az1--x-s3.s3express-usw2-az1.us-west-2.amazonaws.com/a.txt",
+			bucket: "data--usw2-az1--x-s3", key: "a.txt", region: awsconfig.RegionOregon,
+			str: "s3://data--usw2-az1--x-s3/a.txt", directory: true},
+		{in: "http://127.0.0.1:29000/test/a.txt", bucket: "test", key: "a.txt", str: "s3://test/a.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			ref, err := awss3.ParseObjectRef(tt.in)
+			assert.NilError(t, err)
+			assert.Equal(t, tt.bucket, ref.Bucket())
+			assert.Equal(t, tt.key, ref.Key())
+			assert.Equal(t, tt.region, ref.Region())
+			assert.Equal(t, tt.directory, ref.IsDirectoryBucket())
+			assert.Equal(t, tt.accessPoint, ref.IsAccessPoint())
+			str := tt.str
+			if str == "" {
+				str = tt.in
+			}
+			assert.Equal(t, str, ref.String())
+
+			parsed, err := awss3.ParseObjectRef(ref.String())
+			assert.NilError(t, err)
+			assert.Equal(t, ref.Bucket(), parsed.Bucket())
+			assert.Equal(t, ref.Key(), parsed.Key())
+		})
+	}
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		for _, in := range []string{
+			"s3://",
+			"s3:///key",
+			"arn:aws:sqs:ap-northeast-1:123456789012:queue",
+			"arn:aws:s3:ap-northeast-1:123456789012:accesspoint/my-ap/a.txt",
+			"arn:aws:s3express:us-west-2:123456789012:session/x",
+			"ftp://example.com/bucket/key",
+			"https://ec2.ap-northeast-1.amazonaws.com/a",
+			"https://example.com/",
+		} {
+			_, err := awss3.ParseObjectRef(in)
+			assert.ErrorIs(t, err, awss3.ErrInvalidObjectRef, in)
+		}
+		_, err := awss3.ParseS3URI("https://my-bucket.s3.amazonaws.com/a.txt")
+		assert.ErrorIs(t, err, awss3.ErrInvalidObjectRef)
+	})
+	t.Run("UnmarshalText", func(t *testing.T) {
+		t.Parallel()
+		var conf struct {
+			Input awss3.ObjectRef `json:"input"`
+		}
+		assert.NilError(t, json.Unmarshal([]byte(`{"input":"s3://my-bucket/in/a.csv"}`), &conf))
+		assert.Equal(t, awss3.NewObjectRef("my-bucket", "in/a.csv"), conf.Input)
+		b, err := json.Marshal(conf)
+		assert.NilError(t, err)
+		assert.Equal(t, `{"input":"s3://my-bucket/in/a.csv"}`, string(b))
+		assert.Assert(t, json.Unmarshal([]byte(`{"input":"s3://"}`), &conf) != nil)
+	})
+	t.Run("Client", func(t *testing.T) {
+		t.Parallel()
+		ctx := ctxawslocal.WithContext(
+			context.Background(),
+			ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"),
+			ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+			ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+		)
+		client, err := awss3.NewClient(ctx, TestRegion)
+		assert.NilError(t, err)
+
+		prefix := fmt.Sprintf("awstest/%s/", ulid.MustNew())
+		ref, err := awss3.ParseS3URI(fmt.Sprintf("s3://%s/%sa.txt", TestBucket, prefix))
+		assert.NilError(t, err)
+		_, err = client.PutObjectRef(ctx, ref, strings.NewReader("hello"))
+		assert.NilError(t, err)
+
+		res, err := client.HeadObjectRef(ctx, ref)
+		assert.NilError(t, err)
+		assert.Equal(t, int64(5), aws.ToInt64(res.ContentLength))
+
+		dest := ref.WithKey(awss3.Key(prefix + "b.txt"))
+		_, err = client.CopyObjectRef(ctx, ref, dest)
+		assert.NilError(t, err)
+		var buf bytes.Buffer
+		assert.NilError(t, client.GetObjectWriterRef(ctx, dest, &buf))
+		assert.Equal(t, "hello", buf.String())
+
+		objects, err := client.ListObjectsRef(ctx, ref.WithKey(awss3.Key(prefix)))
+		assert.NilError(t, err)
+		assert.Equal(t, 2, len(objects))
+
+		_, err = client.DeleteObjectRef(ctx, dest)
+		assert.NilError(t, err)
+		_, err = client.HeadObjectRef(ctx, dest)
+		assert.ErrorIs(t, err, awss3.ErrNotFound)
+	})
+}
+
 // TestNewClient_returnsWorkingClient verifies that NewClient constructs a client
 // that can successfully upload and inspect an object on S3.
+func TestNewClient_WithRateLimit(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+	const bytesPerSecond = 256 * 1024
+	client, err := awss3.NewClient(ctx, TestRegion, awss3.WithRateLimit(bytesPerSecond))
+	assert.NilError(t, err)
+
+	// the first second of the limit is available as a burst
+	body := bytes.Repeat([]byte("a"), 2*bytesPerSecond)
+	key := awss3.Key(fmt.Sprintf("awstest/%s.bin", ulid.MustNew()))
+	startedAt := time.Now()
+	_, err = client.UploadManager(ctx, TestBucket, key, bytes.NewReader(body))
+	assert.NilError(t, err)
+	assert.Assert(t, time.Since(startedAt) >= 900*time.Millisecond, time.Since(startedAt))
+
+	// uploads and downloads share the limit
+	startedAt = time.Now()
+	var buf bytes.Buffer
+	assert.NilError(t, client.GetObjectWriter(ctx, TestBucket, key, &buf))
+	assert.Assert(t, bytes.Equal(body, buf.Bytes()))
+	assert.Assert(t, time.Since(startedAt) >= 1800*time.Millisecond, time.Since(startedAt))
+
+	// a canceled context stops a throttled transfer
+	cancelCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	buf.Reset()
+	err = client.GetObjectWriter(cancelCtx, TestBucket, key, &buf)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
 func TestNewClient_returnsWorkingClient(t *testing.T) {
 	t.Parallel()
 	ctx := ctxawslocal.WithContext(
@@ -0,0 +1,271 @@
+package awss3test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	amzDateFormat    = "20060102T150405Z"
+	// maxClockSkew is the difference between the clock of a client and the server that S3 accepts.
+	maxClockSkew = 15 * time.Minute
+	// maxPresignExpires is the longest validity of a presigned URL.
+	maxPresignExpires = 7 * 24 * time.Hour
+)
+
+var (
+	errAccessDenied = &s3Error{status: http.StatusForbidden, code: "AccessDenied", message: "Access Denied"}
+	errExpired      = &s3Error{status: http.StatusForbidden, code: "AccessDenied", message: "Request has expired"}
+	errSignature    = &s3Error{
+		status: http.StatusForbidden,
+		code:   "SignatureDoesNotMatch",
+		message: "The request signature we calculated does not match the signature you provided. " +
+			"Check your key and signing method.",
+	}
+	errAccessKey = &s3Error{
+		status:  http.StatusForbidden,
+		code:    "InvalidAccessKeyId",
+		message: "The AWS Access Key Id you provided does not exist in our records.",
+	}
+	errSkewed = &s3Error{
+		status:  http.StatusForbidden,
+		code:    "RequestTimeTooSkewed",
+		message: "The difference between the request time and the current time is too large.",
+	}
+)
+
+// credentialScope is the scope of a Signature Version 4 credential: AKID/date/region/service/aws4_request.
+type credentialScope struct {
+	accessKey string
+	date      string
+	region    string
+	service   string
+}
+
+func parseCredential(credential string) (credentialScope, bool) {
+	parts := strings.Split(credential, "/")
+	if len(parts) != 5 || parts[4] != "aws4_request" {
+		return credentialScope{}, false
+	}
+	return credentialScope{accessKey: parts[0], date: parts[1], region: parts[2], service: parts[3]}, true
+}
+
+func (c credentialScope) String() string {
+	return strings.Join([]string{c.date, c.region, c.service, "aws4_request"}, "/")
+}
+
+// authenticate verifies the Signature Version 4 of a request, in its Authorization header or,
+// for a presigned URL, in its query.
+func (s *Server) authenticate(r *http.Request) error {
+	if r.URL.Query().Get("X-Amz-Algorithm") != "" {
+		return s.authenticatePresigned(r)
+	}
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		return errAccessDenied
+	}
+	algorithm, fields, ok := strings.Cut(auth, " ")
+	if !ok || algorithm != signingAlgorithm {
+		return invalidArgument("unsupported authorization type")
+	}
+	var credential, signedHeaders, signature string
+	for _, field := range strings.Split(fields, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch name {
+		case "Credential":
+			credential = value
+		case "SignedHeaders":
+			signedHeaders = value
+		case "Signature":
+			signature = value
+		}
+	}
+	scope, ok := parseCredential(credential)
+	if !ok || signedHeaders == "" || signature == "" {
+		return invalidArgument("malformed authorization header")
+	}
+	amzDate := r.Header.Get("X-Amz-Date")
+	signedAt, err := time.Parse(amzDateFormat, amzDate)
+	if err != nil {
+		return errAccessDenied
+	}
+	if d := s.now().Sub(signedAt); d > maxClockSkew || d < -maxClockSkew {
+		return errSkewed
+	}
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		return &s3Error{
+			status:  http.StatusBadRequest,
+			code:    "InvalidRequest",
+			message: "Missing required header for this request: x-amz-content-sha256",
+		}
+	}
+	return s.verifySignature(r, scope, amzDate, signedHeaders, payloadHash, signature, false)
+}
+
+func (s *Server) authenticatePresigned(r *http.Request) error {
+	query := r.URL.Query()
+	if query.Get("X-Amz-Algorithm") != signingAlgorithm {
+		return invalidArgument("unsupported X-Amz-Algorithm")
+	}
+	scope, ok := parseCredential(query.Get("X-Amz-Credential"))
+	if !ok {
+		return invalidArgument("malformed X-Amz-Credential")
+	}
+	amzDate := query.Get("X-Amz-Date")
+	signedAt, err := time.Parse(amzDateFormat, amzDate)
+	if err != nil {
+		return invalidArgument("malformed X-Amz-Date")
+	}
+	expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if err != nil || expires < 1 || time.Duration(expires)*time.Second > maxPresignExpires {
+		return invalidArgument("X-Amz-Expires must be between 1 and 604800 seconds")
+	}
+	if s.now().After(signedAt.Add(time.Duration(expires) * time.Second)) {
+		return errExpired
+	}
+	payloadHash := query.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		payloadHash = "UNSIGNED-PAYLOAD"
+	}
+	return s.verifySignature(r, scope, amzDate, query.Get("X-Amz-SignedHeaders"), payloadHash,
+		query.Get("X-Amz-Signature"), true)
+}
+
+func (s *Server) verifySignature(
+	r *http.Request, scope credentialScope, amzDate, signedHeaders, payloadHash, signature string, presigned bool,
+) error {
+	if scope.accessKey != s.accessKey {
+		return errAccessKey
+	}
+	headers := strings.Split(signedHeaders, ";")
+	if !slices.Contains(headers, "host") {
+		return errAccessDenied
+	}
+	var canonical strings.Builder
+	canonical.WriteString(r.Method + "\n")
+	rawPath, _, _ := strings.Cut(r.RequestURI, "?")
+	canonical.WriteString(rawPath + "\n")
+	canonical.WriteString(canonicalQuery(r.URL.RawQuery, presigned) + "\n")
+	for _, name := range headers {
+		canonical.WriteString(name + ":" + canonicalHeaderValue(r, name) + "\n")
+	}
+	canonical.WriteString("\n" + signedHeaders + "\n" + payloadHash)
+
+	requestHash := sha256.Sum256([]byte(canonical.String()))
+	stringToSign := signingAlgorithm + "\n" + amzDate + "\n" + scope.String() + "\n" + hex.EncodeToString(requestHash[:])
+	expected := hex.EncodeToString(hmacSHA256(s.signingKey(scope), stringToSign))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errSignature
+	}
+	return nil
+}
+
+// signingKey derives the key of a credential scope from the secret access key.
+func (s *Server) signingKey(scope credentialScope) []byte {
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), scope.date)
+	key = hmacSHA256(key, scope.region)
+	key = hmacSHA256(key, scope.service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalQuery sorts the query parameters and encodes them as Signature Version 4 does.
+// The signature of a presigned URL is not part of what it signs.
+func canonicalQuery(rawQuery string, presigned bool) string {
+	if rawQuery == "" {
+		return ""
+	}
+	var params [][2]string
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(param, "=")
+		name, value = unescapeQuery(name), unescapeQuery(value)
+		if presigned && name == "X-Amz-Signature" {
+			continue
+		}
+		params = append(params, [2]string{uriEncode(name), uriEncode(value)})
+	}
+	// parameters are sorted by name and then by value, so "a=" comes before "a-b="
+	slices.SortFunc(params, func(a, b [2]string) int {
+		if c := strings.Compare(a[0], b[0]); c != 0 {
+			return c
+		}
+		return strings.Compare(a[1], b[1])
+	})
+	encoded := make([]string, 0, len(params))
+	for _, p := range params {
+		encoded = append(encoded, p[0]+"="+p[1])
+	}
+	return strings.Join(encoded, "&")
+}
+
+func unescapeQuery(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			v, _ := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			b.WriteByte(byte(v))
+			i += 2
+		case s[i] == '+':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+// uriEncode encodes every byte except the unreserved characters of RFC 3986.
+func uriEncode(s string) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexDigits[c>>4])
+		b.WriteByte(hexDigits[c&0xf])
+	}
+	return b.String()
+}
+
+func canonicalHeaderValue(r *http.Request, name string) string {
+	switch name {
+	case "host":
+		return r.Host
+	case "content-length":
+		if v := r.Header.Get("Content-Length"); v != "" {
+			return v
+		}
+		return strconv.FormatInt(r.ContentLength, 10)
+	}
+	values := slices.Clone(r.Header.Values(name))
+	for i, v := range values {
+		values[i] = strings.Join(strings.Fields(v), " ")
+	}
+	return strings.Join(values, ",")
+}
@@ -0,0 +1,444 @@
+package awss3test
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxKeys is the largest number of keys S3 returns in one page of a listing or deletes with one DeleteObjects.
+const maxKeys = 1000
+
+type bucket struct {
+	name    string
+	created time.Time
+	objects map[string]*object
+	uploads map[string]*upload
+	// configurations are the XML documents of the configuration subresources, such as lifecycle and cors.
+	configurations map[string][]byte
+}
+
+func newBucket(name string, created time.Time) *bucket {
+	return &bucket{
+		name:           name,
+		created:        created,
+		objects:        make(map[string]*object),
+		uploads:        make(map[string]*upload),
+		configurations: make(map[string][]byte),
+	}
+}
+
+func (b *bucket) sortedKeys() []string {
+	keys := make([]string, 0, len(b.objects))
+	for key := range b.objects {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// bucket returns a bucket; the caller must hold s.mu.
+func (s *Server) bucket(name string) (*bucket, error) {
+	b, ok := s.buckets[name]
+	if !ok {
+		return nil, errNoSuchBucket
+	}
+	return b, nil
+}
+
+func (s *Server) createBucket(w http.ResponseWriter, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.buckets[name]; ok {
+		return &s3Error{
+			status:  http.StatusConflict,
+			code:    "BucketAlreadyOwnedByYou",
+			message: "Your previous request to create the named bucket succeeded and you already own it.",
+		}
+	}
+	s.buckets[name] = newBucket(name, s.now())
+	w.Header().Set("Location", "/"+name)
+	return nil
+}
+
+func (s *Server) deleteBucket(w http.ResponseWriter, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bucket(name)
+	if err != nil {
+		return err
+	}
+	if len(b.objects) > 0 {
+		return &s3Error{
+			status:  http.StatusConflict,
+			code:    "BucketNotEmpty",
+			message: "The bucket you tried to delete is not empty",
+		}
+	}
+	delete(s.buckets, name)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// errNoSuchConfiguration is the error of S3 for a configuration subresource that is not set.
+var errNoSuchConfiguration = map[string]*s3Error{
+	"lifecycle": {
+		status: http.StatusNotFound, code: "NoSuchLifecycleConfiguration",
+		message: "The lifecycle configuration does not exist",
+	},
+	"cors": {
+		status: http.StatusNotFound, code: "NoSuchCORSConfiguration",
+		message: "The CORS configuration does not exist",
+	},
+}
+
+// bucketConfiguration stores, returns and deletes a configuration subresource of a bucket as an XML document.
+// The documents are checked to be well-formed but not validated.
+func (s *Server) bucketConfiguration(w http.ResponseWriter, r *http.Request, bucketName, name string) error {
+	var document []byte
+	if r.Method == http.MethodPut {
+		body, _, err := readPayload(r)
+		if err != nil {
+			return err
+		}
+		if err := xml.Unmarshal(body, new(struct{})); err != nil {
+			return errMalformedXML
+		}
+		document = body
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	switch r.Method {
+	case http.MethodPut:
+		b.configurations[name] = document
+		return nil
+	case http.MethodGet:
+		document, ok := b.configurations[name]
+		if !ok {
+			return errNoSuchConfiguration[name]
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Header().Set("Content-Length", strconv.Itoa(len(document)))
+		_, _ = w.Write(document)
+		return nil
+	case http.MethodDelete:
+		delete(b.configurations, name)
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	return errMethodNotAllowed
+}
+
+type listAllMyBucketsResult struct {
+	XMLName xml.Name       `xml:"ListAllMyBucketsResult"`
+	Xmlns   string         `xml:"xmlns,attr"`
+	Owner   owner          `xml:"Owner"`
+	Buckets []bucketResult `xml:"Buckets>Bucket"`
+}
+
+type owner struct {
+	ID          string `xml:"ID"`
+	DisplayName string `xml:"DisplayName"`
+}
+
+type bucketResult struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+var fakeOwner = owner{ID: "awss3test", DisplayName: "awss3test"}
+
+func (s *Server) listBuckets(w http.ResponseWriter) error {
+	s.mu.Lock()
+	result := listAllMyBucketsResult{Xmlns: s3Namespace, Owner: fakeOwner}
+	for _, b := range s.buckets {
+		result.Buckets = append(result.Buckets, bucketResult{Name: b.name, CreationDate: timestamp(b.created)})
+	}
+	s.mu.Unlock()
+	slices.SortFunc(result.Buckets, func(a, b bucketResult) int { return strings.Compare(a.Name, b.Name) })
+	writeXML(w, http.StatusOK, result)
+	return nil
+}
+
+type objectResult struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+	Owner        *owner `xml:"Owner,omitempty"`
+}
+
+type commonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type listBucketResult struct {
+	XMLName               xml.Name       `xml:"ListBucketResult"`
+	Xmlns                 string         `xml:"xmlns,attr"`
+	Name                  string         `xml:"Name"`
+	Prefix                string         `xml:"Prefix"`
+	Delimiter             string         `xml:"Delimiter,omitempty"`
+	EncodingType          string         `xml:"EncodingType,omitempty"`
+	MaxKeys               int            `xml:"MaxKeys"`
+	IsTruncated           bool           `xml:"IsTruncated"`
+	Marker                *string        `xml:"Marker"`
+	NextMarker            string         `xml:"NextMarker,omitempty"`
+	ContinuationToken     string         `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string         `xml:"NextContinuationToken,omitempty"`
+	StartAfter            string         `xml:"StartAfter,omitempty"`
+	KeyCount              *int           `xml:"KeyCount"`
+	Contents              []objectResult `xml:"Contents"`
+	CommonPrefixes        []commonPrefix `xml:"CommonPrefixes"`
+}
+
+// listing is a page of keys and common prefixes.
+type listing struct {
+	objects     []*object
+	prefixes    []string
+	isTruncated bool
+	// last is the last key or common prefix of the page.
+	last string
+}
+
+// list returns the keys after marker that begin with prefix, grouping the keys that contain delimiter after
+// the prefix into common prefixes. A marker that is a common prefix skips all the keys under it.
+func (b *bucket) list(prefix, delimiter, marker string, limit int) listing {
+	var page listing
+	for _, key := range b.sortedKeys() {
+		if !strings.HasPrefix(key, prefix) || key <= marker {
+			continue
+		}
+		if delimiter != "" && len(marker) > len(prefix) && strings.HasSuffix(marker, delimiter) &&
+			strings.HasPrefix(key, marker) {
+			continue
+		}
+		entry, isPrefix := key, false
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				entry, isPrefix = key[:len(prefix)+i+len(delimiter)], true
+			}
+		}
+		if isPrefix && len(page.prefixes) > 0 && page.prefixes[len(page.prefixes)-1] == entry {
+			continue
+		}
+		if len(page.objects)+len(page.prefixes) == limit {
+			page.isTruncated = true
+			break
+		}
+		if isPrefix {
+			page.prefixes = append(page.prefixes, entry)
+		} else {
+			page.objects = append(page.objects, b.objects[key])
+		}
+		page.last = entry
+	}
+	return page
+}
+
+func parseMaxKeys(query url.Values) (int, error) {
+	v := query.Get("max-keys")
+	if v == "" {
+		return maxKeys, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, invalidArgument("max-keys must be a non-negative integer")
+	}
+	return min(n, maxKeys), nil
+}
+
+func (s *Server) listObjectsV2(w http.ResponseWriter, r *http.Request, bucketName string) error {
+	query := r.URL.Query()
+	limit, err := parseMaxKeys(query)
+	if err != nil {
+		return err
+	}
+	encodingType := query.Get("encoding-type")
+	if encodingType != "" && encodingType != "url" {
+		return invalidArgument("invalid encoding-type %q", encodingType)
+	}
+	marker := query.Get("start-after")
+	token := query.Get("continuation-token")
+	if token != "" {
+		b, err := base64.StdEncoding.DecodeString(token)
+		if err != nil {
+			return invalidArgument("the continuation token provided is incorrect")
+		}
+		marker = string(b)
+	}
+	prefix, delimiter := query.Get("prefix"), query.Get("delimiter")
+
+	s.mu.Lock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	page := b.list(prefix, delimiter, marker, limit)
+	s.mu.Unlock()
+
+	encode := keyEncoder(encodingType)
+	keyCount := len(page.objects) + len(page.prefixes)
+	result := listBucketResult{
+		Xmlns:             s3Namespace,
+		Name:              bucketName,
+		Prefix:            encode(prefix),
+		Delimiter:         encode(delimiter),
+		EncodingType:      encodingType,
+		MaxKeys:           limit,
+		IsTruncated:       page.isTruncated,
+		ContinuationToken: token,
+		StartAfter:        encode(query.Get("start-after")),
+		KeyCount:          &keyCount,
+	}
+	if page.isTruncated {
+		result.NextContinuationToken = base64.StdEncoding.EncodeToString([]byte(page.last))
+	}
+	result.Contents, result.CommonPrefixes = listingResults(page, encode, query.Get("fetch-owner") == "true")
+	writeXML(w, http.StatusOK, result)
+	return nil
+}
+
+func (s *Server) listObjects(w http.ResponseWriter, r *http.Request, bucketName string) error {
+	query := r.URL.Query()
+	limit, err := parseMaxKeys(query)
+	if err != nil {
+		return err
+	}
+	encodingType := query.Get("encoding-type")
+	if encodingType != "" && encodingType != "url" {
+		return invalidArgument("invalid encoding-type %q", encodingType)
+	}
+	prefix, delimiter, marker := query.Get("prefix"), query.Get("delimiter"), query.Get("marker")
+
+	s.mu.Lock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	page := b.list(prefix, delimiter, marker, limit)
+	s.mu.Unlock()
+
+	encode := keyEncoder(encodingType)
+	encodedMarker := encode(marker)
+	result := listBucketResult{
+		Xmlns:        s3Namespace,
+		Name:         bucketName,
+		Prefix:       encode(prefix),
+		Delimiter:    encode(delimiter),
+		EncodingType: encodingType,
+		MaxKeys:      limit,
+		IsTruncated:  page.isTruncated,
+		Marker:       &encodedMarker,
+	}
+	if page.isTruncated && delimiter != "" {
+		result.NextMarker = encode(page.last)
+	}
+	result.Contents, result.CommonPrefixes = listingResults(page, encode, true)
+	writeXML(w, http.StatusOK, result)
+	return nil
+}
+
+func listingResults(page listing, encode func(string) string, withOwner bool) ([]objectResult, []commonPrefix) {
+	contents := make([]objectResult, 0, len(page.objects))
+	for _, obj := range page.objects {
+		result := objectResult{
+			Key:          encode(obj.key),
+			LastModified: timestamp(obj.lastModified),
+			ETag:         obj.etag,
+			Size:         int64(len(obj.data)),
+			StorageClass: obj.storageClass,
+		}
+		if withOwner {
+			result.Owner = &fakeOwner
+		}
+		contents = append(contents, result)
+	}
+	prefixes := make([]commonPrefix, 0, len(page.prefixes))
+	for _, p := range page.prefixes {
+		prefixes = append(prefixes, commonPrefix{Prefix: encode(p)})
+	}
+	return contents, prefixes
+}
+
+// keyEncoder returns the encoding of the keys of a listing with encoding-type.
+func keyEncoder(encodingType string) func(string) string {
+	if encodingType != "url" {
+		return func(s string) string { return s }
+	}
+	return func(s string) string {
+		return strings.ReplaceAll(url.QueryEscape(s), "%2F", "/")
+	}
+}
+
+type deleteRequest struct {
+	Quiet   bool `xml:"Quiet"`
+	Objects []struct {
+		Key string `xml:"Key"`
+	} `xml:"Object"`
+}
+
+type deletedObject struct {
+	Key string `xml:"Key"`
+}
+
+type deleteError struct {
+	Key     string `xml:"Key"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+type deleteResult struct {
+	XMLName xml.Name        `xml:"DeleteResult"`
+	Xmlns   string          `xml:"xmlns,attr"`
+	Deleted []deletedObject `xml:"Deleted"`
+	Errors  []deleteError   `xml:"Error"`
+}
+
+func (s *Server) deleteObjects(w http.ResponseWriter, r *http.Request, bucketName string) error {
+	body, _, err := readPayload(r)
+	if err != nil {
+		return err
+	}
+	var req deleteRequest
+	if err := xml.Unmarshal(body, &req); err != nil || len(req.Objects) == 0 {
+		return errMalformedXML
+	}
+	if len(req.Objects) > maxKeys {
+		return errMalformedXML
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	result := deleteResult{Xmlns: s3Namespace}
+	for _, obj := range req.Objects {
+		if obj.Key == "" {
+			result.Errors = append(result.Errors, deleteError{
+				Key: obj.Key, Code: "InvalidArgument", Message: "Invalid key",
+			})
+			continue
+		}
+		delete(b.objects, obj.Key)
+		if !req.Quiet {
+			result.Deleted = append(result.Deleted, deletedObject{Key: obj.Key})
+		}
+	}
+	writeXML(w, http.StatusOK, result)
+	return nil
+}
@@ -0,0 +1,442 @@
+package awss3test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxPartNumber = 10000
+	// minPartSize is the smallest size of a part of a multipart upload other than the last part.
+	minPartSize = 5 << 20
+	maxParts    = 1000
+)
+
+type upload struct {
+	id        string
+	key       string
+	initiated time.Time
+	// object holds the attributes given to CreateMultipartUpload.
+	object            *object
+	checksumAlgorithm string
+	checksumType      string
+	parts             map[int32]*part
+}
+
+type part struct {
+	number       int32
+	data         []byte
+	etag         string
+	lastModified time.Time
+	checksum     string
+}
+
+// upload returns a multipart upload; the caller must hold s.mu.
+func (s *Server) upload(bucketName, key, uploadID string) (*bucket, *upload, error) {
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return nil, nil, err
+	}
+	u, ok := b.uploads[uploadID]
+	if !ok || u.key != key {
+		return nil, nil, errNoSuchUpload
+	}
+	return b, u, nil
+}
+
+func newUploadID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type initiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+func (s *Server) createMultipartUpload(w http.ResponseWriter, r *http.Request, bucketName, key string) error {
+	obj, err := newObjectFromHeaders(key, r.Header)
+	if err != nil {
+		return err
+	}
+	algorithm := strings.ToUpper(r.Header.Get("X-Amz-Checksum-Algorithm"))
+	if _, ok := checksumAlgorithms[algorithm]; algorithm != "" && !ok {
+		return invalidArgument("unsupported checksum algorithm %q", algorithm)
+	}
+	checksumType := strings.ToUpper(r.Header.Get("X-Amz-Checksum-Type"))
+	switch {
+	case checksumType == "" && algorithm != "":
+		checksumType = "COMPOSITE"
+		if strings.HasPrefix(algorithm, "CRC64") {
+			checksumType = "FULL_OBJECT"
+		}
+	case checksumType != "" && checksumType != "COMPOSITE" && checksumType != "FULL_OBJECT":
+		return invalidArgument("unsupported checksum type %q", checksumType)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	u := &upload{
+		id:                newUploadID(),
+		key:               key,
+		initiated:         s.now(),
+		object:            obj,
+		checksumAlgorithm: algorithm,
+		checksumType:      checksumType,
+		parts:             make(map[int32]*part),
+	}
+	b.uploads[u.id] = u
+	if algorithm != "" {
+		w.Header().Set("x-amz-checksum-algorithm", algorithm)
+		w.Header().Set("x-amz-checksum-type", checksumType)
+	}
+	writeXML(w, http.StatusOK, initiateMultipartUploadResult{
+		Xmlns: s3Namespace, Bucket: bucketName, Key: key, UploadID: u.id,
+	})
+	return nil
+}
+
+func parsePartNumber(v string) (int32, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > maxPartNumber {
+		return 0, invalidArgument("part number must be an integer between 1 and %d, inclusive", maxPartNumber)
+	}
+	return int32(n), nil
+}
+
+func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request, bucketName, key, uploadID string) error {
+	number, err := parsePartNumber(r.URL.Query().Get("partNumber"))
+	if err != nil {
+		return err
+	}
+	data, checksums, err := readPayload(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, u, err := s.upload(bucketName, key, uploadID)
+	if err != nil {
+		return err
+	}
+	p := &part{number: number, data: data, etag: strconv.Quote(md5Hex(data)), lastModified: s.now()}
+	if u.checksumAlgorithm != "" {
+		p.checksum = checksums[u.checksumAlgorithm]
+		if p.checksum == "" {
+			p.checksum = checksum(u.checksumAlgorithm, data)
+		}
+		w.Header().Set(checksumHeader(u.checksumAlgorithm), p.checksum)
+	}
+	u.parts[number] = p
+	w.Header().Set("ETag", p.etag)
+	return nil
+}
+
+type copyPartResult struct {
+	XMLName      xml.Name `xml:"CopyPartResult"`
+	Xmlns        string   `xml:"xmlns,attr"`
+	ETag         string   `xml:"ETag"`
+	LastModified string   `xml:"LastModified"`
+}
+
+func (s *Server) uploadPartCopy(w http.ResponseWriter, r *http.Request, bucketName, key, uploadID string) error {
+	number, err := parsePartNumber(r.URL.Query().Get("partNumber"))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, u, err := s.upload(bucketName, key, uploadID)
+	if err != nil {
+		return err
+	}
+	srcObj, _, err := s.copySourceObject(r.Header)
+	if err != nil {
+		return err
+	}
+	data := srcObj.data
+	if v := r.Header.Get("X-Amz-Copy-Source-Range"); v != "" {
+		start, end, ok, err := parseRange(v, int64(len(data)))
+		if err != nil || !ok || end >= int64(len(data)) {
+			return invalidArgument("the x-amz-copy-source-range value must be of the form bytes=first-last " +
+				"where first and last are the zero-based offsets of the first and last bytes to copy")
+		}
+		data = data[start : end+1]
+	}
+	data = slices.Clone(data)
+	p := &part{number: number, data: data, etag: strconv.Quote(md5Hex(data)), lastModified: s.now()}
+	if u.checksumAlgorithm != "" {
+		p.checksum = checksum(u.checksumAlgorithm, data)
+	}
+	u.parts[number] = p
+	writeXML(w, http.StatusOK, copyPartResult{
+		Xmlns: s3Namespace, ETag: p.etag, LastModified: timestamp(p.lastModified),
+	})
+	return nil
+}
+
+func (s *Server) abortMultipartUpload(w http.ResponseWriter, bucketName, key, uploadID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, _, err := s.upload(bucketName, key, uploadID)
+	if err != nil {
+		return err
+	}
+	delete(b.uploads, uploadID)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+type completedPart struct {
+	PartNumber        int32  `xml:"PartNumber"`
+	ETag              string `xml:"ETag"`
+	ChecksumCRC32     string `xml:"ChecksumCRC32"`
+	ChecksumCRC32C    string `xml:"ChecksumCRC32C"`
+	ChecksumCRC64NVME string `xml:"ChecksumCRC64NVME"`
+	ChecksumSHA1      string `xml:"ChecksumSHA1"`
+	ChecksumSHA256    string `xml:"ChecksumSHA256"`
+}
+
+func (p completedPart) checksum(algorithm string) string {
+	return map[string]string{
+		"CRC32":     p.ChecksumCRC32,
+		"CRC32C":    p.ChecksumCRC32C,
+		"CRC64NVME": p.ChecksumCRC64NVME,
+		"SHA1":      p.ChecksumSHA1,
+		"SHA256":    p.ChecksumSHA256,
+	}[algorithm]
+}
+
+type completeMultipartUpload struct {
+	Parts []completedPart `xml:"Part"`
+}
+
+type completeMultipartUploadResult struct {
+	XMLName           xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns             string   `xml:"xmlns,attr"`
+	Location          string   `xml:"Location"`
+	Bucket            string   `xml:"Bucket"`
+	Key               string   `xml:"Key"`
+	ETag              string   `xml:"ETag"`
+	ChecksumCRC32     string   `xml:"ChecksumCRC32,omitempty"`
+	ChecksumCRC32C    string   `xml:"ChecksumCRC32C,omitempty"`
+	ChecksumCRC64NVME string   `xml:"ChecksumCRC64NVME,omitempty"`
+	ChecksumSHA1      string   `xml:"ChecksumSHA1,omitempty"`
+	ChecksumSHA256    string   `xml:"ChecksumSHA256,omitempty"`
+	ChecksumType      string   `xml:"ChecksumType,omitempty"`
+}
+
+func errInvalidPart(number int32) error {
+	return &s3Error{
+		status: http.StatusBadRequest, code: "InvalidPart",
+		message: fmt.Sprintf("One or more of the specified parts could not be found. "+
+			"The part may not have been uploaded, or the specified entity tag may not match the part's entity tag. "+
+			"Part number: %d", number),
+	}
+}
+
+func (s *Server) completeMultipartUpload(
+	w http.ResponseWriter, r *http.Request, bucketName, key, uploadID string,
+) error {
+	body, _, err := readPayload(r)
+	if err != nil {
+		return err
+	}
+	var req completeMultipartUpload
+	if err := xml.Unmarshal(body, &req); err != nil || len(req.Parts) == 0 {
+		return errMalformedXML
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, u, err := s.upload(bucketName, key, uploadID)
+	if err != nil {
+		return err
+	}
+	if err := checkWriteConditions(r.Header, b, key); err != nil {
+		return err
+	}
+	var (
+		data      []byte
+		sizes     []int64
+		md5s      []byte
+		checksums []string
+	)
+	for i, completed := range req.Parts {
+		if i > 0 && completed.PartNumber <= req.Parts[i-1].PartNumber {
+			return &s3Error{
+				status: http.StatusBadRequest, code: "InvalidPartOrder",
+				message: "The list of parts was not in ascending order. Parts must be ordered by part number.",
+			}
+		}
+		p, ok := u.parts[completed.PartNumber]
+		if !ok || !etagMatches(completed.ETag, p.etag) {
+			return errInvalidPart(completed.PartNumber)
+		}
+		if u.checksumAlgorithm != "" {
+			if v := completed.checksum(u.checksumAlgorithm); v != "" && v != p.checksum {
+				return errInvalidPart(completed.PartNumber)
+			}
+		}
+		if i < len(req.Parts)-1 && len(p.data) < minPartSize {
+			return &s3Error{
+				status: http.StatusBadRequest, code: "EntityTooSmall",
+				message: "Your proposed upload is smaller than the minimum allowed object size.",
+			}
+		}
+		data = append(data, p.data...)
+		sizes = append(sizes, int64(len(p.data)))
+		sum, _ := hex.DecodeString(strings.Trim(p.etag, `"`))
+		md5s = append(md5s, sum...)
+		checksums = append(checksums, p.checksum)
+	}
+
+	obj := u.object.clone()
+	obj.setContent(data, map[string]string{}, s.now())
+	obj.etag = strconv.Quote(fmt.Sprintf("%s-%d", md5Hex(md5s), len(req.Parts)))
+	obj.parts = sizes
+	if algorithm := u.checksumAlgorithm; algorithm != "" {
+		obj.checksumType = u.checksumType
+		if u.checksumType == "FULL_OBJECT" {
+			obj.checksums[algorithm] = checksum(algorithm, data)
+		} else {
+			composite, err := compositeChecksum(algorithm, checksums)
+			if err != nil {
+				return err
+			}
+			obj.checksums[algorithm] = composite
+		}
+	}
+	b.objects[key] = obj
+	delete(b.uploads, uploadID)
+
+	result := completeMultipartUploadResult{
+		Xmlns:        s3Namespace,
+		Location:     s.URL() + "/" + bucketName + "/" + key,
+		Bucket:       bucketName,
+		Key:          key,
+		ETag:         obj.etag,
+		ChecksumType: obj.checksumType,
+	}
+	result.ChecksumCRC32, result.ChecksumCRC32C = obj.checksums["CRC32"], obj.checksums["CRC32C"]
+	result.ChecksumCRC64NVME = obj.checksums["CRC64NVME"]
+	result.ChecksumSHA1, result.ChecksumSHA256 = obj.checksums["SHA1"], obj.checksums["SHA256"]
+	writeXML(w, http.StatusOK, result)
+	return nil
+}
+
+type listPartsResult struct {
+	XMLName              xml.Name     `xml:"ListPartsResult"`
+	Xmlns                string       `xml:"xmlns,attr"`
+	Bucket               string       `xml:"Bucket"`
+	Key                  string       `xml:"Key"`
+	UploadID             string       `xml:"UploadId"`
+	PartNumberMarker     int32        `xml:"PartNumberMarker"`
+	NextPartNumberMarker int32        `xml:"NextPartNumberMarker"`
+	MaxParts             int          `xml:"MaxParts"`
+	IsTruncated          bool         `xml:"IsTruncated"`
+	StorageClass         string       `xml:"StorageClass"`
+	ChecksumAlgorithm    string       `xml:"ChecksumAlgorithm,omitempty"`
+	ChecksumType         string       `xml:"ChecksumType,omitempty"`
+	Parts                []partResult `xml:"Part"`
+}
+
+type partResult struct {
+	PartNumber        int32  `xml:"PartNumber"`
+	LastModified      string `xml:"LastModified"`
+	ETag              string `xml:"ETag"`
+	Size              int64  `xml:"Size"`
+	ChecksumCRC32     string `xml:"ChecksumCRC32,omitempty"`
+	ChecksumCRC32C    string `xml:"ChecksumCRC32C,omitempty"`
+	ChecksumCRC64NVME string `xml:"ChecksumCRC64NVME,omitempty"`
+	ChecksumSHA1      string `xml:"ChecksumSHA1,omitempty"`
+	ChecksumSHA256    string `xml:"ChecksumSHA256,omitempty"`
+}
+
+func (s *Server) listParts(w http.ResponseWriter, r *http.Request, bucketName, key, uploadID string) error {
+	query := r.URL.Query()
+	limit := maxParts
+	if v := query.Get("max-parts"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return invalidArgument("max-parts must be a non-negative integer")
+		}
+		limit = min(n, maxParts)
+	}
+	var marker int32
+	if v := query.Get("part-number-marker"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return invalidArgument("part-number-marker must be a non-negative integer")
+		}
+		marker = int32(n)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, u, err := s.upload(bucketName, key, uploadID)
+	if err != nil {
+		return err
+	}
+	result := listPartsResult{
+		Xmlns:             s3Namespace,
+		Bucket:            bucketName,
+		Key:               key,
+		UploadID:          uploadID,
+		PartNumberMarker:  marker,
+		MaxParts:          limit,
+		StorageClass:      u.object.storageClass,
+		ChecksumAlgorithm: u.checksumAlgorithm,
+		ChecksumType:      u.checksumType,
+	}
+	for _, number := range slices.Sorted(maps.Keys(u.parts)) {
+		if number <= marker {
+			continue
+		}
+		if len(result.Parts) == limit {
+			result.IsTruncated = true
+			break
+		}
+		p := u.parts[number]
+		pr := partResult{
+			PartNumber:   number,
+			LastModified: timestamp(p.lastModified),
+			ETag:         p.etag,
+			Size:         int64(len(p.data)),
+		}
+		switch u.checksumAlgorithm {
+		case "CRC32":
+			pr.ChecksumCRC32 = p.checksum
+		case "CRC32C":
+			pr.ChecksumCRC32C = p.checksum
+		case "CRC64NVME":
+			pr.ChecksumCRC64NVME = p.checksum
+		case "SHA1":
+			pr.ChecksumSHA1 = p.checksum
+		case "SHA256":
+			pr.ChecksumSHA256 = p.checksum
+		}
+		result.Parts = append(result.Parts, pr)
+		result.NextPartNumberMarker = number
+	}
+	writeXML(w, http.StatusOK, result)
+	return nil
+}
@@ -0,0 +1,624 @@
+package awss3test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultContentType  = "binary/octet-stream"
+	defaultStorageClass = "STANDARD"
+	maxTags             = 10
+)
+
+// object is a stored object. Objects are never modified once stored; changes store a copy.
+type object struct {
+	key                string
+	data               []byte
+	etag               string
+	lastModified       time.Time
+	contentType        string
+	cacheControl       string
+	contentDisposition string
+	contentEncoding    string
+	contentLanguage    string
+	expires            string
+	metadata           map[string]string
+	tags               map[string]string
+	storageClass       string
+	checksums          map[string]string
+	checksumType       string
+	// parts are the sizes of the parts of an object uploaded with a multipart upload.
+	parts []int64
+}
+
+func (o *object) clone() *object {
+	c := *o
+	c.metadata = maps.Clone(o.metadata)
+	c.tags = maps.Clone(o.tags)
+	c.checksums = maps.Clone(o.checksums)
+	return &c
+}
+
+// setContent sets the content, the ETag and the modification time of a single-part object.
+func (o *object) setContent(data []byte, checksums map[string]string, now time.Time) {
+	o.data = data
+	o.etag = strconv.Quote(md5Hex(data))
+	o.lastModified = now.UTC().Truncate(time.Second)
+	o.checksums = checksums
+	o.checksumType = ""
+	if len(checksums) > 0 {
+		o.checksumType = "FULL_OBJECT"
+	}
+	o.parts = nil
+}
+
+// newObjectFromHeaders returns an object with the attributes of a PutObject, CopyObject with the REPLACE
+// metadata directive or CreateMultipartUpload request.
+func newObjectFromHeaders(key string, header http.Header) (*object, error) {
+	obj := &object{
+		key:                key,
+		contentType:        header.Get("Content-Type"),
+		cacheControl:       header.Get("Cache-Control"),
+		contentDisposition: header.Get("Content-Disposition"),
+		contentEncoding:    storedContentEncoding(header),
+		contentLanguage:    header.Get("Content-Language"),
+		expires:            header.Get("Expires"),
+		metadata:           metadataFromHeaders(header),
+		storageClass:       header.Get("X-Amz-Storage-Class"),
+	}
+	if obj.contentType == "" {
+		obj.contentType = defaultContentType
+	}
+	if obj.storageClass == "" {
+		obj.storageClass = defaultStorageClass
+	}
+	tags, err := parseTagging(header.Get("X-Amz-Tagging"))
+	if err != nil {
+		return nil, err
+	}
+	obj.tags = tags
+	return obj, nil
+}
+
+func metadataFromHeaders(header http.Header) map[string]string {
+	metadata := make(map[string]string)
+	for name, values := range header {
+		if k, ok := strings.CutPrefix(strings.ToLower(name), "x-amz-meta-"); ok && len(values) > 0 {
+			metadata[k] = strings.Join(values, ",")
+		}
+	}
+	return metadata
+}
+
+func errInvalidTag(message string) error {
+	return &s3Error{status: http.StatusBadRequest, code: "InvalidTag", message: message}
+}
+
+// parseTagging parses the tags of an x-amz-tagging header, encoded as a URL query.
+func parseTagging(v string) (map[string]string, error) {
+	tags := make(map[string]string)
+	if v == "" {
+		return tags, nil
+	}
+	query, err := url.ParseQuery(v)
+	if err != nil {
+		return nil, errInvalidTag("the header 'x-amz-tagging' shall be encoded as UTF-8 then URLEncoded URL query parameters")
+	}
+	for k, values := range query {
+		if len(values) > 1 {
+			return nil, errInvalidTag("cannot provide multiple tags with the same key")
+		}
+		tags[k] = values[0]
+	}
+	return tags, validateTags(tags)
+}
+
+func validateTags(tags map[string]string) error {
+	if len(tags) > maxTags {
+		return errInvalidTag("object tags cannot be greater than 10")
+	}
+	for k, v := range tags {
+		if k == "" || len(k) > 128 {
+			return errInvalidTag("the TagKey you have provided is invalid")
+		}
+		if len(v) > 256 {
+			return errInvalidTag("the TagValue you have provided is invalid")
+		}
+	}
+	return nil
+}
+
+// object returns an object; the caller must hold s.mu.
+func (s *Server) object(bucketName, key string) (*object, error) {
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := b.objects[key]
+	if !ok {
+		return nil, errNoSuchKey
+	}
+	return obj, nil
+}
+
+// checkWriteConditions evaluates the If-None-Match and If-Match headers of a conditional write of key;
+// the caller must hold s.mu.
+func checkWriteConditions(header http.Header, b *bucket, key string) error {
+	current, exists := b.objects[key]
+	if v := header.Get("If-None-Match"); v != "" {
+		if v != "*" {
+			return &s3Error{
+				status: http.StatusNotImplemented, code: "NotImplemented",
+				message: "A header you provided implies functionality that is not implemented",
+			}
+		}
+		if exists {
+			return errPreconditionFailed
+		}
+	}
+	if v := header.Get("If-Match"); v != "" {
+		if !exists {
+			return errNoSuchKey
+		}
+		if !etagMatches(v, current.etag) {
+			return errPreconditionFailed
+		}
+	}
+	return nil
+}
+
+// etagMatches reports whether an If-Match or If-None-Match header matches an ETag.
+func etagMatches(condition, etag string) bool {
+	for _, v := range strings.Split(condition, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || v == etag || strconv.Quote(v) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) putObject(w http.ResponseWriter, r *http.Request, bucketName, key string) error {
+	obj, err := newObjectFromHeaders(key, r.Header)
+	if err != nil {
+		return err
+	}
+	data, checksums, err := readPayload(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	if err := checkWriteConditions(r.Header, b, key); err != nil {
+		return err
+	}
+	obj.setContent(data, checksums, s.now())
+	b.objects[key] = obj
+	w.Header().Set("ETag", obj.etag)
+	writeChecksumHeaders(w.Header(), obj)
+	return nil
+}
+
+func writeChecksumHeaders(header http.Header, obj *object) {
+	for algorithm, v := range obj.checksums {
+		header.Set(checksumHeader(algorithm), v)
+	}
+	if obj.checksumType != "" {
+		header.Set("x-amz-checksum-type", obj.checksumType)
+	}
+}
+
+func (s *Server) deleteObject(w http.ResponseWriter, bucketName, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	delete(b.objects, key)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+var errInvalidPartNumber = &s3Error{
+	status:  http.StatusRequestedRangeNotSatisfiable,
+	code:    "InvalidPartNumber",
+	message: "The requested partnumber is not satisfiable",
+}
+
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request, bucketName, key string) error {
+	s.mu.Lock()
+	obj, err := s.object(bucketName, key)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := checkReadConditions(r.Header, obj); err != nil {
+		if err == errNotModified {
+			w.Header().Set("ETag", obj.etag)
+			w.Header().Set("Last-Modified", obj.lastModified.Format(http.TimeFormat))
+		}
+		return err
+	}
+
+	size := int64(len(obj.data))
+	start, end, partial := int64(0), size-1, false
+	header := w.Header()
+	query := r.URL.Query()
+	if v := query.Get("partNumber"); v != "" {
+		if r.Header.Get("Range") != "" {
+			return invalidArgument("cannot specify both Range header and partNumber query parameter")
+		}
+		partNumber, err := strconv.Atoi(v)
+		if err != nil || partNumber < 1 || partNumber > maxPartNumber {
+			return invalidArgument("part number must be an integer between 1 and %d", maxPartNumber)
+		}
+		parts := obj.parts
+		if parts == nil {
+			parts = []int64{size}
+		} else {
+			header.Set("x-amz-mp-parts-count", strconv.Itoa(len(parts)))
+		}
+		if partNumber > len(parts) {
+			return errInvalidPartNumber
+		}
+		start = 0
+		for _, n := range parts[:partNumber-1] {
+			start += n
+		}
+		end = start + parts[partNumber-1] - 1
+		partial = size > 0
+	} else if v := r.Header.Get("Range"); v != "" {
+		var ok bool
+		if start, end, ok, err = parseRange(v, size); err != nil {
+			header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+			return err
+		}
+		partial = ok
+		if !ok {
+			start, end = 0, size-1
+		}
+	}
+
+	header.Set("Accept-Ranges", "bytes")
+	header.Set("ETag", obj.etag)
+	header.Set("Last-Modified", obj.lastModified.Format(http.TimeFormat))
+	header.Set("Content-Type", obj.contentType)
+	for name, v := range map[string]string{
+		"Cache-Control":       obj.cacheControl,
+		"Content-Disposition": obj.contentDisposition,
+		"Content-Encoding":    obj.contentEncoding,
+		"Content-Language":    obj.contentLanguage,
+		"Expires":             obj.expires,
+	} {
+		if v != "" {
+			header.Set(name, v)
+		}
+	}
+	for k, v := range obj.metadata {
+		header.Set("x-amz-meta-"+k, v)
+	}
+	if len(obj.tags) > 0 {
+		header.Set("x-amz-tagging-count", strconv.Itoa(len(obj.tags)))
+	}
+	if obj.storageClass != defaultStorageClass {
+		header.Set("x-amz-storage-class", obj.storageClass)
+	}
+	if !partial && strings.EqualFold(r.Header.Get("X-Amz-Checksum-Mode"), "ENABLED") {
+		writeChecksumHeaders(header, obj)
+	}
+	for param, name := range map[string]string{
+		"response-content-type":        "Content-Type",
+		"response-content-disposition": "Content-Disposition",
+		"response-cache-control":       "Cache-Control",
+		"response-content-encoding":    "Content-Encoding",
+		"response-content-language":    "Content-Language",
+		"response-expires":             "Expires",
+	} {
+		if v := query.Get(param); v != "" {
+			header.Set(name, v)
+		}
+	}
+
+	status := http.StatusOK
+	body := obj.data
+	if partial {
+		status = http.StatusPartialContent
+		header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+		body = obj.data[start : end+1]
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(body)
+	}
+	return nil
+}
+
+// checkReadConditions evaluates the conditional headers of a GetObject or HeadObject request
+// in the order S3 documents.
+func checkReadConditions(header http.Header, obj *object) error {
+	ifMatch, ifNoneMatch := header.Get("If-Match"), header.Get("If-None-Match")
+	if ifMatch != "" && !etagMatches(ifMatch, obj.etag) {
+		return errPreconditionFailed
+	}
+	if v := header.Get("If-Unmodified-Since"); ifMatch == "" && v != "" {
+		if t, err := http.ParseTime(v); err == nil && obj.lastModified.After(t) {
+			return errPreconditionFailed
+		}
+	}
+	if ifNoneMatch != "" && etagMatches(ifNoneMatch, obj.etag) {
+		return errNotModified
+	}
+	if v := header.Get("If-Modified-Since"); ifNoneMatch == "" && v != "" {
+		if t, err := http.ParseTime(v); err == nil && !obj.lastModified.After(t) {
+			return errNotModified
+		}
+	}
+	return nil
+}
+
+// parseRange parses a Range header of a single byte range. As S3 does, it ignores a header it cannot parse,
+// returning ok false, and returns errInvalidRange for a range that starts after the end of the object.
+func parseRange(v string, size int64) (start, end int64, ok bool, err error) {
+	spec, found := strings.CutPrefix(v, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false, nil
+	}
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false, nil
+	}
+	if first == "" {
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return 0, 0, false, nil
+		}
+		if n == 0 || size == 0 {
+			return 0, 0, false, errInvalidRange
+		}
+		return max(size-n, 0), size - 1, true, nil
+	}
+	start, err = strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false, nil
+	}
+	end = size - 1
+	if last != "" {
+		if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+			return 0, 0, false, nil
+		}
+		end = min(end, size-1)
+	}
+	if start >= size {
+		return 0, 0, false, errInvalidRange
+	}
+	return start, end, true, nil
+}
+
+// copySource is the object named by an x-amz-copy-source header.
+type copySource struct {
+	bucket string
+	key    string
+}
+
+func parseCopySource(v string) (copySource, error) {
+	v, _, _ = strings.Cut(v, "?")
+	unescaped, err := url.PathUnescape(v)
+	if err != nil {
+		return copySource{}, invalidArgument("invalid copy source encoding")
+	}
+	bucketName, key, ok := strings.Cut(strings.TrimPrefix(unescaped, "/"), "/")
+	if !ok || bucketName == "" || key == "" {
+		return copySource{}, invalidArgument("copy source bucket name and key must be specified")
+	}
+	return copySource{bucket: bucketName, key: key}, nil
+}
+
+// checkCopySourceConditions evaluates the x-amz-copy-source-if-* headers of a copy request.
+func checkCopySourceConditions(header http.Header, obj *object) error {
+	if v := header.Get("X-Amz-Copy-Source-If-Match"); v != "" && !etagMatches(v, obj.etag) {
+		return errPreconditionFailed
+	}
+	if v := header.Get("X-Amz-Copy-Source-If-None-Match"); v != "" && etagMatches(v, obj.etag) {
+		return errPreconditionFailed
+	}
+	if v := header.Get("X-Amz-Copy-Source-If-Unmodified-Since"); v != "" {
+		if t, err := http.ParseTime(v); err == nil && obj.lastModified.After(t) {
+			return errPreconditionFailed
+		}
+	}
+	if v := header.Get("X-Amz-Copy-Source-If-Modified-Since"); v != "" {
+		if t, err := http.ParseTime(v); err == nil && !obj.lastModified.After(t) {
+			return errPreconditionFailed
+		}
+	}
+	return nil
+}
+
+// copySourceObject returns the source object of a copy request; the caller must hold s.mu.
+func (s *Server) copySourceObject(header http.Header) (*object, copySource, error) {
+	src, err := parseCopySource(header.Get("X-Amz-Copy-Source"))
+	if err != nil {
+		return nil, src, err
+	}
+	obj, err := s.object(src.bucket, src.key)
+	if err != nil {
+		return nil, src, err
+	}
+	return obj, src, checkCopySourceConditions(header, obj)
+}
+
+type copyObjectResult struct {
+	XMLName           xml.Name `xml:"CopyObjectResult"`
+	Xmlns             string   `xml:"xmlns,attr"`
+	ETag              string   `xml:"ETag"`
+	LastModified      string   `xml:"LastModified"`
+	ChecksumCRC32     string   `xml:"ChecksumCRC32,omitempty"`
+	ChecksumCRC32C    string   `xml:"ChecksumCRC32C,omitempty"`
+	ChecksumCRC64NVME string   `xml:"ChecksumCRC64NVME,omitempty"`
+	ChecksumSHA1      string   `xml:"ChecksumSHA1,omitempty"`
+	ChecksumSHA256    string   `xml:"ChecksumSHA256,omitempty"`
+	ChecksumType      string   `xml:"ChecksumType,omitempty"`
+}
+
+func (s *Server) copyObject(w http.ResponseWriter, r *http.Request, bucketName, key string) error {
+	metadataDirective := r.Header.Get("X-Amz-Metadata-Directive")
+	taggingDirective := r.Header.Get("X-Amz-Tagging-Directive")
+	for _, directive := range []string{metadataDirective, taggingDirective} {
+		if directive != "" && directive != "COPY" && directive != "REPLACE" {
+			return invalidArgument("unknown directive %q", directive)
+		}
+	}
+	algorithm := strings.ToUpper(r.Header.Get("X-Amz-Checksum-Algorithm"))
+	if _, ok := checksumAlgorithms[algorithm]; algorithm != "" && !ok {
+		return invalidArgument("unsupported checksum algorithm %q", algorithm)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	srcObj, src, err := s.copySourceObject(r.Header)
+	if err != nil {
+		return err
+	}
+	storageClass := r.Header.Get("X-Amz-Storage-Class")
+	if src.bucket == bucketName && src.key == key && metadataDirective != "REPLACE" &&
+		(storageClass == "" || storageClass == srcObj.storageClass) && algorithm == "" {
+		return &s3Error{
+			status: http.StatusBadRequest, code: "InvalidRequest",
+			message: "This copy request is illegal because it is trying to copy an object to itself without " +
+				"changing the object's metadata, storage class, website redirect location or encryption attributes.",
+		}
+	}
+	if err := checkWriteConditions(r.Header, b, key); err != nil {
+		return err
+	}
+
+	obj := srcObj.clone()
+	obj.key = key
+	if metadataDirective == "REPLACE" {
+		replaced, err := newObjectFromHeaders(key, r.Header)
+		if err != nil {
+			return err
+		}
+		obj.contentType, obj.cacheControl = replaced.contentType, replaced.cacheControl
+		obj.contentDisposition, obj.contentEncoding = replaced.contentDisposition, replaced.contentEncoding
+		obj.contentLanguage, obj.expires, obj.metadata = replaced.contentLanguage, replaced.expires, replaced.metadata
+	}
+	if taggingDirective == "REPLACE" {
+		if obj.tags, err = parseTagging(r.Header.Get("X-Amz-Tagging")); err != nil {
+			return err
+		}
+	}
+	if storageClass != "" {
+		obj.storageClass = storageClass
+	}
+	checksums := make(map[string]string)
+	if srcObj.checksumType == "FULL_OBJECT" {
+		maps.Copy(checksums, srcObj.checksums)
+	}
+	if algorithm != "" {
+		checksums[algorithm] = checksum(algorithm, srcObj.data)
+	}
+	obj.setContent(srcObj.data, checksums, s.now())
+	b.objects[key] = obj
+
+	result := copyObjectResult{
+		Xmlns:        s3Namespace,
+		ETag:         obj.etag,
+		LastModified: timestamp(obj.lastModified),
+		ChecksumType: obj.checksumType,
+	}
+	result.ChecksumCRC32, result.ChecksumCRC32C = obj.checksums["CRC32"], obj.checksums["CRC32C"]
+	result.ChecksumCRC64NVME = obj.checksums["CRC64NVME"]
+	result.ChecksumSHA1, result.ChecksumSHA256 = obj.checksums["SHA1"], obj.checksums["SHA256"]
+	writeXML(w, http.StatusOK, result)
+	return nil
+}
+
+type tag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+type tagging struct {
+	XMLName xml.Name `xml:"Tagging"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	TagSet  []tag    `xml:"TagSet>Tag"`
+}
+
+func (s *Server) getObjectTagging(w http.ResponseWriter, bucketName, key string) error {
+	s.mu.Lock()
+	obj, err := s.object(bucketName, key)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	result := tagging{Xmlns: s3Namespace, TagSet: []tag{}}
+	for _, k := range slices.Sorted(maps.Keys(obj.tags)) {
+		result.TagSet = append(result.TagSet, tag{Key: k, Value: obj.tags[k]})
+	}
+	writeXML(w, http.StatusOK, result)
+	return nil
+}
+
+func (s *Server) putObjectTagging(w http.ResponseWriter, r *http.Request, bucketName, key string) error {
+	body, _, err := readPayload(r)
+	if err != nil {
+		return err
+	}
+	var req tagging
+	if err := xml.Unmarshal(body, &req); err != nil {
+		return errMalformedXML
+	}
+	tags := make(map[string]string, len(req.TagSet))
+	for _, t := range req.TagSet {
+		if _, ok := tags[t.Key]; ok {
+			return errInvalidTag("cannot provide multiple tags with the same key")
+		}
+		tags[t.Key] = t.Value
+	}
+	if err := validateTags(tags); err != nil {
+		return err
+	}
+	return s.setTags(bucketName, key, tags)
+}
+
+func (s *Server) deleteObjectTagging(w http.ResponseWriter, bucketName, key string) error {
+	if err := s.setTags(bucketName, key, map[string]string{}); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// setTags replaces the tags of an object without changing its modification time, as S3 does.
+func (s *Server) setTags(bucketName, key string, tags map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, err := s.object(bucketName, key)
+	if err != nil {
+		return err
+	}
+	obj = obj.clone()
+	obj.tags = tags
+	s.buckets[bucketName].objects[key] = obj
+	return nil
+}
@@ -0,0 +1,194 @@
+package awss3test
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"  // nolint:gosec
+	"crypto/sha1" // nolint:gosec
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+	"io"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// checksumAlgorithms are the checksum algorithms the server verifies and stores, by their names in S3.
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"CRC32":     func() hash.Hash { return crc32.NewIEEE() },
+	"CRC32C":    func() hash.Hash { return crc32.New(crc32.MakeTable(crc32.Castagnoli)) },
+	"CRC64NVME": func() hash.Hash { return crc64.New(crc64NVMETable) },
+	"SHA1":      sha1.New,
+	"SHA256":    sha256.New,
+}
+
+var crc64NVMETable = crc64.MakeTable(0x9a6c9329ac4bc9b5)
+
+func checksumHeader(algorithm string) string {
+	return "x-amz-checksum-" + strings.ToLower(algorithm)
+}
+
+func checksum(algorithm string, data []byte) string {
+	h := checksumAlgorithms[algorithm]()
+	h.Write(data)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func md5Hex(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+var (
+	errBadDigest = &s3Error{
+		status: http.StatusBadRequest, code: "BadDigest",
+		message: "The Content-MD5 or checksum value that you specified did not match what the server received.",
+	}
+	errSHA256Mismatch = &s3Error{
+		status: http.StatusBadRequest, code: "XAmzContentSHA256Mismatch",
+		message: "The provided 'x-amz-content-sha256' header does not match what was computed.",
+	}
+	errIncompleteBody = &s3Error{
+		status: http.StatusBadRequest, code: "IncompleteBody",
+		message: "You did not provide the number of bytes specified by the Content-Length HTTP header.",
+	}
+)
+
+// readPayload reads the body of a request, decoding the aws-chunked encoding used by the AWS SDK for streaming
+// uploads, and verifies its Content-MD5, its x-amz-content-sha256 and its checksum, sent in a header or a
+// trailer. It returns the checksums of the payload by algorithm. The signatures of the chunks are not verified.
+func readPayload(r *http.Request) ([]byte, map[string]string, error) {
+	contentSHA256 := r.Header.Get("X-Amz-Content-Sha256")
+	var body []byte
+	trailers := make(http.Header)
+	if strings.HasPrefix(contentSHA256, "STREAMING-") || hasContentEncoding(r.Header, "aws-chunked") {
+		var err error
+		if body, err = readChunked(r.Body, trailers); err != nil {
+			return nil, nil, err
+		}
+		if v := r.Header.Get("X-Amz-Decoded-Content-Length"); v != "" && v != strconv.Itoa(len(body)) {
+			return nil, nil, errIncompleteBody
+		}
+	} else {
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return nil, nil, errIncompleteBody
+		}
+		if isHexSHA256(contentSHA256) {
+			sum := sha256.Sum256(body)
+			if !strings.EqualFold(contentSHA256, hex.EncodeToString(sum[:])) {
+				return nil, nil, errSHA256Mismatch
+			}
+		}
+	}
+	if v := r.Header.Get("Content-MD5"); v != "" {
+		sum := md5.Sum(body)
+		if v != base64.StdEncoding.EncodeToString(sum[:]) {
+			return nil, nil, errBadDigest
+		}
+	}
+	checksums := make(map[string]string)
+	for algorithm := range checksumAlgorithms {
+		name := checksumHeader(algorithm)
+		expected := r.Header.Get(name)
+		if expected == "" {
+			expected = trailers.Get(name)
+		}
+		if expected == "" {
+			continue
+		}
+		if actual := checksum(algorithm, body); expected != actual {
+			return nil, nil, errBadDigest
+		}
+		checksums[algorithm] = expected
+	}
+	if algorithm := strings.ToUpper(r.Header.Get("X-Amz-Sdk-Checksum-Algorithm")); algorithm != "" {
+		if _, ok := checksumAlgorithms[algorithm]; !ok {
+			return nil, nil, invalidArgument("unsupported checksum algorithm %q", algorithm)
+		}
+	}
+	return body, checksums, nil
+}
+
+// readChunked decodes a body in the aws-chunked encoding:
+//
+//	<hex size>[;chunk-signature=<signature>]\r\n<data>\r\n ... 0[;chunk-signature=<signature>]\r\n<trailers>\r\n
+func readChunked(body io.Reader, trailers http.Header) ([]byte, error) {
+	br := bufio.NewReader(body)
+	var data bytes.Buffer
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, errIncompleteBody
+		}
+		sizeField, _, _ := strings.Cut(strings.TrimRight(line, "\r\n"), ";")
+		size, err := strconv.ParseInt(strings.TrimSpace(sizeField), 16, 64)
+		if err != nil || size < 0 {
+			return nil, invalidArgument("malformed aws-chunked encoding")
+		}
+		if size == 0 {
+			break
+		}
+		if _, err := io.CopyN(&data, br, size); err != nil {
+			return nil, errIncompleteBody
+		}
+		if crlf, err := br.ReadString('\n'); err != nil || strings.TrimRight(crlf, "\r\n") != "" {
+			return nil, invalidArgument("malformed aws-chunked encoding")
+		}
+	}
+	header, err := textproto.NewReader(br).ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return nil, invalidArgument("malformed aws-chunked trailer")
+	}
+	for name, values := range header {
+		trailers[name] = values
+	}
+	return data.Bytes(), nil
+}
+
+func hasContentEncoding(header http.Header, encoding string) bool {
+	for _, v := range strings.Split(header.Get("Content-Encoding"), ",") {
+		if strings.TrimSpace(v) == encoding {
+			return true
+		}
+	}
+	return false
+}
+
+// storedContentEncoding removes aws-chunked, which only applies to the transfer, from a Content-Encoding.
+func storedContentEncoding(header http.Header) string {
+	var encodings []string
+	for _, v := range strings.Split(header.Get("Content-Encoding"), ",") {
+		if v = strings.TrimSpace(v); v != "" && v != "aws-chunked" {
+			encodings = append(encodings, v)
+		}
+	}
+	return strings.Join(encodings, ",")
+}
+
+func isHexSHA256(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// compositeChecksum is the checksum of a multipart object computed from the checksums of its parts,
+// in the form <checksum of the concatenated checksums>-<number of parts>.
+func compositeChecksum(algorithm string, partChecksums []string) (string, error) {
+	h := checksumAlgorithms[algorithm]()
+	for _, c := range partChecksums {
+		b, err := base64.StdEncoding.DecodeString(c)
+		if err != nil {
+			return "", err
+		}
+		h.Write(b)
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)) + "-" + strconv.Itoa(len(partChecksums)), nil
+}
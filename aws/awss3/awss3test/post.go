@@ -0,0 +1,257 @@
+package awss3test
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPostFormMemory is the size of a POST form kept in memory; larger files are buffered on disk.
+const maxPostFormMemory = 32 << 20
+
+func policyViolation(format string, args ...any) error {
+	return &s3Error{
+		status:  http.StatusForbidden,
+		code:    "AccessDenied",
+		message: "Invalid according to Policy: " + fmt.Sprintf(format, args...),
+	}
+}
+
+type postPolicy struct {
+	Expiration string            `json:"expiration"`
+	Conditions []json.RawMessage `json:"conditions"`
+}
+
+type postResponse struct {
+	XMLName  xml.Name `xml:"PostResponse"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
+
+// postObject serves an upload from a browser form, authenticated by the signature of its POST policy.
+// ref: https://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-HTTPPOSTConstructPolicy.html
+func (s *Server) postObject(w http.ResponseWriter, r *http.Request, bucketName string) error {
+	if err := r.ParseMultipartForm(maxPostFormMemory); err != nil {
+		return &s3Error{status: http.StatusBadRequest, code: "MalformedPOSTRequest", message: err.Error()}
+	}
+	defer func() { _ = r.MultipartForm.RemoveAll() }()
+	files := r.MultipartForm.File["file"]
+	if len(files) != 1 {
+		return invalidArgument("POST requires exactly one file upload per request")
+	}
+	fields := make(map[string]string)
+	for name, values := range r.MultipartForm.Value {
+		if len(values) > 0 {
+			fields[strings.ToLower(name)] = values[0]
+		}
+	}
+	file, err := files[0].Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return errIncompleteBody
+	}
+	fields["key"] = strings.ReplaceAll(fields["key"], "${filename}", files[0].Filename)
+	if fields["key"] == "" {
+		return invalidArgument("bucket POST must contain a field named 'key'")
+	}
+	if err := s.verifyPostPolicy(bucketName, fields, int64(len(data))); err != nil {
+		return err
+	}
+
+	header := make(http.Header)
+	for name, v := range fields {
+		header.Set(name, v)
+	}
+	obj, err := newObjectFromHeaders(fields["key"], header)
+	if err != nil {
+		return err
+	}
+	if v := fields["tagging"]; v != "" {
+		var req tagging
+		if err := xml.Unmarshal([]byte(v), &req); err != nil {
+			return errMalformedXML
+		}
+		for _, t := range req.TagSet {
+			obj.tags[t.Key] = t.Value
+		}
+		if err := validateTags(obj.tags); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	b, err := s.bucket(bucketName)
+	if err == nil {
+		obj.setContent(data, map[string]string{}, s.now())
+		b.objects[obj.key] = obj
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	location := s.URL() + "/" + bucketName + "/" + url.PathEscape(obj.key)
+	w.Header().Set("ETag", obj.etag)
+	w.Header().Set("Location", location)
+	if redirect := fields["success_action_redirect"]; redirect != "" {
+		if u, err := url.Parse(redirect); err == nil {
+			query := u.Query()
+			query.Set("bucket", bucketName)
+			query.Set("key", obj.key)
+			query.Set("etag", obj.etag)
+			u.RawQuery = query.Encode()
+			http.Redirect(w, r, u.String(), http.StatusSeeOther)
+			return nil
+		}
+	}
+	switch fields["success_action_status"] {
+	case "200":
+		w.WriteHeader(http.StatusOK)
+	case "201":
+		writeXML(w, http.StatusCreated, postResponse{
+			Location: location, Bucket: bucketName, Key: obj.key, ETag: obj.etag,
+		})
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+	return nil
+}
+
+// verifyPostPolicy verifies the signature of the policy of a POST form, its expiration and its conditions.
+// Every field of the form must be allowed by a condition.
+func (s *Server) verifyPostPolicy(bucketName string, fields map[string]string, size int64) error {
+	encodedPolicy := fields["policy"]
+	if encodedPolicy == "" {
+		return errAccessDenied
+	}
+	if fields["x-amz-algorithm"] != signingAlgorithm {
+		return invalidArgument("unsupported x-amz-algorithm")
+	}
+	scope, ok := parseCredential(fields["x-amz-credential"])
+	if !ok {
+		return invalidArgument("malformed x-amz-credential")
+	}
+	if scope.accessKey != s.accessKey {
+		return errAccessKey
+	}
+	expected := hex.EncodeToString(hmacSHA256(s.signingKey(scope), encodedPolicy))
+	if !hmac.Equal([]byte(expected), []byte(fields["x-amz-signature"])) {
+		return errSignature
+	}
+
+	b, err := base64.StdEncoding.DecodeString(encodedPolicy)
+	if err != nil {
+		return invalidArgument("invalid policy document encoding")
+	}
+	var policy postPolicy
+	if err := json.Unmarshal(b, &policy); err != nil {
+		return invalidArgument("invalid policy document")
+	}
+	expiration, err := time.Parse(time.RFC3339, policy.Expiration)
+	if err != nil {
+		return invalidArgument("invalid policy expiration")
+	}
+	if s.now().After(expiration) {
+		return policyViolation("Policy expired.")
+	}
+
+	values := map[string]string{"bucket": bucketName}
+	for name, v := range fields {
+		values[name] = v
+	}
+	covered := map[string]bool{"policy": true, "x-amz-signature": true, "file": true}
+	for _, raw := range policy.Conditions {
+		if err := checkPostCondition(raw, values, covered, size); err != nil {
+			return err
+		}
+	}
+	for name := range fields {
+		if !covered[name] && !strings.HasPrefix(name, "x-ignore-") {
+			return policyViolation("Extra input fields: %s", name)
+		}
+	}
+	return nil
+}
+
+// checkPostCondition checks one condition of a POST policy: {"field": "value"}, ["eq", "$field", "value"],
+// ["starts-with", "$field", "prefix"] or ["content-length-range", min, max].
+func checkPostCondition(raw json.RawMessage, values map[string]string, covered map[string]bool, size int64) error {
+	var exact map[string]string
+	if err := json.Unmarshal(raw, &exact); err == nil {
+		for name, want := range exact {
+			name = strings.ToLower(name)
+			covered[name] = true
+			if values[name] != want {
+				return policyViolation("Policy Condition failed: [\"eq\", \"$%s\", %q]", name, want)
+			}
+		}
+		return nil
+	}
+	var condition []any
+	if err := json.Unmarshal(raw, &condition); err != nil || len(condition) != 3 {
+		return invalidArgument("invalid policy condition %s", raw)
+	}
+	operator, _ := condition[0].(string)
+	if strings.ToLower(operator) == "content-length-range" {
+		low, err1 := policyInt(condition[1])
+		high, err2 := policyInt(condition[2])
+		if err1 != nil || err2 != nil {
+			return invalidArgument("invalid content-length-range %s", raw)
+		}
+		if size > high {
+			return &s3Error{
+				status: http.StatusBadRequest, code: "EntityTooLarge",
+				message: "Your proposed upload exceeds the maximum allowed size",
+			}
+		}
+		if size < low {
+			return &s3Error{
+				status: http.StatusBadRequest, code: "EntityTooSmall",
+				message: "Your proposed upload is smaller than the minimum allowed size",
+			}
+		}
+		return nil
+	}
+	field, _ := condition[1].(string)
+	want, _ := condition[2].(string)
+	name := strings.ToLower(strings.TrimPrefix(field, "$"))
+	covered[name] = true
+	switch strings.ToLower(operator) {
+	case "eq":
+		if values[name] != want {
+			return policyViolation("Policy Condition failed: [\"eq\", %q, %q]", field, want)
+		}
+	case "starts-with":
+		if !strings.HasPrefix(values[name], want) {
+			return policyViolation("Policy Condition failed: [\"starts-with\", %q, %q]", field, want)
+		}
+	default:
+		return invalidArgument("invalid policy condition %s", raw)
+	}
+	return nil
+}
+
+func policyInt(v any) (int64, error) {
+	switch v := v.(type) {
+	case float64:
+		return int64(v), nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	}
+	return 0, fmt.Errorf("invalid number %v", v)
+}
@@ -0,0 +1,436 @@
+// Package awss3test provides an in-process fake S3 server for unit tests of code that uses awss3,
+// so they run without Docker or MinIO.
+//
+// The server implements the subset of S3 used by awss3 with path-style requests: buckets, put, get (with ranges,
+// part numbers and conditions), head, list, delete, copy, tagging, multipart uploads and presigned URLs and POST
+// policies. Every request is authenticated with Signature Version 4, so presigned requests are verified as S3 would.
+// Lifecycle and CORS configurations are stored as they are sent, without being applied.
+// Other operations, such as S3 Select and versioning, answer NotImplemented.
+//
+//	srv := awss3test.NewServer(t, awss3test.WithBuckets("test"))
+//	ctx := srv.Context(context.Background())
+//	client, err := awss3.NewClient(ctx, awsconfig.RegionTokyo)
+package awss3test
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/88labs/go-utils/aws/awss3"
+	"github.com/88labs/go-utils/aws/ctxawslocal"
+)
+
+const (
+	// DefaultAccessKey and DefaultSecretAccessKey are the credentials accepted by default.
+	// They are the defaults of ctxawslocal, so a context with only the endpoint of the server is accepted.
+	DefaultAccessKey       = "test"
+	DefaultSecretAccessKey = "test"
+
+	s3Namespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+)
+
+// Server is a fake S3 server. It keeps the objects in memory and is safe for concurrent use.
+type Server struct {
+	server          *httptest.Server
+	accessKey       string
+	secretAccessKey string
+	now             func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	requestID atomic.Int64
+
+	initialBuckets []string
+}
+
+// Option configures a Server created with NewServer.
+type Option interface {
+	apply(*Server)
+}
+
+type optionFunc func(*Server)
+
+func (f optionFunc) apply(s *Server) {
+	f(s)
+}
+
+// WithBuckets creates buckets when the server starts.
+func WithBuckets(names ...string) Option {
+	return optionFunc(func(s *Server) {
+		s.initialBuckets = append(s.initialBuckets, names...)
+	})
+}
+
+// WithCredentials sets the credentials the server accepts. The default is DefaultAccessKey and DefaultSecretAccessKey.
+func WithCredentials(accessKey, secretAccessKey string) Option {
+	return optionFunc(func(s *Server) {
+		s.accessKey = accessKey
+		s.secretAccessKey = secretAccessKey
+	})
+}
+
+// WithClock sets the clock used for the modification times of objects and the expiration of presigned requests.
+func WithClock(now func() time.Time) Option {
+	return optionFunc(func(s *Server) {
+		if now != nil {
+			s.now = now
+		}
+	})
+}
+
+// NewServer starts a fake S3 server, which is closed when tb and its subtests complete.
+func NewServer(tb testing.TB, opts ...Option) *Server {
+	tb.Helper()
+	s := &Server{
+		accessKey:       DefaultAccessKey,
+		secretAccessKey: DefaultSecretAccessKey,
+		now:             time.Now,
+		buckets:         make(map[string]*bucket),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt.apply(s)
+		}
+	}
+	for _, name := range s.initialBuckets {
+		s.CreateBucket(name)
+	}
+	s.server = httptest.NewServer(s)
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// URL returns the endpoint of the server, such as http://127.0.0.1:12345.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Context returns ctx with the endpoint and the credentials of the server set by ctxawslocal,
+// so that awss3.NewClient and the package-level functions of awss3 send their requests to the server.
+//
+// The package-level functions share a client created with the first context they are called with;
+// use awss3.NewClient for tests that run several servers.
+func (s *Server) Context(ctx context.Context) context.Context {
+	return ctxawslocal.WithContext(ctx,
+		ctxawslocal.WithS3Endpoint(s.URL()),
+		ctxawslocal.WithAccessKey(s.accessKey),
+		ctxawslocal.WithSecretAccessKey(s.secretAccessKey),
+	)
+}
+
+// LocalProfile returns the endpoint and the credentials of the server.
+func (s *Server) LocalProfile() awss3.LocalProfile {
+	return awss3.LocalProfile{
+		Endpoint:        s.URL(),
+		AccessKey:       s.accessKey,
+		SecretAccessKey: s.secretAccessKey,
+	}
+}
+
+// ClientOption returns an option of awss3.NewClient that sends the requests of the client to the server,
+// whatever the context passed to NewClient.
+func (s *Server) ClientOption() awss3.ClientOption {
+	return awss3.WithLocalProfile(s.LocalProfile())
+}
+
+// CreateBucket creates a bucket. Creating a bucket that exists does nothing.
+func (s *Server) CreateBucket(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.buckets[name]; !ok {
+		s.buckets[name] = newBucket(name, s.now())
+	}
+}
+
+// Object returns the content of an object, and false if the object does not exist.
+func (s *Server) Object(bucketName, key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return nil, false
+	}
+	obj, ok := b.objects[key]
+	if !ok {
+		return nil, false
+	}
+	return slices.Clone(obj.data), true
+}
+
+// Keys returns the keys of the objects of a bucket in lexicographical order.
+func (s *Server) Keys(bucketName string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return nil
+	}
+	return b.sortedKeys()
+}
+
+// ServeHTTP serves the S3 API with path-style requests.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("x-amz-request-id", strconv.FormatInt(s.requestID.Add(1), 10))
+	if err := s.serve(w, r); err != nil {
+		writeError(w, r, err)
+	}
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) error {
+	bucketName, key, err := splitPath(r)
+	if err != nil {
+		return err
+	}
+	if r.Method == http.MethodPost && key == "" && strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		// browser uploads are authenticated by the signature of their policy
+		return s.postObject(w, r, bucketName)
+	}
+	if err := s.authenticate(r); err != nil {
+		return err
+	}
+	hoistQueryHeaders(r)
+	switch {
+	case bucketName == "":
+		if r.Method == http.MethodGet {
+			return s.listBuckets(w)
+		}
+		return errMethodNotAllowed
+	case key == "":
+		return s.serveBucket(w, r, bucketName)
+	default:
+		return s.serveObject(w, r, bucketName, key)
+	}
+}
+
+func (s *Server) serveBucket(w http.ResponseWriter, r *http.Request, bucketName string) error {
+	query := r.URL.Query()
+	if subresource := unsupportedSubresource(query, "delete", "list-type", "prefix", "delimiter", "max-keys", "marker",
+		"continuation-token", "start-after", "encoding-type", "fetch-owner", "lifecycle", "cors",
+		"x-id"); subresource != "" {
+		return notImplemented(subresource)
+	}
+	for name := range errNoSuchConfiguration {
+		if query.Has(name) {
+			return s.bucketConfiguration(w, r, bucketName, name)
+		}
+	}
+	switch r.Method {
+	case http.MethodPut:
+		return s.createBucket(w, bucketName)
+	case http.MethodHead:
+		_, err := s.bucket(bucketName)
+		return err
+	case http.MethodDelete:
+		return s.deleteBucket(w, bucketName)
+	case http.MethodGet:
+		if query.Get("list-type") == "2" {
+			return s.listObjectsV2(w, r, bucketName)
+		}
+		return s.listObjects(w, r, bucketName)
+	case http.MethodPost:
+		if query.Has("delete") {
+			return s.deleteObjects(w, r, bucketName)
+		}
+	}
+	return errMethodNotAllowed
+}
+
+func (s *Server) serveObject(w http.ResponseWriter, r *http.Request, bucketName, key string) error {
+	query := r.URL.Query()
+	if subresource := unsupportedSubresource(query, "tagging", "uploads", "uploadId", "partNumber", "max-parts",
+		"part-number-marker", "response-content-type", "response-content-disposition", "response-cache-control",
+		"response-content-encoding", "response-content-language", "response-expires", "x-id"); subresource != "" {
+		return notImplemented(subresource)
+	}
+	uploadID := query.Get("uploadId")
+	switch r.Method {
+	case http.MethodPut:
+		switch {
+		case query.Has("tagging"):
+			return s.putObjectTagging(w, r, bucketName, key)
+		case uploadID != "" && r.Header.Get("x-amz-copy-source") != "":
+			return s.uploadPartCopy(w, r, bucketName, key, uploadID)
+		case uploadID != "":
+			return s.uploadPart(w, r, bucketName, key, uploadID)
+		case r.Header.Get("x-amz-copy-source") != "":
+			return s.copyObject(w, r, bucketName, key)
+		default:
+			return s.putObject(w, r, bucketName, key)
+		}
+	case http.MethodGet, http.MethodHead:
+		switch {
+		case query.Has("tagging") && r.Method == http.MethodGet:
+			return s.getObjectTagging(w, bucketName, key)
+		case uploadID != "" && r.Method == http.MethodGet:
+			return s.listParts(w, r, bucketName, key, uploadID)
+		default:
+			return s.getObject(w, r, bucketName, key)
+		}
+	case http.MethodDelete:
+		switch {
+		case query.Has("tagging"):
+			return s.deleteObjectTagging(w, bucketName, key)
+		case uploadID != "":
+			return s.abortMultipartUpload(w, bucketName, key, uploadID)
+		default:
+			return s.deleteObject(w, bucketName, key)
+		}
+	case http.MethodPost:
+		switch {
+		case query.Has("uploads"):
+			return s.createMultipartUpload(w, r, bucketName, key)
+		case uploadID != "":
+			return s.completeMultipartUpload(w, r, bucketName, key, uploadID)
+		}
+	}
+	return errMethodNotAllowed
+}
+
+// splitPath returns the bucket and the key of a path-style request.
+// The raw path is unescaped once, so keys keep consecutive and trailing slashes.
+func splitPath(r *http.Request) (bucketName, key string, err error) {
+	rawPath, _, _ := strings.Cut(r.RequestURI, "?")
+	path, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return "", "", &s3Error{status: http.StatusBadRequest, code: "InvalidURI", message: err.Error()}
+	}
+	path = strings.TrimPrefix(path, "/")
+	bucketName, key, _ = strings.Cut(path, "/")
+	return bucketName, key, nil
+}
+
+// presignParams are the query parameters of a presigned URL that sign it rather than set a header.
+var presignParams = []string{
+	"x-amz-algorithm", "x-amz-credential", "x-amz-date", "x-amz-expires", "x-amz-signedheaders", "x-amz-signature",
+}
+
+// hoistQueryHeaders sets the x-amz-* headers that a presigned URL carries in its query, as S3 does.
+func hoistQueryHeaders(r *http.Request) {
+	for name, values := range r.URL.Query() {
+		lower := strings.ToLower(name)
+		if !strings.HasPrefix(lower, "x-amz-") || slices.Contains(presignParams, lower) || len(values) == 0 {
+			continue
+		}
+		if r.Header.Get(name) == "" {
+			r.Header.Set(name, values[0])
+		}
+	}
+}
+
+// unsupportedSubresource returns a query parameter that is neither supported nor an x-amz-* parameter.
+func unsupportedSubresource(query map[string][]string, supported ...string) string {
+	for _, name := range slices.Sorted(maps.Keys(query)) {
+		if !slices.Contains(supported, name) && !strings.HasPrefix(strings.ToLower(name), "x-amz-") {
+			return name
+		}
+	}
+	return ""
+}
+
+// s3Error is an error response of S3.
+type s3Error struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *s3Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.code, e.message)
+}
+
+var (
+	errNoSuchBucket = &s3Error{
+		status: http.StatusNotFound, code: "NoSuchBucket", message: "The specified bucket does not exist",
+	}
+	errNoSuchKey = &s3Error{
+		status: http.StatusNotFound, code: "NoSuchKey", message: "The specified key does not exist.",
+	}
+	errNoSuchUpload = &s3Error{
+		status: http.StatusNotFound, code: "NoSuchUpload", message: "The specified multipart upload does not exist.",
+	}
+	errPreconditionFailed = &s3Error{
+		status:  http.StatusPreconditionFailed,
+		code:    "PreconditionFailed",
+		message: "At least one of the pre-conditions you specified did not hold",
+	}
+	errNotModified      = &s3Error{status: http.StatusNotModified, code: "NotModified", message: "Not Modified"}
+	errInvalidRange     = &s3Error{status: http.StatusRequestedRangeNotSatisfiable, code: "InvalidRange", message: "The requested range is not satisfiable"}
+	errMalformedXML     = &s3Error{status: http.StatusBadRequest, code: "MalformedXML", message: "The XML you provided was not well-formed or did not validate against our published schema."}
+	errMethodNotAllowed = &s3Error{
+		status: http.StatusMethodNotAllowed, code: "MethodNotAllowed",
+		message: "The specified method is not allowed against this resource.",
+	}
+)
+
+func notImplemented(subresource string) error {
+	return &s3Error{
+		status:  http.StatusNotImplemented,
+		code:    "NotImplemented",
+		message: fmt.Sprintf("awss3test does not implement %q", subresource),
+	}
+}
+
+func invalidArgument(format string, args ...any) error {
+	return &s3Error{status: http.StatusBadRequest, code: "InvalidArgument", message: fmt.Sprintf(format, args...)}
+}
+
+type errorResponse struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string   `xml:"Code"`
+	Message   string   `xml:"Message"`
+	Resource  string   `xml:"Resource"`
+	RequestID string   `xml:"RequestId"`
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var s3Err *s3Error
+	if !errors.As(err, &s3Err) {
+		s3Err = &s3Error{status: http.StatusInternalServerError, code: "InternalError", message: err.Error()}
+	}
+	if r.Method == http.MethodHead || s3Err.status == http.StatusNotModified {
+		w.WriteHeader(s3Err.status)
+		return
+	}
+	writeXML(w, s3Err.status, errorResponse{
+		Code:      s3Err.code,
+		Message:   s3Err.message,
+		Resource:  r.URL.Path,
+		RequestID: w.Header().Get("x-amz-request-id"),
+	})
+}
+
+func writeXML(w http.ResponseWriter, status int, v any) {
+	b, err := xml.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Length", strconv.Itoa(len(xml.Header)+len(b)))
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(b)
+}
+
+// timestamp formats a time as in the XML responses of S3.
+func timestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
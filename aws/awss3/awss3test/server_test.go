@@ -0,0 +1,334 @@
+package awss3test_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	"gotest.tools/v3/assert"
+
+	"github.com/88labs/go-utils/aws/awsconfig"
+	"github.com/88labs/go-utils/aws/awss3"
+	"github.com/88labs/go-utils/aws/awss3/awss3test"
+	"github.com/88labs/go-utils/aws/awss3/options/s3list"
+	"github.com/88labs/go-utils/aws/awss3/options/s3multipart"
+	"github.com/88labs/go-utils/aws/awss3/options/s3presigned"
+	"github.com/88labs/go-utils/aws/awss3/options/s3presignedpost"
+	"github.com/88labs/go-utils/aws/awss3/options/s3upload"
+)
+
+const (
+	TestRegion = awsconfig.RegionTokyo
+	TestBucket = "test"
+)
+
+func newClient(t *testing.T, srv *awss3test.Server) *awss3.Client {
+	t.Helper()
+	client, err := awss3.NewClient(context.Background(), TestRegion, srv.ClientOption())
+	assert.NilError(t, err)
+	return client
+}
+
+func errorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return ""
+}
+
+func TestServer_Objects(t *testing.T) {
+	t.Parallel()
+	srv := awss3test.NewServer(t, awss3test.WithBuckets(TestBucket))
+	client := newClient(t, srv)
+	ctx := context.Background()
+
+	t.Run("PutObject and HeadObject", func(t *testing.T) {
+		t.Parallel()
+		_, err := client.PutObject(ctx, TestBucket, "put/a.txt", strings.NewReader("hello"),
+			s3upload.WithContentType("text/plain"),
+			s3upload.WithMetadata(map[string]string{"owner": "api"}),
+		)
+		assert.NilError(t, err)
+
+		head, err := client.HeadObject(ctx, TestBucket, "put/a.txt")
+		assert.NilError(t, err)
+		assert.Equal(t, int64(5), aws.ToInt64(head.ContentLength))
+		assert.Equal(t, "text/plain", aws.ToString(head.ContentType))
+		assert.Equal(t, "api", head.Metadata["owner"])
+		data, ok := srv.Object(TestBucket, "put/a.txt")
+		assert.Assert(t, ok)
+		assert.Equal(t, "hello", string(data))
+	})
+	t.Run("GetObjectRange", func(t *testing.T) {
+		t.Parallel()
+		_, err := client.PutObject(ctx, TestBucket, "range.txt", strings.NewReader("0123456789"))
+		assert.NilError(t, err)
+
+		res, err := client.GetObjectRange(ctx, TestBucket, "range.txt", 2, 3)
+		assert.NilError(t, err)
+		defer res.Body.Close()
+		b, err := io.ReadAll(res.Body)
+		assert.NilError(t, err)
+		assert.Equal(t, "234", string(b))
+		assert.Equal(t, "bytes 2-4/10", aws.ToString(res.ContentRange))
+	})
+	t.Run("HeadObject not found", func(t *testing.T) {
+		t.Parallel()
+		_, err := client.HeadObject(ctx, TestBucket, "not-found.txt")
+		assert.ErrorIs(t, err, awss3.ErrNotFound)
+	})
+	t.Run("Copy and DeleteObject", func(t *testing.T) {
+		t.Parallel()
+		_, err := client.PutObject(ctx, TestBucket, "copy/src.txt", strings.NewReader("copied"))
+		assert.NilError(t, err)
+		assert.NilError(t, client.Copy(ctx, TestBucket, "copy/src.txt", "copy/dst.txt"))
+		data, ok := srv.Object(TestBucket, "copy/dst.txt")
+		assert.Assert(t, ok)
+		assert.Equal(t, "copied", string(data))
+
+		_, err = client.DeleteObject(ctx, TestBucket, "copy/dst.txt")
+		assert.NilError(t, err)
+		_, ok = srv.Object(TestBucket, "copy/dst.txt")
+		assert.Assert(t, !ok)
+	})
+	t.Run("Tags", func(t *testing.T) {
+		t.Parallel()
+		_, err := client.PutObject(ctx, TestBucket, "tags.txt", strings.NewReader("tagged"))
+		assert.NilError(t, err)
+		assert.NilError(t, client.PutObjectTags(ctx, TestBucket, "tags.txt", map[string]string{"env": "test"}))
+		tags, err := client.GetObjectTags(ctx, TestBucket, "tags.txt")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, map[string]string{"env": "test"}, tags)
+		assert.NilError(t, client.DeleteObjectTags(ctx, TestBucket, "tags.txt"))
+		tags, err = client.GetObjectTags(ctx, TestBucket, "tags.txt")
+		assert.NilError(t, err)
+		assert.Equal(t, 0, len(tags))
+	})
+	t.Run("NoSuchBucket", func(t *testing.T) {
+		t.Parallel()
+		_, err := client.PutObject(ctx, "not-found", "a.txt", strings.NewReader("a"))
+		assert.Equal(t, "NoSuchBucket", errorCode(err))
+	})
+}
+
+func TestServer_ListObjects(t *testing.T) {
+	t.Parallel()
+	srv := awss3test.NewServer(t, awss3test.WithBuckets(TestBucket))
+	client := newClient(t, srv)
+	ctx := context.Background()
+	keys := []string{"dir/a.txt", "dir/b.txt", "dir/sub/c.txt", "dir/sub/d.txt", "other.txt"}
+	for _, key := range keys {
+		_, err := client.PutObject(ctx, TestBucket, awss3.Key(key), strings.NewReader(key))
+		assert.NilError(t, err)
+	}
+	assert.DeepEqual(t, keys, srv.Keys(TestBucket))
+
+	t.Run("pages", func(t *testing.T) {
+		t.Parallel()
+		var pages int
+		objects, err := client.ListObjects(ctx, TestBucket,
+			s3list.WithPrefix("dir/"),
+			s3list.WithMaxKeys(1),
+			s3list.WithPageCallback(func(*s3.ListObjectsV2Output) error {
+				pages++
+				return nil
+			}),
+		)
+		assert.NilError(t, err)
+		assert.Equal(t, 4, len(objects))
+		assert.Equal(t, 4, pages)
+	})
+	t.Run("delimiter", func(t *testing.T) {
+		t.Parallel()
+		res, err := client.S3Client().ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket:    aws.String(TestBucket),
+			Prefix:    aws.String("dir/"),
+			Delimiter: aws.String("/"),
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, 2, len(res.Contents))
+		assert.Equal(t, 1, len(res.CommonPrefixes))
+		assert.Equal(t, "dir/sub/", aws.ToString(res.CommonPrefixes[0].Prefix))
+	})
+}
+
+func TestServer_MultipartUpload(t *testing.T) {
+	t.Parallel()
+	srv := awss3test.NewServer(t, awss3test.WithBuckets(TestBucket))
+	client := newClient(t, srv)
+	ctx := context.Background()
+	content := bytes.Repeat([]byte("0123456789abcdef"), 11<<16) // 11MiB, three parts of 5MiB
+
+	u, err := client.NewMultipartUpload(ctx, TestBucket, "multipart.bin", s3multipart.WithPartSize(5<<20))
+	assert.NilError(t, err)
+	_, err = u.Write(content)
+	assert.NilError(t, err)
+	assert.NilError(t, u.Close())
+
+	data, ok := srv.Object(TestBucket, "multipart.bin")
+	assert.Assert(t, ok)
+	assert.Assert(t, bytes.Equal(content, data))
+	head, err := client.HeadObject(ctx, TestBucket, "multipart.bin")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasSuffix(aws.ToString(head.ETag), `-3"`))
+
+	t.Run("DownloadFiles", func(t *testing.T) {
+		dir := t.TempDir()
+		paths, err := client.DownloadFiles(ctx, TestBucket, awss3.NewKeys("multipart.bin"), dir)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, []string{filepath.Join(dir, "multipart.bin")}, paths)
+		b, err := os.ReadFile(paths[0])
+		assert.NilError(t, err)
+		assert.Assert(t, bytes.Equal(content, b))
+	})
+}
+
+func TestServer_Presign(t *testing.T) {
+	t.Parallel()
+	var now atomic.Pointer[time.Time]
+	start := time.Now()
+	now.Store(&start)
+	srv := awss3test.NewServer(t,
+		awss3test.WithBuckets(TestBucket),
+		awss3test.WithClock(func() time.Time { return *now.Load() }),
+	)
+	client := newClient(t, srv)
+	ctx := context.Background()
+	_, err := client.PutObject(ctx, TestBucket, "presign.txt", strings.NewReader("presigned"))
+	assert.NilError(t, err)
+
+	get := func(url string) (int, string) {
+		resp, err := http.Get(url)
+		assert.NilError(t, err)
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		assert.NilError(t, err)
+		return resp.StatusCode, string(b)
+	}
+
+	url, err := client.Presign(ctx, TestBucket, "presign.txt", s3presigned.WithPresignExpires(time.Minute))
+	assert.NilError(t, err)
+	status, body := get(url)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "presigned", body)
+
+	status, _ = get(strings.Replace(url, "presign.txt", "other.txt", 1))
+	assert.Equal(t, http.StatusForbidden, status)
+
+	later := start.Add(2 * time.Minute)
+	now.Store(&later)
+	status, body = get(url)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Assert(t, strings.Contains(body, "Request has expired"))
+}
+
+func TestServer_PresignPost(t *testing.T) {
+	t.Parallel()
+	srv := awss3test.NewServer(t, awss3test.WithBuckets(TestBucket))
+	client := newClient(t, srv)
+	ctx := context.Background()
+
+	post := func(t *testing.T, post *awss3.PresignedPost, extra map[string]string, content []byte) int {
+		t.Helper()
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		for k, v := range post.Fields {
+			assert.NilError(t, w.WriteField(k, v))
+		}
+		for k, v := range extra {
+			assert.NilError(t, w.WriteField(k, v))
+		}
+		fw, err := w.CreateFormFile("file", "upload.txt")
+		assert.NilError(t, err)
+		_, err = fw.Write(content)
+		assert.NilError(t, err)
+		assert.NilError(t, w.Close())
+		resp, err := http.Post(post.URL, w.FormDataContentType(), &body)
+		assert.NilError(t, err)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	t.Run("upload", func(t *testing.T) {
+		t.Parallel()
+		p, err := client.PresignPostObject(ctx, TestBucket, "post/a.txt",
+			s3presignedpost.WithContentLengthRange(1, 10),
+			s3presignedpost.WithSuccessActionStatus(http.StatusCreated),
+		)
+		assert.NilError(t, err)
+		assert.Equal(t, http.StatusCreated, post(t, p, nil, []byte("posted")))
+		data, ok := srv.Object(TestBucket, "post/a.txt")
+		assert.Assert(t, ok)
+		assert.Equal(t, "posted", string(data))
+	})
+	t.Run("too large", func(t *testing.T) {
+		t.Parallel()
+		p, err := client.PresignPostObject(ctx, TestBucket, "post/b.txt", s3presignedpost.WithContentLengthRange(1, 3))
+		assert.NilError(t, err)
+		assert.Equal(t, http.StatusBadRequest, post(t, p, nil, []byte("too large")))
+	})
+	t.Run("field not in policy", func(t *testing.T) {
+		t.Parallel()
+		p, err := client.PresignPostObject(ctx, TestBucket, "post/c.txt")
+		assert.NilError(t, err)
+		assert.Equal(t, http.StatusForbidden, post(t, p, map[string]string{"Content-Type": "text/html"}, []byte("c")))
+	})
+}
+
+func TestServer_Authentication(t *testing.T) {
+	t.Parallel()
+	srv := awss3test.NewServer(t,
+		awss3test.WithBuckets(TestBucket),
+		awss3test.WithCredentials("DUMMYACCESSKEYEXAMPLE", "DUMMYSECRETKEYEXAMPLE"),
+	)
+	ctx := context.Background()
+
+	t.Run("Context", func(t *testing.T) {
+		t.Parallel()
+		ctx := srv.Context(ctx)
+		client, err := awss3.NewClient(ctx, TestRegion)
+		assert.NilError(t, err)
+		_, err = client.PutObject(ctx, TestBucket, "auth.txt", strings.NewReader("auth"))
+		assert.NilError(t, err)
+	})
+	t.Run("wrong secret access key", func(t *testing.T) {
+		t.Parallel()
+		profile := srv.LocalProfile()
+		profile.SecretAccessKey = "WRONG"
+		client, err := awss3.NewClient(ctx, TestRegion, awss3.WithLocalProfile(profile))
+		assert.NilError(t, err)
+		_, err = client.HeadObject(ctx, TestBucket, "auth.txt")
+		assert.ErrorContains(t, err, "StatusCode: 403")
+		_, err = client.PutObject(ctx, TestBucket, "auth.txt", strings.NewReader("auth"))
+		assert.Equal(t, "SignatureDoesNotMatch", errorCode(err))
+	})
+	t.Run("unknown access key", func(t *testing.T) {
+		t.Parallel()
+		profile := srv.LocalProfile()
+		profile.AccessKey = "UNKNOWN"
+		client, err := awss3.NewClient(ctx, TestRegion, awss3.WithLocalProfile(profile))
+		assert.NilError(t, err)
+		_, err = client.PutObject(ctx, TestBucket, "auth.txt", strings.NewReader("auth"))
+		assert.Equal(t, "InvalidAccessKeyId", errorCode(err))
+	})
+	t.Run("anonymous", func(t *testing.T) {
+		t.Parallel()
+		resp, err := http.Get(srv.URL() + "/" + TestBucket + "/auth.txt")
+		assert.NilError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
@@ -0,0 +1,179 @@
+package awss3
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// GetLifecycleRules returns the lifecycle rules of a bucket, or no rules when the bucket has no lifecycle configuration.
+// LifecycleRule covers the prefix and tag filters and the day-based actions; other settings of a rule,
+// such as object size filters or expiration dates, are not returned.
+func (c *Client) GetLifecycleRules(ctx context.Context, bucketName BucketName) (rules []LifecycleRule, err error) {
+	done := c.logOperation(ctx, "GetLifecycleRules", slog.String("bucket", bucketName.String()))
+	defer func() {
+		done(err, slog.Int("rule_count", len(rules)))
+	}()
+
+	config, err := c.getLifecycleConfiguration(ctx, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	rules = make([]LifecycleRule, len(config.rules))
+	for i, rule := range config.rules {
+		rules[i] = newLifecycleRule(rule)
+	}
+	return rules, nil
+}
+
+// PutLifecycleRule adds rule to the lifecycle configuration of a bucket, or replaces the rule with the same ID.
+// Other rules and the TransitionDefaultMinimumObjectSize of the bucket are kept as they are, so services can
+// declare the rules of their own prefixes idempotently.
+//
+// The configuration is read and written back as a whole, and S3 does not support conditional writes of it:
+// concurrent updates of the same bucket may overwrite each other.
+func (c *Client) PutLifecycleRule(ctx context.Context, bucketName BucketName, rule LifecycleRule) (err error) {
+	done := c.logOperation(ctx, "PutLifecycleRule",
+		slog.String("bucket", bucketName.String()),
+		slog.String("rule_id", rule.ID),
+	)
+	defer func() {
+		done(err)
+	}()
+
+	if rule.ID == "" {
+		return errors.New("awss3: lifecycle rule ID is required")
+	}
+	config, err := c.getLifecycleConfiguration(ctx, bucketName)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(config.rules, func(r types.LifecycleRule) bool { return aws.ToString(r.ID) == rule.ID })
+	if i < 0 {
+		config.rules = append(config.rules, rule.sdkRule())
+	} else {
+		config.rules[i] = rule.sdkRule()
+	}
+	return c.putLifecycleConfiguration(ctx, bucketName, config)
+}
+
+// DeleteLifecycleRule removes the rule with id from the lifecycle configuration of a bucket.
+// Other rules are kept, and the configuration is deleted when no rule is left.
+// Deleting a rule that does not exist is not an error.
+func (c *Client) DeleteLifecycleRule(ctx context.Context, bucketName BucketName, id string) (err error) {
+	done := c.logOperation(ctx, "DeleteLifecycleRule",
+		slog.String("bucket", bucketName.String()),
+		slog.String("rule_id", id),
+	)
+	defer func() {
+		done(err)
+	}()
+
+	config, err := c.getLifecycleConfiguration(ctx, bucketName)
+	if err != nil {
+		return err
+	}
+	n := len(config.rules)
+	config.rules = slices.DeleteFunc(config.rules, func(r types.LifecycleRule) bool { return aws.ToString(r.ID) == id })
+	if len(config.rules) == n {
+		return nil
+	}
+	return c.putLifecycleConfiguration(ctx, bucketName, config)
+}
+
+// lifecycleConfiguration is the lifecycle configuration of a bucket, read and written back as a whole
+// by PutLifecycleRule and DeleteLifecycleRule.
+type lifecycleConfiguration struct {
+	rules                              []types.LifecycleRule
+	transitionDefaultMinimumObjectSize types.TransitionDefaultMinimumObjectSize
+}
+
+func (c *Client) getLifecycleConfiguration(
+	ctx context.Context, bucketName BucketName,
+) (lifecycleConfiguration, error) {
+	res, err := c.client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: bucketName.AWSString(),
+	})
+	if err != nil {
+		if hasErrorCode(err, "NoSuchLifecycleConfiguration") {
+			return lifecycleConfiguration{}, nil
+		}
+		return lifecycleConfiguration{}, err
+	}
+	return lifecycleConfiguration{
+		rules:                              res.Rules,
+		transitionDefaultMinimumObjectSize: res.TransitionDefaultMinimumObjectSize,
+	}, nil
+}
+
+func (c *Client) putLifecycleConfiguration(
+	ctx context.Context, bucketName BucketName, config lifecycleConfiguration,
+) error {
+	if len(config.rules) == 0 {
+		_, err := c.client.DeleteBucketLifecycle(ctx, &s3.DeleteBucketLifecycleInput{
+			Bucket: bucketName.AWSString(),
+		})
+		return err
+	}
+	_, err := c.client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                             bucketName.AWSString(),
+		LifecycleConfiguration:             &types.BucketLifecycleConfiguration{Rules: config.rules},
+		TransitionDefaultMinimumObjectSize: config.transitionDefaultMinimumObjectSize,
+	})
+	return err
+}
+
+// GetCORSRules returns the CORS rules of a bucket, or no rules when the bucket has no CORS configuration.
+func (c *Client) GetCORSRules(ctx context.Context, bucketName BucketName) (rules []types.CORSRule, err error) {
+	done := c.logOperation(ctx, "GetCORSRules", slog.String("bucket", bucketName.String()))
+	defer func() {
+		done(err, slog.Int("rule_count", len(rules)))
+	}()
+
+	res, err := c.client.GetBucketCors(ctx, &s3.GetBucketCorsInput{
+		Bucket: bucketName.AWSString(),
+	})
+	if err != nil {
+		if hasErrorCode(err, "NoSuchCORSConfiguration") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return res.CORSRules, nil
+}
+
+// PutCORSRules replaces the CORS rules of a bucket with rules.
+// Passing no rules deletes the CORS configuration.
+func (c *Client) PutCORSRules(ctx context.Context, bucketName BucketName, rules []types.CORSRule) (err error) {
+	done := c.logOperation(ctx, "PutCORSRules",
+		slog.String("bucket", bucketName.String()),
+		slog.Int("rule_count", len(rules)),
+	)
+	defer func() {
+		done(err)
+	}()
+
+	if len(rules) == 0 {
+		_, err = c.client.DeleteBucketCors(ctx, &s3.DeleteBucketCorsInput{
+			Bucket: bucketName.AWSString(),
+		})
+		return err
+	}
+	_, err = c.client.PutBucketCors(ctx, &s3.PutBucketCorsInput{
+		Bucket:            bucketName.AWSString(),
+		CORSConfiguration: &types.CORSConfiguration{CORSRules: rules},
+	})
+	return err
+}
+
+// hasErrorCode reports whether err is an S3 error with code.
+func hasErrorCode(err error, code string) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == code
+}
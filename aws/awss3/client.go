@@ -1,57 +1,56 @@
 package awss3
 
 import (
-	"bytes"
 	"context"
-	"encoding/gob"
 	"fmt"
 	"log/slog"
-	"net"
-	"sync/atomic"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 
 	"github.com/88labs/go-utils/aws/awsconfig"
+	"github.com/88labs/go-utils/aws/awscredentials"
 	"github.com/88labs/go-utils/aws/awss3/options/global/s3dialer"
+	"github.com/88labs/go-utils/aws/awstransport"
 	"github.com/88labs/go-utils/aws/ctxawslocal"
+	"github.com/88labs/go-utils/aws/internal/awsmetrics"
 	"github.com/88labs/go-utils/aws/internal/awstrace"
 )
 
 var (
 	// GlobalDialer Global http dialer settings for awss3 library
+	// It is a fallback for the clients created without WithTransport, whose dialer settings take precedence.
 	GlobalDialer *s3dialer.ConfGlobalDialer
 	// GlobalLogger is used by package-level helpers such as PutObject and HeadObject.
 	// It is nil by default, which disables logging.
-	GlobalLogger   *slog.Logger
-	s3ClientAtomic atomic.Pointer[s3.Client]
+	GlobalLogger *slog.Logger
 )
 
 // Client is an S3 client that manages its own SDK client instance.
-// Unlike the package-level functions that use a singleton, each Client holds
+// Unlike the package-level functions that share the clients of DefaultRegistry, each Client holds
 // its own *s3.Client, enabling external lifecycle management.
 type Client struct {
-	client *s3.Client
-	logger *slog.Logger
+	client     *s3.Client
+	logger     *slog.Logger
+	retryer    *clientRetryer
+	encryption *clientEncryption
 }
 
 // NewClient creates a new Client for the given region.
 // Using ctxawslocal.WithContext, you can make requests for local mocks.
+// Use a Registry to share the clients of several regions and options.
 func NewClient(ctx context.Context, region awsconfig.Region, opts ...ClientOption) (*Client, error) {
-	cfg := defaultClientConfig()
-	for _, opt := range opts {
-		if opt != nil {
-			opt.apply(&cfg)
-		}
-	}
+	return newClientWithConfig(ctx, region, newClientConfig(opts...))
+}
+
+func newClientWithConfig(ctx context.Context, region awsconfig.Region, cfg clientConfig) (*Client, error) {
 	sdkClient, err := newS3Client(ctx, region, cfg)
 	if err != nil {
 		return nil, err
 	}
-	return &Client{client: sdkClient, logger: cfg.logger}, nil
+	return &Client{client: sdkClient, logger: cfg.logger, retryer: cfg.retryer, encryption: cfg.encryption}, nil
 }
 
 // S3Client returns the underlying *s3.Client for advanced usage.
@@ -59,82 +58,85 @@ func (c *Client) S3Client() *s3.Client {
 	return c.client
 }
 
-// GetClient gets the package-level S3 client for aws-sdk-go v2.
+// GetClient gets the package-level S3 client of the region for aws-sdk-go v2 from DefaultRegistry.
 // Using ctxawslocal.WithContext, you can make requests for local mocks
 //
-// Options are used only when the singleton is initialized. Initialize it with
-// WithTrace before calling package-level helpers when those helpers should be
-// traced.
+// Each region and endpoint has its own client. Without options, GetClient returns the client created first
+// for the region, so that the package-level helpers use the client initialized by GetClient: initialize it
+// with WithTrace before calling package-level helpers when those helpers should be traced.
+// With options, it returns the client of those options. GetClient returns ErrEncryptionNotSupported with
+// WithEncryption, since the *s3.Client does not encrypt; use NewClient or Registry.Client for client-side encryption.
 func GetClient(ctx context.Context, region awsconfig.Region, opts ...ClientOption) (*s3.Client, error) {
-	if v := s3ClientAtomic.Load(); v != nil {
-		return v, nil
+	if newClientConfig(opts...).keyProvider != nil {
+		return nil, ErrEncryptionNotSupported
 	}
-	cfg := defaultClientConfig()
-	for _, opt := range opts {
-		if opt != nil {
-			opt.apply(&cfg)
+	if len(opts) == 0 {
+		c, err := DefaultRegistry.first(ctx, region, opts...)
+		if err != nil {
+			return nil, err
+		}
+		if c.encryption == nil {
+			return c.client, nil
 		}
 	}
-	sdkClient, err := newS3Client(ctx, region, cfg)
+	c, err := DefaultRegistry.Client(ctx, region, opts...)
 	if err != nil {
 		return nil, err
 	}
-	s3ClientAtomic.Store(sdkClient)
-	return sdkClient, nil
+	return c.client, nil
 }
 
-// newS3Client creates a fresh *s3.Client without touching the singleton.
+// newS3Client creates a fresh *s3.Client without touching the registry.
 func newS3Client(ctx context.Context, region awsconfig.Region, cfg clientConfig) (*s3.Client, error) {
+	if cfg.localProfile != nil {
+		return getClientLocal(ctx, *cfg.localProfile, cfg)
+	}
 	if localProfile, ok := getLocalEndpoint(ctx); ok {
 		return getClientLocal(ctx, *localProfile, cfg)
 	}
-	awsHttpClient := awshttp.NewBuildableClient()
-	if GlobalDialer != nil {
-		awsHttpClient.WithDialerOptions(func(dialer *net.Dialer) {
-			if GlobalDialer.Timeout != 0 {
-				dialer.Timeout = GlobalDialer.Timeout
-			}
-			if GlobalDialer.Deadline != nil {
-				dialer.Deadline = *GlobalDialer.Deadline
-			}
-			if GlobalDialer.KeepAlive != 0 {
-				dialer.KeepAlive = GlobalDialer.KeepAlive
-			}
-		})
-	}
 	// S3 Client
-	awsCfg, err := awsConfig.LoadDefaultConfig(
+	awsCfg, err := awscredentials.LoadDefaultConfig(
 		ctx,
+		cfg.credentialsOptions,
 		awsConfig.WithRegion(region.String()),
-		awsConfig.WithHTTPClient(awsHttpClient),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("unable to load SDK config, %w", err)
 	}
 	return s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.HTTPClient = configureHTTPClient(o.HTTPClient, cfg)
 		if cfg.traceEnabled {
 			awstrace.AppendMiddlewares(&o.APIOptions, cfg.traceProvider)
 		}
+		if cfg.metricsEnabled {
+			awsmetrics.AppendMiddlewares(&o.APIOptions, cfg.meterProvider)
+		}
+		applyRateLimit(o, cfg)
+		applyRetryPolicy(o, cfg)
+		applyEncryption(o, cfg)
 	}), nil
 }
 
-func getClientLocal(ctx context.Context, localProfile LocalProfile, cfg clientConfig) (*s3.Client, error) {
-	awsHttpClient := awshttp.NewBuildableClient()
-	if GlobalDialer != nil {
-		awsHttpClient.WithDialerOptions(func(dialer *net.Dialer) {
-			if GlobalDialer.Timeout != 0 {
-				dialer.Timeout = GlobalDialer.Timeout
-			}
-			if GlobalDialer.Deadline != nil {
-				dialer.Deadline = *GlobalDialer.Deadline
-			}
-			if GlobalDialer.KeepAlive != 0 {
-				dialer.KeepAlive = GlobalDialer.KeepAlive
-			}
-		})
+// configureHTTPClient applies the transport of cfg to the HTTP client resolved by the SDK config.
+// GlobalDialer is applied first, so that WithTransport overrides it.
+func configureHTTPClient(client aws.HTTPClient, cfg clientConfig) aws.HTTPClient {
+	opts := make([]awstransport.OptionTransport, 0, len(cfg.transportOptions)+3)
+	if d := GlobalDialer; d != nil {
+		if d.Timeout != 0 {
+			opts = append(opts, awstransport.WithDialTimeout(d.Timeout))
+		}
+		if d.Deadline != nil {
+			opts = append(opts, awstransport.WithDialDeadline(*d.Deadline))
+		}
+		if d.KeepAlive != 0 {
+			opts = append(opts, awstransport.WithKeepAlive(d.KeepAlive))
+		}
 	}
+	return awstransport.ConfigureHTTPClient(client, append(opts, cfg.transportOptions...)...)
+}
+
+func getClientLocal(ctx context.Context, localProfile LocalProfile, cfg clientConfig) (*s3.Client, error) {
 	awsCfg, err := awsConfig.LoadDefaultConfig(ctx,
-		awsConfig.WithHTTPClient(awsHttpClient),
 		awsConfig.WithCredentialsProvider(credentials.StaticCredentialsProvider{
 			Value: aws.Credentials{
 				AccessKeyID:     localProfile.AccessKey,
@@ -150,9 +152,16 @@ func getClientLocal(ctx context.Context, localProfile LocalProfile, cfg clientCo
 	return s3.NewFromConfig(awsCfg, func(o *s3.Options) {
 		o.BaseEndpoint = aws.String(localProfile.Endpoint)
 		o.UsePathStyle = true
+		o.HTTPClient = configureHTTPClient(o.HTTPClient, cfg)
 		if cfg.traceEnabled {
 			awstrace.AppendMiddlewares(&o.APIOptions, cfg.traceProvider)
 		}
+		if cfg.metricsEnabled {
+			awsmetrics.AppendMiddlewares(&o.APIOptions, cfg.meterProvider)
+		}
+		applyRateLimit(o, cfg)
+		applyRetryPolicy(o, cfg)
+		applyEncryption(o, cfg)
 	}), nil
 }
 
@@ -163,6 +172,7 @@ func packageClientFromSDK(sdkClient *s3.Client) *Client {
 	}
 }
 
+// LocalProfile is the endpoint and the static credentials of a local S3-compatible server.
 type LocalProfile struct {
 	Endpoint        string
 	AccessKey       string
@@ -181,11 +191,3 @@ func getLocalEndpoint(ctx context.Context) (*LocalProfile, bool) {
 	}
 	return nil, false
 }
-
-func hash(v LocalProfile) ([]byte, error) {
-	var b bytes.Buffer
-	if err := gob.NewEncoder(&b).Encode(v); err != nil {
-		return nil, err
-	}
-	return b.Bytes(), nil
-}
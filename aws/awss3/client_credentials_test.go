@@ -0,0 +1,23 @@
+package awss3_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"gotest.tools/v3/assert"
+
+	"github.com/88labs/go-utils/aws/awscredentials"
+	"github.com/88labs/go-utils/aws/awss3"
+)
+
+func TestNewClient_WithCredentials(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := credentials.NewStaticCredentialsProvider("CUSTOMACCESSKEY", "CUSTOMSECRETKEY", "")
+	client, err := awss3.NewClient(ctx, TestRegion, awss3.WithCredentials(awscredentials.WithProvider(provider)))
+	assert.NilError(t, err)
+	creds, err := client.S3Client().Options().Credentials.Retrieve(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, "CUSTOMACCESSKEY", creds.AccessKeyID)
+}
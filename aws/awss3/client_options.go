@@ -1,15 +1,17 @@
 package awss3
 
 import (
-	"io"
 	"log/slog"
 
+	"go.opentelemetry.io/otel/metric"
 	oteltrace "go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
-	"go.uber.org/zap/exp/zapslog"
-)
 
-var noopLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	"github.com/88labs/go-utils/aws/awscredentials"
+	"github.com/88labs/go-utils/aws/awstransport"
+	"github.com/88labs/go-utils/aws/internal/awslog"
+	"github.com/88labs/go-utils/backoff"
+)
 
 // ClientOption configures a Client created with NewClient.
 type ClientOption interface {
@@ -17,9 +19,20 @@ type ClientOption interface {
 }
 
 type clientConfig struct {
-	logger        *slog.Logger
-	traceProvider oteltrace.TracerProvider
-	traceEnabled  bool
+	logger             *slog.Logger
+	traceProvider      oteltrace.TracerProvider
+	traceEnabled       bool
+	meterProvider      metric.MeterProvider
+	metricsEnabled     bool
+	rateLimiter        *rateLimiter
+	retryPolicy        RetryPolicy
+	retryLimit         int
+	retryer            *clientRetryer
+	keyProvider        KeyProvider
+	encryption         *clientEncryption
+	localProfile       *LocalProfile
+	transportOptions   []awstransport.OptionTransport
+	credentialsOptions []awscredentials.OptionCredentials
 }
 
 type clientOptionFunc func(*clientConfig)
@@ -34,11 +47,24 @@ func defaultClientConfig() clientConfig {
 	}
 }
 
+// newClientConfig applies opts to the default configuration.
+func newClientConfig(opts ...ClientOption) clientConfig {
+	cfg := defaultClientConfig()
+	for _, opt := range opts {
+		if opt != nil {
+			opt.apply(&cfg)
+		}
+	}
+	cfg.retryer = newClientRetryer(cfg)
+	cfg.encryption = newClientEncryption(cfg.keyProvider)
+	return cfg
+}
+
 // WithLogger configures a Client to emit structured logs via slog.
 // When logger is nil, a no-op logger is used.
 func WithLogger(logger *slog.Logger) ClientOption {
 	return clientOptionFunc(func(cfg *clientConfig) {
-		cfg.logger = normalizeLogger(logger)
+		cfg.logger = awslog.Normalize(logger)
 	})
 }
 
@@ -58,18 +84,96 @@ func WithTrace(provider oteltrace.TracerProvider) ClientOption {
 	})
 }
 
+// WithMetrics records OpenTelemetry metrics of the requests of the client: the number and the latency of
+// the operations, the retries, the throttling errors and the bytes transferred, labelled by service,
+// operation and status. A nil provider uses the globally configured OpenTelemetry provider.
+func WithMetrics(provider metric.MeterProvider) ClientOption {
+	return clientOptionFunc(func(cfg *clientConfig) {
+		cfg.meterProvider = provider
+		cfg.metricsEnabled = true
+	})
+}
+
+// WithRateLimit limits the bandwidth of every request made by the client to bytesPerSecond,
+// shared by uploads and downloads and by all concurrent transfers.
+// Clients created with the same option value share the limit. Values less than 1 disable the limit.
+func WithRateLimit(bytesPerSecond int64) ClientOption {
+	var limiter *rateLimiter
+	if bytesPerSecond > 0 {
+		limiter = newRateLimiter(bytesPerSecond)
+	}
+	return clientOptionFunc(func(cfg *clientConfig) {
+		cfg.rateLimiter = limiter
+	})
+}
+
+// WithRetryPolicy retries every request made by the client, and every object of DownloadFilesParallel
+// and DownloadFilesParallelWithResults, with policy instead of the retryer of the AWS SDK.
+// policy receives a backoff.Retryer that retries the errors reported by IsRetryableError; it may change the
+// number of retries, the intervals or the errors to retry. Every retry is logged by the logger of the client.
+// A nil policy uses the defaults of the backoff package.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return clientOptionFunc(func(cfg *clientConfig) {
+		if policy == nil {
+			policy = func(r *backoff.Retryer) *backoff.Retryer { return r }
+		}
+		cfg.retryPolicy = policy
+	})
+}
+
+// WithRetryLimit limits the retries of the client to maxRetries in total, shared by all requests over the
+// lifetime of the client. Once the limit is reached, the requests of the client are no longer retried.
+// Setting a limit also enables WithRetryPolicy with the defaults of the backoff package.
+// Values less than 1 disable the limit.
+func WithRetryLimit(maxRetries int) ClientOption {
+	return clientOptionFunc(func(cfg *clientConfig) {
+		cfg.retryLimit = max(maxRetries, 0)
+	})
+}
+
+// WithEncryption encrypts the objects uploaded by the client on the client side with AES-256-GCM,
+// using a data key per object generated by provider, and decrypts them transparently when they are downloaded,
+// including ranged reads, OpenObject and parallel downloads. The encrypted data key is stored in the object
+// metadata under keys prefixed with "awss3-cse-". Objects that are not encrypted are downloaded as they are.
+// HeadObject reports the size of the decrypted object, while ListObjects reports the stored size.
+// NewMultipartUpload encrypts its parts when the part size is a multiple of 64 KiB, while CreateMultipartUpload
+// returns ErrEncryptionNotSupported, and S3 Select and presigned URLs are not encrypted.
+// A nil provider disables the encryption.
+func WithEncryption(provider KeyProvider) ClientOption {
+	return clientOptionFunc(func(cfg *clientConfig) {
+		cfg.keyProvider = provider
+	})
+}
+
+// WithLocalProfile sends the requests of the client to a local endpoint, such as MinIO or awss3test, with
+// path-style addressing and static credentials. It takes precedence over the endpoint set by ctxawslocal.
+func WithLocalProfile(profile LocalProfile) ClientOption {
+	return clientOptionFunc(func(cfg *clientConfig) {
+		cfg.localProfile = &profile
+	})
+}
+
 // NewLoggerFromZap bridges a zap logger into slog so it can be used with awss3.
 // When logger is nil, a no-op logger is returned.
 func NewLoggerFromZap(logger *zap.Logger) *slog.Logger {
-	if logger == nil {
-		return noopLogger
-	}
-	return slog.New(zapslog.NewHandler(logger.Core()))
+	return awslog.NewLoggerFromZap(logger)
 }
 
-func normalizeLogger(logger *slog.Logger) *slog.Logger {
-	if logger == nil {
-		return noopLogger
-	}
-	return logger
+// WithTransport configures the HTTP transport of the client: dialer, TLS, proxy, connection pool,
+// timeouts, HTTP/2 and RoundTripper hooks. Settings that are not set fall back to GlobalDialer,
+// then to the defaults of the AWS SDK. It can be given more than once; later options take precedence.
+func WithTransport(opts ...awstransport.OptionTransport) ClientOption {
+	return clientOptionFunc(func(cfg *clientConfig) {
+		cfg.transportOptions = append(cfg.transportOptions, opts...)
+	})
+}
+
+// WithCredentials configures the credentials of the client: STS AssumeRole with an external ID, a session name
+// and a duration, web identity token files, shared config profiles and custom credential providers.
+// Credentials are cached and refreshed before they expire. They are ignored for local endpoints, which use the
+// static credentials of ctxawslocal. It can be given more than once; later options take precedence.
+func WithCredentials(opts ...awscredentials.OptionCredentials) ClientOption {
+	return clientOptionFunc(func(cfg *clientConfig) {
+		cfg.credentialsOptions = append(cfg.credentialsOptions, opts...)
+	})
 }
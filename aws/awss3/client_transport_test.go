@@ -0,0 +1,26 @@
+package awss3_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"gotest.tools/v3/assert"
+
+	"github.com/88labs/go-utils/aws/awss3"
+	"github.com/88labs/go-utils/aws/awstransport"
+)
+
+func TestNewClient_WithTransport(t *testing.T) {
+	t.Parallel()
+	client, err := awss3.NewClient(context.Background(), TestRegion, awss3.WithTransport(
+		awstransport.WithDialTimeout(3*time.Second),
+		awstransport.WithMaxIdleConnsPerHost(64),
+	))
+	assert.NilError(t, err)
+	httpClient, ok := client.S3Client().Options().HTTPClient.(*awshttp.BuildableClient)
+	assert.Assert(t, ok)
+	assert.Equal(t, 3*time.Second, httpClient.GetDialer().Timeout)
+	assert.Equal(t, 64, httpClient.GetTransport().MaxIdleConnsPerHost)
+}
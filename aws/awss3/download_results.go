@@ -0,0 +1,169 @@
+package awss3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/transfermanager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/88labs/go-utils/aws/awss3/options/s3download"
+)
+
+// DownloadFilesParallelWithResults downloads multiple objects in parallel and saves them to a directory,
+// returning a result per key instead of stopping at the first error.
+// Up to s3download.WithConcurrency objects are downloaded at the same time, and failed downloads are retried
+// with the RetryPolicy of the client when IsRetryableError reports them as retryable.
+// Each file is written to a temporary file first and renamed when complete, so a failed or interrupted
+// download never leaves a partial file behind and the files of the other keys are kept.
+//
+// If the file name is duplicated, a sequential number is added to the suffix.
+// With s3download.WithSkipExisting, the download can be resumed: existing files with the same content are
+// skipped and the others are overwritten.
+//
+// The results are returned in the order of the unique keys, one per key.
+// err joins the errors of all failed keys and is nil when every key was downloaded or skipped.
+func (c *Client) DownloadFilesParallelWithResults(
+	ctx context.Context, bucketName BucketName, keys Keys, outputDir string,
+	opts ...s3download.OptionS3Download,
+) (results DownloadResults, err error) {
+	conf := s3download.GetS3DownloadConf(opts...)
+	uniqKeys := keys.Unique()
+	progress := newTransferProgress(conf.ProgressListener, len(uniqKeys))
+	done := c.logOperation(ctx, "DownloadFilesParallelWithResults",
+		slog.String("bucket", bucketName.String()),
+		slog.Int("key_count", len(keys)),
+		slog.String("output_dir", outputDir),
+		slog.Bool("skip_existing", conf.SkipExisting),
+	)
+	defer func() {
+		done(err, append(progress.logAttrs(),
+			slog.Int("downloaded_file_count", len(results.Downloaded())),
+			slog.Int("skipped_count", len(results.Skipped())),
+			slog.Int("failed_count", len(results.Failed())),
+		)...)
+	}()
+
+	paths := downloadFilePaths(uniqKeys, outputDir, conf.FileNameReplacer, !conf.SkipExisting)
+	// the downloads are retried as a whole, so their requests are not retried again
+	downloader := transfermanager.New(singleRetryClient{c.client}, c.downloadOptions)
+	results = make(DownloadResults, len(uniqKeys))
+	var eg errgroup.Group
+	eg.SetLimit(conf.Concurrency)
+	for i, key := range uniqKeys {
+		results[i] = DownloadResult{Key: key, Path: paths[i]}
+		eg.Go(func() error {
+			c.downloadResult(ctx, downloader, bucketName, &results[i], conf.SkipExisting, progress.object(key))
+			return nil
+		})
+	}
+	_ = eg.Wait()
+	return results, results.Err()
+}
+
+// downloadResult downloads a single key and records the outcome in r.
+func (c *Client) downloadResult(
+	ctx context.Context, downloader *transfermanager.Client, bucketName BucketName, r *DownloadResult,
+	skipExisting bool, progressOpt func(*transfermanager.Options),
+) {
+	if skipExisting {
+		size, ok, err := c.existingFileMatches(ctx, bucketName, r.Key, r.Path)
+		if err != nil {
+			r.Err = err
+			return
+		}
+		if ok {
+			r.Skipped = true
+			r.Bytes = size
+			return
+		}
+	}
+	r.Err = c.retryer.do(ctx, "DownloadFilesParallelWithResults", func(ctx context.Context) error {
+		r.Attempts++
+		return downloadFile(ctx, downloader, bucketName, r.Key, r.Path, time.Time{}, progressOpt)
+	})
+	if r.Err != nil {
+		return
+	}
+	info, err := os.Stat(r.Path)
+	if err != nil {
+		r.Err = err
+		return
+	}
+	r.Bytes = info.Size()
+}
+
+// existingFileMatches reports whether the file at filePath has the size of the object and,
+// when the ETag of the object is an MD5 of its content, the same MD5.
+func (c *Client) existingFileMatches(
+	ctx context.Context, bucketName BucketName, key Key, filePath string,
+) (int64, bool, error) {
+	info, err := os.Stat(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	head, err := c.headObjectInput(ctx, &s3.HeadObjectInput{
+		Bucket: bucketName.AWSString(),
+		Key:    key.AWSString(),
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	if !info.Mode().IsRegular() || info.Size() != aws.ToInt64(head.ContentLength) {
+		return 0, false, nil
+	}
+	etag := strings.Trim(aws.ToString(head.ETag), `"`)
+	if strings.Contains(etag, "-") {
+		// multipart ETags are not an MD5 of the content
+		return info.Size(), true, nil
+	}
+	if c.encryption != nil && hasEncryptionEnvelope(head.Metadata) {
+		// the ETag is the MD5 of the ciphertext; HeadObject already reported the plaintext size
+		return info.Size(), true, nil
+	}
+	sum, err := fileMD5(filePath)
+	if err != nil {
+		return 0, false, err
+	}
+	return info.Size(), sum == etag, nil
+}
+
+// downloadFilePaths resolves the file path of every key. If the file name is duplicated within keys,
+// or with an existing file when checkExisting is set, a sequential number is added to the suffix.
+func downloadFilePaths(
+	keys Keys, outputDir string, replacer s3download.FileNameReplacerFunc, checkExisting bool,
+) []string {
+	paths := make([]string, len(keys))
+	reserved := make(map[string]bool, len(keys))
+	for i, key := range keys {
+		fileName := filepath.Base(key.String())
+		if replacer != nil {
+			fileName = replacer(key.String(), fileName)
+		}
+		ext := filepath.Ext(fileName)
+		filePath := filepath.Join(outputDir, fileName)
+		for n := 2; ; n++ {
+			if !reserved[filePath] {
+				if _, err := os.Stat(filePath); !checkExisting || err != nil {
+					break
+				}
+			}
+			filePath = filepath.Join(outputDir, fmt.Sprintf("%s_%d%s", strings.TrimSuffix(fileName, ext), n, ext))
+		}
+		reserved[filePath] = true
+		paths[i] = filePath
+	}
+	return paths
+}
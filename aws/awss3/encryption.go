@@ -0,0 +1,678 @@
+package awss3
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/transfermanager"
+	tmtypes "github.com/aws/aws-sdk-go-v2/feature/s3/transfermanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+var (
+	// ErrDecryption is returned when an object encrypted on the client cannot be decrypted,
+	// because its envelope is invalid or its content has been modified or truncated.
+	ErrDecryption = errors.New("Decryption")
+	// ErrEncryptionNotSupported is returned by the operations that cannot encrypt or decrypt objects
+	// on a Client created with WithEncryption.
+	ErrEncryptionNotSupported = errors.New("EncryptionNotSupported")
+)
+
+// The envelope of an object encrypted on the client is stored in its user metadata.
+// The keys are lowercase because S3 compatible storages such as MinIO lowercase metadata keys.
+const (
+	encryptionAlgorithmKey = "awss3-cse-algorithm"
+	encryptionDataKeyKey   = "awss3-cse-key"
+	encryptionKeyIDKey     = "awss3-cse-key-id"
+	encryptionChunkSizeKey = "awss3-cse-chunk-size"
+
+	// encryptionAlgorithm encrypts the object in chunks with AES-256-GCM, so that any range of it
+	// can be decrypted and authenticated on its own.
+	encryptionAlgorithm = "AES256-GCM-CHUNKED"
+	// encryptionChunkSize is the plaintext size of a chunk. Every chunk is followed by its GCM tag.
+	encryptionChunkSize = 64 * 1024
+	// encryptionOverhead is the size of the GCM tag of a chunk.
+	encryptionOverhead = 16
+	// encryptedChunkSize is the stored size of a chunk.
+	encryptedChunkSize = encryptionChunkSize + encryptionOverhead
+
+	// dataKeyCacheSize is the number of decrypted data keys a client keeps, so that the ranged requests
+	// of a download decrypt the data key once.
+	dataKeyCacheSize = 128
+)
+
+// clientEncryption encrypts the objects uploaded by a client and decrypts the objects it downloads.
+// It is a middleware of the SDK client, so every upload and download path of the client is covered,
+// including the ranged requests of OpenObject and of the transfer manager.
+type clientEncryption struct {
+	provider KeyProvider
+
+	mu   sync.Mutex
+	keys map[string]cipher.AEAD
+}
+
+func newClientEncryption(provider KeyProvider) *clientEncryption {
+	if provider == nil {
+		return nil
+	}
+	return &clientEncryption{provider: provider, keys: make(map[string]cipher.AEAD)}
+}
+
+// applyEncryption adds the encryption middleware to the SDK client.
+func applyEncryption(o *s3.Options, cfg clientConfig) {
+	if cfg.encryption == nil {
+		return
+	}
+	o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(cfg.encryption, middleware.After)
+	})
+}
+
+// withoutEncryption removes the encryption middleware, for the presign clients:
+// a presigned request is sent by someone else, so its body and range cannot be rewritten.
+func withoutEncryption(o *s3.Options) {
+	o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+		if _, ok := stack.Initialize.Get((*clientEncryption)(nil).ID()); ok {
+			_, err := stack.Initialize.Remove((*clientEncryption)(nil).ID())
+			return err
+		}
+		return nil
+	})
+}
+
+func (e *clientEncryption) ID() string {
+	return "awss3Encryption"
+}
+
+func (e *clientEncryption) HandleInitialize(
+	ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+) (middleware.InitializeOutput, middleware.Metadata, error) {
+	switch input := in.Parameters.(type) {
+	case *s3.PutObjectInput:
+		if hasEncryptionEnvelope(input.Metadata) {
+			// the body has already been encrypted by UploadManager
+			break
+		}
+		encrypted, err := e.encryptPutObjectInput(ctx, input)
+		if err != nil {
+			return middleware.InitializeOutput{}, middleware.Metadata{}, err
+		}
+		in.Parameters = encrypted
+	case *s3.GetObjectInput:
+		return e.getObject(ctx, in, input, next)
+	case *s3.HeadObjectInput:
+		out, md, err := next.HandleInitialize(ctx, in)
+		if err != nil {
+			return out, md, err
+		}
+		if res, ok := out.Result.(*s3.HeadObjectOutput); ok && input.PartNumber == nil &&
+			hasEncryptionEnvelope(res.Metadata) {
+			size, ok := plaintextSize(aws.ToInt64(res.ContentLength))
+			if !ok {
+				return out, md, fmt.Errorf("%w: invalid object size %d", ErrDecryption, aws.ToInt64(res.ContentLength))
+			}
+			res.ContentLength = aws.Int64(size)
+		}
+		return out, md, nil
+	}
+	return next.HandleInitialize(ctx, in)
+}
+
+// newEnvelope generates the data key of an object and returns the metadata that stores it.
+func (e *clientEncryption) newEnvelope(ctx context.Context) (map[string]string, cipher.AEAD, error) {
+	key, err := e.provider.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("awss3: generate data key: %w", err)
+	}
+	aead, err := newDataKeyAEAD(key.Plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+	return map[string]string{
+		encryptionAlgorithmKey: encryptionAlgorithm,
+		encryptionDataKeyKey:   base64.StdEncoding.EncodeToString(key.Encrypted),
+		encryptionKeyIDKey:     key.KeyID,
+		encryptionChunkSizeKey: strconv.Itoa(encryptionChunkSize),
+	}, aead, nil
+}
+
+// openEnvelope decrypts the data key stored in the metadata of an object.
+func (e *clientEncryption) openEnvelope(ctx context.Context, metadata map[string]string) (cipher.AEAD, error) {
+	if alg := metadata[encryptionAlgorithmKey]; alg != encryptionAlgorithm {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrDecryption, alg)
+	}
+	if chunkSize := metadata[encryptionChunkSizeKey]; chunkSize != strconv.Itoa(encryptionChunkSize) {
+		return nil, fmt.Errorf("%w: unsupported chunk size %q", ErrDecryption, chunkSize)
+	}
+	keyID := metadata[encryptionKeyIDKey]
+	cacheKey := keyID + "/" + metadata[encryptionDataKeyKey]
+	e.mu.Lock()
+	aead, ok := e.keys[cacheKey]
+	e.mu.Unlock()
+	if ok {
+		return aead, nil
+	}
+
+	encrypted, err := base64.StdEncoding.DecodeString(metadata[encryptionDataKeyKey])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid data key: %w", ErrDecryption, err)
+	}
+	plaintext, err := e.provider.DecryptDataKey(ctx, keyID, encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("awss3: decrypt data key: %w", err)
+	}
+	if aead, err = newDataKeyAEAD(plaintext); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	if len(e.keys) >= dataKeyCacheSize {
+		clear(e.keys)
+	}
+	e.keys[cacheKey] = aead
+	e.mu.Unlock()
+	return aead, nil
+}
+
+func newDataKeyAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != dataKeySize {
+		return nil, fmt.Errorf("awss3: data key must be %d bytes, got %d", dataKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// storedSize returns the size of an object as stored in S3. The middleware makes HeadObject report
+// the size of the decrypted object, while UploadPartCopy ranges are ranges of the stored object.
+func (c *Client) storedSize(head *s3.HeadObjectOutput) int64 {
+	size := aws.ToInt64(head.ContentLength)
+	if c.encryption != nil && hasEncryptionEnvelope(head.Metadata) {
+		return encryptedSize(size)
+	}
+	return size
+}
+
+func hasEncryptionEnvelope(metadata map[string]string) bool {
+	return metadata[encryptionAlgorithmKey] != ""
+}
+
+// withEncryptionEnvelope returns metadata with the envelope of source, if source has one.
+// A copy that replaces the metadata of an encrypted object must keep its envelope.
+func withEncryptionEnvelope(metadata, source map[string]string) map[string]string {
+	if !hasEncryptionEnvelope(source) {
+		return metadata
+	}
+	merged := maps.Clone(metadata)
+	if merged == nil {
+		merged = make(map[string]string, 4)
+	}
+	for k, v := range source {
+		if strings.HasPrefix(k, "awss3-cse-") {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+func (e *clientEncryption) encryptPutObjectInput(ctx context.Context, input *s3.PutObjectInput) (*s3.PutObjectInput, error) {
+	envelope, aead, err := e.newEnvelope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := *input
+	encrypted.Metadata = maps.Clone(input.Metadata)
+	if encrypted.Metadata == nil {
+		encrypted.Metadata = make(map[string]string, len(envelope))
+	}
+	maps.Copy(encrypted.Metadata, envelope)
+	body := input.Body
+	if body == nil {
+		body = strings.NewReader("")
+	}
+	encrypted.Body = newEncryptReader(body, aead)
+	if input.ContentLength != nil {
+		encrypted.ContentLength = aws.Int64(encryptedSize(*input.ContentLength))
+	}
+	// the checksums of the plaintext do not match the stored object; the SDK computes them on the ciphertext
+	encrypted.ContentMD5 = nil
+	encrypted.ChecksumCRC32 = nil
+	encrypted.ChecksumCRC32C = nil
+	encrypted.ChecksumCRC64NVME = nil
+	encrypted.ChecksumMD5 = nil
+	encrypted.ChecksumSHA1 = nil
+	encrypted.ChecksumSHA256 = nil
+	encrypted.ChecksumSHA512 = nil
+	encrypted.ChecksumXXHASH128 = nil
+	encrypted.ChecksumXXHASH3 = nil
+	encrypted.ChecksumXXHASH64 = nil
+	return &encrypted, nil
+}
+
+// encryptUploadObjectInput encrypts the body of an upload of the transfer manager.
+// The parts of a multipart upload are consecutive parts of the encrypted stream, and the PutObject request
+// of a small object carries the envelope, so the middleware does not encrypt it again.
+func (c *Client) encryptUploadObjectInput(ctx context.Context, input *transfermanager.UploadObjectInput) error {
+	if c.encryption == nil {
+		return nil
+	}
+	envelope, aead, err := c.encryption.newEnvelope(ctx)
+	if err != nil {
+		return err
+	}
+	metadata := maps.Clone(input.Metadata)
+	if metadata == nil {
+		metadata = make(map[string]string, len(envelope))
+	}
+	maps.Copy(metadata, envelope)
+	input.Metadata = metadata
+	input.Body = newEncryptReader(input.Body, aead)
+	if input.ContentLength != nil {
+		input.ContentLength = aws.Int64(encryptedSize(*input.ContentLength))
+	}
+	input.ChecksumCRC32 = nil
+	input.ChecksumCRC32C = nil
+	input.ChecksumCRC64NVME = nil
+	input.ChecksumSHA1 = nil
+	input.ChecksumSHA256 = nil
+	input.ChecksumSHA512 = nil
+	return nil
+}
+
+// downloadOptions configures the downloads of the transfer manager. The parts of an encrypted object are not
+// aligned to its chunks, so it is downloaded by ranges, which the middleware maps to chunks.
+func (c *Client) downloadOptions(o *transfermanager.Options) {
+	if c.encryption != nil {
+		o.GetObjectType = tmtypes.GetObjectRanges
+	}
+}
+
+// getObject requests the chunks covering the requested range and decrypts them.
+// A range of an object that is not encrypted is requested again as it was.
+func (e *clientEncryption) getObject(
+	ctx context.Context, in middleware.InitializeInput, input *s3.GetObjectInput, next middleware.InitializeHandler,
+) (middleware.InitializeOutput, middleware.Metadata, error) {
+	var (
+		rng    byteRange
+		ranged = input.Range != nil
+	)
+	if ranged {
+		var err error
+		if rng, err = parseByteRange(aws.ToString(input.Range)); err != nil {
+			return middleware.InitializeOutput{}, middleware.Metadata{}, err
+		}
+		chunked := *input
+		chunked.Range = aws.String(rng.chunkRange())
+		in.Parameters = &chunked
+	}
+	out, md, err := next.HandleInitialize(ctx, in)
+	if err != nil {
+		return out, md, err
+	}
+	res, ok := out.Result.(*s3.GetObjectOutput)
+	if !ok {
+		return out, md, nil
+	}
+	if !hasEncryptionEnvelope(res.Metadata) {
+		if ranged {
+			_ = res.Body.Close()
+			in.Parameters = input
+			return next.HandleInitialize(ctx, in)
+		}
+		return out, md, nil
+	}
+	fail := func(err error) (middleware.InitializeOutput, middleware.Metadata, error) {
+		_ = res.Body.Close()
+		return middleware.InitializeOutput{}, md, err
+	}
+	if input.PartNumber != nil {
+		return fail(fmt.Errorf("%w: GetObject with a part number", ErrEncryptionNotSupported))
+	}
+	aead, err := e.openEnvelope(ctx, res.Metadata)
+	if err != nil {
+		return fail(err)
+	}
+
+	start, stored := int64(0), aws.ToInt64(res.ContentLength)
+	if res.ContentRange != nil {
+		if start, stored, err = parseContentRange(aws.ToString(res.ContentRange)); err != nil {
+			return fail(err)
+		}
+	}
+	size, ok := plaintextSize(stored)
+	if !ok {
+		return fail(fmt.Errorf("%w: invalid object size %d", ErrDecryption, stored))
+	}
+	from, to := int64(0), size-1
+	if ranged {
+		if rng.start >= size {
+			return fail(&smithy.GenericAPIError{
+				Code:    "InvalidRange",
+				Message: "The requested range is not satisfiable",
+				Fault:   smithy.FaultClient,
+			})
+		}
+		from = rng.start
+		if rng.end >= 0 {
+			to = min(rng.end, size-1)
+		}
+	}
+	chunk := from / encryptionChunkSize
+	if start != chunk*encryptedChunkSize {
+		return fail(fmt.Errorf("%w: unexpected content range %s", ErrDecryption, aws.ToString(res.ContentRange)))
+	}
+	res.Body = &decryptReader{
+		body:      res.Body,
+		aead:      aead,
+		index:     uint64(chunk),
+		last:      uint64(chunkCount(stored) - 1),
+		skip:      int(from - chunk*encryptionChunkSize),
+		remaining: to - from + 1,
+		buf:       make([]byte, encryptedChunkSize),
+	}
+	res.ContentLength = aws.Int64(to - from + 1)
+	if ranged {
+		res.ContentRange = aws.String(fmt.Sprintf("bytes %d-%d/%d", from, to, size))
+	}
+	res.ChecksumCRC32 = nil
+	res.ChecksumCRC32C = nil
+	res.ChecksumCRC64NVME = nil
+	res.ChecksumSHA1 = nil
+	res.ChecksumSHA256 = nil
+	return out, md, nil
+}
+
+// byteRange is a range of a Range header. end is -1 for a range to the end of the object.
+type byteRange struct {
+	start, end int64
+}
+
+func parseByteRange(s string) (byteRange, error) {
+	spec, ok := strings.CutPrefix(s, "bytes=")
+	first, last, found := strings.Cut(spec, "-")
+	if !ok || !found || strings.Contains(last, ",") {
+		return byteRange{}, fmt.Errorf("%w: range %q", ErrEncryptionNotSupported, s)
+	}
+	if first == "" {
+		// the offset of a suffix range depends on the size of the object
+		return byteRange{}, fmt.Errorf("%w: suffix range %q", ErrEncryptionNotSupported, s)
+	}
+	r := byteRange{end: -1}
+	var err error
+	if r.start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return byteRange{}, fmt.Errorf("invalid range %q: %w", s, err)
+	}
+	if last != "" {
+		if r.end, err = strconv.ParseInt(last, 10, 64); err != nil || r.end < r.start {
+			return byteRange{}, fmt.Errorf("invalid range %q", s)
+		}
+	}
+	return r, nil
+}
+
+// chunkRange returns the Range header of the stored chunks that cover the range.
+func (r byteRange) chunkRange() string {
+	start := r.start / encryptionChunkSize * encryptedChunkSize
+	if r.end < 0 {
+		return fmt.Sprintf("bytes=%d-", start)
+	}
+	return fmt.Sprintf("bytes=%d-%d", start, (r.end/encryptionChunkSize+1)*encryptedChunkSize-1)
+}
+
+// parseContentRange returns the start and the object size of a Content-Range header.
+func parseContentRange(s string) (start, size int64, err error) {
+	var end int64
+	if _, err := fmt.Sscanf(s, "bytes %d-%d/%d", &start, &end, &size); err != nil {
+		return 0, 0, fmt.Errorf("%w: invalid content range %q", ErrDecryption, s)
+	}
+	return start, size, nil
+}
+
+// chunkCount returns the number of chunks of an object of stored size. An empty object has one empty chunk.
+func chunkCount(stored int64) int64 {
+	return max((stored+encryptedChunkSize-1)/encryptedChunkSize, 1)
+}
+
+// encryptedSize returns the stored size of an object of size bytes.
+func encryptedSize(size int64) int64 {
+	return size + max((size+encryptionChunkSize-1)/encryptionChunkSize, 1)*encryptionOverhead
+}
+
+// plaintextSize returns the size of the object stored in stored bytes.
+func plaintextSize(stored int64) (int64, bool) {
+	size := stored - chunkCount(stored)*encryptionOverhead
+	if size < 0 || encryptedSize(size) != stored {
+		return 0, false
+	}
+	return size, true
+}
+
+// chunkNonce returns the nonce of a chunk. The data key is unique to the object, so the index is unique
+// to the chunk, and the flag of the last chunk detects an object truncated at a chunk boundary.
+func chunkNonce(nonce []byte, index uint64, last bool) []byte {
+	clear(nonce)
+	binary.BigEndian.PutUint64(nonce[3:11], index)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// encryptReader encrypts a plaintext stream into chunks.
+type encryptReader struct {
+	src   io.Reader
+	aead  cipher.AEAD
+	index uint64
+	nonce []byte
+	// plain holds a chunk and the first byte of the next one, to know whether the chunk is the last.
+	plain     []byte
+	carry     bool
+	sealed    []byte
+	out       []byte
+	exhausted bool
+}
+
+// newEncryptReader returns a reader of the encrypted src. It is an io.Seeker if src is one,
+// so that the SDK can compute the length of the body and rewind it on retries.
+func newEncryptReader(src io.Reader, aead cipher.AEAD) io.Reader {
+	r := &encryptReader{
+		src:    src,
+		aead:   aead,
+		nonce:  make([]byte, aead.NonceSize()),
+		plain:  make([]byte, encryptionChunkSize+1),
+		sealed: make([]byte, 0, encryptedChunkSize),
+	}
+	if seeker, ok := src.(io.Seeker); ok {
+		// the offsets are relative to the position of the source when the reader is created
+		if start, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			return &encryptReadSeeker{encryptReader: r, seeker: seeker, srcStart: start, srcSize: -1}
+		}
+	}
+	return r
+}
+
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.exhausted {
+			return 0, io.EOF
+		}
+		if err := r.nextChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *encryptReader) nextChunk() error {
+	n := 0
+	if r.carry {
+		r.plain[0] = r.plain[encryptionChunkSize]
+		n = 1
+	}
+	m, err := io.ReadFull(r.src, r.plain[n:])
+	n += m
+	last := false
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		last = true
+	case err != nil:
+		return err
+	}
+	r.carry = !last
+	chunk := r.plain[:min(n, encryptionChunkSize)]
+	r.out = r.aead.Seal(r.sealed[:0], chunkNonce(r.nonce, r.index, last), chunk, nil)
+	r.index++
+	r.exhausted = last
+	return nil
+}
+
+func (r *encryptReader) reset(index uint64) {
+	r.index = index
+	r.carry = false
+	r.out = nil
+	r.exhausted = false
+}
+
+type encryptReadSeeker struct {
+	*encryptReader
+	seeker   io.Seeker
+	srcStart int64
+	srcSize  int64
+	pos      int64
+}
+
+func (r *encryptReadSeeker) Read(p []byte) (int, error) {
+	n, err := r.encryptReader.Read(p)
+	r.pos += int64(n)
+	return n, err
+}
+
+func (r *encryptReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	if r.srcSize < 0 {
+		current, err := r.seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, err
+		}
+		end, err := r.seeker.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := r.seeker.Seek(current, io.SeekStart); err != nil {
+			return 0, err
+		}
+		r.srcSize = end - r.srcStart
+	}
+	size := encryptedSize(r.srcSize)
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.pos
+	case io.SeekEnd:
+		offset += size
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position: %d", offset)
+	}
+	if offset == r.pos {
+		return offset, nil
+	}
+	r.pos = offset
+	if offset >= size {
+		r.out = nil
+		r.exhausted = true
+		return offset, nil
+	}
+	chunk := offset / encryptedChunkSize
+	if _, err := r.seeker.Seek(r.srcStart+chunk*encryptionChunkSize, io.SeekStart); err != nil {
+		return 0, err
+	}
+	r.reset(uint64(chunk))
+	if skip := offset - chunk*encryptedChunkSize; skip > 0 {
+		if err := r.nextChunk(); err != nil {
+			return 0, err
+		}
+		r.out = r.out[skip:]
+	}
+	return offset, nil
+}
+
+// decryptReader decrypts the chunks of a stored object from index, and returns remaining bytes
+// after skipping skip bytes of the first chunk.
+type decryptReader struct {
+	body      io.ReadCloser
+	aead      cipher.AEAD
+	index     uint64
+	last      uint64
+	skip      int
+	remaining int64
+	nonce     [12]byte
+	buf       []byte
+	out       []byte
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.remaining <= 0 {
+			return 0, io.EOF
+		}
+		if err := r.nextChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *decryptReader) nextChunk() error {
+	if r.index > r.last {
+		return io.ErrUnexpectedEOF
+	}
+	n, err := io.ReadFull(r.body, r.buf)
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		if r.index != r.last || n < encryptionOverhead {
+			return io.ErrUnexpectedEOF
+		}
+	case err != nil:
+		return err
+	}
+	plain, err := r.aead.Open(r.buf[:0], chunkNonce(r.nonce[:], r.index, r.index == r.last), r.buf[:n], nil)
+	if err != nil {
+		return fmt.Errorf("%w: chunk %d: %w", ErrDecryption, r.index, err)
+	}
+	if r.skip > len(plain) {
+		return fmt.Errorf("%w: chunk %d is shorter than expected", ErrDecryption, r.index)
+	}
+	plain = plain[r.skip:]
+	r.skip = 0
+	if int64(len(plain)) > r.remaining {
+		plain = plain[:r.remaining]
+	}
+	r.remaining -= int64(len(plain))
+	r.out = plain
+	r.index++
+	return nil
+}
+
+func (r *decryptReader) Close() error {
+	return r.body.Close()
+}
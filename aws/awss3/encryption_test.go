@@ -0,0 +1,398 @@
+package awss3_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/smithy-go"
+	"gotest.tools/v3/assert"
+
+	"github.com/88labs/go-utils/ulid"
+
+	"github.com/88labs/go-utils/aws/awss3"
+	"github.com/88labs/go-utils/aws/awss3/options/s3download"
+	"github.com/88labs/go-utils/aws/awss3/options/s3multipart"
+	"github.com/88labs/go-utils/aws/awss3/options/s3sync"
+	"github.com/88labs/go-utils/aws/awss3/options/s3upload"
+	"github.com/88labs/go-utils/aws/awss3/options/s3zip"
+	"github.com/88labs/go-utils/aws/ctxawslocal"
+)
+
+func newStaticKeyProvider(t *testing.T, keyID string) awss3.KeyProvider {
+	t.Helper()
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NilError(t, err)
+	provider, err := awss3.NewStaticKeyProvider(keyID, key)
+	assert.NilError(t, err)
+	return provider
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	assert.NilError(t, err)
+	return b
+}
+
+func TestNewClient_WithEncryption(t *testing.T) {
+	t.Parallel()
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint("http://127.0.0.1:29000"), // use Minio
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+	client, err := awss3.NewClient(ctx, TestRegion, awss3.WithEncryption(newStaticKeyProvider(t, "test-key")))
+	assert.NilError(t, err)
+	plain, err := awss3.NewClient(ctx, TestRegion)
+	assert.NilError(t, err)
+
+	// three chunks, the last one partial
+	body := randomBytes(t, 2*64*1024+100)
+	key := awss3.Key(fmt.Sprintf("awstest/%s.bin", ulid.MustNew()))
+	_, err = client.PutObject(ctx, TestBucket, key, bytes.NewReader(body),
+		s3upload.WithMetadata(map[string]string{"owner": "awss3"}))
+	assert.NilError(t, err)
+
+	t.Run("PutObject stores the ciphertext and the envelope", func(t *testing.T) {
+		var stored bytes.Buffer
+		assert.NilError(t, plain.GetObjectWriter(ctx, TestBucket, key, &stored))
+		assert.Equal(t, stored.Len(), len(body)+3*16)
+		assert.Assert(t, !bytes.Contains(stored.Bytes(), body[:64]))
+
+		head, err := plain.HeadObject(ctx, TestBucket, key)
+		assert.NilError(t, err)
+		assert.Equal(t, head.Metadata["owner"], "awss3")
+		assert.Equal(t, head.Metadata["awss3-cse-algorithm"], "AES256-GCM-CHUNKED")
+		assert.Equal(t, head.Metadata["awss3-cse-key-id"], "test-key")
+	})
+	t.Run("GetObjectWriter and HeadObject decrypt", func(t *testing.T) {
+		var got bytes.Buffer
+		assert.NilError(t, client.GetObjectWriter(ctx, TestBucket, key, &got))
+		assert.Assert(t, bytes.Equal(got.Bytes(), body))
+
+		head, err := client.HeadObject(ctx, TestBucket, key)
+		assert.NilError(t, err)
+		assert.Equal(t, aws.ToInt64(head.ContentLength), int64(len(body)))
+	})
+	t.Run("GetObjectRange decrypts the range", func(t *testing.T) {
+		tests := map[string]struct {
+			offset, length int64
+		}{
+			"within a chunk":       {offset: 10, length: 100},
+			"across chunks":        {offset: 64*1024 - 10, length: 64*1024 + 20},
+			"to the end":           {offset: 64*1024 + 5, length: 0},
+			"past the end":         {offset: int64(len(body)) - 10, length: 100},
+			"last chunk from head": {offset: 2 * 64 * 1024, length: 0},
+		}
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				res, err := client.GetObjectRange(ctx, TestBucket, key, tt.offset, tt.length)
+				assert.NilError(t, err)
+				defer res.Body.Close()
+				got, err := io.ReadAll(res.Body)
+				assert.NilError(t, err)
+				end := int64(len(body))
+				if tt.length > 0 {
+					end = min(tt.offset+tt.length, end)
+				}
+				assert.Assert(t, bytes.Equal(got, body[tt.offset:end]))
+				assert.Equal(t, aws.ToInt64(res.ContentLength), end-tt.offset)
+				assert.Equal(t, aws.ToString(res.ContentRange),
+					fmt.Sprintf("bytes %d-%d/%d", tt.offset, end-1, len(body)))
+			})
+		}
+		_, err := client.GetObjectRange(ctx, TestBucket, key, int64(len(body)), 10)
+		var apiErr smithy.APIError
+		assert.Assert(t, errors.As(err, &apiErr))
+		assert.Equal(t, apiErr.ErrorCode(), "InvalidRange")
+	})
+	t.Run("OpenObject reads the plaintext", func(t *testing.T) {
+		r, err := client.OpenObject(ctx, TestBucket, key)
+		assert.NilError(t, err)
+		defer r.Close()
+		assert.Equal(t, r.Size(), int64(len(body)))
+		p := make([]byte, 1000)
+		_, err = r.ReadAt(p, 64*1024-500)
+		assert.NilError(t, err)
+		assert.Assert(t, bytes.Equal(p, body[64*1024-500:64*1024+500]))
+		all, err := io.ReadAll(r)
+		assert.NilError(t, err)
+		assert.Assert(t, bytes.Equal(all, body))
+	})
+	t.Run("empty objects", func(t *testing.T) {
+		emptyKey := awss3.Key(fmt.Sprintf("awstest/%s.bin", ulid.MustNew()))
+		_, err := client.PutObject(ctx, TestBucket, emptyKey, bytes.NewReader(nil))
+		assert.NilError(t, err)
+		var got bytes.Buffer
+		assert.NilError(t, client.GetObjectWriter(ctx, TestBucket, emptyKey, &got))
+		assert.Equal(t, got.Len(), 0)
+		head, err := plain.HeadObject(ctx, TestBucket, emptyKey)
+		assert.NilError(t, err)
+		assert.Equal(t, aws.ToInt64(head.ContentLength), int64(16))
+	})
+	t.Run("objects that are not encrypted are read as they are", func(t *testing.T) {
+		plainKey := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		_, err := plain.PutObject(ctx, TestBucket, plainKey, bytes.NewReader(body))
+		assert.NilError(t, err)
+		var got bytes.Buffer
+		assert.NilError(t, client.GetObjectWriter(ctx, TestBucket, plainKey, &got))
+		assert.Assert(t, bytes.Equal(got.Bytes(), body))
+		res, err := client.GetObjectRange(ctx, TestBucket, plainKey, 70000, 10)
+		assert.NilError(t, err)
+		defer res.Body.Close()
+		part, err := io.ReadAll(res.Body)
+		assert.NilError(t, err)
+		assert.Assert(t, bytes.Equal(part, body[70000:70010]))
+	})
+	t.Run("UploadManager and DownloadFilesParallel with multipart transfers", func(t *testing.T) {
+		large := randomBytes(t, 17*1024*1024+3)
+		largeKey := awss3.Key(fmt.Sprintf("awstest/%s.bin", ulid.MustNew()))
+		_, err := client.UploadManager(ctx, TestBucket, largeKey, bytes.NewReader(large))
+		assert.NilError(t, err)
+		head, err := plain.HeadObject(ctx, TestBucket, largeKey)
+		assert.NilError(t, err)
+		assert.Equal(t, head.Metadata["awss3-cse-key-id"], "test-key")
+
+		paths, err := client.DownloadFilesParallel(ctx, TestBucket, awss3.Keys{largeKey}, t.TempDir())
+		assert.NilError(t, err)
+		got, err := os.ReadFile(paths[0])
+		assert.NilError(t, err)
+		assert.Assert(t, bytes.Equal(got, large))
+	})
+	t.Run("Sync and SkipExisting skip unchanged files", func(t *testing.T) {
+		prefix := fmt.Sprintf("awstest/%s/", ulid.MustNew())
+		files := map[string]string{"a.txt": "a", "sub/b.txt": string(randomBytes(t, 64*1024+1))}
+		src := t.TempDir()
+		writeSyncFiles(t, src, files)
+		results, err := client.Sync(ctx, src, TestBucket, prefix)
+		assert.NilError(t, err)
+		assert.Equal(t, 2, len(results.Transferred()))
+		results, err = client.Sync(ctx, src, TestBucket, prefix)
+		assert.NilError(t, err)
+		assert.Equal(t, 0, len(results.Transferred()))
+		assert.Equal(t, 2, len(results.Skipped()))
+
+		dst := t.TempDir()
+		download := s3sync.WithDirection(s3sync.DirectionDownload)
+		results, err = client.Sync(ctx, dst, TestBucket, prefix, download)
+		assert.NilError(t, err)
+		assert.Equal(t, 2, len(results.Transferred()))
+		assert.DeepEqual(t, files, readSyncFiles(t, dst))
+		results, err = client.Sync(ctx, dst, TestBucket, prefix, download)
+		assert.NilError(t, err)
+		assert.Equal(t, 0, len(results.Transferred()))
+		assert.Equal(t, 2, len(results.Skipped()))
+
+		keys := awss3.Keys{awss3.Key(prefix + "a.txt"), awss3.Key(prefix + "sub/b.txt")}
+		out := t.TempDir()
+		downloaded, err := client.DownloadFilesParallelWithResults(ctx, TestBucket, keys, out)
+		assert.NilError(t, err)
+		assert.Equal(t, 2, len(downloaded.Downloaded()))
+		downloaded, err = client.DownloadFilesParallelWithResults(ctx, TestBucket, keys, out,
+			s3download.WithSkipExisting(true))
+		assert.NilError(t, err)
+		assert.Equal(t, 2, len(downloaded.Skipped()))
+	})
+	t.Run("ReplaceMetadata keeps the envelope", func(t *testing.T) {
+		_, err := client.ReplaceMetadata(ctx, TestBucket, key, map[string]string{"owner": "replaced"})
+		assert.NilError(t, err)
+		var got bytes.Buffer
+		assert.NilError(t, client.GetObjectWriter(ctx, TestBucket, key, &got))
+		assert.Assert(t, bytes.Equal(got.Bytes(), body))
+	})
+	t.Run("a different key cannot decrypt", func(t *testing.T) {
+		other, err := awss3.NewClient(ctx, TestRegion, awss3.WithEncryption(newStaticKeyProvider(t, "test-key")))
+		assert.NilError(t, err)
+		err = other.GetObjectWriter(ctx, TestBucket, key, io.Discard)
+		assert.Assert(t, err != nil)
+	})
+	t.Run("tampered objects fail to decrypt", func(t *testing.T) {
+		tamperedKey := awss3.Key(fmt.Sprintf("awstest/%s.bin", ulid.MustNew()))
+		_, err := client.PutObject(ctx, TestBucket, tamperedKey, bytes.NewReader(body))
+		assert.NilError(t, err)
+		head, err := plain.HeadObject(ctx, TestBucket, tamperedKey)
+		assert.NilError(t, err)
+		var stored bytes.Buffer
+		assert.NilError(t, plain.GetObjectWriter(ctx, TestBucket, tamperedKey, &stored))
+		tampered := stored.Bytes()
+		tampered[100] ^= 1
+		_, err = plain.PutObject(ctx, TestBucket, tamperedKey, bytes.NewReader(tampered),
+			s3upload.WithMetadata(head.Metadata))
+		assert.NilError(t, err)
+		err = client.GetObjectWriter(ctx, TestBucket, tamperedKey, io.Discard)
+		assert.ErrorIs(t, err, awss3.ErrDecryption)
+	})
+	t.Run("GetClient does not drop the encryption", func(t *testing.T) {
+		_, err := awss3.GetClient(ctx, TestRegion, awss3.WithEncryption(newStaticKeyProvider(t, "test-key")))
+		assert.ErrorIs(t, err, awss3.ErrEncryptionNotSupported)
+	})
+	t.Run("CreateMultipartUpload is not supported", func(t *testing.T) {
+		_, err := client.CreateMultipartUpload(ctx, TestBucket, key)
+		assert.ErrorIs(t, err, awss3.ErrEncryptionNotSupported)
+	})
+	t.Run("NewMultipartUpload encrypts the parts", func(t *testing.T) {
+		partSize := s3multipart.MinPartSize
+		for _, size := range []int64{0, 100, partSize, 2*partSize + 100} {
+			multipartKey := awss3.Key(fmt.Sprintf("awstest/%s.bin", ulid.MustNew()))
+			multipartBody := randomBytes(t, int(size))
+			u, err := client.NewMultipartUpload(ctx, TestBucket, multipartKey, s3multipart.WithPartSize(partSize))
+			assert.NilError(t, err)
+			for chunk := range slices.Chunk(multipartBody, 1000*1000) {
+				_, err = u.Write(chunk)
+				assert.NilError(t, err)
+			}
+			assert.NilError(t, u.Close())
+
+			var stored bytes.Buffer
+			assert.NilError(t, plain.GetObjectWriter(ctx, TestBucket, multipartKey, &stored))
+			assert.Equal(t, int64(stored.Len()), size+max((size+64*1024-1)/(64*1024), 1)*16)
+			var got bytes.Buffer
+			assert.NilError(t, client.GetObjectWriter(ctx, TestBucket, multipartKey, &got))
+			assert.Assert(t, bytes.Equal(got.Bytes(), multipartBody), "size %d", size)
+		}
+
+		_, err := client.NewMultipartUpload(ctx, TestBucket, key, s3multipart.WithPartSize(partSize+1))
+		assert.ErrorIs(t, err, awss3.ErrEncryptionNotSupported)
+	})
+	t.Run("UploadZip encrypts the archive", func(t *testing.T) {
+		zipKey := awss3.Key(fmt.Sprintf("awstest/%s.zip", ulid.MustNew()))
+		names, err := client.UploadZip(ctx, TestBucket, awss3.Keys{key}, TestBucket, zipKey)
+		assert.NilError(t, err)
+		head, err := plain.HeadObject(ctx, TestBucket, zipKey)
+		assert.NilError(t, err)
+		assert.Equal(t, head.Metadata["awss3-cse-algorithm"], "AES256-GCM-CHUNKED")
+
+		var archive bytes.Buffer
+		assert.NilError(t, client.GetObjectWriter(ctx, TestBucket, zipKey, &archive))
+		zr, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+		assert.NilError(t, err)
+		assert.Equal(t, len(zr.File), 1)
+		assert.Equal(t, zr.File[0].Name, names[0])
+		f, err := zr.File[0].Open()
+		assert.NilError(t, err)
+		got, err := io.ReadAll(f)
+		assert.NilError(t, err)
+		assert.Assert(t, bytes.Equal(got, body))
+
+		_, err = client.UploadZip(ctx, TestBucket, awss3.Keys{key}, TestBucket, zipKey,
+			s3zip.WithMultipartOptions(s3multipart.WithPartSize(s3multipart.MinPartSize+1)))
+		assert.ErrorIs(t, err, awss3.ErrEncryptionNotSupported)
+	})
+	t.Run("ResumeMultipartUpload keeps the data key", func(t *testing.T) {
+		partSize := s3multipart.MinPartSize
+		multipartKey := awss3.Key(fmt.Sprintf("awstest/%s.bin", ulid.MustNew()))
+		multipartBody := randomBytes(t, int(3*partSize+100))
+		u, err := client.NewMultipartUpload(ctx, TestBucket, multipartKey,
+			s3multipart.WithPartSize(partSize), s3multipart.WithLeavePartsOnError())
+		assert.NilError(t, err)
+		_, err = u.Write(multipartBody[:3*partSize+10])
+		assert.NilError(t, err)
+		var state awss3.MultipartUploadState
+		for range 100 {
+			if state = u.State(); len(state.Parts) == 3 {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		assert.Equal(t, len(state.Parts), 3)
+
+		_, err = plain.ResumeMultipartUpload(ctx, state)
+		assert.ErrorIs(t, err, awss3.ErrEncryptionNotSupported)
+		resumed, err := client.ResumeMultipartUpload(ctx, state)
+		assert.NilError(t, err)
+		// the last full part is uploaded again
+		offset := resumed.State().Offset()
+		assert.Equal(t, offset, 2*partSize)
+		_, err = resumed.Write(multipartBody[offset:])
+		assert.NilError(t, err)
+		assert.NilError(t, resumed.Close())
+
+		var got bytes.Buffer
+		assert.NilError(t, client.GetObjectWriter(ctx, TestBucket, multipartKey, &got))
+		assert.Assert(t, bytes.Equal(got.Bytes(), multipartBody))
+	})
+}
+
+// fakeKMS wraps the data keys with a static key provider.
+type fakeKMS struct {
+	provider awss3.KeyProvider
+	contexts []map[string]string
+}
+
+func (f *fakeKMS) GenerateDataKey(
+	ctx context.Context, params *kms.GenerateDataKeyInput, _ ...func(*kms.Options),
+) (*kms.GenerateDataKeyOutput, error) {
+	f.contexts = append(f.contexts, params.EncryptionContext)
+	key, err := f.provider.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &kms.GenerateDataKeyOutput{
+		KeyId:          aws.String("arn:aws:kms:ap-northeast-1:123456789012:key/" + aws.ToString(params.KeyId)),
+		Plaintext:      key.Plaintext,
+		CiphertextBlob: key.Encrypted,
+	}, nil
+}
+
+func (f *fakeKMS) Decrypt(
+	ctx context.Context, params *kms.DecryptInput, _ ...func(*kms.Options),
+) (*kms.DecryptOutput, error) {
+	f.contexts = append(f.contexts, params.EncryptionContext)
+	plaintext, err := f.provider.DecryptDataKey(ctx, "static", params.CiphertextBlob)
+	if err != nil {
+		return nil, err
+	}
+	return &kms.DecryptOutput{KeyId: params.KeyId, Plaintext: plaintext}, nil
+}
+
+func TestNewKMSKeyProvider(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	fake := &fakeKMS{provider: newStaticKeyProvider(t, "static")}
+	provider := awss3.NewKMSKeyProvider(fake, "my-key")
+
+	key, err := provider.GenerateDataKey(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, key.KeyID, "arn:aws:kms:ap-northeast-1:123456789012:key/my-key")
+	assert.Equal(t, len(key.Plaintext), 32)
+
+	plaintext, err := provider.DecryptDataKey(ctx, key.KeyID, key.Encrypted)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, plaintext, key.Plaintext)
+	assert.Equal(t, len(fake.contexts), 2)
+	assert.DeepEqual(t, fake.contexts[0], fake.contexts[1])
+}
+
+func TestNewStaticKeyProvider(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	_, err := awss3.NewStaticKeyProvider("short", make([]byte, 16))
+	assert.ErrorContains(t, err, "32 bytes")
+
+	provider := newStaticKeyProvider(t, "key-1")
+	key, err := provider.GenerateDataKey(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, key.KeyID, "key-1")
+	plaintext, err := provider.DecryptDataKey(ctx, "key-1", key.Encrypted)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, plaintext, key.Plaintext)
+
+	_, err = provider.DecryptDataKey(ctx, "key-2", key.Encrypted)
+	assert.ErrorContains(t, err, "key-2")
+	_, err = newStaticKeyProvider(t, "key-1").DecryptDataKey(ctx, "key-1", key.Encrypted)
+	assert.Assert(t, err != nil)
+}
@@ -0,0 +1,137 @@
+package awss3
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// dataKeySize is the size of the AES-256 data keys used by client-side encryption.
+const dataKeySize = 32
+
+// DataKey is the key an object is encrypted with.
+// Every object has its own data key, which is stored with the object encrypted by a KeyProvider.
+type DataKey struct {
+	// KeyID identifies the key that encrypted the data key, such as a KMS key ARN.
+	KeyID string
+	// Plaintext is the 32-byte AES-256 key. It is never stored.
+	Plaintext []byte
+	// Encrypted is the data key encrypted with the key of KeyID, stored in the object metadata.
+	Encrypted []byte
+}
+
+// KeyProvider generates and decrypts the data keys of client-side encryption.
+// Use NewKMSKeyProvider in production and NewStaticKeyProvider in tests.
+type KeyProvider interface {
+	// GenerateDataKey returns a new data key for an object.
+	GenerateDataKey(ctx context.Context) (DataKey, error)
+	// DecryptDataKey returns the plaintext of a data key returned by GenerateDataKey.
+	DecryptDataKey(ctx context.Context, keyID string, encrypted []byte) ([]byte, error)
+}
+
+// KMSClient is the part of *kms.Client used by the KMS key provider.
+type KMSClient interface {
+	GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (
+		*kms.GenerateDataKeyOutput, error)
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// NewKMSKeyProvider returns a KeyProvider that generates the data keys with the KMS key keyID,
+// which may be a key ID, a key ARN or an alias. Every object makes a GenerateDataKey request when
+// it is uploaded, and a Decrypt request when it is downloaded.
+func NewKMSKeyProvider(client KMSClient, keyID string) KeyProvider {
+	return kmsKeyProvider{client: client, keyID: keyID}
+}
+
+type kmsKeyProvider struct {
+	client KMSClient
+	keyID  string
+}
+
+// kmsEncryptionContext binds the data keys to client-side encryption of awss3.
+var kmsEncryptionContext = map[string]string{encryptionAlgorithmKey: encryptionAlgorithm}
+
+func (p kmsKeyProvider) GenerateDataKey(ctx context.Context) (DataKey, error) {
+	out, err := p.client.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:             aws.String(p.keyID),
+		KeySpec:           kmstypes.DataKeySpecAes256,
+		EncryptionContext: kmsEncryptionContext,
+	})
+	if err != nil {
+		return DataKey{}, err
+	}
+	return DataKey{
+		// KeyId of the output is the ARN even when keyID is an alias
+		KeyID:     aws.ToString(out.KeyId),
+		Plaintext: out.Plaintext,
+		Encrypted: out.CiphertextBlob,
+	}, nil
+}
+
+func (p kmsKeyProvider) DecryptDataKey(ctx context.Context, keyID string, encrypted []byte) ([]byte, error) {
+	out, err := p.client.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:             aws.String(keyID),
+		CiphertextBlob:    encrypted,
+		EncryptionContext: kmsEncryptionContext,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
+
+// NewStaticKeyProvider returns a KeyProvider that encrypts the data keys with key, a 32-byte AES-256 key.
+// It is meant for tests and local development; keyID is stored with the objects and must match on download.
+func NewStaticKeyProvider(keyID string, key []byte) (KeyProvider, error) {
+	if len(key) != dataKeySize {
+		return nil, fmt.Errorf("awss3: static key must be %d bytes, got %d", dataKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return staticKeyProvider{keyID: keyID, aead: aead}, nil
+}
+
+type staticKeyProvider struct {
+	keyID string
+	aead  cipher.AEAD
+}
+
+func (p staticKeyProvider) GenerateDataKey(_ context.Context) (DataKey, error) {
+	plaintext := make([]byte, dataKeySize)
+	nonce := make([]byte, p.aead.NonceSize(), p.aead.NonceSize()+dataKeySize+p.aead.Overhead())
+	if _, err := rand.Read(plaintext); err != nil {
+		return DataKey{}, err
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return DataKey{}, err
+	}
+	return DataKey{
+		KeyID:     p.keyID,
+		Plaintext: plaintext,
+		Encrypted: p.aead.Seal(nonce, nonce, plaintext, []byte(p.keyID)),
+	}, nil
+}
+
+func (p staticKeyProvider) DecryptDataKey(_ context.Context, keyID string, encrypted []byte) ([]byte, error) {
+	if keyID != p.keyID {
+		return nil, fmt.Errorf("awss3: data key was encrypted with key %q, not %q", keyID, p.keyID)
+	}
+	if len(encrypted) < p.aead.NonceSize() {
+		return nil, errors.New("awss3: encrypted data key is too short")
+	}
+	nonce, sealed := encrypted[:p.aead.NonceSize()], encrypted[p.aead.NonceSize():]
+	return p.aead.Open(nil, nonce, sealed, []byte(keyID))
+}
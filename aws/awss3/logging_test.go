@@ -71,6 +71,38 @@ func TestNewClient_WithZapLogger_logsHeadObject(t *testing.T) {
 	assert.Equal(t, fields["key"], key.String())
 }
 
+func TestNewClient_WithLogger_logsTransferProgress(t *testing.T) {
+	t.Parallel()
+
+	ctx := newLoggingTestContext()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	client, err := awss3.NewClient(ctx, TestRegion, awss3.WithLogger(logger))
+	assert.NilError(t, err)
+
+	key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+	_, err = client.UploadManager(ctx, TestBucket, key, bytes.NewReader(bytes.Repeat([]byte{1}, 128)))
+	assert.NilError(t, err)
+
+	entry := decodeLastJSONLogEntry(t, buf.String())
+	assert.Equal(t, entry["operation"], "UploadManager")
+	assert.Equal(t, entry["bytes_transferred"], float64(128))
+	assert.Equal(t, entry["total_bytes"], float64(128))
+	assert.Equal(t, entry["completed_object_count"], float64(1))
+
+	buf.Reset()
+	_, err = client.DownloadFiles(ctx, TestBucket, awss3.Keys{key}, t.TempDir())
+	assert.NilError(t, err)
+
+	entry = decodeLastJSONLogEntry(t, buf.String())
+	assert.Equal(t, entry["operation"], "DownloadFiles")
+	assert.Equal(t, entry["bytes_transferred"], float64(128))
+	assert.Equal(t, entry["completed_object_count"], float64(1))
+	assert.Equal(t, entry["downloaded_file_count"], float64(1))
+}
+
 func TestNewClient_WithNilLogger_usesNoopLogger(t *testing.T) {
 	ctx := newLoggingTestContext()
 	key := uploadLoggingFixture(t, ctx, 16)
@@ -0,0 +1,543 @@
+package awss3
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/sha1" // nolint:gosec
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	gohash "hash"
+	"hash/crc32"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/88labs/go-utils/aws/awss3/options/s3multipart"
+	"github.com/88labs/go-utils/aws/awss3/options/s3upload"
+)
+
+// ErrMultipartUploadClosed is returned when writing to a MultipartUpload that has been closed or aborted.
+var ErrMultipartUploadClosed = errors.New("MultipartUploadClosed")
+
+// MultipartUploadState is the serialisable state of a MultipartUpload.
+// Persist it (e.g. as JSON) to resume an interrupted upload with ResumeMultipartUpload.
+// It never contains the SSE-C key. The data key of an upload of a client with WithEncryption is kept encrypted
+// in Envelope, and the parts are the size of the encrypted parts.
+type MultipartUploadState struct {
+	Bucket            BucketName              `json:"bucket"`
+	Key               Key                     `json:"key"`
+	UploadID          string                  `json:"upload_id"`
+	PartSize          int64                   `json:"part_size"`
+	ChecksumAlgorithm types.ChecksumAlgorithm `json:"checksum_algorithm"`
+	Parts             []UploadedPart          `json:"parts"`
+	Envelope          map[string]string       `json:"envelope,omitempty"`
+}
+
+// UploadedPart is a part of a multipart upload that has been uploaded to S3.
+type UploadedPart struct {
+	PartNumber int32  `json:"part_number"`
+	ETag       string `json:"etag"`
+	Checksum   string `json:"checksum"`
+	Size       int64  `json:"size"`
+}
+
+// Offset returns the number of bytes covered by the leading contiguous full-size parts.
+// A resumed upload continues from this offset of the source.
+func (s MultipartUploadState) Offset() int64 {
+	var offset int64
+	for i, p := range s.Parts {
+		if p.PartNumber != int32(i+1) || p.Size != s.storedPartSize() {
+			break
+		}
+		offset += s.PartSize
+	}
+	return offset
+}
+
+// storedPartSize returns the size in S3 of a full part, which includes the GCM tags of an encrypted upload.
+func (s MultipartUploadState) storedPartSize() int64 {
+	if hasEncryptionEnvelope(s.Envelope) {
+		return encryptedSize(s.PartSize)
+	}
+	return s.PartSize
+}
+
+// MultipartUpload is a managed multipart upload that implements io.WriteCloser.
+// Written bytes are buffered into parts of s3multipart.WithPartSize, which are uploaded concurrently
+// with a per-part checksum. Close uploads the last part and completes the upload.
+//
+// Unless s3multipart.WithLeavePartsOnError is set, the upload is aborted automatically
+// when a part fails or the context passed to NewMultipartUpload is canceled.
+//
+// On a client with WithEncryption, the parts are encrypted as consecutive chunks of the object, so the object
+// is the same as one uploaded with PutObject. The part size must be a multiple of 64 KiB, and a full part is
+// uploaded when the next byte is written, since the last chunk of the object is sealed differently.
+type MultipartUpload struct {
+	client            *Client
+	ctx               context.Context
+	attrs             uploadAttributes
+	bucketName        BucketName
+	key               Key
+	uploadID          string
+	partSize          int64
+	checksumAlgorithm types.ChecksumAlgorithm
+	leavePartsOnError bool
+	envelope          map[string]string
+	aead              cipher.AEAD
+	eg                *errgroup.Group
+	egCtx             context.Context
+	stopAfterFunc     func() bool
+
+	// mu serialises Write, Close and Abort.
+	mu       sync.Mutex
+	buf      []byte
+	nextPart int32
+	done     bool
+	result   error
+
+	partsMu sync.Mutex
+	parts   map[int32]UploadedPart
+}
+
+// NewMultipartUpload initiates a multipart upload and returns a session to write the object to.
+// The caller must call Close to complete the upload.
+func (c *Client) NewMultipartUpload(
+	ctx context.Context, bucketName BucketName, key Key, opts ...s3multipart.OptionS3Multipart,
+) (*MultipartUpload, error) {
+	conf := s3multipart.GetS3MultipartConf(opts...)
+	uploadOpts := append(
+		[]s3upload.OptionS3Upload{s3upload.WithChecksumAlgorithm(types.ChecksumAlgorithmCrc32)},
+		conf.UploadOptions...,
+	)
+	attrs := newUploadAttributes(uploadOpts...)
+	if _, err := newPartHash(attrs.checksumAlgorithm); err != nil {
+		return nil, err
+	}
+	input := &s3.CreateMultipartUploadInput{
+		Bucket: bucketName.AWSString(),
+		Key:    key.AWSString(),
+	}
+	attrs.applyCreateMultipartUploadInput(input)
+	var (
+		envelope map[string]string
+		aead     cipher.AEAD
+	)
+	if c.encryption != nil {
+		if conf.PartSize%encryptionChunkSize != 0 {
+			return nil, fmt.Errorf("%w: part size %d is not a multiple of %d",
+				ErrEncryptionNotSupported, conf.PartSize, encryptionChunkSize)
+		}
+		var err error
+		if envelope, aead, err = c.encryption.newEnvelope(ctx); err != nil {
+			return nil, err
+		}
+		input.Metadata = maps.Clone(input.Metadata)
+		if input.Metadata == nil {
+			input.Metadata = make(map[string]string, len(envelope))
+		}
+		maps.Copy(input.Metadata, envelope)
+	}
+	uploadID, err := c.createMultipartUpload(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return c.newMultipartUpload(ctx, attrs, MultipartUploadState{
+		Bucket:            bucketName,
+		Key:               key,
+		UploadID:          uploadID,
+		PartSize:          conf.PartSize,
+		ChecksumAlgorithm: attrs.checksumAlgorithm,
+		Envelope:          envelope,
+	}, aead, conf.Concurrency, conf.LeavePartsOnError), nil
+}
+
+// ResumeMultipartUpload resumes an upload from a state returned by MultipartUpload.State.
+// The parts actually stored in S3 are fetched with ListParts, and the leading contiguous full-size parts are kept.
+// The caller must skip State().Offset() bytes of the source before writing the rest of the object.
+// The part size and checksum algorithm of the state are used; s3multipart.WithPartSize is ignored.
+// An upload of a client with WithEncryption is resumed by a client with WithEncryption, and its last full part
+// is uploaded again, since the part that ends the object is encrypted differently.
+func (c *Client) ResumeMultipartUpload(
+	ctx context.Context, state MultipartUploadState, opts ...s3multipart.OptionS3Multipart,
+) (*MultipartUpload, error) {
+	conf := s3multipart.GetS3MultipartConf(opts...)
+	attrs := newUploadAttributes(conf.UploadOptions...)
+	attrs.checksumAlgorithm = state.ChecksumAlgorithm
+	if _, err := newPartHash(attrs.checksumAlgorithm); err != nil {
+		return nil, err
+	}
+	var aead cipher.AEAD
+	if encrypted := hasEncryptionEnvelope(state.Envelope); encrypted || c.encryption != nil {
+		if !encrypted || c.encryption == nil {
+			return nil, fmt.Errorf("%w: the upload and the client must both use WithEncryption", ErrEncryptionNotSupported)
+		}
+		var err error
+		if aead, err = c.encryption.openEnvelope(ctx, state.Envelope); err != nil {
+			return nil, err
+		}
+	}
+	listed, err := c.listParts(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[int32]UploadedPart, len(state.Parts))
+	for _, p := range state.Parts {
+		known[p.PartNumber] = p
+	}
+	resumed := state
+	resumed.Parts = nil
+	for i, p := range listed {
+		part := UploadedPart{
+			PartNumber: aws.ToInt32(p.PartNumber),
+			ETag:       aws.ToString(p.ETag),
+			Checksum:   partChecksum(state.ChecksumAlgorithm, p),
+			Size:       aws.ToInt64(p.Size),
+		}
+		if part.Checksum == "" {
+			if k, ok := known[part.PartNumber]; ok && k.ETag == part.ETag {
+				part.Checksum = k.Checksum
+			}
+		}
+		if part.PartNumber != int32(i+1) || part.Size != state.storedPartSize() || part.Checksum == "" {
+			break
+		}
+		resumed.Parts = append(resumed.Parts, part)
+	}
+	if aead != nil && len(resumed.Parts) > 0 {
+		resumed.Parts = resumed.Parts[:len(resumed.Parts)-1]
+	}
+	return c.newMultipartUpload(ctx, attrs, resumed, aead, conf.Concurrency, conf.LeavePartsOnError), nil
+}
+
+func (c *Client) newMultipartUpload(
+	ctx context.Context, attrs uploadAttributes, state MultipartUploadState, aead cipher.AEAD,
+	concurrency int, leavePartsOnError bool,
+) *MultipartUpload {
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(concurrency)
+	u := &MultipartUpload{
+		client:            c,
+		ctx:               ctx,
+		attrs:             attrs,
+		bucketName:        state.Bucket,
+		key:               state.Key,
+		uploadID:          state.UploadID,
+		partSize:          state.PartSize,
+		checksumAlgorithm: state.ChecksumAlgorithm,
+		leavePartsOnError: leavePartsOnError,
+		envelope:          state.Envelope,
+		aead:              aead,
+		eg:                eg,
+		egCtx:             egCtx,
+		nextPart:          int32(len(state.Parts) + 1),
+		parts:             make(map[int32]UploadedPart, len(state.Parts)),
+	}
+	for _, p := range state.Parts {
+		u.parts[p.PartNumber] = p
+	}
+	u.stopAfterFunc = context.AfterFunc(ctx, func() {
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		_ = u.abortLocked(context.Cause(ctx))
+	})
+	return u
+}
+
+func (c *Client) listParts(ctx context.Context, state MultipartUploadState) (parts []types.Part, err error) {
+	done := c.logOperation(ctx, "ListParts",
+		slog.String("bucket", state.Bucket.String()),
+		slog.String("key", state.Key.String()),
+	)
+	defer func() {
+		done(err)
+	}()
+
+	paginator := s3.NewListPartsPaginator(c.client, &s3.ListPartsInput{
+		Bucket:   state.Bucket.AWSString(),
+		Key:      state.Key.AWSString(),
+		UploadId: aws.String(state.UploadID),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, page.Parts...)
+	}
+	slices.SortFunc(parts, func(a, b types.Part) int {
+		return int(aws.ToInt32(a.PartNumber) - aws.ToInt32(b.PartNumber))
+	})
+	return parts, nil
+}
+
+// UploadID returns the ID of the multipart upload.
+func (u *MultipartUpload) UploadID() string {
+	return u.uploadID
+}
+
+// State returns the serialisable state of the upload. Parts that are still being uploaded are not included.
+func (u *MultipartUpload) State() MultipartUploadState {
+	u.partsMu.Lock()
+	parts := slices.SortedFunc(maps.Values(u.parts), func(a, b UploadedPart) int {
+		return int(a.PartNumber - b.PartNumber)
+	})
+	u.partsMu.Unlock()
+	return MultipartUploadState{
+		Bucket:            u.bucketName,
+		Key:               u.key,
+		UploadID:          u.uploadID,
+		PartSize:          u.partSize,
+		ChecksumAlgorithm: u.checksumAlgorithm,
+		Parts:             parts,
+		Envelope:          u.envelope,
+	}
+}
+
+// Write buffers p and uploads a part each time the buffer reaches the part size.
+// It blocks while the maximum number of parts are being uploaded.
+func (u *MultipartUpload) Write(p []byte) (n int, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.done {
+		if u.result != nil {
+			return 0, u.result
+		}
+		return 0, ErrMultipartUploadClosed
+	}
+	for len(p) > 0 {
+		if u.egCtx.Err() != nil {
+			return n, u.abortLocked(nil)
+		}
+		if u.aead != nil && int64(len(u.buf)) == u.partSize {
+			// more bytes follow, so the buffered part does not end the object
+			if err := u.flushLocked(false); err != nil {
+				return n, u.abortLocked(err)
+			}
+		}
+		if u.buf == nil {
+			u.buf = make([]byte, 0, u.partSize)
+		}
+		k := min(len(p), int(u.partSize)-len(u.buf))
+		u.buf = append(u.buf, p[:k]...)
+		p = p[k:]
+		n += k
+		if u.aead == nil && int64(len(u.buf)) == u.partSize {
+			if err := u.flushLocked(false); err != nil {
+				return n, u.abortLocked(err)
+			}
+		}
+	}
+	return n, nil
+}
+
+// Close uploads the buffered bytes as the last part, waits for all parts and completes the upload.
+// If any part failed, the upload is aborted unless s3multipart.WithLeavePartsOnError is set.
+func (u *MultipartUpload) Close() error {
+	defer u.stopAfterFunc()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.done {
+		return u.result
+	}
+	if len(u.buf) > 0 || u.nextPart == 1 {
+		if err := u.flushLocked(true); err != nil {
+			return u.abortLocked(err)
+		}
+	}
+	if err := u.eg.Wait(); err != nil {
+		return u.abortLocked(err)
+	}
+	if err := u.ctx.Err(); err != nil {
+		return u.abortLocked(context.Cause(u.ctx))
+	}
+	state := u.State()
+	completedParts := make([]types.CompletedPart, len(state.Parts))
+	for i, p := range state.Parts {
+		completedParts[i] = completedPart(u.checksumAlgorithm, p)
+	}
+	input := &s3.CompleteMultipartUploadInput{
+		Bucket:          u.bucketName.AWSString(),
+		Key:             u.key.AWSString(),
+		UploadId:        aws.String(u.uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completedParts},
+	}
+	u.attrs.applyCompleteMultipartUploadInput(input)
+	if _, err := u.client.completeMultipartUpload(u.ctx, input); err != nil {
+		return u.abortLocked(err)
+	}
+	u.done = true
+	return nil
+}
+
+// Abort stops the upload, waits for the parts being uploaded and aborts the multipart upload,
+// regardless of s3multipart.WithLeavePartsOnError.
+func (u *MultipartUpload) Abort() error {
+	defer u.stopAfterFunc()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.done {
+		return ErrMultipartUploadClosed
+	}
+	u.leavePartsOnError = false
+	_ = u.abortLocked(nil)
+	return u.result
+}
+
+// flushLocked uploads the buffer as the next part in the background. last reports whether the part ends the object.
+func (u *MultipartUpload) flushLocked(last bool) error {
+	if u.nextPart > maxUploadParts {
+		return fmt.Errorf("multipart upload exceeds %d parts; increase the part size", maxUploadParts)
+	}
+	partNumber := u.nextPart
+	body := u.buf
+	u.nextPart++
+	u.buf = nil
+	u.eg.Go(func() error {
+		return u.uploadPart(partNumber, body, last)
+	})
+	return nil
+}
+
+func (u *MultipartUpload) uploadPart(partNumber int32, body []byte, last bool) error {
+	if u.aead != nil {
+		body = u.sealPart(partNumber, body, last)
+	}
+	h, err := newPartHash(u.checksumAlgorithm)
+	if err != nil {
+		return err
+	}
+	h.Write(body)
+	checksum := base64.StdEncoding.EncodeToString(h.Sum(nil))
+	input := &s3.UploadPartInput{
+		Bucket:        u.bucketName.AWSString(),
+		Key:           u.key.AWSString(),
+		PartNumber:    aws.Int32(partNumber),
+		UploadId:      aws.String(u.uploadID),
+		Body:          bytes.NewReader(body),
+		ContentLength: aws.Int64(int64(len(body))),
+	}
+	u.attrs.applyUploadPartInput(input)
+	setUploadPartChecksum(input, u.checksumAlgorithm, checksum)
+	res, err := u.client.uploadPart(u.egCtx, input)
+	if err != nil {
+		return err
+	}
+	u.partsMu.Lock()
+	u.parts[partNumber] = UploadedPart{
+		PartNumber: partNumber,
+		ETag:       aws.ToString(res.ETag),
+		Checksum:   checksum,
+		Size:       int64(len(body)),
+	}
+	u.partsMu.Unlock()
+	return nil
+}
+
+// sealPart encrypts the chunks of a part. The part size is a multiple of the chunk size, so the chunk indexes
+// continue those of the previous parts, as in an object encrypted at once.
+func (u *MultipartUpload) sealPart(partNumber int32, body []byte, last bool) []byte {
+	index := uint64(int64(partNumber-1) * u.partSize / encryptionChunkSize)
+	sealed := make([]byte, 0, encryptedSize(int64(len(body))))
+	nonce := make([]byte, u.aead.NonceSize())
+	for {
+		chunk := body[:min(len(body), encryptionChunkSize)]
+		body = body[len(chunk):]
+		sealed = u.aead.Seal(sealed, chunkNonce(nonce, index, last && len(body) == 0), chunk, nil)
+		index++
+		if len(body) == 0 {
+			return sealed
+		}
+	}
+}
+
+// abortLocked marks the upload as done, waits for the parts being uploaded and,
+// unless parts are left for a resume, aborts the multipart upload.
+// cause is returned together with the first part error.
+func (u *MultipartUpload) abortLocked(cause error) error {
+	if u.done {
+		return u.result
+	}
+	u.done = true
+	u.buf = nil
+	err := errors.Join(cause, u.eg.Wait())
+	if err == nil && u.ctx.Err() != nil {
+		err = context.Cause(u.ctx)
+	}
+	if !u.leavePartsOnError {
+		err = errors.Join(err, u.client.AbortMultipartUpload(
+			context.WithoutCancel(u.ctx), u.bucketName, u.key, u.uploadID,
+		))
+	}
+	u.result = err
+	return err
+}
+
+func newPartHash(algorithm types.ChecksumAlgorithm) (gohash.Hash, error) {
+	switch algorithm {
+	case types.ChecksumAlgorithmCrc32:
+		return crc32.NewIEEE(), nil
+	case types.ChecksumAlgorithmCrc32c:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli)), nil
+	case types.ChecksumAlgorithmSha1:
+		return sha1.New(), nil // nolint:gosec
+	case types.ChecksumAlgorithmSha256:
+		return sha256.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported checksum algorithm for multipart upload: %q", algorithm)
+	}
+}
+
+func setUploadPartChecksum(input *s3.UploadPartInput, algorithm types.ChecksumAlgorithm, checksum string) {
+	switch algorithm {
+	case types.ChecksumAlgorithmCrc32:
+		input.ChecksumCRC32 = aws.String(checksum)
+	case types.ChecksumAlgorithmCrc32c:
+		input.ChecksumCRC32C = aws.String(checksum)
+	case types.ChecksumAlgorithmSha1:
+		input.ChecksumSHA1 = aws.String(checksum)
+	case types.ChecksumAlgorithmSha256:
+		input.ChecksumSHA256 = aws.String(checksum)
+	}
+}
+
+func completedPart(algorithm types.ChecksumAlgorithm, p UploadedPart) types.CompletedPart {
+	part := types.CompletedPart{
+		ETag:       aws.String(p.ETag),
+		PartNumber: aws.Int32(p.PartNumber),
+	}
+	switch algorithm {
+	case types.ChecksumAlgorithmCrc32:
+		part.ChecksumCRC32 = aws.String(p.Checksum)
+	case types.ChecksumAlgorithmCrc32c:
+		part.ChecksumCRC32C = aws.String(p.Checksum)
+	case types.ChecksumAlgorithmSha1:
+		part.ChecksumSHA1 = aws.String(p.Checksum)
+	case types.ChecksumAlgorithmSha256:
+		part.ChecksumSHA256 = aws.String(p.Checksum)
+	}
+	return part
+}
+
+func partChecksum(algorithm types.ChecksumAlgorithm, p types.Part) string {
+	switch algorithm {
+	case types.ChecksumAlgorithmCrc32:
+		return aws.ToString(p.ChecksumCRC32)
+	case types.ChecksumAlgorithmCrc32c:
+		return aws.ToString(p.ChecksumCRC32C)
+	case types.ChecksumAlgorithmSha1:
+		return aws.ToString(p.ChecksumSHA1)
+	case types.ChecksumAlgorithmSha256:
+		return aws.ToString(p.ChecksumSHA256)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,202 @@
+package awss3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/88labs/go-utils/aws/awss3/options/s3download"
+)
+
+// ErrObjectChanged is returned by ObjectReader when the object was overwritten after it was opened.
+var ErrObjectChanged = errors.New("ObjectChanged")
+
+// ObjectReader reads an S3 object with ranged GET requests.
+// It implements io.ReadSeekCloser and io.ReaderAt, e.g. for archive/zip, Parquet readers or http.ServeContent.
+//
+// Every request is conditioned on the ETag returned when the object was opened,
+// so reads fail with ErrObjectChanged instead of mixing two versions of the object.
+// Reads are served from a read-ahead buffer of s3download.WithReadAheadSize.
+// ReadAt may be called concurrently, but requests are serialised.
+type ObjectReader struct {
+	client        *Client
+	ctx           context.Context
+	bucketName    BucketName
+	key           Key
+	size          int64
+	etag          string
+	versionID     *string
+	readAheadSize int64
+
+	mu     sync.Mutex
+	offset int64
+	buf    []byte
+	bufOff int64
+	closed bool
+}
+
+// OpenObject opens an object for random access reads.
+// It returns ErrNotFound if the object does not exist.
+func (c *Client) OpenObject(
+	ctx context.Context, bucketName BucketName, key Key, opts ...s3download.OptionS3Download,
+) (r *ObjectReader, err error) {
+	done := c.logOperation(ctx, "OpenObject",
+		slog.String("bucket", bucketName.String()),
+		slog.String("key", key.String()),
+	)
+	defer func() {
+		done(err)
+	}()
+
+	conf := s3download.GetS3DownloadConf(opts...)
+	head, err := c.headObjectInput(ctx, &s3.HeadObjectInput{
+		Bucket:          bucketName.AWSString(),
+		Key:             key.AWSString(),
+		IfMatch:         conf.IfMatch,
+		IfNoneMatch:     conf.IfNoneMatch,
+		IfModifiedSince: conf.IfModifiedSince,
+		VersionId:       conf.VersionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectReader{
+		client:        c,
+		ctx:           ctx,
+		bucketName:    bucketName,
+		key:           key,
+		size:          aws.ToInt64(head.ContentLength),
+		etag:          aws.ToString(head.ETag),
+		versionID:     conf.VersionID,
+		readAheadSize: conf.ReadAheadSize,
+	}, nil
+}
+
+// Size returns the size of the object.
+func (r *ObjectReader) Size() int64 {
+	return r.size
+}
+
+// ETag returns the ETag of the object the reader is pinned to.
+func (r *ObjectReader) ETag() string {
+	return r.etag
+}
+
+// Read reads up to len(p) bytes from the current offset.
+func (r *ObjectReader) Read(p []byte) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n, err = r.readAtLocked(p, r.offset)
+	r.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		// io.Reader may return EOF on the next call
+		err = nil
+	}
+	return n, err
+}
+
+// ReadAt reads len(p) bytes from offset off. It does not change the offset used by Read and Seek.
+func (r *ObjectReader) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset: %d", off)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for n < len(p) && err == nil {
+		var m int
+		m, err = r.readAtLocked(p[n:], off+int64(n))
+		n += m
+	}
+	return n, err
+}
+
+// Seek sets the offset for the next Read.
+func (r *ObjectReader) Seek(offset int64, whence int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position: %d", offset)
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+// Close releases the read-ahead buffer. Reads after Close return os.ErrClosed.
+func (r *ObjectReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	r.buf = nil
+	return nil
+}
+
+// readAtLocked reads from the read-ahead buffer, filling it with a ranged GET when off is outside it.
+func (r *ObjectReader) readAtLocked(p []byte, off int64) (int, error) {
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if off < r.bufOff || off >= r.bufOff+int64(len(r.buf)) {
+		length := min(max(int64(len(p)), r.readAheadSize), r.size-off)
+		if err := r.fillLocked(off, length); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf[off-r.bufOff:])
+	if off+int64(n) == r.size {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *ObjectReader) fillLocked(off, length int64) (err error) {
+	res, err := r.client.getObjectRange(r.ctx, &s3.GetObjectInput{
+		Bucket:    r.bucketName.AWSString(),
+		Key:       r.key.AWSString(),
+		Range:     aws.String(httpRange(off, length)),
+		IfMatch:   aws.String(r.etag),
+		VersionId: r.versionID,
+	})
+	if err != nil {
+		if errors.Is(err, ErrPreconditionFailed) || errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("%w: %s", ErrObjectChanged, r.key)
+		}
+		return err
+	}
+	defer func() {
+		if closeErr := res.Body.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(res.Body, buf); err != nil {
+		return err
+	}
+	r.buf = buf
+	r.bufOff = off
+	return nil
+}
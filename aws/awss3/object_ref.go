@@ -0,0 +1,392 @@
+package awss3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/transfermanager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/88labs/go-utils/aws/awsconfig"
+	"github.com/88labs/go-utils/aws/awss3/options/s3copy"
+	"github.com/88labs/go-utils/aws/awss3/options/s3delete"
+	"github.com/88labs/go-utils/aws/awss3/options/s3download"
+	"github.com/88labs/go-utils/aws/awss3/options/s3head"
+	"github.com/88labs/go-utils/aws/awss3/options/s3list"
+	"github.com/88labs/go-utils/aws/awss3/options/s3presigned"
+	"github.com/88labs/go-utils/aws/awss3/options/s3upload"
+)
+
+// ErrInvalidObjectRef is returned when a string cannot be parsed into an ObjectRef.
+var ErrInvalidObjectRef = errors.New("InvalidObjectRef")
+
+const (
+	s3URIScheme = "s3://"
+	// directoryBucketSuffix ends the names of S3 Express One Zone directory buckets.
+	directoryBucketSuffix = "--x-s3"
+)
+
+// ObjectRef is a bucket and a key parsed from an s3:// URI, an S3 URL or an ARN.
+// The bucket of an access point is its ARN, which every Client operation accepts in place of a bucket name.
+// Directory buckets of S3 Express One Zone are referenced by name, as any other bucket.
+type ObjectRef struct {
+	bucket BucketName
+	key    Key
+	region awsconfig.Region
+}
+
+// NewObjectRef returns an ObjectRef to key in bucketName.
+func NewObjectRef(bucketName BucketName, key Key) ObjectRef {
+	return ObjectRef{bucket: bucketName, key: key}
+}
+
+// ParseObjectRef parses an s3:// URI, an http(s) URL or an ARN, detected from the prefix of s.
+func ParseObjectRef(s string) (ObjectRef, error) {
+	switch {
+	case strings.HasPrefix(s, s3URIScheme):
+		return ParseS3URI(s)
+	case strings.HasPrefix(s, "arn:"):
+		return ParseARN(s)
+	default:
+		return ParseURL(s)
+	}
+}
+
+// ParseS3URI parses a URI of the form s3://bucket/key.
+// As in the AWS CLI, the bucket can be an access point ARN: s3://arn:aws:s3:region:account:accesspoint/name/key.
+func ParseS3URI(uri string) (ObjectRef, error) {
+	rest, ok := strings.CutPrefix(uri, s3URIScheme)
+	if !ok {
+		return ObjectRef{}, fmt.Errorf("%w: %q is not an s3:// URI", ErrInvalidObjectRef, uri)
+	}
+	if strings.HasPrefix(rest, "arn:") {
+		ref, key, err := parseAccessPointARN(rest)
+		if err != nil {
+			return ObjectRef{}, err
+		}
+		ref.key = Key(key)
+		return ref, nil
+	}
+	bucket, key, _ := strings.Cut(rest, "/")
+	if bucket == "" {
+		return ObjectRef{}, fmt.Errorf("%w: %q has no bucket", ErrInvalidObjectRef, uri)
+	}
+	return ObjectRef{bucket: BucketName(bucket), key: Key(key)}, nil
+}
+
+// ParseARN parses the ARN of a bucket, an object, an access point or an S3 Express directory bucket:
+//
+//	arn:aws:s3:::bucket/key
+//	arn:aws:s3:region:account:accesspoint/name/object/key
+//	arn:aws:s3-object-lambda:region:account:accesspoint/name/object/key
+//	arn:aws:s3-outposts:region:account:outpost/id/accesspoint/name/object/key
+//	arn:aws:s3express:region:account:bucket/name--zone--x-s3/key
+func ParseARN(s string) (ObjectRef, error) {
+	a, err := arn.Parse(s)
+	if err != nil {
+		return ObjectRef{}, fmt.Errorf("%w: %w", ErrInvalidObjectRef, err)
+	}
+	switch a.Service {
+	case "s3":
+		if a.Region == "" && a.AccountID == "" {
+			bucket, key, _ := strings.Cut(a.Resource, "/")
+			if bucket == "" {
+				return ObjectRef{}, fmt.Errorf("%w: %q has no bucket", ErrInvalidObjectRef, s)
+			}
+			return ObjectRef{bucket: BucketName(bucket), key: Key(key)}, nil
+		}
+		return parseAccessPointObjectARN(s)
+	case "s3-object-lambda", "s3-outposts":
+		return parseAccessPointObjectARN(s)
+	case "s3express":
+		resource, ok := strings.CutPrefix(a.Resource, "bucket/")
+		if !ok {
+			return ObjectRef{}, fmt.Errorf("%w: %q is not a directory bucket ARN", ErrInvalidObjectRef, s)
+		}
+		bucket, key, _ := strings.Cut(resource, "/")
+		if bucket == "" {
+			return ObjectRef{}, fmt.Errorf("%w: %q has no bucket", ErrInvalidObjectRef, s)
+		}
+		return ObjectRef{bucket: BucketName(bucket), key: Key(key), region: awsconfig.Region(a.Region)}, nil
+	default:
+		return ObjectRef{}, fmt.Errorf("%w: %q is not an S3 ARN", ErrInvalidObjectRef, s)
+	}
+}
+
+// parseAccessPointObjectARN parses an access point ARN followed by an optional /object/key.
+func parseAccessPointObjectARN(s string) (ObjectRef, error) {
+	ref, rest, err := parseAccessPointARN(s)
+	if err != nil {
+		return ObjectRef{}, err
+	}
+	if rest != "" {
+		key, ok := strings.CutPrefix(rest, "object/")
+		if !ok {
+			return ObjectRef{}, fmt.Errorf("%w: %q has no object/ before the key", ErrInvalidObjectRef, s)
+		}
+		ref.key = Key(key)
+	}
+	return ref, nil
+}
+
+// parseAccessPointARN splits s after the name of the access point and returns the rest without the leading slash.
+func parseAccessPointARN(s string) (ObjectRef, string, error) {
+	a, err := arn.Parse(s)
+	if err != nil {
+		return ObjectRef{}, "", fmt.Errorf("%w: %w", ErrInvalidObjectRef, err)
+	}
+	segments := strings.Split(a.Resource, "/")
+	for i, segment := range segments {
+		if segment != "accesspoint" {
+			continue
+		}
+		if i+1 >= len(segments) || segments[i+1] == "" {
+			break
+		}
+		a.Resource = strings.Join(segments[:i+2], "/")
+		ref := ObjectRef{bucket: BucketName(a.String()), region: awsconfig.Region(a.Region)}
+		return ref, strings.Join(segments[i+2:], "/"), nil
+	}
+	return ObjectRef{}, "", fmt.Errorf("%w: %q is not an access point ARN", ErrInvalidObjectRef, s)
+}
+
+// ParseURL parses the URL of an object:
+//
+//	https://bucket.s3.region.amazonaws.com/key (virtual-hosted style, also s3-region and dualstack)
+//	https://s3.region.amazonaws.com/bucket/key (path style)
+//	https://name-account.s3-accesspoint.region.amazonaws.com/key (access point)
+//	https://bucket--zone--x-s3.s3express-zone.region.amazonaws.com/key (S3 Express One Zone)
+//
+// Hosts other than amazonaws.com, such as a local MinIO, are parsed as path style. s3:// URIs are accepted too.
+func ParseURL(rawURL string) (ObjectRef, error) {
+	if strings.HasPrefix(rawURL, s3URIScheme) {
+		return ParseS3URI(rawURL)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ObjectRef{}, fmt.Errorf("%w: %w", ErrInvalidObjectRef, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ObjectRef{}, fmt.Errorf("%w: %q is not an http(s) URL", ErrInvalidObjectRef, rawURL)
+	}
+	host := strings.ToLower(u.Hostname())
+	path := strings.TrimPrefix(u.Path, "/")
+
+	partition := "aws"
+	domain, ok := strings.CutSuffix(host, ".amazonaws.com")
+	if !ok {
+		if domain, ok = strings.CutSuffix(host, ".amazonaws.com.cn"); ok {
+			partition = "aws-cn"
+		}
+	}
+	if !ok {
+		return parsePathStyle(rawURL, path, "")
+	}
+
+	// the service label is searched from the right, as bucket names can look like one
+	labels := strings.Split(domain, ".")
+	i := len(labels) - 1
+	for ; i >= 0; i-- {
+		if labels[i] == "s3" || strings.HasPrefix(labels[i], "s3-") || strings.HasPrefix(labels[i], "s3express") {
+			break
+		}
+	}
+	if i < 0 {
+		return ObjectRef{}, fmt.Errorf("%w: %q is not an S3 URL", ErrInvalidObjectRef, rawURL)
+	}
+	label := labels[i]
+	region := urlRegion(label, labels[i+1:])
+	virtualHost := strings.Join(labels[:i], ".")
+	switch {
+	case label == "s3-accesspoint" || label == "s3-object-lambda":
+		service := "s3"
+		if label == "s3-object-lambda" {
+			service = label
+		}
+		cut := strings.LastIndex(virtualHost, "-")
+		if cut <= 0 || region == "" {
+			return ObjectRef{}, fmt.Errorf("%w: %q is not an access point URL", ErrInvalidObjectRef, rawURL)
+		}
+		a := arn.ARN{
+			Partition: partition,
+			Service:   service,
+			Region:    region.String(),
+			AccountID: virtualHost[cut+1:],
+			Resource:  "accesspoint/" + virtualHost[:cut],
+		}
+		return ObjectRef{bucket: BucketName(a.String()), key: Key(path), region: region}, nil
+	case virtualHost == "":
+		return parsePathStyle(rawURL, path, region)
+	default:
+		return ObjectRef{bucket: BucketName(virtualHost), key: Key(path), region: region}, nil
+	}
+}
+
+func parsePathStyle(rawURL, path string, region awsconfig.Region) (ObjectRef, error) {
+	bucket, key, _ := strings.Cut(path, "/")
+	if bucket == "" {
+		return ObjectRef{}, fmt.Errorf("%w: %q has no bucket", ErrInvalidObjectRef, rawURL)
+	}
+	return ObjectRef{bucket: BucketName(bucket), key: Key(key), region: region}, nil
+}
+
+// urlRegion returns the region of an S3 endpoint from its service label (s3, s3-region, s3express-zone, ...)
+// and the labels after it. It is empty for the global endpoint s3.amazonaws.com.
+func urlRegion(label string, rest []string) awsconfig.Region {
+	for _, prefix := range []string{"s3-website-", "s3-"} {
+		if region, ok := strings.CutPrefix(label, prefix); ok && len(rest) == 0 && region != "external-1" {
+			return awsconfig.Region(region)
+		}
+	}
+	for i := len(rest) - 1; i >= 0; i-- {
+		if rest[i] != "dualstack" {
+			return awsconfig.Region(rest[i])
+		}
+	}
+	return ""
+}
+
+// Bucket returns the bucket name, or the ARN of an access point.
+func (r ObjectRef) Bucket() BucketName {
+	return r.bucket
+}
+
+// Key returns the object key. It is empty for a reference to a bucket.
+func (r ObjectRef) Key() Key {
+	return r.key
+}
+
+// Region returns the region in the parsed URL or ARN. It is empty when the reference does not carry a region.
+func (r ObjectRef) Region() awsconfig.Region {
+	return r.region
+}
+
+// IsAccessPoint reports whether the bucket is an access point ARN.
+func (r ObjectRef) IsAccessPoint() bool {
+	return r.bucket.isAccessPoint()
+}
+
+// IsDirectoryBucket reports whether the bucket is an S3 Express One Zone directory bucket.
+func (r ObjectRef) IsDirectoryBucket() bool {
+	return r.bucket.isDirectoryBucket()
+}
+
+// WithKey returns a reference to key in the same bucket.
+func (r ObjectRef) WithKey(key Key) ObjectRef {
+	r.key = key
+	return r
+}
+
+// String returns the reference as an s3:// URI, or as an object ARN for access points.
+// The result is parsed back by ParseObjectRef.
+func (r ObjectRef) String() string {
+	if r.IsAccessPoint() {
+		if r.key == "" {
+			return r.bucket.String()
+		}
+		return r.bucket.String() + "/object/" + r.key.String()
+	}
+	if r.key == "" {
+		return s3URIScheme + r.bucket.String()
+	}
+	return s3URIScheme + r.bucket.String() + "/" + r.key.String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r ObjectRef) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler with ParseObjectRef, so that configs can hold any of the forms.
+func (r *ObjectRef) UnmarshalText(text []byte) error {
+	ref, err := ParseObjectRef(string(text))
+	if err != nil {
+		return err
+	}
+	*r = ref
+	return nil
+}
+
+func (k BucketName) isAccessPoint() bool {
+	return arn.IsARN(k.String())
+}
+
+func (k BucketName) isDirectoryBucket() bool {
+	return strings.HasSuffix(k.String(), directoryBucketSuffix)
+}
+
+// PutObjectRef is PutObject for an ObjectRef.
+func (c *Client) PutObjectRef(
+	ctx context.Context, ref ObjectRef, body io.Reader, opts ...s3upload.OptionS3Upload,
+) (*s3.PutObjectOutput, error) {
+	return c.PutObject(ctx, ref.Bucket(), ref.Key(), body, opts...)
+}
+
+// UploadManagerRef is UploadManager for an ObjectRef.
+func (c *Client) UploadManagerRef(
+	ctx context.Context, ref ObjectRef, body io.Reader, opts ...s3upload.OptionS3Upload,
+) (*transfermanager.UploadObjectOutput, error) {
+	return c.UploadManager(ctx, ref.Bucket(), ref.Key(), body, opts...)
+}
+
+// HeadObjectRef is HeadObject for an ObjectRef.
+func (c *Client) HeadObjectRef(
+	ctx context.Context, ref ObjectRef, opts ...s3head.OptionS3Head,
+) (*s3.HeadObjectOutput, error) {
+	return c.HeadObject(ctx, ref.Bucket(), ref.Key(), opts...)
+}
+
+// GetObjectWriterRef is GetObjectWriter for an ObjectRef.
+func (c *Client) GetObjectWriterRef(
+	ctx context.Context, ref ObjectRef, w io.Writer, opts ...s3download.OptionS3Download,
+) error {
+	return c.GetObjectWriter(ctx, ref.Bucket(), ref.Key(), w, opts...)
+}
+
+// OpenObjectRef is OpenObject for an ObjectRef.
+func (c *Client) OpenObjectRef(
+	ctx context.Context, ref ObjectRef, opts ...s3download.OptionS3Download,
+) (*ObjectReader, error) {
+	return c.OpenObject(ctx, ref.Bucket(), ref.Key(), opts...)
+}
+
+// DeleteObjectRef is DeleteObject for an ObjectRef.
+func (c *Client) DeleteObjectRef(
+	ctx context.Context, ref ObjectRef, opts ...s3delete.OptionS3Delete,
+) (*s3.DeleteObjectOutput, error) {
+	return c.DeleteObject(ctx, ref.Bucket(), ref.Key(), opts...)
+}
+
+// ListObjectsRef is ListObjects for the objects under the key of ref, which is used as the prefix.
+// s3list.WithPrefix in opts takes precedence.
+func (c *Client) ListObjectsRef(
+	ctx context.Context, ref ObjectRef, opts ...s3list.OptionS3List,
+) (Objects, error) {
+	return c.ListObjects(ctx, ref.Bucket(), append([]s3list.OptionS3List{s3list.WithPrefix(ref.Key().String())}, opts...)...)
+}
+
+// PresignRef is Presign for an ObjectRef.
+func (c *Client) PresignRef(
+	ctx context.Context, ref ObjectRef, opts ...s3presigned.OptionS3Presigned,
+) (string, error) {
+	return c.Presign(ctx, ref.Bucket(), ref.Key(), opts...)
+}
+
+// CopyObjectRef is CopyObject from src to dest.
+func (c *Client) CopyObjectRef(
+	ctx context.Context, src, dest ObjectRef, opts ...s3copy.OptionS3Copy,
+) (*CopyObjectResult, error) {
+	return c.CopyObject(ctx, src.Bucket(), src.Key(), dest.Bucket(), dest.Key(), opts...)
+}
+
+// WaitUntilObjectExistsRef is WaitUntilObjectExists for an ObjectRef.
+func (c *Client) WaitUntilObjectExistsRef(
+	ctx context.Context, ref ObjectRef, opts ...s3head.OptionS3Head,
+) (*s3.HeadObjectOutput, error) {
+	return c.WaitUntilObjectExists(ctx, ref.Bucket(), ref.Key(), opts...)
+}
@@ -0,0 +1,171 @@
+package awss3
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/88labs/go-utils/aws/awss3/options/s3copy"
+)
+
+// GetObjectTags returns the tags of an object.
+// It returns ErrNotFound if the object does not exist.
+func (c *Client) GetObjectTags(ctx context.Context, bucketName BucketName, key Key) (tags map[string]string, err error) {
+	done := c.logOperation(ctx, "GetObjectTags",
+		slog.String("bucket", bucketName.String()),
+		slog.String("key", key.String()),
+	)
+	defer func() {
+		done(err, slog.Int("tag_count", len(tags)))
+	}()
+
+	res, err := c.client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+		Bucket: bucketName.AWSString(),
+		Key:    key.AWSString(),
+	})
+	if err != nil {
+		return nil, objectReadError(err)
+	}
+	return tagSetMap(res.TagSet), nil
+}
+
+// PutObjectTags replaces all tags of an object with tags.
+// It returns ErrNotFound if the object does not exist.
+func (c *Client) PutObjectTags(
+	ctx context.Context, bucketName BucketName, key Key, tags map[string]string,
+) (err error) {
+	done := c.logOperation(ctx, "PutObjectTags",
+		slog.String("bucket", bucketName.String()),
+		slog.String("key", key.String()),
+		slog.Int("tag_count", len(tags)),
+	)
+	defer func() {
+		done(err)
+	}()
+
+	tagSet := make([]types.Tag, 0, len(tags))
+	for k, v := range tags {
+		tagSet = append(tagSet, types.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	if _, err := c.client.PutObjectTagging(ctx, &s3.PutObjectTaggingInput{
+		Bucket:  bucketName.AWSString(),
+		Key:     key.AWSString(),
+		Tagging: &types.Tagging{TagSet: tagSet},
+	}); err != nil {
+		return objectReadError(err)
+	}
+	return nil
+}
+
+// DeleteObjectTags removes all tags of an object.
+// It returns ErrNotFound if the object does not exist.
+func (c *Client) DeleteObjectTags(ctx context.Context, bucketName BucketName, key Key) (err error) {
+	done := c.logOperation(ctx, "DeleteObjectTags",
+		slog.String("bucket", bucketName.String()),
+		slog.String("key", key.String()),
+	)
+	defer func() {
+		done(err)
+	}()
+
+	if _, err := c.client.DeleteObjectTagging(ctx, &s3.DeleteObjectTaggingInput{
+		Bucket: bucketName.AWSString(),
+		Key:    key.AWSString(),
+	}); err != nil {
+		return objectReadError(err)
+	}
+	return nil
+}
+
+// ReplaceMetadata replaces the user metadata of an object in place by copying the object onto itself.
+// Content headers, tags, the storage class and the server-side encryption of the object are kept.
+// The copy is conditioned on the current ETag, so ErrPreconditionFailed is returned if the object
+// changes in the meantime. Objects larger than 5 GiB are copied with parallel UploadPartCopy requests.
+// It returns ErrNotFound if the object does not exist.
+func (c *Client) ReplaceMetadata(
+	ctx context.Context, bucketName BucketName, key Key, metadata map[string]string,
+) (res *CopyObjectResult, err error) {
+	done := c.logOperation(ctx, "ReplaceMetadata",
+		slog.String("bucket", bucketName.String()),
+		slog.String("key", key.String()),
+		slog.Int("metadata_count", len(metadata)),
+	)
+	defer func() {
+		done(err)
+	}()
+
+	head, err := c.headObject(ctx, bucketName, key)
+	if err != nil {
+		return nil, err
+	}
+	copySource := key.bucketJoinEscapedAWSString(bucketName)
+	kmsKeyID := head.SSEKMSKeyId
+	if head.ServerSideEncryption != types.ServerSideEncryptionAwsKms {
+		kmsKeyID = nil
+	}
+	// the envelope of an object encrypted on the client must be kept to decrypt it
+	metadata = withEncryptionEnvelope(metadata, head.Metadata)
+	size := c.storedSize(head)
+	if size > s3copy.MaxCopyObjectSize {
+		conf := s3copy.GetS3CopyConf()
+		input := &s3.CreateMultipartUploadInput{
+			Bucket:               bucketName.AWSString(),
+			Key:                  key.AWSString(),
+			Metadata:             metadata,
+			CacheControl:         head.CacheControl,
+			ContentDisposition:   head.ContentDisposition,
+			ContentEncoding:      head.ContentEncoding,
+			ContentLanguage:      head.ContentLanguage,
+			ContentType:          head.ContentType,
+			Expires:              head.Expires,
+			StorageClass:         head.StorageClass,
+			ServerSideEncryption: head.ServerSideEncryption,
+			SSEKMSKeyId:          kmsKeyID,
+		}
+		if aws.ToInt32(head.TagCount) > 0 {
+			tags, err := c.client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+				Bucket: bucketName.AWSString(),
+				Key:    key.AWSString(),
+			})
+			if err != nil {
+				return nil, objectReadError(err)
+			}
+			input.Tagging = aws.String(encodeTagSet(tags.TagSet))
+		}
+		res, err = c.multipartCopy(ctx, input, copySource, head.ETag, size, conf.PartSize, conf.Concurrency)
+		if err != nil {
+			return nil, objectReadError(err)
+		}
+		return res, nil
+	}
+
+	out, err := c.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:               bucketName.AWSString(),
+		Key:                  key.AWSString(),
+		CopySource:           copySource,
+		CopySourceIfMatch:    head.ETag,
+		MetadataDirective:    types.MetadataDirectiveReplace,
+		Metadata:             metadata,
+		CacheControl:         head.CacheControl,
+		ContentDisposition:   head.ContentDisposition,
+		ContentEncoding:      head.ContentEncoding,
+		ContentLanguage:      head.ContentLanguage,
+		ContentType:          head.ContentType,
+		Expires:              head.Expires,
+		StorageClass:         head.StorageClass,
+		ServerSideEncryption: head.ServerSideEncryption,
+		SSEKMSKeyId:          kmsKeyID,
+		TaggingDirective:     types.TaggingDirectiveCopy,
+	})
+	if err != nil {
+		return nil, objectReadError(err)
+	}
+	res = &CopyObjectResult{VersionID: aws.ToString(out.VersionId)}
+	if out.CopyObjectResult != nil {
+		res.ETag = aws.ToString(out.CopyObjectResult.ETag)
+	}
+	return res, nil
+}
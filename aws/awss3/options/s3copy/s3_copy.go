@@ -0,0 +1,179 @@
+package s3copy
+
+import "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+type OptionS3Copy interface {
+	Apply(*confS3Copy)
+}
+
+type confS3Copy struct {
+	// MetadataDirective specifies whether the metadata is copied from the source object
+	// or replaced with the metadata provided in the request. Default is COPY.
+	MetadataDirective types.MetadataDirective
+	// Metadata is the user metadata stored with the destination object when metadata is replaced.
+	Metadata map[string]string
+	// ContentType is the Content-Type of the destination object when metadata is replaced.
+	ContentType *string
+	// TaggingDirective specifies whether the tags are copied from the source object
+	// or replaced with the tags provided in the request. Default is COPY.
+	TaggingDirective types.TaggingDirective
+	// Tags are the tags of the destination object when tags are replaced.
+	Tags map[string]string
+	// StorageClass is the storage class of the destination object.
+	// If unset, the storage class of the source object is kept.
+	StorageClass types.StorageClass
+	// MultipartThreshold is the source object size above which UploadPartCopy is used
+	// instead of a single CopyObject request.
+	MultipartThreshold int64
+	// PartSize is the size of each UploadPartCopy part.
+	PartSize int64
+	// Concurrency is the maximum number of UploadPartCopy requests sent at the same time.
+	Concurrency int
+}
+
+const (
+	// MaxCopyObjectSize is the largest object that can be copied with a single CopyObject request.
+	MaxCopyObjectSize int64 = 5 * 1024 * 1024 * 1024
+	// MinPartSize is the smallest part size accepted by S3 for all but the last part.
+	MinPartSize int64 = 5 * 1024 * 1024
+)
+
+// nolint:revive
+func GetS3CopyConf(opts ...OptionS3Copy) confS3Copy {
+	// default options
+	c := confS3Copy{
+		MetadataDirective:  types.MetadataDirectiveCopy,
+		TaggingDirective:   types.TaggingDirectiveCopy,
+		MultipartThreshold: MaxCopyObjectSize,
+		PartSize:           64 * 1024 * 1024,
+		Concurrency:        5,
+	}
+	for _, opt := range opts {
+		opt.Apply(&c)
+	}
+	return c
+}
+
+type OptionMetadataDirective types.MetadataDirective
+
+func (o OptionMetadataDirective) Apply(c *confS3Copy) {
+	c.MetadataDirective = types.MetadataDirective(o)
+}
+
+// WithMetadataDirective
+// Specifies whether the metadata is copied from the source object (COPY, default)
+// or replaced with the metadata provided in the request (REPLACE).
+// When metadata is replaced, user metadata and content headers that are not provided are dropped.
+func WithMetadataDirective(directive types.MetadataDirective) OptionMetadataDirective {
+	return OptionMetadataDirective(directive)
+}
+
+type OptionMetadata map[string]string
+
+func (o OptionMetadata) Apply(c *confS3Copy) {
+	c.Metadata = o
+	c.MetadataDirective = types.MetadataDirectiveReplace
+}
+
+// WithMetadata
+// Replaces the user metadata of the destination object.
+// This implies the REPLACE metadata directive.
+func WithMetadata(metadata map[string]string) OptionMetadata {
+	return OptionMetadata(metadata)
+}
+
+type OptionContentType string
+
+func (o OptionContentType) Apply(c *confS3Copy) {
+	v := string(o)
+	c.ContentType = &v
+	c.MetadataDirective = types.MetadataDirectiveReplace
+}
+
+// WithContentType
+// Sets the Content-Type of the destination object.
+// This implies the REPLACE metadata directive, so combine it with WithMetadata
+// to keep user metadata.
+func WithContentType(contentType string) OptionContentType {
+	return OptionContentType(contentType)
+}
+
+type OptionTaggingDirective types.TaggingDirective
+
+func (o OptionTaggingDirective) Apply(c *confS3Copy) {
+	c.TaggingDirective = types.TaggingDirective(o)
+}
+
+// WithTaggingDirective
+// Specifies whether the tags are copied from the source object (COPY, default)
+// or replaced with the tags provided in the request (REPLACE).
+func WithTaggingDirective(directive types.TaggingDirective) OptionTaggingDirective {
+	return OptionTaggingDirective(directive)
+}
+
+type OptionTags map[string]string
+
+func (o OptionTags) Apply(c *confS3Copy) {
+	c.Tags = o
+	c.TaggingDirective = types.TaggingDirectiveReplace
+}
+
+// WithTags
+// Replaces the tags of the destination object.
+// This implies the REPLACE tagging directive.
+func WithTags(tags map[string]string) OptionTags {
+	return OptionTags(tags)
+}
+
+type OptionStorageClass types.StorageClass
+
+func (o OptionStorageClass) Apply(c *confS3Copy) {
+	c.StorageClass = types.StorageClass(o)
+}
+
+// WithStorageClass
+// Sets the storage class of the destination object.
+// If unset, the storage class of the source object is kept.
+func WithStorageClass(storageClass types.StorageClass) OptionStorageClass {
+	return OptionStorageClass(storageClass)
+}
+
+type OptionMultipartThreshold int64
+
+func (o OptionMultipartThreshold) Apply(c *confS3Copy) {
+	c.MultipartThreshold = min(int64(o), MaxCopyObjectSize)
+}
+
+// WithMultipartThreshold
+// Sets the source object size above which the copy is split into parallel UploadPartCopy requests.
+// Default and maximum is 5 GiB, the largest size supported by a single CopyObject request.
+func WithMultipartThreshold(threshold int64) OptionMultipartThreshold {
+	return OptionMultipartThreshold(threshold)
+}
+
+type OptionPartSize int64
+
+func (o OptionPartSize) Apply(c *confS3Copy) {
+	c.PartSize = max(int64(o), MinPartSize)
+}
+
+// WithPartSize
+// Sets the size of each UploadPartCopy part. Default is 64 MiB and the minimum is 5 MiB.
+// The part size is increased automatically when the object would need more than 10,000 parts.
+func WithPartSize(partSize int64) OptionPartSize {
+	return OptionPartSize(partSize)
+}
+
+type OptionConcurrency int
+
+func (o OptionConcurrency) Apply(c *confS3Copy) {
+	if o > 0 {
+		c.Concurrency = int(o)
+	}
+}
+
+// WithConcurrency
+// Sets the maximum number of UploadPartCopy requests sent at the same time. Default is 5.
+func WithConcurrency(concurrency int) OptionConcurrency {
+	return OptionConcurrency(concurrency)
+}
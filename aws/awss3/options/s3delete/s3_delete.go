@@ -0,0 +1,83 @@
+package s3delete
+
+type OptionS3Delete interface {
+	Apply(*confS3Delete)
+}
+
+type confS3Delete struct {
+	// Concurrency is the maximum number of DeleteObjects requests sent at the same time.
+	Concurrency int
+	// DryRun reports the keys that would be deleted without deleting them.
+	DryRun bool
+	// VersionID selects the version deleted by DeleteObject.
+	VersionID *string
+	// IfMatch is the ETag the object must match (If-Match) for DeleteObject.
+	IfMatch *string
+}
+
+// nolint:revive
+func GetS3DeleteConf(opts ...OptionS3Delete) confS3Delete {
+	// default options
+	c := confS3Delete{
+		Concurrency: 5,
+	}
+	for _, opt := range opts {
+		opt.Apply(&c)
+	}
+	return c
+}
+
+type OptionConcurrency int
+
+func (o OptionConcurrency) Apply(c *confS3Delete) {
+	if o > 0 {
+		c.Concurrency = int(o)
+	}
+}
+
+// WithConcurrency
+// Sets the maximum number of DeleteObjects requests sent at the same time.
+// Each request deletes up to 1,000 keys. Default is 5. Values less than 1 are ignored.
+func WithConcurrency(concurrency int) OptionConcurrency {
+	return OptionConcurrency(concurrency)
+}
+
+type OptionDryRun bool
+
+func (o OptionDryRun) Apply(c *confS3Delete) {
+	c.DryRun = bool(o)
+}
+
+// WithDryRun
+// When enabled, DeleteObjects and DeletePrefix only report the keys that would be deleted and
+// returns them as successful results without sending any delete request.
+func WithDryRun(dryRun bool) OptionDryRun {
+	return OptionDryRun(dryRun)
+}
+
+type OptionVersionID string
+
+func (o OptionVersionID) Apply(c *confS3Delete) {
+	v := string(o)
+	c.VersionID = &v
+}
+
+// WithVersionID
+// Permanently delete a specific version of the object. Applies to DeleteObject.
+func WithVersionID(versionID string) OptionVersionID {
+	return OptionVersionID(versionID)
+}
+
+type OptionIfMatch string
+
+func (o OptionIfMatch) Apply(c *confS3Delete) {
+	v := string(o)
+	c.IfMatch = &v
+}
+
+// WithIfMatch
+// Delete the object only if its ETag matches, otherwise awss3.ErrPreconditionFailed is returned.
+// Applies to DeleteObject.
+func WithIfMatch(etag string) OptionIfMatch {
+	return OptionIfMatch(etag)
+}
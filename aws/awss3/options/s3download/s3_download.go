@@ -1,5 +1,11 @@
 package s3download
 
+import (
+	"time"
+
+	"github.com/88labs/go-utils/aws/awss3/options/s3progress"
+)
+
 type OptionS3Download interface {
 	Apply(*confS3Download)
 }
@@ -7,8 +13,32 @@ type OptionS3Download interface {
 type confS3Download struct {
 	// Sets the function used to replace file names in downloaded files.
 	FileNameReplacer FileNameReplacerFunc
+	// ReadAheadSize is the minimum number of bytes fetched by each ranged GET of an object reader.
+	ReadAheadSize int64
+	// ProgressListener receives the progress events of DownloadFiles and DownloadFilesParallel.
+	ProgressListener s3progress.Listener
+	// Concurrency is the maximum number of objects downloaded at the same time by the parallel downloads.
+	Concurrency int
+	// SkipExisting skips keys whose file already exists with the same size and ETag.
+	SkipExisting bool
+
+	// Preconditions and version of single-object reads (GetObjectWriter, GetObjectRange, OpenObject).
+	// IfMatch is the ETag the object must match (If-Match).
+	IfMatch *string
+	// IfNoneMatch is the ETag the object must not match (If-None-Match).
+	IfNoneMatch *string
+	// IfModifiedSince requires the object to be modified after the time (If-Modified-Since).
+	IfModifiedSince *time.Time
+	// VersionID selects a specific version of the object.
+	VersionID *string
 }
 
+// DefaultConcurrency is the default number of objects downloaded at the same time by the parallel downloads.
+const DefaultConcurrency = 10
+
+// DefaultReadAheadSize is the default minimum number of bytes fetched by each ranged GET of an object reader.
+const DefaultReadAheadSize int64 = 1024 * 1024
+
 type FileNameReplacerFunc func(S3Key string, baseFileName string) string
 type OptionFileNameReplacer FileNameReplacerFunc
 
@@ -24,9 +54,123 @@ func WithFileNameReplacerFunc(fileNameReplacerFunc FileNameReplacerFunc) OptionF
 
 // nolint:revive
 func GetS3DownloadConf(opts ...OptionS3Download) confS3Download {
-	var c confS3Download
+	c := confS3Download{
+		ReadAheadSize: DefaultReadAheadSize,
+		Concurrency:   DefaultConcurrency,
+	}
 	for _, opt := range opts {
 		opt.Apply(&c)
 	}
 	return c
 }
+
+type OptionReadAheadSize int64
+
+func (o OptionReadAheadSize) Apply(c *confS3Download) {
+	c.ReadAheadSize = max(int64(o), 0)
+}
+
+// WithReadAheadSize
+// Sets the minimum number of bytes fetched by each ranged GET of awss3.OpenObject. Default is 1 MiB.
+// Larger values reduce the number of requests for sequential reads; 0 fetches exactly what is read.
+func WithReadAheadSize(size int64) OptionReadAheadSize {
+	return OptionReadAheadSize(size)
+}
+
+type OptionIfMatch string
+
+func (o OptionIfMatch) Apply(c *confS3Download) {
+	v := string(o)
+	c.IfMatch = &v
+}
+
+// WithIfMatch
+// Read the object only if its ETag matches, otherwise awss3.ErrPreconditionFailed is returned.
+// Applies to GetObjectWriter, GetObjectRange and OpenObject.
+func WithIfMatch(etag string) OptionIfMatch {
+	return OptionIfMatch(etag)
+}
+
+type OptionIfNoneMatch string
+
+func (o OptionIfNoneMatch) Apply(c *confS3Download) {
+	v := string(o)
+	c.IfNoneMatch = &v
+}
+
+// WithIfNoneMatch
+// Read the object only if its ETag does not match, otherwise awss3.ErrNotModified is returned.
+// Applies to GetObjectWriter, GetObjectRange and OpenObject.
+func WithIfNoneMatch(etag string) OptionIfNoneMatch {
+	return OptionIfNoneMatch(etag)
+}
+
+type OptionIfModifiedSince time.Time
+
+func (o OptionIfModifiedSince) Apply(c *confS3Download) {
+	v := time.Time(o)
+	c.IfModifiedSince = &v
+}
+
+// WithIfModifiedSince
+// Read the object only if it has been modified since the time, otherwise awss3.ErrNotModified is returned.
+// Applies to GetObjectWriter, GetObjectRange and OpenObject.
+func WithIfModifiedSince(t time.Time) OptionIfModifiedSince {
+	return OptionIfModifiedSince(t)
+}
+
+type OptionVersionID string
+
+func (o OptionVersionID) Apply(c *confS3Download) {
+	v := string(o)
+	c.VersionID = &v
+}
+
+// WithVersionID
+// Read a specific version of the object.
+// Applies to GetObjectWriter, GetObjectRange and OpenObject.
+func WithVersionID(versionID string) OptionVersionID {
+	return OptionVersionID(versionID)
+}
+
+type OptionProgressListener s3progress.Listener
+
+func (o OptionProgressListener) Apply(c *confS3Download) {
+	c.ProgressListener = s3progress.Listener(o)
+}
+
+// WithProgressListener
+// Sets a listener that receives the bytes transferred and the completion of every object.
+// Applies to DownloadFiles and DownloadFilesParallel.
+func WithProgressListener(listener s3progress.Listener) OptionProgressListener {
+	return OptionProgressListener(listener)
+}
+
+type OptionConcurrency int
+
+func (o OptionConcurrency) Apply(c *confS3Download) {
+	if o > 0 {
+		c.Concurrency = int(o)
+	}
+}
+
+// WithConcurrency
+// Sets the maximum number of objects downloaded at the same time by DownloadFilesParallel and
+// DownloadFilesParallelWithResults. Default is 10. Values less than 1 are ignored.
+func WithConcurrency(concurrency int) OptionConcurrency {
+	return OptionConcurrency(concurrency)
+}
+
+type OptionSkipExisting bool
+
+func (o OptionSkipExisting) Apply(c *confS3Download) {
+	c.SkipExisting = bool(o)
+}
+
+// WithSkipExisting
+// Resumes an interrupted DownloadFilesParallelWithResults: keys whose file already exists in the output directory
+// with the size of the object, and the MD5 of the ETag when the object was uploaded in a single part, are skipped.
+// Existing files are overwritten instead of being saved with a sequential number.
+func WithSkipExisting(enabled bool) OptionSkipExisting {
+	return OptionSkipExisting(enabled)
+}
@@ -23,6 +23,15 @@ type confS3Head struct {
 
 	// LogWaitAttempts is used to enable logging for waiter retry attempts
 	LogWaitAttempts bool
+
+	// IfMatch is the ETag the object must match (If-Match).
+	IfMatch *string
+	// IfNoneMatch is the ETag the object must not match (If-None-Match).
+	IfNoneMatch *string
+	// IfModifiedSince requires the object to be modified after the time (If-Modified-Since).
+	IfModifiedSince *time.Time
+	// VersionID selects a specific version of the object.
+	VersionID *string
 }
 
 type OptionTimeout time.Duration
@@ -90,3 +99,55 @@ func GetS3HeadConf(opts ...OptionS3Head) confS3Head {
 	}
 	return c
 }
+
+type OptionIfMatch string
+
+func (o OptionIfMatch) Apply(c *confS3Head) {
+	v := string(o)
+	c.IfMatch = &v
+}
+
+// WithIfMatch
+// Return the object only if its ETag matches, otherwise awss3.ErrPreconditionFailed is returned.
+func WithIfMatch(etag string) OptionIfMatch {
+	return OptionIfMatch(etag)
+}
+
+type OptionIfNoneMatch string
+
+func (o OptionIfNoneMatch) Apply(c *confS3Head) {
+	v := string(o)
+	c.IfNoneMatch = &v
+}
+
+// WithIfNoneMatch
+// Return the object only if its ETag does not match, otherwise awss3.ErrNotModified is returned.
+func WithIfNoneMatch(etag string) OptionIfNoneMatch {
+	return OptionIfNoneMatch(etag)
+}
+
+type OptionIfModifiedSince time.Time
+
+func (o OptionIfModifiedSince) Apply(c *confS3Head) {
+	v := time.Time(o)
+	c.IfModifiedSince = &v
+}
+
+// WithIfModifiedSince
+// Return the object only if it has been modified since the time, otherwise awss3.ErrNotModified is returned.
+func WithIfModifiedSince(t time.Time) OptionIfModifiedSince {
+	return OptionIfModifiedSince(t)
+}
+
+type OptionVersionID string
+
+func (o OptionVersionID) Apply(c *confS3Head) {
+	v := string(o)
+	c.VersionID = &v
+}
+
+// WithVersionID
+// Head a specific version of the object.
+func WithVersionID(versionID string) OptionVersionID {
+	return OptionVersionID(versionID)
+}
@@ -1,5 +1,7 @@
 package s3list
 
+import "github.com/aws/aws-sdk-go-v2/service/s3"
+
 type OptionS3List interface {
 	Apply(*confS3List)
 }
@@ -11,6 +13,22 @@ type confS3List struct {
 	// Directory buckets - For directory buckets, only prefixes that end in a
 	// delimiter ( / ) are supported.
 	Prefix *string
+
+	// Delimiter is a character used to group keys.
+	// Keys that contain the delimiter after the prefix are rolled up into a
+	// single common prefix instead of being returned as objects.
+	Delimiter *string
+
+	// StartAfter is where S3 starts listing from. S3 starts listing after this
+	// specified key.
+	StartAfter *string
+
+	// MaxKeys sets the maximum number of keys returned in each page.
+	// S3 never returns more than 1,000 keys per page.
+	MaxKeys *int32
+
+	// PageCallback is called for every page returned by ListObjectsV2.
+	PageCallback PageCallbackFunc
 }
 
 type OptionPrefix string
@@ -30,6 +48,71 @@ func WithPrefix(prefix string) OptionPrefix {
 	return OptionPrefix(prefix)
 }
 
+type OptionDelimiter string
+
+func (o OptionDelimiter) Apply(c *confS3List) {
+	v := string(o)
+	c.Delimiter = &v
+}
+
+// WithDelimiter
+// A delimiter is a character used to group keys, usually "/".
+// Keys that contain the delimiter after the prefix are returned once as a
+// common prefix, which allows a bucket to be browsed like a folder tree.
+//
+// Directory buckets - For directory buckets, / is the only supported delimiter.
+func WithDelimiter(delimiter string) OptionDelimiter {
+	return OptionDelimiter(delimiter)
+}
+
+type OptionStartAfter string
+
+func (o OptionStartAfter) Apply(c *confS3List) {
+	v := string(o)
+	c.StartAfter = &v
+}
+
+// WithStartAfter
+// StartAfter is where you want Amazon S3 to start listing from. Amazon S3 starts
+// listing after this specified key. StartAfter can be any key in the bucket.
+//
+// This functionality is not supported for directory buckets.
+func WithStartAfter(startAfter string) OptionStartAfter {
+	return OptionStartAfter(startAfter)
+}
+
+type OptionMaxKeys int32
+
+func (o OptionMaxKeys) Apply(c *confS3List) {
+	v := int32(o)
+	c.MaxKeys = &v
+}
+
+// WithMaxKeys
+// Sets the maximum number of keys returned in a single ListObjectsV2 response.
+// This is a page size, not a limit on the total number of listed objects;
+// stop iterating ListObjectsIter to list fewer objects.
+// S3 returns at most 1,000 keys per page regardless of this value.
+func WithMaxKeys(maxKeys int32) OptionMaxKeys {
+	return OptionMaxKeys(maxKeys)
+}
+
+// PageCallbackFunc is called with every ListObjectsV2 response.
+// Returning a non-nil error stops the listing and the error is returned to the caller.
+type PageCallbackFunc func(page *s3.ListObjectsV2Output) error
+type OptionPageCallback PageCallbackFunc
+
+func (o OptionPageCallback) Apply(c *confS3List) {
+	c.PageCallback = PageCallbackFunc(o)
+}
+
+// WithPageCallback
+// Sets a function that is called with every ListObjectsV2 response before its
+// objects are returned. It can be used to track progress or continuation tokens.
+func WithPageCallback(pageCallback PageCallbackFunc) OptionPageCallback {
+	return OptionPageCallback(pageCallback)
+}
+
 // nolint:revive
 func GetS3ListConf(opts ...OptionS3List) confS3List {
 	c := confS3List{}
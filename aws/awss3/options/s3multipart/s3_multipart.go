@@ -0,0 +1,90 @@
+package s3multipart
+
+import "github.com/88labs/go-utils/aws/awss3/options/s3upload"
+
+type OptionS3Multipart interface {
+	Apply(*confS3Multipart)
+}
+
+type confS3Multipart struct {
+	// PartSize is the number of bytes buffered before a part is uploaded.
+	PartSize int64
+	// Concurrency is the maximum number of parts uploaded at the same time.
+	Concurrency int
+	// LeavePartsOnError disables the automatic abort of the upload on error or context cancel,
+	// so that the upload can be resumed later.
+	LeavePartsOnError bool
+	// UploadOptions are the object attributes applied to the multipart upload.
+	UploadOptions []s3upload.OptionS3Upload
+}
+
+// MinPartSize is the smallest part size accepted by S3 for all but the last part.
+const MinPartSize int64 = 5 * 1024 * 1024
+
+// nolint:revive
+func GetS3MultipartConf(opts ...OptionS3Multipart) confS3Multipart {
+	// default options
+	c := confS3Multipart{
+		PartSize:    8 * 1024 * 1024,
+		Concurrency: 5,
+	}
+	for _, opt := range opts {
+		opt.Apply(&c)
+	}
+	return c
+}
+
+type OptionPartSize int64
+
+func (o OptionPartSize) Apply(c *confS3Multipart) {
+	c.PartSize = max(int64(o), MinPartSize)
+}
+
+// WithPartSize
+// Sets the number of bytes buffered in memory before a part is uploaded. Default is 8 MiB and the minimum is 5 MiB.
+// Up to Concurrency+1 parts are held in memory at the same time.
+func WithPartSize(partSize int64) OptionPartSize {
+	return OptionPartSize(partSize)
+}
+
+type OptionConcurrency int
+
+func (o OptionConcurrency) Apply(c *confS3Multipart) {
+	if o > 0 {
+		c.Concurrency = int(o)
+	}
+}
+
+// WithConcurrency
+// Sets the maximum number of parts uploaded at the same time. Default is 5.
+func WithConcurrency(concurrency int) OptionConcurrency {
+	return OptionConcurrency(concurrency)
+}
+
+type OptionLeavePartsOnError bool
+
+func (o OptionLeavePartsOnError) Apply(c *confS3Multipart) {
+	c.LeavePartsOnError = bool(o)
+}
+
+// WithLeavePartsOnError
+// Keeps the uploaded parts when a part fails or the context is canceled, instead of aborting the upload.
+// Use it with MultipartUpload.State to resume the upload later.
+// Incomplete uploads are billed until they are completed or aborted.
+func WithLeavePartsOnError() OptionLeavePartsOnError {
+	return true
+}
+
+type OptionUploadOptions []s3upload.OptionS3Upload
+
+func (o OptionUploadOptions) Apply(c *confS3Multipart) {
+	c.UploadOptions = append(c.UploadOptions, o...)
+}
+
+// WithUploadOptions
+// Sets the object attributes (content type, metadata, tags, encryption, checksum algorithm) of the upload.
+// The checksum algorithm defaults to CRC32; CRC32, CRC32C, SHA1 and SHA256 are supported.
+// When resuming an upload encrypted with SSE-C, the same key must be passed again.
+func WithUploadOptions(opts ...s3upload.OptionS3Upload) OptionUploadOptions {
+	return opts
+}
@@ -1,6 +1,10 @@
 package s3presigned
 
-import "time"
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
 
 type OptionS3Presigned interface {
 	Apply(*confS3Presigned)
@@ -13,6 +17,26 @@ type confS3Presigned struct {
 	PresignExpires time.Duration
 	// PresignFileName is the name of the file that will be returned in the Content-Disposition header.
 	PresignFileName string
+	// VersionID selects a specific version of the object for Presign.
+	VersionID *string
+	// IfMatch is the ETag the existing object must match (If-Match) for PresignPutObject.
+	IfMatch *string
+	// IfNoneMatch is the ETag the existing object must not match (If-None-Match) for PresignPutObject.
+	IfNoneMatch *string
+	// ContentType is the Content-Type signed into the URL of PresignPutObject.
+	ContentType *string
+	// ContentLength is the Content-Length signed into the URL of PresignPutObject.
+	ContentLength *int64
+	// ContentMD5 is the base64-encoded MD5 digest signed into the URL of PresignPutObject.
+	ContentMD5 *string
+	// ChecksumSHA256 is the base64-encoded SHA-256 checksum signed into the URL of PresignPutObject.
+	ChecksumSHA256 *string
+	// Metadata is the user metadata signed into the URL of PresignPutObject.
+	Metadata map[string]string
+	// ServerSideEncryption is the server-side encryption algorithm signed into the URL of PresignPutObject.
+	ServerSideEncryption types.ServerSideEncryption
+	// SSEKMSKeyID is the KMS key signed into the URL of PresignPutObject when ServerSideEncryption is aws:kms.
+	SSEKMSKeyID *string
 }
 type ContentDispositionType int
 
@@ -74,3 +98,137 @@ func (o OptionContentDispositionType) Apply(c *confS3Presigned) {
 func WithContentDispositionType(tp ContentDispositionType) OptionContentDispositionType {
 	return OptionContentDispositionType(tp)
 }
+
+type OptionVersionID string
+
+func (o OptionVersionID) Apply(c *confS3Presigned) {
+	v := string(o)
+	c.VersionID = &v
+}
+
+// WithVersionID
+// Presign a GET of a specific version of the object. Applies to Presign.
+func WithVersionID(versionID string) OptionVersionID {
+	return OptionVersionID(versionID)
+}
+
+type OptionIfMatch string
+
+func (o OptionIfMatch) Apply(c *confS3Presigned) {
+	v := string(o)
+	c.IfMatch = &v
+}
+
+// WithIfMatch
+// Sign an If-Match header into the URL of PresignPutObject, so the upload succeeds only if the existing object's ETag matches.
+// The uploader must send the same If-Match header.
+func WithIfMatch(etag string) OptionIfMatch {
+	return OptionIfMatch(etag)
+}
+
+type OptionIfNoneMatch string
+
+func (o OptionIfNoneMatch) Apply(c *confS3Presigned) {
+	v := string(o)
+	c.IfNoneMatch = &v
+}
+
+// WithIfNoneMatch
+// Sign an If-None-Match header into the URL of PresignPutObject. S3 only supports "*",
+// which allows the upload only if no object exists with the key. The uploader must send the same header.
+func WithIfNoneMatch(etag string) OptionIfNoneMatch {
+	return OptionIfNoneMatch(etag)
+}
+
+type OptionContentType string
+
+func (o OptionContentType) Apply(c *confS3Presigned) {
+	v := string(o)
+	c.ContentType = &v
+}
+
+// WithContentType
+// Sign the Content-Type into the URL of PresignPutObject. The uploader must send the same Content-Type header.
+func WithContentType(contentType string) OptionContentType {
+	return OptionContentType(contentType)
+}
+
+type OptionContentLength int64
+
+func (o OptionContentLength) Apply(c *confS3Presigned) {
+	v := int64(o)
+	c.ContentLength = &v
+}
+
+// WithContentLength
+// Sign the Content-Length into the URL of PresignPutObject, so only a body of exactly this size is accepted.
+func WithContentLength(contentLength int64) OptionContentLength {
+	return OptionContentLength(contentLength)
+}
+
+type OptionContentMD5 string
+
+func (o OptionContentMD5) Apply(c *confS3Presigned) {
+	v := string(o)
+	c.ContentMD5 = &v
+}
+
+// WithContentMD5
+// Sign the base64-encoded MD5 digest of the body into the URL of PresignPutObject,
+// so only the approved content is accepted.
+func WithContentMD5(contentMD5 string) OptionContentMD5 {
+	return OptionContentMD5(contentMD5)
+}
+
+type OptionChecksumSHA256 string
+
+func (o OptionChecksumSHA256) Apply(c *confS3Presigned) {
+	v := string(o)
+	c.ChecksumSHA256 = &v
+}
+
+// WithChecksumSHA256
+// Sign the base64-encoded SHA-256 checksum of the body (x-amz-checksum-sha256) into the URL of PresignPutObject,
+// so only the approved content is accepted.
+func WithChecksumSHA256(checksum string) OptionChecksumSHA256 {
+	return OptionChecksumSHA256(checksum)
+}
+
+type OptionMetadata map[string]string
+
+func (o OptionMetadata) Apply(c *confS3Presigned) {
+	c.Metadata = o
+}
+
+// WithMetadata
+// Sign user metadata (x-amz-meta-*) into the URL of PresignPutObject. The uploader must send the same headers.
+func WithMetadata(metadata map[string]string) OptionMetadata {
+	return OptionMetadata(metadata)
+}
+
+type optionServerSideEncryption struct {
+	algorithm types.ServerSideEncryption
+	kmsKeyID  *string
+}
+
+func (o optionServerSideEncryption) Apply(c *confS3Presigned) {
+	c.ServerSideEncryption = o.algorithm
+	c.SSEKMSKeyID = o.kmsKeyID
+}
+
+// WithSSES3
+// Sign server-side encryption with Amazon S3 managed keys (SSE-S3) into the URL of PresignPutObject.
+func WithSSES3() OptionS3Presigned {
+	return optionServerSideEncryption{algorithm: types.ServerSideEncryptionAes256}
+}
+
+// WithSSEKMS
+// Sign server-side encryption with AWS KMS keys (SSE-KMS) into the URL of PresignPutObject.
+// If keyID is empty, the AWS managed key aws/s3 is used.
+func WithSSEKMS(keyID string) OptionS3Presigned {
+	o := optionServerSideEncryption{algorithm: types.ServerSideEncryptionAwsKms}
+	if keyID != "" {
+		o.kmsKeyID = &keyID
+	}
+	return o
+}
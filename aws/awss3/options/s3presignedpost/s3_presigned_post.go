@@ -0,0 +1,137 @@
+package s3presignedpost
+
+import "time"
+
+type OptionS3PresignedPost interface {
+	Apply(*confS3PresignedPost)
+}
+
+type confS3PresignedPost struct {
+	// PresignExpires is the duration that the POST policy will be valid for.
+	PresignExpires time.Duration
+	// ContentLengthRange limits the size of the uploaded file in bytes. Nil means no limit.
+	ContentLengthRange *ContentLengthRange
+	// KeyStartsWith allows the browser to choose any key with this prefix.
+	KeyStartsWith *string
+	// ContentType is the exact Content-Type the browser must send.
+	ContentType *string
+	// ContentTypeStartsWith is the prefix of the Content-Type the browser must send.
+	ContentTypeStartsWith *string
+	// Metadata is the user metadata the browser must send as x-amz-meta-* fields.
+	Metadata map[string]string
+	// SuccessActionStatus is the status code returned to the browser on success (200, 201 or 204).
+	SuccessActionStatus int
+}
+
+// ContentLengthRange is the minimum and maximum size of the uploaded file in bytes.
+type ContentLengthRange struct {
+	Min int64
+	Max int64
+}
+
+// nolint:revive
+func GetS3PresignedPostConf(opts ...OptionS3PresignedPost) confS3PresignedPost {
+	// default options
+	c := confS3PresignedPost{
+		PresignExpires: 15 * time.Minute,
+	}
+	for _, opt := range opts {
+		opt.Apply(&c)
+	}
+	return c
+}
+
+type OptionPresignExpires time.Duration
+
+func (o OptionPresignExpires) Apply(c *confS3PresignedPost) {
+	c.PresignExpires = time.Duration(o)
+}
+
+// WithPresignExpires
+// PresignExpires is the duration that the POST policy will be valid for. Default is 15 minutes.
+func WithPresignExpires(presignExpires time.Duration) OptionPresignExpires {
+	return OptionPresignExpires(presignExpires)
+}
+
+type OptionContentLengthRange ContentLengthRange
+
+func (o OptionContentLengthRange) Apply(c *confS3PresignedPost) {
+	r := ContentLengthRange(o)
+	c.ContentLengthRange = &r
+}
+
+// WithContentLengthRange
+// Limits the size of the uploaded file to between minBytes and maxBytes inclusive.
+// S3 rejects uploads outside the range with EntityTooSmall or EntityTooLarge.
+func WithContentLengthRange(minBytes, maxBytes int64) OptionContentLengthRange {
+	return OptionContentLengthRange{Min: minBytes, Max: maxBytes}
+}
+
+type OptionKeyStartsWith string
+
+func (o OptionKeyStartsWith) Apply(c *confS3PresignedPost) {
+	v := string(o)
+	c.KeyStartsWith = &v
+}
+
+// WithKeyStartsWith
+// Allows the browser to upload to any key that starts with prefix instead of the exact key.
+// The key passed to PresignPostObject is returned as the default "key" field and may contain
+// the ${filename} variable, e.g. "uploads/${filename}".
+func WithKeyStartsWith(prefix string) OptionKeyStartsWith {
+	return OptionKeyStartsWith(prefix)
+}
+
+type OptionContentType string
+
+func (o OptionContentType) Apply(c *confS3PresignedPost) {
+	v := string(o)
+	c.ContentType = &v
+	c.ContentTypeStartsWith = nil
+}
+
+// WithContentType
+// Requires the browser to send exactly this Content-Type. It is returned as a form field.
+func WithContentType(contentType string) OptionContentType {
+	return OptionContentType(contentType)
+}
+
+type OptionContentTypeStartsWith string
+
+func (o OptionContentTypeStartsWith) Apply(c *confS3PresignedPost) {
+	v := string(o)
+	c.ContentTypeStartsWith = &v
+	c.ContentType = nil
+}
+
+// WithContentTypeStartsWith
+// Requires the browser to send a Content-Type field starting with prefix, e.g. "image/".
+func WithContentTypeStartsWith(prefix string) OptionContentTypeStartsWith {
+	return OptionContentTypeStartsWith(prefix)
+}
+
+type OptionMetadata map[string]string
+
+func (o OptionMetadata) Apply(c *confS3PresignedPost) {
+	c.Metadata = o
+}
+
+// WithMetadata
+// Requires the browser to send these user metadata as x-amz-meta-* form fields.
+// They are returned as form fields.
+func WithMetadata(metadata map[string]string) OptionMetadata {
+	return OptionMetadata(metadata)
+}
+
+type OptionSuccessActionStatus int
+
+func (o OptionSuccessActionStatus) Apply(c *confS3PresignedPost) {
+	c.SuccessActionStatus = int(o)
+}
+
+// WithSuccessActionStatus
+// Sets the status code S3 returns to the browser after a successful upload: 200, 201 or 204 (S3 default).
+// With 201, S3 returns an XML document describing the uploaded object.
+func WithSuccessActionStatus(status int) OptionSuccessActionStatus {
+	return OptionSuccessActionStatus(status)
+}
@@ -0,0 +1,57 @@
+package s3progress
+
+// EventType is the kind of a progress event.
+type EventType int
+
+const (
+	// EventStart is reported when the transfer of an object starts, and again when it is retried.
+	EventStart EventType = iota
+	// EventBytes is reported when bytes of an object have been transferred.
+	EventBytes
+	// EventComplete is reported once when an object has been transferred.
+	EventComplete
+	// EventFailed is reported once when the transfer of an object failed.
+	EventFailed
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventStart:
+		return "start"
+	case EventBytes:
+		return "bytes"
+	case EventComplete:
+		return "complete"
+	case EventFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a progress event of a transfer.
+type Event struct {
+	Type EventType
+	// Key is the key of the object the event is about.
+	Key string
+	// BytesTransferred is the number of bytes of the object transferred so far.
+	BytesTransferred int64
+	// TotalBytes is the size of the object, or 0 when it is not known yet.
+	TotalBytes int64
+	// Err is the error of the object. It is set for EventFailed only.
+	Err error
+
+	// OperationBytesTransferred is the number of bytes of all objects of the operation transferred so far.
+	OperationBytesTransferred int64
+	// OperationTotalBytes is the size of all objects whose size is known so far.
+	OperationTotalBytes int64
+	// CompletedObjects is the number of objects transferred so far.
+	CompletedObjects int
+	// ObjectCount is the number of objects of the operation.
+	ObjectCount int
+}
+
+// Listener receives the progress events of a transfer.
+// Events of a single operation are delivered one at a time, but possibly from different goroutines,
+// so the listener must return quickly and must not block on the operation.
+type Listener func(e Event)
@@ -0,0 +1,147 @@
+package s3select
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type OptionS3Select interface {
+	Apply(*confS3Select)
+}
+
+// Stats is the number of bytes of an S3 Select query.
+type Stats struct {
+	// BytesScanned is the number of object bytes scanned.
+	BytesScanned int64
+	// BytesProcessed is the number of uncompressed object bytes processed.
+	BytesProcessed int64
+	// BytesReturned is the number of bytes of records returned.
+	BytesReturned int64
+	// Final is set for the stats sent when the query completes, and unset for progress while it runs.
+	Final bool
+}
+
+// StatsListener receives the stats of a query. It is called from the goroutine reading the records.
+type StatsListener func(s Stats)
+
+type confS3Select struct {
+	// InputSerialization describes the format of the object. Default is JSON Lines.
+	InputSerialization types.InputSerialization
+	// ScanRange limits the bytes of the object that are scanned.
+	ScanRange *types.ScanRange
+	// StatsListener receives the progress and the final stats of the query.
+	StatsListener StatsListener
+}
+
+// nolint:revive
+func GetS3SelectConf(opts ...OptionS3Select) confS3Select {
+	// default options
+	c := confS3Select{
+		InputSerialization: types.InputSerialization{
+			JSON:            &types.JSONInput{Type: types.JSONTypeLines},
+			CompressionType: types.CompressionTypeNone,
+		},
+	}
+	for _, opt := range opts {
+		opt.Apply(&c)
+	}
+	return c
+}
+
+type OptionJSONInput types.JSONType
+
+func (o OptionJSONInput) Apply(c *confS3Select) {
+	c.InputSerialization.CSV = nil
+	c.InputSerialization.Parquet = nil
+	c.InputSerialization.JSON = &types.JSONInput{Type: types.JSONType(o)}
+}
+
+// WithJSONLinesInput
+// Reads the object as JSON Lines, one JSON object per line. This is the default.
+func WithJSONLinesInput() OptionJSONInput {
+	return OptionJSONInput(types.JSONTypeLines)
+}
+
+// WithJSONDocumentInput
+// Reads the object as a single JSON document.
+func WithJSONDocumentInput() OptionJSONInput {
+	return OptionJSONInput(types.JSONTypeDocument)
+}
+
+type OptionParquetInput struct{}
+
+func (o OptionParquetInput) Apply(c *confS3Select) {
+	c.InputSerialization.CSV = nil
+	c.InputSerialization.JSON = nil
+	c.InputSerialization.Parquet = &types.ParquetInput{}
+	// Parquet objects are compressed by columns, and S3 only accepts NONE.
+	c.InputSerialization.CompressionType = types.CompressionTypeNone
+}
+
+// WithParquetInput
+// Reads the object as Apache Parquet. Compression and scan ranges are not supported by S3 for Parquet.
+func WithParquetInput() OptionParquetInput {
+	return OptionParquetInput{}
+}
+
+type OptionCSVInput types.CSVInput
+
+func (o OptionCSVInput) Apply(c *confS3Select) {
+	v := types.CSVInput(o)
+	c.InputSerialization.JSON = nil
+	c.InputSerialization.Parquet = nil
+	c.InputSerialization.CSV = &v
+}
+
+// WithCSVInput
+// Reads the object as CSV. The records are still returned as JSON; with FileHeaderInfo USE,
+// the columns are named after the header.
+// https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/s3/types#CSVInput
+func WithCSVInput(csvInput types.CSVInput) OptionCSVInput {
+	return OptionCSVInput(csvInput)
+}
+
+type OptionCompressionType types.CompressionType
+
+func (o OptionCompressionType) Apply(c *confS3Select) {
+	c.InputSerialization.CompressionType = types.CompressionType(o)
+}
+
+// WithCompressionType
+// Specifies object's compression format. Valid values: NONE, GZIP, BZIP2. Default
+// Value: NONE.
+func WithCompressionType(compressionType types.CompressionType) OptionCompressionType {
+	return OptionCompressionType(compressionType)
+}
+
+type OptionScanRange types.ScanRange
+
+func (o OptionScanRange) Apply(c *confS3Select) {
+	v := types.ScanRange(o)
+	c.ScanRange = &v
+}
+
+// WithScanRange
+// Scans only the records that start between the byte offsets start and end of the object.
+// An end less than 1 scans until the end of the object.
+// Supported for uncompressed JSON Lines and CSV objects.
+func WithScanRange(start, end int64) OptionScanRange {
+	r := types.ScanRange{Start: aws.Int64(max(start, 0))}
+	if end > 0 {
+		r.End = aws.Int64(end)
+	}
+	return OptionScanRange(r)
+}
+
+type OptionStatsListener StatsListener
+
+func (o OptionStatsListener) Apply(c *confS3Select) {
+	c.StatsListener = StatsListener(o)
+}
+
+// WithStatsListener
+// Receives the bytes scanned, processed and returned while the query runs, and once more with
+// Final set when it completes.
+func WithStatsListener(listener StatsListener) OptionStatsListener {
+	return OptionStatsListener(listener)
+}
@@ -0,0 +1,152 @@
+package s3sync
+
+import "github.com/88labs/go-utils/aws/awss3/options/s3upload"
+
+type OptionS3Sync interface {
+	Apply(*confS3Sync)
+}
+
+// Direction is the direction in which Sync copies files.
+type Direction int
+
+const (
+	// DirectionUpload copies new and changed local files to S3.
+	DirectionUpload Direction = iota
+	// DirectionDownload copies new and changed objects to the local directory.
+	DirectionDownload
+)
+
+// Action is what was done with a single file.
+type Action string
+
+const (
+	ActionUpload   Action = "upload"
+	ActionDownload Action = "download"
+	ActionDelete   Action = "delete"
+	// ActionSkip means the file was unchanged and was not transferred.
+	ActionSkip Action = "skip"
+)
+
+// Progress is reported after every file has been processed.
+type Progress struct {
+	Action Action
+	Key    string
+	Path   string
+	// Size is the number of bytes of the file.
+	Size int64
+	// Err is the error of the file, or nil when it succeeded.
+	Err error
+	// Completed is the number of files processed so far, including this one.
+	Completed int
+	// Total is the number of files to process.
+	Total int
+}
+
+// ProgressFunc is called after every file has been processed.
+// It may be called from multiple goroutines, but never concurrently.
+type ProgressFunc func(p Progress)
+
+type confS3Sync struct {
+	// Concurrency is the maximum number of files transferred at the same time.
+	Concurrency int
+	// Direction is the direction in which Sync copies files.
+	Direction Direction
+	// Delete removes files from the destination that do not exist in the source. Applies to Sync.
+	Delete bool
+	// DryRun reports what would be done without transferring or deleting anything.
+	DryRun bool
+	// Progress is called after every file has been processed.
+	Progress ProgressFunc
+	// UploadOptions are the object attributes applied to uploaded files.
+	UploadOptions []s3upload.OptionS3Upload
+}
+
+// nolint:revive
+func GetS3SyncConf(opts ...OptionS3Sync) confS3Sync {
+	// default options
+	c := confS3Sync{
+		Concurrency: 5,
+		Direction:   DirectionUpload,
+	}
+	for _, opt := range opts {
+		opt.Apply(&c)
+	}
+	return c
+}
+
+type OptionConcurrency int
+
+func (o OptionConcurrency) Apply(c *confS3Sync) {
+	if o > 0 {
+		c.Concurrency = int(o)
+	}
+}
+
+// WithConcurrency
+// Sets the maximum number of files transferred at the same time. Default is 5. Values less than 1 are ignored.
+func WithConcurrency(concurrency int) OptionConcurrency {
+	return OptionConcurrency(concurrency)
+}
+
+type OptionDirection Direction
+
+func (o OptionDirection) Apply(c *confS3Sync) {
+	c.Direction = Direction(o)
+}
+
+// WithDirection
+// Sets the direction in which Sync copies files. Default is DirectionUpload.
+func WithDirection(direction Direction) OptionDirection {
+	return OptionDirection(direction)
+}
+
+type OptionDelete bool
+
+func (o OptionDelete) Apply(c *confS3Sync) {
+	c.Delete = bool(o)
+}
+
+// WithDelete
+// When enabled, Sync deletes the files in the destination that do not exist in the source:
+// objects under the prefix when uploading, local files when downloading.
+func WithDelete(enabled bool) OptionDelete {
+	return OptionDelete(enabled)
+}
+
+type OptionDryRun bool
+
+func (o OptionDryRun) Apply(c *confS3Sync) {
+	c.DryRun = bool(o)
+}
+
+// WithDryRun
+// When enabled, only the planned actions are returned and reported to the progress function.
+// No file is transferred or deleted.
+func WithDryRun(dryRun bool) OptionDryRun {
+	return OptionDryRun(dryRun)
+}
+
+type OptionProgress ProgressFunc
+
+func (o OptionProgress) Apply(c *confS3Sync) {
+	c.Progress = ProgressFunc(o)
+}
+
+// WithProgress
+// Sets a function called after every file has been transferred, skipped or deleted.
+func WithProgress(fn ProgressFunc) OptionProgress {
+	return OptionProgress(fn)
+}
+
+type OptionUploadOptions []s3upload.OptionS3Upload
+
+func (o OptionUploadOptions) Apply(c *confS3Sync) {
+	c.UploadOptions = append(c.UploadOptions, o...)
+}
+
+// WithUploadOptions
+// Sets the object attributes (metadata, tags, encryption, storage class...) of uploaded files.
+// When no content type is set, it is detected from the file extension.
+func WithUploadOptions(opts ...s3upload.OptionS3Upload) OptionUploadOptions {
+	return opts
+}
@@ -1,6 +1,14 @@
 package s3upload
 
-import "time"
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/88labs/go-utils/aws/awss3/options/s3progress"
+)
 
 type OptionS3Upload interface {
 	Apply(*confS3Upload)
@@ -8,6 +16,35 @@ type OptionS3Upload interface {
 
 type confS3Upload struct {
 	S3Expires *time.Duration
+	// ContentType is a standard MIME type describing the format of the object data.
+	ContentType *string
+	// ContentDisposition specifies presentational information for the object.
+	ContentDisposition *string
+	// CacheControl specifies caching behavior along the request/reply chain.
+	CacheControl *string
+	// Metadata is the user metadata stored with the object as x-amz-meta-* headers.
+	Metadata map[string]string
+	// Tags are the object tags.
+	Tags map[string]string
+	// StorageClass is the storage class of the object.
+	StorageClass types.StorageClass
+	// ACL is the canned ACL applied to the object.
+	ACL types.ObjectCannedACL
+	// ServerSideEncryption is the server-side encryption algorithm (AES256 or aws:kms).
+	ServerSideEncryption types.ServerSideEncryption
+	// SSEKMSKeyID is the KMS key used when ServerSideEncryption is aws:kms.
+	SSEKMSKeyID *string
+	// SSECustomerKey is the 256-bit customer-provided key used for SSE-C.
+	SSECustomerKey []byte
+	// ChecksumAlgorithm is the algorithm used to compute the object checksum.
+	ChecksumAlgorithm types.ChecksumAlgorithm
+	// ProgressListener receives the progress events of UploadManager.
+	ProgressListener s3progress.Listener
+
+	// IfMatch is the ETag the existing object must match (If-Match).
+	IfMatch *string
+	// IfNoneMatch is the ETag the existing object must not match (If-None-Match).
+	IfNoneMatch *string
 }
 
 // nolint:revive
@@ -20,6 +57,35 @@ func GetS3UploadConf(opts ...OptionS3Upload) confS3Upload {
 	return c
 }
 
+// SSECustomerAlgorithm returns the algorithm header value for SSE-C, or nil when SSE-C is not used.
+func (c confS3Upload) SSECustomerAlgorithm() *string {
+	if len(c.SSECustomerKey) == 0 {
+		return nil
+	}
+	v := string(types.ServerSideEncryptionAes256)
+	return &v
+}
+
+// SSECustomerKeyBase64 returns the base64-encoded SSE-C key, or nil when SSE-C is not used.
+func (c confS3Upload) SSECustomerKeyBase64() *string {
+	if len(c.SSECustomerKey) == 0 {
+		return nil
+	}
+	v := base64.StdEncoding.EncodeToString(c.SSECustomerKey)
+	return &v
+}
+
+// SSECustomerKeyMD5 returns the base64-encoded MD5 digest of the SSE-C key,
+// or nil when SSE-C is not used.
+func (c confS3Upload) SSECustomerKeyMD5() *string {
+	if len(c.SSECustomerKey) == 0 {
+		return nil
+	}
+	sum := md5.Sum(c.SSECustomerKey)
+	v := base64.StdEncoding.EncodeToString(sum[:])
+	return &v
+}
+
 type OptionS3Expires time.Duration
 
 func (o OptionS3Expires) Apply(c *confS3Upload) {
@@ -33,3 +99,192 @@ func (o OptionS3Expires) Apply(c *confS3Upload) {
 func WithS3Expires(s3Expires time.Duration) OptionS3Expires {
 	return OptionS3Expires(s3Expires)
 }
+
+type OptionContentType string
+
+func (o OptionContentType) Apply(c *confS3Upload) {
+	v := string(o)
+	c.ContentType = &v
+}
+
+// WithContentType
+// A standard MIME type describing the format of the object data.
+func WithContentType(contentType string) OptionContentType {
+	return OptionContentType(contentType)
+}
+
+type OptionContentDisposition string
+
+func (o OptionContentDisposition) Apply(c *confS3Upload) {
+	v := string(o)
+	c.ContentDisposition = &v
+}
+
+// WithContentDisposition
+// Specifies presentational information for the object,
+// e.g. the value built by awss3.ResponseContentDisposition.
+func WithContentDisposition(contentDisposition string) OptionContentDisposition {
+	return OptionContentDisposition(contentDisposition)
+}
+
+type OptionCacheControl string
+
+func (o OptionCacheControl) Apply(c *confS3Upload) {
+	v := string(o)
+	c.CacheControl = &v
+}
+
+// WithCacheControl
+// Can be used to specify caching behavior along the request/reply chain.
+func WithCacheControl(cacheControl string) OptionCacheControl {
+	return OptionCacheControl(cacheControl)
+}
+
+type OptionMetadata map[string]string
+
+func (o OptionMetadata) Apply(c *confS3Upload) {
+	c.Metadata = o
+}
+
+// WithMetadata
+// A map of user metadata to store with the object in S3 as x-amz-meta-* headers.
+func WithMetadata(metadata map[string]string) OptionMetadata {
+	return OptionMetadata(metadata)
+}
+
+type OptionTags map[string]string
+
+func (o OptionTags) Apply(c *confS3Upload) {
+	c.Tags = o
+}
+
+// WithTags
+// The tags for the object. For Copy, the tags of the source object are replaced.
+func WithTags(tags map[string]string) OptionTags {
+	return OptionTags(tags)
+}
+
+type OptionStorageClass types.StorageClass
+
+func (o OptionStorageClass) Apply(c *confS3Upload) {
+	c.StorageClass = types.StorageClass(o)
+}
+
+// WithStorageClass
+// The storage class of the object. If unset, S3 uses the STANDARD storage class.
+func WithStorageClass(storageClass types.StorageClass) OptionStorageClass {
+	return OptionStorageClass(storageClass)
+}
+
+type OptionACL types.ObjectCannedACL
+
+func (o OptionACL) Apply(c *confS3Upload) {
+	c.ACL = types.ObjectCannedACL(o)
+}
+
+// WithACL
+// The canned ACL to apply to the object.
+// Buckets with the bucket owner enforced Object Ownership setting reject ACLs other than bucket-owner-full-control.
+func WithACL(acl types.ObjectCannedACL) OptionACL {
+	return OptionACL(acl)
+}
+
+type optionServerSideEncryption struct {
+	algorithm types.ServerSideEncryption
+	kmsKeyID  *string
+}
+
+func (o optionServerSideEncryption) Apply(c *confS3Upload) {
+	c.ServerSideEncryption = o.algorithm
+	c.SSEKMSKeyID = o.kmsKeyID
+	c.SSECustomerKey = nil
+}
+
+// WithSSES3
+// Encrypt the object with server-side encryption using Amazon S3 managed keys (SSE-S3).
+func WithSSES3() OptionS3Upload {
+	return optionServerSideEncryption{algorithm: types.ServerSideEncryptionAes256}
+}
+
+// WithSSEKMS
+// Encrypt the object with server-side encryption using AWS KMS keys (SSE-KMS).
+// If keyID is empty, the AWS managed key aws/s3 is used.
+func WithSSEKMS(keyID string) OptionS3Upload {
+	o := optionServerSideEncryption{algorithm: types.ServerSideEncryptionAwsKms}
+	if keyID != "" {
+		o.kmsKeyID = &keyID
+	}
+	return o
+}
+
+type OptionSSECustomerKey []byte
+
+func (o OptionSSECustomerKey) Apply(c *confS3Upload) {
+	c.SSECustomerKey = o
+	c.ServerSideEncryption = ""
+	c.SSEKMSKeyID = nil
+}
+
+// WithSSECustomerKey
+// Encrypt the object with server-side encryption using a customer-provided 256-bit key (SSE-C).
+// The same key must be provided to read the object and to upload every part of a multipart upload.
+// S3 only accepts SSE-C requests over HTTPS.
+func WithSSECustomerKey(key []byte) OptionSSECustomerKey {
+	return OptionSSECustomerKey(key)
+}
+
+type OptionChecksumAlgorithm types.ChecksumAlgorithm
+
+func (o OptionChecksumAlgorithm) Apply(c *confS3Upload) {
+	c.ChecksumAlgorithm = types.ChecksumAlgorithm(o)
+}
+
+// WithChecksumAlgorithm
+// The algorithm used to create the checksum that S3 validates on upload.
+// For multipart uploads, the same algorithm must be used for every part.
+func WithChecksumAlgorithm(checksumAlgorithm types.ChecksumAlgorithm) OptionChecksumAlgorithm {
+	return OptionChecksumAlgorithm(checksumAlgorithm)
+}
+
+type OptionIfMatch string
+
+func (o OptionIfMatch) Apply(c *confS3Upload) {
+	v := string(o)
+	c.IfMatch = &v
+}
+
+// WithIfMatch
+// Write the object only if the existing object's ETag matches (optimistic concurrency),
+// otherwise awss3.ErrPreconditionFailed is returned.
+// For multipart uploads the condition is checked by CompleteMultipartUpload.
+func WithIfMatch(etag string) OptionIfMatch {
+	return OptionIfMatch(etag)
+}
+
+type OptionIfNoneMatch string
+
+func (o OptionIfNoneMatch) Apply(c *confS3Upload) {
+	v := string(o)
+	c.IfNoneMatch = &v
+}
+
+// WithIfNoneMatch
+// Write the object only if no object exists with the key. S3 only supports "*";
+// if the object exists, awss3.ErrPreconditionFailed is returned.
+// For multipart uploads the condition is checked by CompleteMultipartUpload.
+func WithIfNoneMatch(etag string) OptionIfNoneMatch {
+	return OptionIfNoneMatch(etag)
+}
+
+type OptionProgressListener s3progress.Listener
+
+func (o OptionProgressListener) Apply(c *confS3Upload) {
+	c.ProgressListener = s3progress.Listener(o)
+}
+
+// WithProgressListener
+// Sets a listener that receives the bytes transferred and the completion of the object.
+// Applies to UploadManager.
+func WithProgressListener(listener s3progress.Listener) OptionProgressListener {
+	return OptionProgressListener(listener)
+}
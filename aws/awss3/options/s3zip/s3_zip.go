@@ -0,0 +1,130 @@
+package s3zip
+
+import (
+	"archive/zip"
+
+	"github.com/88labs/go-utils/aws/awss3/options/s3download"
+	"github.com/88labs/go-utils/aws/awss3/options/s3multipart"
+)
+
+type OptionS3Zip interface {
+	Apply(*confS3Zip)
+}
+
+type confS3Zip struct {
+	// Prefix adds the objects under the prefix to the archive.
+	Prefix *string
+	// FileNameReplacer replaces the names of the files in the archive.
+	FileNameReplacer s3download.FileNameReplacerFunc
+	// Concurrency is the number of objects fetched ahead of the one being written to the archive.
+	Concurrency int
+	// PrefetchSize is the number of bytes of each object buffered in memory ahead of time.
+	PrefetchSize int64
+	// Method is the compression method of the files in the archive.
+	Method uint16
+	// MultipartOptions are the options of the multipart upload of UploadZip.
+	MultipartOptions []s3multipart.OptionS3Multipart
+}
+
+// DefaultConcurrency is the default number of objects fetched ahead of the one being written to the archive.
+const DefaultConcurrency = 4
+
+// DefaultPrefetchSize is the default number of bytes of each object buffered in memory ahead of time.
+const DefaultPrefetchSize int64 = 4 * 1024 * 1024
+
+// nolint:revive
+func GetS3ZipConf(opts ...OptionS3Zip) confS3Zip {
+	// default options
+	c := confS3Zip{
+		Concurrency:  DefaultConcurrency,
+		PrefetchSize: DefaultPrefetchSize,
+		Method:       zip.Deflate,
+	}
+	for _, opt := range opts {
+		opt.Apply(&c)
+	}
+	return c
+}
+
+type OptionPrefix string
+
+func (o OptionPrefix) Apply(c *confS3Zip) {
+	v := string(o)
+	c.Prefix = &v
+}
+
+// WithPrefix
+// Adds all the objects under the prefix to the archive, after the keys passed explicitly.
+func WithPrefix(prefix string) OptionPrefix {
+	return OptionPrefix(prefix)
+}
+
+type OptionFileNameReplacer s3download.FileNameReplacerFunc
+
+func (o OptionFileNameReplacer) Apply(c *confS3Zip) {
+	c.FileNameReplacer = s3download.FileNameReplacerFunc(o)
+}
+
+// WithFileNameReplacerFunc
+// Sets the function used to replace the names of the files in the archive, as in awss3.DownloadFiles.
+// Duplicated names get a sequential number suffix.
+func WithFileNameReplacerFunc(fileNameReplacerFunc s3download.FileNameReplacerFunc) OptionFileNameReplacer {
+	return OptionFileNameReplacer(fileNameReplacerFunc)
+}
+
+type OptionConcurrency int
+
+func (o OptionConcurrency) Apply(c *confS3Zip) {
+	if o > 0 {
+		c.Concurrency = int(o)
+	}
+}
+
+// WithConcurrency
+// Sets the number of objects fetched ahead of the one being written to the archive. Default is 4.
+// Values less than 1 are ignored.
+func WithConcurrency(concurrency int) OptionConcurrency {
+	return OptionConcurrency(concurrency)
+}
+
+type OptionPrefetchSize int64
+
+func (o OptionPrefetchSize) Apply(c *confS3Zip) {
+	if o > 0 {
+		c.PrefetchSize = int64(o)
+	}
+}
+
+// WithPrefetchSize
+// Sets the number of bytes of each object fetched ahead that are buffered in memory. Default is 4 MiB.
+// The rest of larger objects is streamed when they are written, so the memory used is bounded by
+// (Concurrency + 1) * PrefetchSize. Values less than 1 are ignored.
+func WithPrefetchSize(size int64) OptionPrefetchSize {
+	return OptionPrefetchSize(size)
+}
+
+type OptionMethod uint16
+
+func (o OptionMethod) Apply(c *confS3Zip) {
+	c.Method = uint16(o)
+}
+
+// WithMethod
+// Sets the compression method of the files in the archive. Default is zip.Deflate.
+// zip.Store avoids compressing files that are already compressed, such as images or videos.
+func WithMethod(method uint16) OptionMethod {
+	return OptionMethod(method)
+}
+
+type OptionMultipartOptions []s3multipart.OptionS3Multipart
+
+func (o OptionMultipartOptions) Apply(c *confS3Zip) {
+	c.MultipartOptions = append(c.MultipartOptions, o...)
+}
+
+// WithMultipartOptions
+// Sets the options of the multipart upload of awss3.UploadZip, such as the part size and the object attributes.
+// The content type defaults to application/zip.
+func WithMultipartOptions(opts ...s3multipart.OptionS3Multipart) OptionMultipartOptions {
+	return opts
+}
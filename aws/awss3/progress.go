@@ -0,0 +1,99 @@
+package awss3
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/feature/s3/transfermanager"
+
+	"github.com/88labs/go-utils/aws/awss3/options/s3progress"
+)
+
+// transferProgress tracks the bytes transferred by an operation, forwards the events to the
+// listener of the caller and reports the totals to logOperation.
+type transferProgress struct {
+	mu               sync.Mutex
+	listener         s3progress.Listener
+	objectCount      int
+	bytesTransferred int64
+	totalBytes       int64
+	completed        int
+}
+
+func newTransferProgress(listener s3progress.Listener, objectCount int) *transferProgress {
+	return &transferProgress{listener: listener, objectCount: objectCount}
+}
+
+// object returns the transfer manager option that reports the progress of key.
+// Retries of the key must reuse the option, so that the bytes of the failed attempt are not counted twice.
+func (p *transferProgress) object(key Key) func(*transfermanager.Options) {
+	l := &objectProgress{progress: p, key: key}
+	return func(o *transfermanager.Options) {
+		o.ObjectProgressListeners.Register(l)
+	}
+}
+
+func (p *transferProgress) update(
+	l *objectProgress, eventType s3progress.EventType, bytesTransferred, totalBytes int64, err error,
+) {
+	totalBytes = max(totalBytes, 0)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// a retried transfer starts again from 0
+	p.bytesTransferred += bytesTransferred - l.bytesTransferred
+	p.totalBytes += totalBytes - l.totalBytes
+	l.bytesTransferred, l.totalBytes = bytesTransferred, totalBytes
+	if eventType == s3progress.EventComplete {
+		p.completed++
+	}
+	if p.listener == nil {
+		return
+	}
+	p.listener(s3progress.Event{
+		Type:                      eventType,
+		Key:                       l.key.String(),
+		BytesTransferred:          bytesTransferred,
+		TotalBytes:                totalBytes,
+		Err:                       err,
+		OperationBytesTransferred: p.bytesTransferred,
+		OperationTotalBytes:       p.totalBytes,
+		CompletedObjects:          p.completed,
+		ObjectCount:               p.objectCount,
+	})
+}
+
+// logAttrs returns the progress attributes added to the operation log.
+func (p *transferProgress) logAttrs() []slog.Attr {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return []slog.Attr{
+		slog.Int64("bytes_transferred", p.bytesTransferred),
+		slog.Int64("total_bytes", p.totalBytes),
+		slog.Int("completed_object_count", p.completed),
+	}
+}
+
+// objectProgress adapts the transfer manager listeners of a single object to transferProgress.
+type objectProgress struct {
+	progress         *transferProgress
+	key              Key
+	bytesTransferred int64
+	totalBytes       int64
+}
+
+func (l *objectProgress) OnObjectTransferStart(_ context.Context, e *transfermanager.ObjectTransferStartEvent) {
+	l.progress.update(l, s3progress.EventStart, 0, e.TotalBytes, nil)
+}
+
+func (l *objectProgress) OnObjectBytesTransferred(_ context.Context, e *transfermanager.ObjectBytesTransferredEvent) {
+	l.progress.update(l, s3progress.EventBytes, e.BytesTransferred, e.TotalBytes, nil)
+}
+
+func (l *objectProgress) OnObjectTransferComplete(_ context.Context, e *transfermanager.ObjectTransferCompleteEvent) {
+	l.progress.update(l, s3progress.EventComplete, e.BytesTransferred, e.TotalBytes, nil)
+}
+
+func (l *objectProgress) OnObjectTransferFailed(_ context.Context, e *transfermanager.ObjectTransferFailedEvent) {
+	l.progress.update(l, s3progress.EventFailed, e.BytesTransferred, e.TotalBytes, e.Error)
+}
@@ -0,0 +1,119 @@
+package awss3
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// rateLimitChunkSize is the maximum number of bytes read before waiting for the limiter,
+// so that a single large read does not burst far above the limit.
+const rateLimitChunkSize = 32 * 1024
+
+// rateLimiter is a token bucket shared by every request of a Client.
+// Bytes are taken after they are read, and the reader waits until the bucket is no longer in debt.
+type rateLimiter struct {
+	mu             sync.Mutex
+	bytesPerSecond float64
+	burst          float64
+	tokens         float64
+	last           time.Time
+}
+
+func newRateLimiter(bytesPerSecond int64) *rateLimiter {
+	return &rateLimiter{
+		bytesPerSecond: float64(bytesPerSecond),
+		burst:          float64(bytesPerSecond),
+		tokens:         float64(bytesPerSecond),
+		last:           time.Now(),
+	}
+}
+
+// chunkSize returns the maximum number of bytes to read at once.
+func (l *rateLimiter) chunkSize() int {
+	return max(1, min(rateLimitChunkSize, int(l.burst)))
+}
+
+// wait takes n bytes from the bucket and blocks until they are paid back or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.bytesPerSecond)
+	l.last = now
+	l.tokens -= float64(n)
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.bytesPerSecond * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rateLimitedBody throttles the reads of a request or response body.
+type rateLimitedBody struct {
+	ctx     context.Context
+	body    io.ReadCloser
+	limiter *rateLimiter
+}
+
+func (b *rateLimitedBody) Read(p []byte) (int, error) {
+	if len(p) > b.limiter.chunkSize() {
+		p = p[:b.limiter.chunkSize()]
+	}
+	n, err := b.body.Read(p)
+	if n > 0 {
+		if waitErr := b.limiter.wait(b.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+func (b *rateLimitedBody) Close() error {
+	return b.body.Close()
+}
+
+// rateLimitedHTTPClient throttles the request and response bodies of every request sent by the S3 client.
+type rateLimitedHTTPClient struct {
+	client  s3.HTTPClient
+	limiter *rateLimiter
+}
+
+func (c *rateLimitedHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if req.Body != nil && req.Body != http.NoBody {
+		req = req.Clone(ctx)
+		req.Body = &rateLimitedBody{ctx: ctx, body: req.Body, limiter: c.limiter}
+	}
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.Body != nil {
+		res.Body = &rateLimitedBody{ctx: ctx, body: res.Body, limiter: c.limiter}
+	}
+	return res, nil
+}
+
+// applyRateLimit wraps the HTTP client of the S3 client when a rate limit is configured.
+func applyRateLimit(o *s3.Options, cfg clientConfig) {
+	if cfg.rateLimiter == nil {
+		return
+	}
+	o.HTTPClient = &rateLimitedHTTPClient{client: o.HTTPClient, limiter: cfg.rateLimiter}
+}
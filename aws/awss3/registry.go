@@ -0,0 +1,98 @@
+package awss3
+
+import (
+	"context"
+
+	"github.com/88labs/go-utils/aws/awsconfig"
+	"github.com/88labs/go-utils/aws/awscredentials"
+	"github.com/88labs/go-utils/aws/awstransport"
+	"github.com/88labs/go-utils/aws/internal/awsregistry"
+)
+
+// DefaultRegistry is the Registry of GetClient and the package-level helpers.
+var DefaultRegistry = NewRegistry()
+
+// Registry lazily creates and caches clients by region, assumed role, endpoint and options,
+// so that a process can work with several regions and accounts while sharing their connections.
+//
+// Options are compared by value, except loggers, trace and meter providers, key providers, retry policies,
+// rate limits, TLS configurations and RoundTripper hooks, which are compared by identity: create them once and
+// reuse them, otherwise every call creates a new client.
+// Clients are cached until they are removed with Remove or Clear, so a process that cannot reuse those options
+// should Remove the clients it no longer needs.
+type Registry struct {
+	clients awsregistry.Registry[*Client]
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Client returns the client of region and opts, creating it on the first call.
+// Using ctxawslocal.WithContext, you can make requests for local mocks; the endpoint is a part of the key.
+func (r *Registry) Client(ctx context.Context, region awsconfig.Region, opts ...ClientOption) (*Client, error) {
+	cfg := newClientConfig(opts...)
+	return r.clients.Get(registryKey(ctx, region, cfg), func() (*Client, error) {
+		return newClientWithConfig(ctx, region, cfg)
+	})
+}
+
+// first returns the client created first for region and the endpoint of ctx whatever its options,
+// or the client of opts when there is none.
+func (r *Registry) first(ctx context.Context, region awsconfig.Region, opts ...ClientOption) (*Client, error) {
+	cfg := newClientConfig(opts...)
+	return r.clients.GetFirst(registryKey(ctx, region, cfg), func() (*Client, error) {
+		return newClientWithConfig(ctx, region, cfg)
+	})
+}
+
+// Len returns the number of cached clients.
+func (r *Registry) Len() int {
+	return r.clients.Len()
+}
+
+// Clear removes all the cached clients. Clients already returned keep working.
+func (r *Registry) Clear() {
+	r.clients.Clear()
+}
+
+// Remove removes the cached client of region and opts and reports whether there was one.
+// The client keeps working for the callers that already got it.
+func (r *Registry) Remove(ctx context.Context, region awsconfig.Region, opts ...ClientOption) bool {
+	return r.clients.Delete(registryKey(ctx, region, newClientConfig(opts...)))
+}
+
+func registryKey(ctx context.Context, region awsconfig.Region, cfg clientConfig) awsregistry.Key {
+	var localProfile LocalProfile
+	if cfg.localProfile != nil {
+		localProfile = *cfg.localProfile
+	} else if p, ok := getLocalEndpoint(ctx); ok {
+		localProfile = *p
+	}
+	var globalDialer any
+	if GlobalDialer != nil {
+		globalDialer = *GlobalDialer
+	}
+	return awsregistry.Key{
+		Region:   region.String(),
+		RoleARN:  awscredentials.RoleARN(cfg.credentialsOptions...),
+		Endpoint: localProfile.Endpoint,
+		Options: awsregistry.OptionsHash(
+			localProfile,
+			cfg.logger,
+			cfg.traceEnabled,
+			cfg.traceProvider,
+			cfg.metricsEnabled,
+			cfg.meterProvider,
+			// each WithRateLimit option has its own limiter, which is shared only by the clients of that option
+			cfg.rateLimiter,
+			cfg.retryPolicy,
+			cfg.retryLimit,
+			cfg.keyProvider,
+			awstransport.GetTransportConf(cfg.transportOptions...),
+			awscredentials.GetCredentialsConf(cfg.credentialsOptions...),
+			globalDialer,
+		),
+	}
+}
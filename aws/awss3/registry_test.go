@@ -0,0 +1,55 @@
+package awss3_test
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/88labs/go-utils/aws/awsconfig"
+	"github.com/88labs/go-utils/aws/awss3"
+)
+
+func TestRegistry_Client(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	registry := awss3.NewRegistry()
+	provider := newStaticKeyProvider(t, "key-1")
+	profile := awss3.LocalProfile{
+		Endpoint:        "http://127.0.0.1:29000",
+		AccessKey:       "DUMMYACCESSKEYEXAMPLE",
+		SecretAccessKey: "DUMMYSECRETKEYEXAMPLE",
+	}
+
+	base, err := registry.Client(ctx, awsconfig.RegionTokyo)
+	assert.NilError(t, err)
+	rateLimit := awss3.WithRateLimit(1024)
+	for name, opt := range map[string]awss3.ClientOption{
+		"WithRateLimit":    rateLimit,
+		"WithRetryLimit":   awss3.WithRetryLimit(3),
+		"WithEncryption":   awss3.WithEncryption(provider),
+		"WithLocalProfile": awss3.WithLocalProfile(profile),
+	} {
+		client, err := registry.Client(ctx, awsconfig.RegionTokyo, opt)
+		assert.NilError(t, err, name)
+		assert.Assert(t, client != base, "%s returned the default client", name)
+		again, err := registry.Client(ctx, awsconfig.RegionTokyo, opt)
+		assert.NilError(t, err, name)
+		assert.Assert(t, client == again, "equal %s options returned different clients", name)
+	}
+
+	// each WithRateLimit option has its own limiter
+	limited, err := registry.Client(ctx, awsconfig.RegionTokyo, rateLimit)
+	assert.NilError(t, err)
+	other, err := registry.Client(ctx, awsconfig.RegionTokyo, awss3.WithRateLimit(1024))
+	assert.NilError(t, err)
+	assert.Assert(t, limited != other, "separate WithRateLimit options returned the same client")
+	assert.Equal(t, 6, registry.Len())
+
+	assert.Assert(t, registry.Remove(ctx, awsconfig.RegionTokyo, rateLimit))
+	assert.Assert(t, !registry.Remove(ctx, awsconfig.RegionTokyo, rateLimit))
+	assert.Equal(t, 5, registry.Len())
+	recreated, err := registry.Client(ctx, awsconfig.RegionTokyo, rateLimit)
+	assert.NilError(t, err)
+	assert.Assert(t, recreated != limited, "Remove kept the client")
+}
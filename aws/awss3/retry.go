@@ -0,0 +1,247 @@
+package awss3
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+
+	"github.com/88labs/go-utils/backoff"
+)
+
+// RetryPolicy configures how failed requests are retried, e.g. the number of retries and the intervals.
+// It receives a backoff.Retryer that retries the errors reported by IsRetryableError with the defaults of
+// the backoff package, so a generic preset such as func[C backoff.Configurator[C]](c C) C can be used as is.
+type RetryPolicy func(r *backoff.Retryer) *backoff.Retryer
+
+// errRetryLimitExceeded stops a retry loop when the retry limit of the client is exhausted.
+var errRetryLimitExceeded = errors.New("awss3: retry limit exceeded")
+
+// IsRetryableError reports whether a failed S3 request may succeed when it is retried.
+// Throttling errors such as SlowDown, request timeouts, 5xx responses and connection errors such as
+// connection resets are retryable. Other 4xx responses, canceled contexts and local file errors are not.
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var (
+		pathErr *fs.PathError
+		linkErr *os.LinkError
+		apiErr  smithy.APIError
+		resErr  *awshttp.ResponseError
+	)
+	if errors.As(err, &pathErr) || errors.As(err, &linkErr) {
+		return false
+	}
+	if errors.As(err, &apiErr) {
+		code := apiErr.ErrorCode()
+		if _, ok := retry.DefaultThrottleErrorCodes[code]; ok {
+			return true
+		}
+		if _, ok := retry.DefaultRetryableErrorCodes[code]; ok {
+			return true
+		}
+	}
+	if errors.As(err, &resErr) {
+		code := resErr.HTTPStatusCode()
+		return code >= http.StatusInternalServerError ||
+			code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		// the connection was closed while the body was read
+		return true
+	}
+	return retry.RetryableConnectionError{}.IsErrorRetryable(err) == aws.TrueTernary
+}
+
+// retryBudget is the number of retries left to a client. Retries are never given back,
+// so the client retries at most limit times in its lifetime.
+type retryBudget struct {
+	mu     sync.Mutex
+	tokens int
+}
+
+func newRetryBudget(limit int) *retryBudget {
+	return &retryBudget{tokens: limit}
+}
+
+// take takes a token for a retry and reports whether one was available.
+// A nil budget is unlimited.
+func (b *retryBudget) take() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens == 0 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// exhausted reports whether no retry is left.
+func (b *retryBudget) exhausted() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens == 0
+}
+
+// clientRetryer retries the requests and transfers of a client with its RetryPolicy and retry limit.
+// A nil clientRetryer retries with the defaults of the backoff package.
+type clientRetryer struct {
+	policy RetryPolicy
+	budget *retryBudget
+	logger *slog.Logger
+}
+
+func newClientRetryer(cfg clientConfig) *clientRetryer {
+	r := &clientRetryer{policy: cfg.retryPolicy, logger: cfg.logger}
+	if cfg.retryLimit > 0 {
+		r.budget = newRetryBudget(cfg.retryLimit)
+	}
+	return r
+}
+
+// enabled reports whether the client replaces the retries of the AWS SDK.
+func (r *clientRetryer) enabled() bool {
+	return r != nil && (r.policy != nil || r.budget != nil)
+}
+
+// do calls fn until it succeeds, the error is not retried by the policy or the retry limit is exhausted.
+// Every retry is logged with the operation and the error of the previous attempt.
+func (r *clientRetryer) do(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	retryer := backoff.New().WithRetryIf(IsRetryableError)
+	var (
+		budget *retryBudget
+		logger *slog.Logger
+	)
+	if r != nil {
+		if r.policy != nil {
+			retryer = r.policy(retryer)
+		}
+		budget, logger = r.budget, r.logger
+	}
+
+	// The retryer does not know the retry limit, so the loop is stopped by canceling its context.
+	retryCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	var (
+		attempt int
+		lastErr error
+	)
+	// fn gets ctx rather than retryCtx, since a response body may still be read after do returns.
+	err := retryer.Do(retryCtx, func(context.Context) error {
+		attempt++
+		if attempt > 1 {
+			if !budget.take() {
+				cancel(errRetryLimitExceeded)
+				return lastErr
+			}
+			if logger != nil {
+				logger.LogAttrs(ctx, slog.LevelWarn, "awss3 retrying request",
+					slog.String("component", "awss3"),
+					slog.String("operation", operation),
+					slog.Int("attempt", attempt),
+					slog.Any("error", lastErr),
+				)
+			}
+		}
+		lastErr = fn(ctx)
+		if lastErr == nil {
+			return nil
+		}
+		if budget.exhausted() {
+			cancel(errRetryLimitExceeded)
+		}
+		return lastErr
+	})
+	if err != nil && ctx.Err() == nil && errors.Is(context.Cause(retryCtx), errRetryLimitExceeded) {
+		return lastErr
+	}
+	return err
+}
+
+// retryMiddleware retries every request of the S3 client with the clientRetryer.
+// It is inserted before the retry middleware of the AWS SDK, whose retryer is disabled.
+type retryMiddleware struct {
+	retryer *clientRetryer
+}
+
+func (*retryMiddleware) ID() string {
+	return "awss3Retry"
+}
+
+func (m *retryMiddleware) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (out middleware.FinalizeOutput, metadata middleware.Metadata, err error) {
+	attempt := 0
+	err = m.retryer.do(ctx, middleware.GetOperationName(ctx), func(ctx context.Context) error {
+		attempt++
+		attemptIn := in
+		if req, ok := in.Request.(*smithyhttp.Request); ok {
+			// every attempt sends a fresh copy of the request, with the body rewound for retries
+			clone := req.Clone()
+			if attempt > 1 {
+				if err := clone.RewindStream(); err != nil {
+					return err
+				}
+			}
+			attemptIn.Request = clone
+		}
+		var attemptErr error
+		out, metadata, attemptErr = next.HandleFinalize(ctx, attemptIn)
+		return attemptErr
+	})
+	return out, metadata, err
+}
+
+// withoutRetryMiddleware removes the retryMiddleware from the request, for the transfers that are retried as a whole
+// by clientRetryer.do, so that each attempt sends its requests once and a retry is counted and logged once.
+func withoutRetryMiddleware(o *s3.Options) {
+	o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+		id := (*retryMiddleware)(nil).ID()
+		if _, ok := stack.Finalize.Get(id); !ok {
+			return nil
+		}
+		_, err := stack.Finalize.Remove(id)
+		return err
+	})
+}
+
+// singleRetryClient is the S3 client of the downloaders whose downloads are retried by clientRetryer.do.
+type singleRetryClient struct {
+	*s3.Client
+}
+
+func (c singleRetryClient) GetObject(
+	ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options),
+) (*s3.GetObjectOutput, error) {
+	return c.Client.GetObject(ctx, params, append(optFns, withoutRetryMiddleware)...)
+}
+
+// applyRetryPolicy replaces the retryer of the AWS SDK when a retry policy or a retry limit is configured.
+func applyRetryPolicy(o *s3.Options, cfg clientConfig) {
+	if !cfg.retryer.enabled() {
+		return
+	}
+	o.Retryer = aws.NopRetryer{}
+	o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+		return stack.Finalize.Insert(&retryMiddleware{retryer: cfg.retryer}, "Retry", middleware.Before)
+	})
+}
@@ -0,0 +1,252 @@
+package awss3_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"gotest.tools/v3/assert"
+
+	"github.com/88labs/go-utils/backoff"
+	"github.com/88labs/go-utils/ulid"
+
+	"github.com/88labs/go-utils/aws/awss3"
+	"github.com/88labs/go-utils/aws/ctxawslocal"
+)
+
+// faultProxy forwards requests to Minio and answers the first failures requests with status.
+type faultProxy struct {
+	status   atomic.Int32
+	failures atomic.Int32
+	requests atomic.Int32
+}
+
+func newFaultProxy(t *testing.T) (*faultProxy, context.Context) {
+	t.Helper()
+	target, err := url.Parse("http://127.0.0.1:29000")
+	assert.NilError(t, err)
+	p := &faultProxy{}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.requests.Add(1)
+		if p.failures.Add(-1) >= 0 {
+			_, _ = io.Copy(io.Discard, r.Body)
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(int(p.status.Load()))
+			_, _ = fmt.Fprintf(w, "<Error><Code>%s</Code><Message>injected</Message></Error>",
+				strings.ReplaceAll(http.StatusText(int(p.status.Load())), " ", ""))
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithS3Endpoint(server.URL),
+		ctxawslocal.WithAccessKey("DUMMYACCESSKEYEXAMPLE"),
+		ctxawslocal.WithSecretAccessKey("DUMMYSECRETKEYEXAMPLE"),
+	)
+	return p, ctx
+}
+
+// fail makes the next n requests fail with status.
+func (p *faultProxy) fail(n int, status int) {
+	p.status.Store(int32(status))
+	p.failures.Store(int32(n))
+	p.requests.Store(0)
+}
+
+func fastRetry(maxRetries int) awss3.RetryPolicy {
+	return func(r *backoff.Retryer) *backoff.Retryer {
+		return r.WithMaxRetries(maxRetries).WithInitialInterval(time.Millisecond).WithMaxInterval(time.Millisecond)
+	}
+}
+
+func TestNewClient_WithRetryPolicy(t *testing.T) {
+	t.Parallel()
+	proxy, ctx := newFaultProxy(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	client, err := awss3.NewClient(ctx, TestRegion, awss3.WithRetryPolicy(fastRetry(3)), awss3.WithLogger(logger))
+	assert.NilError(t, err)
+
+	t.Run("retries throttling with the body rewound", func(t *testing.T) {
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		proxy.fail(2, http.StatusServiceUnavailable)
+		buf.Reset()
+		_, err := client.PutObject(ctx, TestBucket, key, strings.NewReader("retried"))
+		assert.NilError(t, err)
+		assert.Equal(t, proxy.requests.Load(), int32(3))
+
+		var got bytes.Buffer
+		assert.NilError(t, client.GetObjectWriter(ctx, TestBucket, key, &got))
+		assert.Equal(t, got.String(), "retried")
+
+		var retries []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if entry := decodeLastJSONLogEntry(t, line); entry["msg"] == "awss3 retrying request" {
+				retries = append(retries, entry)
+			}
+		}
+		assert.Equal(t, len(retries), 2)
+		assert.Equal(t, retries[0]["operation"], "PutObject")
+		assert.Equal(t, retries[0]["attempt"], float64(2))
+		assert.Equal(t, retries[1]["attempt"], float64(3))
+	})
+	t.Run("gives up after the max retries", func(t *testing.T) {
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		proxy.fail(10, http.StatusServiceUnavailable)
+		_, err := client.PutObject(ctx, TestBucket, key, strings.NewReader("failed"))
+		assert.Assert(t, err != nil)
+		assert.Assert(t, awss3.IsRetryableError(err))
+		assert.Equal(t, proxy.requests.Load(), int32(4))
+	})
+	t.Run("does not retry client errors", func(t *testing.T) {
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		proxy.fail(10, http.StatusForbidden)
+		_, err := client.PutObject(ctx, TestBucket, key, strings.NewReader("forbidden"))
+		assert.Assert(t, err != nil)
+		assert.Equal(t, proxy.requests.Load(), int32(1))
+	})
+	t.Run("retries downloads", func(t *testing.T) {
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		proxy.fail(0, http.StatusOK)
+		_, err := client.PutObject(ctx, TestBucket, key, strings.NewReader("download"))
+		assert.NilError(t, err)
+
+		proxy.fail(2, http.StatusInternalServerError)
+		outputDir := t.TempDir()
+		results, err := client.DownloadFilesParallelWithResults(ctx, TestBucket, awss3.Keys{key}, outputDir)
+		assert.NilError(t, err)
+		assert.Equal(t, len(results.Downloaded()), 1)
+		b, err := os.ReadFile(filepath.Join(outputDir, filepath.Base(key.String())))
+		assert.NilError(t, err)
+		assert.Equal(t, string(b), "download")
+	})
+	t.Run("retries downloads once", func(t *testing.T) {
+		key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+		proxy.fail(0, http.StatusOK)
+		_, err := client.PutObject(ctx, TestBucket, key, strings.NewReader("download"))
+		assert.NilError(t, err)
+
+		proxy.fail(100, http.StatusInternalServerError)
+		buf.Reset()
+		results, err := client.DownloadFilesParallelWithResults(ctx, TestBucket, awss3.Keys{key}, t.TempDir())
+		assert.Assert(t, err != nil)
+		assert.Equal(t, len(results.Failed()), 1)
+		assert.Equal(t, results[0].Attempts, 4)
+		assert.Equal(t, proxy.requests.Load(), int32(4))
+		assert.Equal(t, strings.Count(buf.String(), "awss3 retrying request"), 3)
+
+		proxy.fail(100, http.StatusInternalServerError)
+		_, err = client.DownloadFilesParallel(ctx, TestBucket, awss3.Keys{key}, t.TempDir())
+		assert.Assert(t, err != nil)
+		assert.Equal(t, proxy.requests.Load(), int32(4))
+
+		proxy.fail(100, http.StatusInternalServerError)
+		_, err = client.WriteZip(ctx, TestBucket, awss3.Keys{key}, io.Discard)
+		assert.Assert(t, err != nil)
+		assert.Equal(t, proxy.requests.Load(), int32(4))
+	})
+}
+
+func TestNewClient_WithRetryLimit(t *testing.T) {
+	t.Parallel()
+	proxy, ctx := newFaultProxy(t)
+
+	client, err := awss3.NewClient(ctx, TestRegion, awss3.WithRetryPolicy(fastRetry(5)), awss3.WithRetryLimit(2))
+	assert.NilError(t, err)
+	key := awss3.Key(fmt.Sprintf("awstest/%s.txt", ulid.MustNew()))
+
+	// the limit is shared by the requests of the client
+	proxy.fail(10, http.StatusServiceUnavailable)
+	_, err = client.PutObject(ctx, TestBucket, key, strings.NewReader("limited"))
+	assert.Assert(t, err != nil)
+	assert.Equal(t, proxy.requests.Load(), int32(3))
+
+	proxy.fail(10, http.StatusServiceUnavailable)
+	_, err = client.PutObject(ctx, TestBucket, key, strings.NewReader("limited"))
+	assert.Assert(t, err != nil)
+	assert.Equal(t, proxy.requests.Load(), int32(1))
+
+	// successful requests do not give retries back
+	proxy.fail(0, http.StatusOK)
+	_, err = client.PutObject(ctx, TestBucket, key, strings.NewReader("limited"))
+	assert.NilError(t, err)
+	proxy.fail(1, http.StatusServiceUnavailable)
+	_, err = client.PutObject(ctx, TestBucket, key, strings.NewReader("limited"))
+	assert.Assert(t, err != nil)
+	assert.Equal(t, proxy.requests.Load(), int32(1))
+
+	// other clients have their own limit
+	other, err := awss3.NewClient(ctx, TestRegion, awss3.WithRetryPolicy(fastRetry(5)), awss3.WithRetryLimit(2))
+	assert.NilError(t, err)
+	proxy.fail(1, http.StatusServiceUnavailable)
+	_, err = other.PutObject(ctx, TestBucket, key, strings.NewReader("limited"))
+	assert.NilError(t, err)
+}
+
+func TestIsRetryableError(t *testing.T) {
+	t.Parallel()
+	responseError := func(status int, err error) error {
+		return &smithy.OperationError{ServiceID: "S3", OperationName: "GetObject", Err: &awshttp.ResponseError{
+			ResponseError: &smithyhttp.ResponseError{
+				Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+				Err:      err,
+			},
+		}}
+	}
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"SlowDown": {
+			err:  responseError(http.StatusServiceUnavailable, &smithy.GenericAPIError{Code: "SlowDown"}),
+			want: true,
+		},
+		"throttling with status 400": {
+			err:  responseError(http.StatusBadRequest, &smithy.GenericAPIError{Code: "Throttling"}),
+			want: true,
+		},
+		"internal error":  {err: responseError(http.StatusInternalServerError, errors.New("internal")), want: true},
+		"request timeout": {err: responseError(http.StatusRequestTimeout, errors.New("timeout")), want: true},
+		"connection reset": {
+			err:  &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)},
+			want: true,
+		},
+		"unexpected EOF": {err: fmt.Errorf("read body: %w", io.ErrUnexpectedEOF), want: true},
+		"access denied": {
+			err:  responseError(http.StatusForbidden, &smithy.GenericAPIError{Code: "AccessDenied"}),
+			want: false,
+		},
+		"not found":        {err: responseError(http.StatusNotFound, errors.New("not found")), want: false},
+		"ErrNotFound":      {err: awss3.ErrNotFound, want: false},
+		"canceled":         {err: context.Canceled, want: false},
+		"local file error": {err: &os.PathError{Op: "open", Path: "x", Err: syscall.ENOSPC}, want: false},
+		"nil":              {err: nil, want: false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, awss3.IsRetryableError(tt.err), tt.want)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package awss3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"log/slog"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/88labs/go-utils/aws/awss3/options/s3select"
+)
+
+// ErrSelectIncomplete is returned when the S3 Select stream ends before the query completes,
+// so the records yielded so far may be partial.
+var ErrSelectIncomplete = errors.New("SelectIncomplete")
+
+// SelectObject runs an S3 Select query on an object and yields the records one at a time as JSON.
+// The object is read as JSON Lines by default; use s3select.WithJSONDocumentInput, s3select.WithParquetInput or
+// s3select.WithCSVInput for other formats. The records are streamed, and breaking out of the loop stops the query.
+//
+// An error of the request or of the stream, including ErrSelectIncomplete, is yielded once as the final element.
+// Use DecodeRecords to decode the records into a type.
+// SQL Reference : https://docs.aws.amazon.com/AmazonS3/latest/userguide/s3-glacier-select-sql-reference-select.html
+func (c *Client) SelectObject(
+	ctx context.Context, bucketName BucketName, key Key, query string, opts ...s3select.OptionS3Select,
+) iter.Seq2[json.RawMessage, error] {
+	return func(yield func(json.RawMessage, error) bool) {
+		conf := s3select.GetS3SelectConf(opts...)
+		done := c.logOperation(ctx, "SelectObject",
+			slog.String("bucket", bucketName.String()),
+			slog.String("key", key.String()),
+		)
+		var (
+			err         error
+			recordCount int
+			stats       s3select.Stats
+		)
+		defer func() {
+			done(err,
+				slog.Int("record_count", recordCount),
+				slog.Int64("bytes_scanned", stats.BytesScanned),
+				slog.Int64("bytes_processed", stats.BytesProcessed),
+				slog.Int64("bytes_returned", stats.BytesReturned),
+			)
+		}()
+
+		input := conf.InputSerialization
+		req := &s3.SelectObjectContentInput{
+			Bucket:             bucketName.AWSString(),
+			Key:                key.AWSString(),
+			ExpressionType:     types.ExpressionTypeSql,
+			Expression:         aws.String(query),
+			InputSerialization: &input,
+			OutputSerialization: &types.OutputSerialization{
+				JSON: &types.JSONOutput{RecordDelimiter: aws.String("\n")},
+			},
+			ScanRange:       conf.ScanRange,
+			RequestProgress: &types.RequestProgress{Enabled: aws.Bool(conf.StatsListener != nil)},
+		}
+		resp, err := c.client.SelectObjectContent(ctx, req)
+		if err != nil {
+			if hasErrorCode(err, "InvalidRange") {
+				// the scan range starts after the end of the object
+				err = nil
+				return
+			}
+			yield(nil, err)
+			return
+		}
+		stream := resp.GetStream()
+		defer stream.Close()
+
+		records := &selectRecordReader{
+			events: stream.Events(),
+			onStats: func(s s3select.Stats) {
+				stats = s
+				if conf.StatsListener != nil {
+					conf.StatsListener(s)
+				}
+			},
+		}
+		dec := json.NewDecoder(records)
+		for {
+			var record json.RawMessage
+			if err = dec.Decode(&record); err != nil {
+				break
+			}
+			recordCount++
+			if !yield(record, nil) {
+				return
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			err = stream.Err()
+			if err == nil && !records.ended {
+				err = ErrSelectIncomplete
+			}
+		}
+		if err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// DecodeRecords decodes the JSON records of SelectObject into T.
+// A record that cannot be decoded is yielded as an error, and the iteration continues with the next record.
+func DecodeRecords[T any](records iter.Seq2[json.RawMessage, error]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for record, err := range records {
+			var v T
+			if err == nil {
+				err = json.Unmarshal(record, &v)
+			}
+			if !yield(v, err) {
+				return
+			}
+		}
+	}
+}
+
+// selectRecordReader reads the record payloads of an S3 Select event stream
+// and reports the progress and stats events.
+type selectRecordReader struct {
+	events  <-chan types.SelectObjectContentEventStream
+	onStats func(s s3select.Stats)
+	payload []byte
+	ended   bool
+}
+
+func (r *selectRecordReader) Read(p []byte) (int, error) {
+	for len(r.payload) == 0 {
+		event, ok := <-r.events
+		if !ok {
+			return 0, io.EOF
+		}
+		switch v := event.(type) {
+		case *types.SelectObjectContentEventStreamMemberRecords:
+			r.payload = v.Value.Payload
+		case *types.SelectObjectContentEventStreamMemberProgress:
+			if d := v.Value.Details; d != nil {
+				r.onStats(newSelectStats(d.BytesScanned, d.BytesProcessed, d.BytesReturned, false))
+			}
+		case *types.SelectObjectContentEventStreamMemberStats:
+			if d := v.Value.Details; d != nil {
+				r.onStats(newSelectStats(d.BytesScanned, d.BytesProcessed, d.BytesReturned, true))
+			}
+		case *types.SelectObjectContentEventStreamMemberEnd:
+			r.ended = true
+		}
+	}
+	n := copy(p, r.payload)
+	r.payload = r.payload[n:]
+	return n, nil
+}
+
+func newSelectStats(scanned, processed, returned *int64, final bool) s3select.Stats {
+	return s3select.Stats{
+		BytesScanned:   aws.ToInt64(scanned),
+		BytesProcessed: aws.ToInt64(processed),
+		BytesReturned:  aws.ToInt64(returned),
+		Final:          final,
+	}
+}
@@ -0,0 +1,527 @@
+package awss3
+
+import (
+	"context"
+	"crypto/md5" // nolint:gosec
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"mime"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/transfermanager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/88labs/go-utils/aws/awss3/options/s3list"
+	"github.com/88labs/go-utils/aws/awss3/options/s3sync"
+)
+
+// downloadTempPattern is the pattern of the temporary files downloads are written to before being renamed.
+const downloadTempPattern = ".awss3-download-*"
+
+// errUnsafeKey is returned for keys that cannot be mapped to a path inside the local directory.
+var errUnsafeKey = errors.New("awss3: key is not a local path")
+
+// localFile is a regular file found under the local directory.
+type localFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// syncItem is a single planned action of UploadDirectory, DownloadPrefix or Sync.
+type syncItem struct {
+	action s3sync.Action
+	key    Key
+	path   string
+	// remote is set for deletes of objects, as opposed to local files.
+	remote bool
+	// local and object are set when the file exists on both sides and must be compared before the transfer.
+	local  *localFile
+	object *types.Object
+	// lastModified is applied to downloaded files so that the next Sync sees them as unchanged.
+	lastModified time.Time
+	size         int64
+	err          error
+}
+
+// UploadDirectory uploads every regular file under localDir, keeping the relative paths as keys under prefix.
+// Files are uploaded concurrently up to s3sync.WithConcurrency. Symbolic links are not followed.
+//
+// The results are returned in path order, one per file.
+// err joins the errors of all failed files and is nil when every file was uploaded.
+func (c *Client) UploadDirectory(
+	ctx context.Context, bucketName BucketName, prefix string, localDir string, opts ...s3sync.OptionS3Sync,
+) (results SyncResults, err error) {
+	conf := s3sync.GetS3SyncConf(opts...)
+	done := c.logOperation(ctx, "UploadDirectory",
+		slog.String("bucket", bucketName.String()),
+		slog.String("prefix", prefix),
+		slog.String("local_dir", localDir),
+		slog.Bool("dry_run", conf.DryRun),
+	)
+	defer func() {
+		done(err, syncLogAttrs(results)...)
+	}()
+
+	files, err := listLocalFiles(localDir)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]syncItem, 0, len(files))
+	for _, rel := range slices.Sorted(maps.Keys(files)) {
+		f := files[rel]
+		items = append(items, syncItem{action: s3sync.ActionUpload, key: syncKey(prefix, rel), path: f.path, size: f.size})
+	}
+	results = c.runSync(ctx, bucketName, items, opts...)
+	return results, results.Err()
+}
+
+// DownloadPrefix downloads every object under prefix into localDir, keeping the key hierarchy below the prefix.
+// Objects are downloaded concurrently up to s3sync.WithConcurrency. Each file is written to a temporary file
+// first and renamed when complete, and its modification time is set to the LastModified of the object.
+// Keys ending with "/" are skipped, and keys that would escape localDir (e.g. "../") are reported as failed.
+//
+// The results are returned in key order, one per object.
+// err joins the listing error and the errors of all failed objects.
+func (c *Client) DownloadPrefix(
+	ctx context.Context, bucketName BucketName, prefix string, localDir string, opts ...s3sync.OptionS3Sync,
+) (results SyncResults, err error) {
+	conf := s3sync.GetS3SyncConf(opts...)
+	done := c.logOperation(ctx, "DownloadPrefix",
+		slog.String("bucket", bucketName.String()),
+		slog.String("prefix", prefix),
+		slog.String("local_dir", localDir),
+		slog.Bool("dry_run", conf.DryRun),
+	)
+	defer func() {
+		done(err, syncLogAttrs(results)...)
+	}()
+
+	objects, err := c.listSyncObjects(ctx, bucketName, prefix)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]syncItem, 0, len(objects))
+	for _, rel := range slices.Sorted(maps.Keys(objects)) {
+		items = append(items, newDownloadItem(localDir, rel, objects[rel]))
+	}
+	results = c.runSync(ctx, bucketName, items, opts...)
+	return results, results.Err()
+}
+
+// Sync makes prefix mirror localDir (s3sync.DirectionUpload, the default) or localDir mirror prefix
+// (s3sync.DirectionDownload), transferring only new and changed files.
+//
+// A file is changed when its size differs, or when the source is newer than the destination and,
+// for objects uploaded in a single part, its MD5 differs from the ETag.
+// With s3sync.WithDelete, files in the destination that do not exist in the source are deleted.
+// When downloading, a localDir that does not exist yet is created.
+//
+// The results contain one entry per file, including the skipped ones.
+// err joins the errors of all failed files.
+func (c *Client) Sync(
+	ctx context.Context, localDir string, bucketName BucketName, prefix string, opts ...s3sync.OptionS3Sync,
+) (results SyncResults, err error) {
+	conf := s3sync.GetS3SyncConf(opts...)
+	done := c.logOperation(ctx, "Sync",
+		slog.String("bucket", bucketName.String()),
+		slog.String("prefix", prefix),
+		slog.String("local_dir", localDir),
+		slog.Bool("download", conf.Direction == s3sync.DirectionDownload),
+		slog.Bool("delete", conf.Delete),
+		slog.Bool("dry_run", conf.DryRun),
+	)
+	defer func() {
+		done(err, syncLogAttrs(results)...)
+	}()
+
+	files, err := listLocalFiles(localDir)
+	if pathErr := (*fs.PathError)(nil); conf.Direction == s3sync.DirectionDownload &&
+		errors.As(err, &pathErr) && pathErr.Path == localDir && errors.Is(err, fs.ErrNotExist) {
+		// the first download: the downloaded files create their directories
+		files, err = map[string]localFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	objects, err := c.listSyncObjects(ctx, bucketName, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]syncItem, 0, max(len(files), len(objects)))
+	switch conf.Direction {
+	case s3sync.DirectionDownload:
+		for _, rel := range slices.Sorted(maps.Keys(objects)) {
+			item := newDownloadItem(localDir, rel, objects[rel])
+			if f, ok := files[rel]; ok {
+				item.local = &f
+			}
+			items = append(items, item)
+		}
+		if conf.Delete {
+			for _, rel := range slices.Sorted(maps.Keys(files)) {
+				if _, ok := objects[rel]; !ok {
+					f := files[rel]
+					items = append(items, syncItem{action: s3sync.ActionDelete, key: syncKey(prefix, rel), path: f.path, size: f.size})
+				}
+			}
+		}
+	default:
+		for _, rel := range slices.Sorted(maps.Keys(files)) {
+			f := files[rel]
+			item := syncItem{action: s3sync.ActionUpload, key: syncKey(prefix, rel), path: f.path, size: f.size}
+			if obj, ok := objects[rel]; ok {
+				item.local = &f
+				item.object = &obj
+			}
+			items = append(items, item)
+		}
+		if conf.Delete {
+			for _, rel := range slices.Sorted(maps.Keys(objects)) {
+				if _, ok := files[rel]; !ok {
+					obj := objects[rel]
+					items = append(items, syncItem{
+						action: s3sync.ActionDelete, key: Key(aws.ToString(obj.Key)), size: aws.ToInt64(obj.Size), remote: true,
+					})
+				}
+			}
+		}
+	}
+	results = c.runSync(ctx, bucketName, items, opts...)
+	return results, results.Err()
+}
+
+func newDownloadItem(localDir, rel string, obj types.Object) syncItem {
+	item := syncItem{
+		action:       s3sync.ActionDownload,
+		key:          Key(aws.ToString(obj.Key)),
+		path:         filepath.Join(localDir, filepath.FromSlash(rel)),
+		size:         aws.ToInt64(obj.Size),
+		lastModified: aws.ToTime(obj.LastModified),
+		object:       &obj,
+	}
+	if !filepath.IsLocal(filepath.FromSlash(rel)) {
+		item.path = ""
+		item.err = fmt.Errorf("%w: %s", errUnsafeKey, item.key)
+	}
+	return item
+}
+
+// runSync executes the planned items concurrently and reports the progress of every item.
+// Objects are deleted after all transfers have finished, in DeleteObjects requests of up to 1,000 keys.
+func (c *Client) runSync(
+	ctx context.Context, bucketName BucketName, items []syncItem, opts ...s3sync.OptionS3Sync,
+) SyncResults {
+	conf := s3sync.GetS3SyncConf(opts...)
+	results := make(SyncResults, len(items))
+	progress := newSyncProgress(conf.Progress, len(items))
+
+	attrs := newUploadAttributes(conf.UploadOptions...)
+	uploader := transfermanager.New(attrs.uploadClient(c.client))
+	downloader := transfermanager.New(c.client, c.downloadOptions)
+
+	var eg errgroup.Group
+	eg.SetLimit(conf.Concurrency)
+	remoteDeletes := make([]int, 0)
+	for i, item := range items {
+		if item.remote {
+			remoteDeletes = append(remoteDeletes, i)
+			continue
+		}
+		eg.Go(func() error {
+			action, err := item.action, item.err
+			if err == nil {
+				action, err = c.processSyncItem(ctx, bucketName, item, uploader, downloader, attrs, conf.DryRun)
+			}
+			results[i] = SyncResult{Action: action, Key: item.key, Path: item.path, Size: item.size, Err: err}
+			progress.report(results[i])
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	if len(remoteDeletes) > 0 {
+		batcher := c.newDeleteBatcher(ctx, bucketName, conf.Concurrency, conf.DryRun)
+		for chunk := range slices.Chunk(remoteDeletes, maxDeleteObjectsKeys) {
+			keys := make(Keys, len(chunk))
+			for j, i := range chunk {
+				keys[j] = items[i].key
+			}
+			batcher.add(keys)
+		}
+		for j, deleted := range batcher.wait() {
+			item := items[remoteDeletes[j]]
+			results[remoteDeletes[j]] = SyncResult{Action: item.action, Key: item.key, Size: item.size, Err: deleted.Err}
+			progress.report(results[remoteDeletes[j]])
+		}
+	}
+	return results
+}
+
+// processSyncItem transfers or deletes a single file and returns the action that was taken.
+func (c *Client) processSyncItem(
+	ctx context.Context, bucketName BucketName, item syncItem,
+	uploader, downloader *transfermanager.Client, attrs uploadAttributes, dryRun bool,
+) (s3sync.Action, error) {
+	if item.local != nil && item.object != nil {
+		changed, err := fileChanged(*item.local, *item.object, item.action == s3sync.ActionUpload, c.encryption != nil)
+		if err != nil {
+			return item.action, err
+		}
+		if !changed {
+			return s3sync.ActionSkip, nil
+		}
+	}
+	if dryRun {
+		return item.action, nil
+	}
+	switch item.action {
+	case s3sync.ActionUpload:
+		return item.action, c.uploadFile(ctx, uploader, attrs, bucketName, item.key, item.path)
+	case s3sync.ActionDownload:
+		return item.action, downloadFile(ctx, downloader, bucketName, item.key, item.path, item.lastModified)
+	case s3sync.ActionDelete:
+		return item.action, os.Remove(item.path)
+	default:
+		return item.action, nil
+	}
+}
+
+// fileChanged reports whether the local file and the object differ.
+// Files of different sizes always differ. Otherwise the file is changed only when the source is newer,
+// and when the ETag of the object is an MD5 (single part, no SSE-KMS), only when the MD5 differs.
+// Objects written by a client with WithEncryption store the ciphertext, so their size is compared with the
+// encrypted size of the file and their ETag is not the MD5 of the file.
+func fileChanged(local localFile, obj types.Object, upload, encrypted bool) (bool, error) {
+	size := local.size
+	if encrypted {
+		size = encryptedSize(size)
+	}
+	if size != aws.ToInt64(obj.Size) {
+		return true, nil
+	}
+	lastModified := aws.ToTime(obj.LastModified)
+	newer := lastModified.After(local.modTime)
+	if upload {
+		newer = local.modTime.After(lastModified)
+	}
+	if !newer {
+		return false, nil
+	}
+	if encrypted {
+		return true, nil
+	}
+	etag := strings.Trim(aws.ToString(obj.ETag), `"`)
+	if len(etag) != hex.EncodedLen(md5.Size) || strings.Contains(etag, "-") {
+		return true, nil
+	}
+	sum, err := fileMD5(local.path)
+	if err != nil {
+		return false, err
+	}
+	return sum != etag, nil
+}
+
+func fileMD5(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := md5.New() // nolint:gosec
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *Client) uploadFile(
+	ctx context.Context, uploader *transfermanager.Client, attrs uploadAttributes,
+	bucketName BucketName, key Key, filePath string,
+) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	input := &transfermanager.UploadObjectInput{
+		Body:   f,
+		Bucket: bucketName.AWSString(),
+		Key:    key.AWSString(),
+	}
+	attrs.applyUploadObjectInput(input)
+	if input.ContentType == nil {
+		if contentType := mime.TypeByExtension(filepath.Ext(filePath)); contentType != "" {
+			input.ContentType = aws.String(contentType)
+		}
+	}
+	if err := c.encryptUploadObjectInput(ctx, input); err != nil {
+		return err
+	}
+	if _, err := uploader.UploadObject(ctx, input); err != nil {
+		return objectWriteError(err)
+	}
+	return nil
+}
+
+// downloadFile downloads an object to a temporary file in the destination directory and renames it to filePath,
+// so that an interrupted download never leaves a partial file behind.
+func downloadFile(
+	ctx context.Context, downloader *transfermanager.Client,
+	bucketName BucketName, key Key, filePath string, lastModified time.Time,
+	optFns ...func(*transfermanager.Options),
+) (err error) {
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, downloadTempPattern)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(f.Name())
+		}
+	}()
+	_, err = downloader.DownloadObject(ctx, &transfermanager.DownloadObjectInput{
+		Bucket:   bucketName.AWSString(),
+		Key:      key.AWSString(),
+		WriterAt: f,
+	}, optFns...)
+	if closeErr := f.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return objectReadError(err)
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		return err
+	}
+	if !lastModified.IsZero() {
+		if err := os.Chtimes(f.Name(), lastModified, lastModified); err != nil {
+			return err
+		}
+	}
+	return os.Rename(f.Name(), filePath)
+}
+
+// listLocalFiles returns the regular files under dir keyed by their slash-separated relative path.
+// Leftover temporary files of interrupted downloads are ignored.
+func listLocalFiles(dir string) (map[string]localFile, error) {
+	files := make(map[string]localFile)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if ok, _ := filepath.Match(downloadTempPattern, d.Name()); ok {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = localFile{path: p, size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// listSyncObjects returns the objects under prefix keyed by their path relative to the prefix.
+// Keys ending with "/" are directory markers and are ignored.
+func (c *Client) listSyncObjects(
+	ctx context.Context, bucketName BucketName, prefix string,
+) (map[string]types.Object, error) {
+	objects := make(map[string]types.Object)
+	dirPrefix := syncPrefix(prefix)
+	input, _ := newListObjectsV2Input(bucketName, s3list.WithPrefix(dirPrefix))
+	err := c.listObjectsPages(ctx, input, nil, func(output *s3.ListObjectsV2Output) bool {
+		for _, obj := range output.Contents {
+			key := aws.ToString(obj.Key)
+			if strings.HasSuffix(key, "/") {
+				continue
+			}
+			objects[strings.TrimPrefix(key, dirPrefix)] = obj
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// syncPrefix returns prefix with a trailing "/", so that "data" does not match "database/".
+func syncPrefix(prefix string) string {
+	if prefix == "" || strings.HasSuffix(prefix, "/") {
+		return prefix
+	}
+	return prefix + "/"
+}
+
+func syncKey(prefix, rel string) Key {
+	return Key(syncPrefix(prefix) + rel)
+}
+
+// syncProgress serialises the calls to the progress function.
+type syncProgress struct {
+	mu        sync.Mutex
+	fn        s3sync.ProgressFunc
+	completed int
+	total     int
+}
+
+func newSyncProgress(fn s3sync.ProgressFunc, total int) *syncProgress {
+	return &syncProgress{fn: fn, total: total}
+}
+
+func (p *syncProgress) report(r SyncResult) {
+	if p.fn == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.completed++
+	p.fn(s3sync.Progress{
+		Action:    r.Action,
+		Key:       r.Key.String(),
+		Path:      r.Path,
+		Size:      r.Size,
+		Err:       r.Err,
+		Completed: p.completed,
+		Total:     p.total,
+	})
+}
+
+func syncLogAttrs(results SyncResults) []slog.Attr {
+	return []slog.Attr{
+		slog.Int("transferred_count", len(results.Transferred())),
+		slog.Int("skipped_count", len(results.Skipped())),
+		slog.Int("deleted_count", len(results.Deleted())),
+		slog.Int("failed_count", len(results.Failed())),
+	}
+}
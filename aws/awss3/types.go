@@ -1,6 +1,9 @@
 package awss3
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
 	"net/url"
 	"path"
 	"path/filepath"
@@ -8,6 +11,8 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/88labs/go-utils/aws/awss3/options/s3sync"
 )
 
 type BucketName string
@@ -39,6 +44,10 @@ func (k Key) bucketJoinEscapedAWSString(bucketName BucketName) *string {
 	// segments must remain literal even though PathEscape encodes reserved
 	// characters inside each segment.
 	escapedKey := strings.ReplaceAll(url.PathEscape(k.String()), "%2F", "/")
+	if bucketName.isAccessPoint() {
+		// access points are copied from as arn:...:accesspoint/name/object/key
+		return aws.String(bucketName.String() + "/object/" + escapedKey)
+	}
 	return aws.String(bucketName.String() + "/" + escapedKey)
 }
 
@@ -82,3 +91,350 @@ func (o Objects) Find(key Key) (types.Object, bool) {
 	}
 	return types.Object{}, false
 }
+
+// ListEntry is an element yielded by ListObjectsIter.
+// When s3list.WithDelimiter is used, keys rolled up by the delimiter are
+// yielded once as a common prefix entry and Object is left empty.
+type ListEntry struct {
+	Object       types.Object
+	CommonPrefix *string
+}
+
+// IsCommonPrefix reports whether the entry is a common prefix rather than an object.
+func (e ListEntry) IsCommonPrefix() bool {
+	return e.CommonPrefix != nil
+}
+
+// Key returns the object key, or the common prefix for common prefix entries.
+func (e ListEntry) Key() Key {
+	if e.CommonPrefix != nil {
+		return Key(*e.CommonPrefix)
+	}
+	return Key(aws.ToString(e.Object.Key))
+}
+
+// DeleteResult is the outcome of deleting a single key with DeleteObjects or DeletePrefix.
+// Err is nil when the key was deleted.
+type DeleteResult struct {
+	Key Key
+	Err error
+}
+
+type DeleteResults []DeleteResult
+
+// Deleted returns the keys that were deleted.
+func (r DeleteResults) Deleted() Keys {
+	keys := make(Keys, 0, len(r))
+	for _, v := range r {
+		if v.Err == nil {
+			keys = append(keys, v.Key)
+		}
+	}
+	return keys
+}
+
+// Failed returns the results whose deletion failed.
+func (r DeleteResults) Failed() DeleteResults {
+	failed := make(DeleteResults, 0)
+	for _, v := range r {
+		if v.Err != nil {
+			failed = append(failed, v)
+		}
+	}
+	return failed
+}
+
+// Err joins the errors of all failed deletions. It returns nil when every key was deleted.
+func (r DeleteResults) Err() error {
+	errs := make([]error, 0)
+	for _, v := range r {
+		if v.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", v.Key, v.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DownloadResult is the outcome of downloading a single key with DownloadFilesParallelWithResults.
+// Err is nil when the file was downloaded or skipped.
+type DownloadResult struct {
+	Key  Key
+	Path string
+	// Bytes is the size of the file.
+	Bytes int64
+	// Attempts is the number of download attempts, or 0 when the file was skipped.
+	Attempts int
+	// Skipped is set when the file already existed with the same content (s3download.WithSkipExisting).
+	Skipped bool
+	Err     error
+}
+
+type DownloadResults []DownloadResult
+
+// Downloaded returns the results of the files that were downloaded.
+func (r DownloadResults) Downloaded() DownloadResults {
+	return r.filter(func(v DownloadResult) bool {
+		return v.Err == nil && !v.Skipped
+	})
+}
+
+// Skipped returns the results of the files that already existed.
+func (r DownloadResults) Skipped() DownloadResults {
+	return r.filter(func(v DownloadResult) bool {
+		return v.Err == nil && v.Skipped
+	})
+}
+
+// Failed returns the results whose download failed.
+func (r DownloadResults) Failed() DownloadResults {
+	return r.filter(func(v DownloadResult) bool {
+		return v.Err != nil
+	})
+}
+
+// Paths returns the paths of the files that were downloaded or skipped, in key order.
+func (r DownloadResults) Paths() []string {
+	paths := make([]string, 0, len(r))
+	for _, v := range r {
+		if v.Err == nil {
+			paths = append(paths, v.Path)
+		}
+	}
+	return paths
+}
+
+// Err joins the errors of all failed downloads. It returns nil when every key was downloaded or skipped.
+func (r DownloadResults) Err() error {
+	errs := make([]error, 0)
+	for _, v := range r {
+		if v.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", v.Key, v.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r DownloadResults) filter(fn func(v DownloadResult) bool) DownloadResults {
+	filtered := make(DownloadResults, 0)
+	for _, v := range r {
+		if fn(v) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// SyncResult is the outcome of a single file in UploadDirectory, DownloadPrefix or Sync.
+// Err is nil when the action succeeded.
+type SyncResult struct {
+	Action s3sync.Action
+	Key    Key
+	Path   string
+	Size   int64
+	Err    error
+}
+
+type SyncResults []SyncResult
+
+// Transferred returns the results of the files that were uploaded or downloaded.
+func (r SyncResults) Transferred() SyncResults {
+	return r.filter(func(v SyncResult) bool {
+		return v.Err == nil && (v.Action == s3sync.ActionUpload || v.Action == s3sync.ActionDownload)
+	})
+}
+
+// Skipped returns the results of the files that were unchanged.
+func (r SyncResults) Skipped() SyncResults {
+	return r.filter(func(v SyncResult) bool {
+		return v.Err == nil && v.Action == s3sync.ActionSkip
+	})
+}
+
+// Deleted returns the results of the files that were deleted.
+func (r SyncResults) Deleted() SyncResults {
+	return r.filter(func(v SyncResult) bool {
+		return v.Err == nil && v.Action == s3sync.ActionDelete
+	})
+}
+
+// Failed returns the results whose action failed.
+func (r SyncResults) Failed() SyncResults {
+	return r.filter(func(v SyncResult) bool {
+		return v.Err != nil
+	})
+}
+
+// Err joins the errors of all failed actions. It returns nil when every action succeeded.
+func (r SyncResults) Err() error {
+	errs := make([]error, 0)
+	for _, v := range r {
+		if v.Err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", v.Action, v.Key, v.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r SyncResults) filter(fn func(v SyncResult) bool) SyncResults {
+	filtered := make(SyncResults, 0)
+	for _, v := range r {
+		if fn(v) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// DeleteObjectError is the per-key error reported by the S3 DeleteObjects API.
+type DeleteObjectError struct {
+	Code    string
+	Message string
+}
+
+func (e *DeleteObjectError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// CopyObjectResult describes the destination object written by CopyObject.
+type CopyObjectResult struct {
+	ETag      string
+	VersionID string
+	// PartCount is the number of UploadPartCopy requests used for the copy,
+	// or 0 when the object was copied with a single CopyObject request.
+	PartCount int
+}
+
+// PresignedRequest is a presigned request returned by PresignPutObjectRequest.
+type PresignedRequest struct {
+	// URL is the presigned URL.
+	URL string
+	// Method is the HTTP method of the request.
+	Method string
+	// Headers are the signed headers the client must send with the request.
+	Headers http.Header
+}
+
+// PresignedPost is a presigned POST policy returned by PresignPostObject.
+type PresignedPost struct {
+	// URL is the URL the form is posted to.
+	URL string
+	// Fields are the form fields that must be sent before the file field.
+	Fields map[string]string
+}
+
+// LifecycleRule is a bucket lifecycle rule managed by GetLifecycleRules, PutLifecycleRule and DeleteLifecycleRule.
+// Durations are in days and 0 leaves the action unset.
+type LifecycleRule struct {
+	// ID identifies the rule within the bucket.
+	ID string
+	// Prefix limits the rule to the keys with the prefix. Empty applies the rule to the whole bucket.
+	Prefix string
+	// Tags limits the rule to the objects that have all the tags.
+	Tags map[string]string
+	// Disabled keeps the rule in the configuration without applying it.
+	Disabled bool
+	// ExpirationDays expires the current version of the objects after the number of days.
+	ExpirationDays int32
+	// NoncurrentVersionExpirationDays deletes noncurrent versions after the number of days.
+	NoncurrentVersionExpirationDays int32
+	// AbortIncompleteMultipartUploadDays aborts the multipart uploads not completed after the number of days.
+	// S3 does not accept it in rules filtered by Tags.
+	AbortIncompleteMultipartUploadDays int32
+	// Transitions moves the objects to other storage classes.
+	Transitions []LifecycleTransition
+}
+
+// LifecycleTransition moves objects to StorageClass after Days.
+type LifecycleTransition struct {
+	Days         int32
+	StorageClass types.TransitionStorageClass
+}
+
+func newLifecycleRule(rule types.LifecycleRule) LifecycleRule {
+	r := LifecycleRule{
+		ID:       aws.ToString(rule.ID),
+		Prefix:   aws.ToString(rule.Prefix), // nolint:staticcheck
+		Disabled: rule.Status != types.ExpirationStatusEnabled,
+	}
+	if f := rule.Filter; f != nil {
+		switch {
+		case f.And != nil:
+			r.Prefix = aws.ToString(f.And.Prefix)
+			r.Tags = tagSetMap(f.And.Tags)
+		case f.Tag != nil:
+			r.Tags = tagSetMap([]types.Tag{*f.Tag})
+		case f.Prefix != nil:
+			r.Prefix = aws.ToString(f.Prefix)
+		}
+	}
+	if rule.Expiration != nil {
+		r.ExpirationDays = aws.ToInt32(rule.Expiration.Days)
+	}
+	if rule.NoncurrentVersionExpiration != nil {
+		r.NoncurrentVersionExpirationDays = aws.ToInt32(rule.NoncurrentVersionExpiration.NoncurrentDays)
+	}
+	if rule.AbortIncompleteMultipartUpload != nil {
+		r.AbortIncompleteMultipartUploadDays = aws.ToInt32(rule.AbortIncompleteMultipartUpload.DaysAfterInitiation)
+	}
+	for _, t := range rule.Transitions {
+		r.Transitions = append(r.Transitions, LifecycleTransition{
+			Days:         aws.ToInt32(t.Days),
+			StorageClass: t.StorageClass,
+		})
+	}
+	return r
+}
+
+func (r LifecycleRule) sdkRule() types.LifecycleRule {
+	rule := types.LifecycleRule{
+		ID:     aws.String(r.ID),
+		Status: types.ExpirationStatusEnabled,
+		Filter: &types.LifecycleRuleFilter{},
+	}
+	if r.Disabled {
+		rule.Status = types.ExpirationStatusDisabled
+	}
+	tagSet := make([]types.Tag, 0, len(r.Tags))
+	for k, v := range r.Tags {
+		tagSet = append(tagSet, types.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	switch {
+	case len(tagSet) > 1 || len(tagSet) == 1 && r.Prefix != "":
+		rule.Filter.And = &types.LifecycleRuleAndOperator{Prefix: aws.String(r.Prefix), Tags: tagSet}
+	case len(tagSet) == 1:
+		rule.Filter.Tag = &tagSet[0]
+	default:
+		rule.Filter.Prefix = aws.String(r.Prefix)
+	}
+	if r.ExpirationDays > 0 {
+		rule.Expiration = &types.LifecycleExpiration{Days: aws.Int32(r.ExpirationDays)}
+	}
+	if r.NoncurrentVersionExpirationDays > 0 {
+		rule.NoncurrentVersionExpiration = &types.NoncurrentVersionExpiration{
+			NoncurrentDays: aws.Int32(r.NoncurrentVersionExpirationDays),
+		}
+	}
+	if r.AbortIncompleteMultipartUploadDays > 0 {
+		rule.AbortIncompleteMultipartUpload = &types.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: aws.Int32(r.AbortIncompleteMultipartUploadDays),
+		}
+	}
+	for _, t := range r.Transitions {
+		rule.Transitions = append(rule.Transitions, types.Transition{
+			Days:         aws.Int32(t.Days),
+			StorageClass: t.StorageClass,
+		})
+	}
+	return rule
+}
+
+func tagSetMap(tagSet []types.Tag) map[string]string {
+	if len(tagSet) == 0 {
+		return nil
+	}
+	tags := make(map[string]string, len(tagSet))
+	for _, t := range tagSet {
+		tags[aws.ToString(t.Key)] = aws.ToString(t.Value)
+	}
+	return tags
+}
@@ -0,0 +1,188 @@
+package awss3
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/transfermanager"
+	tmtypes "github.com/aws/aws-sdk-go-v2/feature/s3/transfermanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/88labs/go-utils/aws/awss3/options/s3upload"
+)
+
+// uploadAttributes holds the object attributes resolved from s3upload options,
+// so that PutObject, UploadManager, CreateMultipartUpload, UploadPart and Copy apply them identically.
+type uploadAttributes struct {
+	expires              *time.Time
+	contentType          *string
+	contentDisposition   *string
+	cacheControl         *string
+	metadata             map[string]string
+	tagging              *string
+	storageClass         types.StorageClass
+	acl                  types.ObjectCannedACL
+	serverSideEncryption types.ServerSideEncryption
+	sseKMSKeyID          *string
+	sseCustomerAlgorithm *string
+	sseCustomerKey       *string
+	sseCustomerKeyMD5    *string
+	checksumAlgorithm    types.ChecksumAlgorithm
+	ifMatch              *string
+	ifNoneMatch          *string
+}
+
+func newUploadAttributes(opts ...s3upload.OptionS3Upload) uploadAttributes {
+	conf := s3upload.GetS3UploadConf(opts...)
+	a := uploadAttributes{
+		contentType:          conf.ContentType,
+		contentDisposition:   conf.ContentDisposition,
+		cacheControl:         conf.CacheControl,
+		metadata:             conf.Metadata,
+		storageClass:         conf.StorageClass,
+		acl:                  conf.ACL,
+		serverSideEncryption: conf.ServerSideEncryption,
+		sseKMSKeyID:          conf.SSEKMSKeyID,
+		sseCustomerAlgorithm: conf.SSECustomerAlgorithm(),
+		sseCustomerKey:       conf.SSECustomerKeyBase64(),
+		sseCustomerKeyMD5:    conf.SSECustomerKeyMD5(),
+		checksumAlgorithm:    conf.ChecksumAlgorithm,
+		ifMatch:              conf.IfMatch,
+		ifNoneMatch:          conf.IfNoneMatch,
+	}
+	if conf.S3Expires != nil {
+		a.expires = aws.Time(time.Now().Add(*conf.S3Expires))
+	}
+	if len(conf.Tags) > 0 {
+		a.tagging = aws.String(encodeTags(conf.Tags))
+	}
+	return a
+}
+
+func (a uploadAttributes) applyPutObjectInput(input *s3.PutObjectInput) {
+	input.Expires = a.expires
+	input.ContentType = a.contentType
+	input.ContentDisposition = a.contentDisposition
+	input.CacheControl = a.cacheControl
+	input.Metadata = a.metadata
+	input.Tagging = a.tagging
+	input.StorageClass = a.storageClass
+	input.ACL = a.acl
+	input.ServerSideEncryption = a.serverSideEncryption
+	input.SSEKMSKeyId = a.sseKMSKeyID
+	input.SSECustomerAlgorithm = a.sseCustomerAlgorithm
+	input.SSECustomerKey = a.sseCustomerKey
+	input.SSECustomerKeyMD5 = a.sseCustomerKeyMD5
+	input.ChecksumAlgorithm = a.checksumAlgorithm
+	input.IfMatch = a.ifMatch
+	input.IfNoneMatch = a.ifNoneMatch
+}
+
+func (a uploadAttributes) applyUploadObjectInput(input *transfermanager.UploadObjectInput) {
+	input.Expires = a.expires
+	input.ContentType = a.contentType
+	input.ContentDisposition = a.contentDisposition
+	input.CacheControl = a.cacheControl
+	input.Metadata = a.metadata
+	input.Tagging = a.tagging
+	input.StorageClass = tmtypes.StorageClass(a.storageClass)
+	input.ACL = tmtypes.ObjectCannedACL(a.acl)
+	input.ServerSideEncryption = tmtypes.ServerSideEncryption(a.serverSideEncryption)
+	input.SSEKMSKeyID = a.sseKMSKeyID
+	input.SSECustomerAlgorithm = a.sseCustomerAlgorithm
+	input.SSECustomerKey = a.sseCustomerKey
+	input.SSECustomerKeyMD5 = a.sseCustomerKeyMD5
+	input.ChecksumAlgorithm = tmtypes.ChecksumAlgorithm(a.checksumAlgorithm)
+}
+
+func (a uploadAttributes) applyCreateMultipartUploadInput(input *s3.CreateMultipartUploadInput) {
+	input.Expires = a.expires
+	input.ContentType = a.contentType
+	input.ContentDisposition = a.contentDisposition
+	input.CacheControl = a.cacheControl
+	input.Metadata = a.metadata
+	input.Tagging = a.tagging
+	input.StorageClass = a.storageClass
+	input.ACL = a.acl
+	input.ServerSideEncryption = a.serverSideEncryption
+	input.SSEKMSKeyId = a.sseKMSKeyID
+	input.SSECustomerAlgorithm = a.sseCustomerAlgorithm
+	input.SSECustomerKey = a.sseCustomerKey
+	input.SSECustomerKeyMD5 = a.sseCustomerKeyMD5
+	input.ChecksumAlgorithm = a.checksumAlgorithm
+}
+
+// applyUploadPartInput applies the attributes S3 requires on every part:
+// the SSE-C key and the checksum algorithm chosen in CreateMultipartUpload.
+func (a uploadAttributes) applyUploadPartInput(input *s3.UploadPartInput) {
+	input.SSECustomerAlgorithm = a.sseCustomerAlgorithm
+	input.SSECustomerKey = a.sseCustomerKey
+	input.SSECustomerKeyMD5 = a.sseCustomerKeyMD5
+	input.ChecksumAlgorithm = a.checksumAlgorithm
+}
+
+// applyCompleteMultipartUploadInput applies the write preconditions, which S3 checks
+// when the multipart upload is completed, and the SSE-C key.
+func (a uploadAttributes) applyCompleteMultipartUploadInput(input *s3.CompleteMultipartUploadInput) {
+	input.IfMatch = a.ifMatch
+	input.IfNoneMatch = a.ifNoneMatch
+	input.SSECustomerAlgorithm = a.sseCustomerAlgorithm
+	input.SSECustomerKey = a.sseCustomerKey
+	input.SSECustomerKeyMD5 = a.sseCustomerKeyMD5
+}
+
+// uploadClient returns the client used by the transfer manager.
+// transfermanager.UploadObjectInput has no precondition fields, so they are set on the requests
+// that create the object.
+func (a uploadAttributes) uploadClient(client transfermanager.S3APIClient) transfermanager.S3APIClient {
+	if a.ifMatch == nil && a.ifNoneMatch == nil {
+		return client
+	}
+	return conditionalUploadClient{S3APIClient: client, ifMatch: a.ifMatch, ifNoneMatch: a.ifNoneMatch}
+}
+
+type conditionalUploadClient struct {
+	transfermanager.S3APIClient
+	ifMatch     *string
+	ifNoneMatch *string
+}
+
+func (c conditionalUploadClient) PutObject(
+	ctx context.Context, input *s3.PutObjectInput, optFns ...func(*s3.Options),
+) (*s3.PutObjectOutput, error) {
+	input.IfMatch = c.ifMatch
+	input.IfNoneMatch = c.ifNoneMatch
+	return c.S3APIClient.PutObject(ctx, input, optFns...)
+}
+
+func (c conditionalUploadClient) CompleteMultipartUpload(
+	ctx context.Context, input *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options),
+) (*s3.CompleteMultipartUploadOutput, error) {
+	input.IfMatch = c.ifMatch
+	input.IfNoneMatch = c.ifNoneMatch
+	return c.S3APIClient.CompleteMultipartUpload(ctx, input, optFns...)
+}
+
+func (a uploadAttributes) applyCopyObjectInput(input *s3.CopyObjectInput) {
+	input.Expires = a.expires
+	input.ContentType = a.contentType
+	input.ContentDisposition = a.contentDisposition
+	input.CacheControl = a.cacheControl
+	input.Metadata = a.metadata
+	if a.tagging != nil {
+		input.Tagging = a.tagging
+		input.TaggingDirective = types.TaggingDirectiveReplace
+	}
+	input.StorageClass = a.storageClass
+	input.ACL = a.acl
+	input.ServerSideEncryption = a.serverSideEncryption
+	input.SSEKMSKeyId = a.sseKMSKeyID
+	input.SSECustomerAlgorithm = a.sseCustomerAlgorithm
+	input.SSECustomerKey = a.sseCustomerKey
+	input.SSECustomerKeyMD5 = a.sseCustomerKeyMD5
+	input.ChecksumAlgorithm = a.checksumAlgorithm
+	input.IfMatch = a.ifMatch
+	input.IfNoneMatch = a.ifNoneMatch
+}
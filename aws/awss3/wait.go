@@ -0,0 +1,257 @@
+package awss3
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/88labs/go-utils/aws/awss3/options/s3head"
+	"github.com/88labs/go-utils/aws/awss3/options/s3list"
+	"github.com/88labs/go-utils/jitter"
+)
+
+// ErrWaitTimeout is returned by the WaitUntil functions when the condition is not met within the timeout
+// set by s3head.WithTimeout.
+var ErrWaitTimeout = errors.New("WaitTimeout")
+
+// Delays of the WaitUntil functions when s3head.WithMinDelay or s3head.WithMaxDelay resolve to zero,
+// the same as s3.ObjectExistsWaiter.
+const (
+	defaultWaitMinDelay = 5 * time.Second
+	defaultWaitMaxDelay = 120 * time.Second
+)
+
+// waitKeysConcurrency is the number of HeadObject requests WaitUntilObjectsExist makes at a time.
+const waitKeysConcurrency = 16
+
+// waitPolicy is the polling of the WaitUntil functions, configured by the s3head options.
+type waitPolicy struct {
+	timeout     time.Duration
+	minDelay    time.Duration
+	maxDelay    time.Duration
+	logAttempts bool
+}
+
+func newWaitPolicy(opts ...s3head.OptionS3Head) waitPolicy {
+	conf := s3head.GetS3HeadConf(opts...)
+	minDelay, maxDelay := conf.MinDelay, conf.MaxDelay
+	if minDelay <= 0 {
+		minDelay = defaultWaitMinDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = defaultWaitMaxDelay
+	}
+	return waitPolicy{
+		timeout:     conf.Timeout,
+		minDelay:    minDelay,
+		maxDelay:    max(maxDelay, minDelay),
+		logAttempts: conf.LogWaitAttempts,
+	}
+}
+
+// delay returns the wait after the given attempt: it doubles from MinDelay up to MaxDelay,
+// and is spread uniformly above MinDelay so that concurrent waiters do not poll in lockstep.
+func (p waitPolicy) delay(attempt int) time.Duration {
+	d := p.minDelay
+	for i := 1; i < attempt && d < p.maxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, p.maxDelay)
+	return p.minDelay + jitter.Apply(d-p.minDelay, 1.0)
+}
+
+// wait calls check until it reports true, returns an error, the timeout of the policy elapses or ctx is done.
+func (c *Client) wait(
+	ctx context.Context, operation string, policy waitPolicy, check func(ctx context.Context) (bool, error),
+) error {
+	waitCtx := ctx
+	if policy.timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, policy.timeout)
+		defer cancel()
+	}
+	for attempt := 1; ; attempt++ {
+		ok, err := check(waitCtx)
+		if ok {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() == nil && waitCtx.Err() != nil {
+				return ErrWaitTimeout
+			}
+			return err
+		}
+		delay := policy.delay(attempt)
+		if policy.logAttempts && c.logger != nil {
+			c.logger.LogAttrs(ctx, slog.LevelInfo, "awss3 waiting",
+				slog.String("component", "awss3"),
+				slog.String("operation", operation),
+				slog.Int("attempt", attempt),
+				slog.Duration("delay", delay),
+			)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-waitCtx.Done():
+			timer.Stop()
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return ErrWaitTimeout
+		case <-timer.C:
+		}
+	}
+}
+
+// isWaitPending reports whether a HeadObject error means that the object is not there yet.
+// Conditional options such as s3head.WithIfNoneMatch make an object that did not change pending too.
+func isWaitPending(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, ErrNotModified) || errors.Is(err, ErrPreconditionFailed)
+}
+
+// WaitUntilObjectExists polls HeadObject until the object exists and returns its metadata.
+// The polling is configured by the s3head options: it waits from MinDelay, doubling up to MaxDelay with jitter,
+// and gives up with ErrWaitTimeout after Timeout. A Timeout of zero waits until ctx is done.
+// LogWaitAttempts logs every attempt with the logger of the client.
+// The conditions of opts are waited for as well, so s3head.WithIfNoneMatch waits until an object changes.
+func (c *Client) WaitUntilObjectExists(
+	ctx context.Context, bucketName BucketName, key Key, opts ...s3head.OptionS3Head,
+) (res *s3.HeadObjectOutput, err error) {
+	done := c.logOperation(ctx, "WaitUntilObjectExists",
+		slog.String("bucket", bucketName.String()),
+		slog.String("key", key.String()),
+	)
+	defer func() {
+		done(err)
+	}()
+
+	input := newHeadObjectInput(bucketName, key, opts...)
+	err = c.wait(ctx, "WaitUntilObjectExists", newWaitPolicy(opts...), func(ctx context.Context) (bool, error) {
+		var headErr error
+		res, headErr = c.headObjectInput(ctx, input)
+		if isWaitPending(headErr) {
+			return false, nil
+		}
+		return headErr == nil, headErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// WaitUntilObjectNotExists polls HeadObject until the object does not exist.
+// The polling is configured by the s3head options as in WaitUntilObjectExists.
+func (c *Client) WaitUntilObjectNotExists(
+	ctx context.Context, bucketName BucketName, key Key, opts ...s3head.OptionS3Head,
+) (err error) {
+	done := c.logOperation(ctx, "WaitUntilObjectNotExists",
+		slog.String("bucket", bucketName.String()),
+		slog.String("key", key.String()),
+	)
+	defer func() {
+		done(err)
+	}()
+
+	input := newHeadObjectInput(bucketName, key, opts...)
+	return c.wait(ctx, "WaitUntilObjectNotExists", newWaitPolicy(opts...), func(ctx context.Context) (bool, error) {
+		_, headErr := c.headObjectInput(ctx, input)
+		switch {
+		case errors.Is(headErr, ErrNotFound):
+			return true, nil
+		case headErr == nil, isWaitPending(headErr):
+			return false, nil
+		default:
+			return false, headErr
+		}
+	})
+}
+
+// WaitUntilObjectsExist polls HeadObject until all the objects exist, for a stage of a pipeline that waits for
+// the output of another. Every attempt only requests the objects that were not found yet.
+// The polling is configured by the s3head options as in WaitUntilObjectExists.
+func (c *Client) WaitUntilObjectsExist(
+	ctx context.Context, bucketName BucketName, keys Keys, opts ...s3head.OptionS3Head,
+) (err error) {
+	done := c.logOperation(ctx, "WaitUntilObjectsExist",
+		slog.String("bucket", bucketName.String()),
+		slog.Int("key_count", len(keys)),
+	)
+	defer func() {
+		done(err)
+	}()
+
+	pending := make(map[Key]struct{}, len(keys))
+	for _, key := range keys {
+		pending[key] = struct{}{}
+	}
+	return c.wait(ctx, "WaitUntilObjectsExist", newWaitPolicy(opts...), func(ctx context.Context) (bool, error) {
+		var (
+			mu    sync.Mutex
+			found []Key
+		)
+		eg, egCtx := errgroup.WithContext(ctx)
+		eg.SetLimit(waitKeysConcurrency)
+		for key := range pending {
+			eg.Go(func() error {
+				_, headErr := c.headObjectInput(egCtx, newHeadObjectInput(bucketName, key, opts...))
+				if isWaitPending(headErr) {
+					return nil
+				}
+				if headErr != nil {
+					return headErr
+				}
+				mu.Lock()
+				found = append(found, key)
+				mu.Unlock()
+				return nil
+			})
+		}
+		err := eg.Wait()
+		for _, key := range found {
+			delete(pending, key)
+		}
+		if err != nil {
+			return false, err
+		}
+		return len(pending) == 0, nil
+	})
+}
+
+// WaitUntilPrefixCount polls ListObjects until at least count objects exist under prefix, and returns them.
+// The polling is configured by the s3head options as in WaitUntilObjectExists.
+func (c *Client) WaitUntilPrefixCount(
+	ctx context.Context, bucketName BucketName, prefix string, count int, opts ...s3head.OptionS3Head,
+) (objects Objects, err error) {
+	done := c.logOperation(ctx, "WaitUntilPrefixCount",
+		slog.String("bucket", bucketName.String()),
+		slog.String("prefix", prefix),
+		slog.Int("count", count),
+	)
+	defer func() {
+		done(err, slog.Int("object_count", len(objects)))
+	}()
+
+	err = c.wait(ctx, "WaitUntilPrefixCount", newWaitPolicy(opts...), func(ctx context.Context) (bool, error) {
+		input, _ := newListObjectsV2Input(bucketName, s3list.WithPrefix(prefix))
+		listed := make(Objects, 0)
+		listErr := c.listObjectsPages(ctx, input, nil, func(output *s3.ListObjectsV2Output) bool {
+			listed = append(listed, output.Contents...)
+			return len(listed) < count
+		})
+		if listErr != nil {
+			return false, listErr
+		}
+		objects = listed
+		return len(objects) >= count, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
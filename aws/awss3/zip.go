@@ -0,0 +1,243 @@
+package awss3
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/88labs/go-utils/aws/awss3/options/s3list"
+	"github.com/88labs/go-utils/aws/awss3/options/s3multipart"
+	"github.com/88labs/go-utils/aws/awss3/options/s3upload"
+	"github.com/88labs/go-utils/aws/awss3/options/s3zip"
+)
+
+// WriteZip streams the objects into a zip archive written to w, and returns the names of the files in the archive
+// in the order of the keys. w is not closed.
+// Objects are fetched in parallel ahead of the one being written (s3zip.WithConcurrency), buffering at most
+// s3zip.WithPrefetchSize bytes of each, so large objects are not held in memory.
+// File names are the base names of the keys; as in DownloadFiles, they can be replaced with
+// s3zip.WithFileNameReplacerFunc, and duplicated names get a sequential number suffix.
+// s3zip.WithPrefix adds the objects under a prefix.
+func (c *Client) WriteZip(
+	ctx context.Context, bucketName BucketName, keys Keys, w io.Writer, opts ...s3zip.OptionS3Zip,
+) (names []string, err error) {
+	conf := s3zip.GetS3ZipConf(opts...)
+	attrs := []slog.Attr{
+		slog.String("bucket", bucketName.String()),
+		slog.Int("key_count", len(keys)),
+	}
+	if conf.Prefix != nil {
+		attrs = append(attrs, slog.String("prefix", *conf.Prefix))
+	}
+	done := c.logOperation(ctx, "WriteZip", attrs...)
+	var written int64
+	defer func() {
+		done(err, slog.Int("file_count", len(names)), slog.Int64("bytes_written", written))
+	}()
+
+	if conf.Prefix != nil {
+		if keys, err = c.appendPrefixKeys(ctx, bucketName, keys, *conf.Prefix); err != nil {
+			return nil, err
+		}
+	}
+	keys = keys.Unique()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	fetches := make(chan *zipFetch, conf.Concurrency)
+	go func() {
+		defer close(fetches)
+		for _, key := range keys {
+			f := &zipFetch{key: key, ready: make(chan struct{})}
+			select {
+			case fetches <- f:
+			case <-ctx.Done():
+				return
+			}
+			go f.fetch(ctx, c, bucketName, conf.PrefetchSize)
+		}
+	}()
+
+	cw := &countingWriter{w: w}
+	zw := zip.NewWriter(cw)
+	names = make([]string, 0, len(keys))
+	used := make(map[string]struct{}, len(keys))
+	for f := range fetches {
+		<-f.ready
+		if err == nil {
+			name := zipEntryName(f.key, conf.FileNameReplacer, used)
+			if err = f.writeTo(zw, name, conf.Method); err == nil {
+				names = append(names, name)
+			} else {
+				// stop the prefetch, the remaining fetches are only drained to close their bodies
+				cancel()
+			}
+		}
+		f.close()
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	written = cw.n
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// UploadZip streams the objects into a zip archive uploaded to destKey of destBucketName with a MultipartUpload,
+// without storing the archive locally. It accepts the options of WriteZip, and s3zip.WithMultipartOptions sets
+// the part size and the attributes of the archive. The upload is aborted when an object cannot be written.
+// On a client with WithEncryption, the objects are decrypted and the archive is encrypted, as with
+// NewMultipartUpload, so the part size must be a multiple of 64 KiB.
+func (c *Client) UploadZip(
+	ctx context.Context, bucketName BucketName, keys Keys, destBucketName BucketName, destKey Key,
+	opts ...s3zip.OptionS3Zip,
+) (names []string, err error) {
+	conf := s3zip.GetS3ZipConf(opts...)
+	multipartOpts := append([]s3multipart.OptionS3Multipart{
+		s3multipart.WithUploadOptions(s3upload.WithContentType("application/zip")),
+	}, conf.MultipartOptions...)
+	u, err := c.NewMultipartUpload(ctx, destBucketName, destKey, multipartOpts...)
+	if err != nil {
+		return nil, err
+	}
+	names, err = c.WriteZip(ctx, bucketName, keys, u, opts...)
+	if err != nil {
+		return nil, errors.Join(err, u.Abort())
+	}
+	if err := u.Close(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// appendPrefixKeys appends the keys of the objects under prefix to keys. Folder placeholders are skipped.
+func (c *Client) appendPrefixKeys(ctx context.Context, bucketName BucketName, keys Keys, prefix string) (Keys, error) {
+	input, _ := newListObjectsV2Input(bucketName, s3list.WithPrefix(prefix))
+	keys = append(Keys{}, keys...)
+	err := c.listObjectsPages(ctx, input, nil, func(output *s3.ListObjectsV2Output) bool {
+		for _, o := range output.Contents {
+			if key := aws.ToString(o.Key); !strings.HasSuffix(key, "/") {
+				keys = append(keys, Key(key))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// zipEntryName returns the name of key in the archive, adding a sequential number when it is already used.
+func zipEntryName(key Key, replacer func(string, string) string, used map[string]struct{}) string {
+	fileName := path.Base(key.String())
+	if replacer != nil {
+		fileName = replacer(key.String(), fileName)
+	}
+	name := fileName
+	for i := 2; ; i++ {
+		if _, ok := used[name]; !ok {
+			break
+		}
+		ext := path.Ext(fileName)
+		name = fmt.Sprintf("%s_%d%s", strings.TrimSuffix(fileName, ext), i, ext)
+	}
+	used[name] = struct{}{}
+	return name
+}
+
+// zipFetch is an object fetched ahead of being written to the archive.
+// head holds the first bytes of the object, and body the rest when the object is larger than the prefetch size.
+type zipFetch struct {
+	key      Key
+	ready    chan struct{}
+	head     []byte
+	body     io.ReadCloser
+	modified time.Time
+	err      error
+}
+
+func (f *zipFetch) fetch(ctx context.Context, c *Client, bucketName BucketName, prefetchSize int64) {
+	defer close(f.ready)
+	f.err = c.retryer.do(ctx, "WriteZip", func(ctx context.Context) error {
+		resp, err := c.client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: bucketName.AWSString(),
+			Key:    f.key.AWSString(),
+		}, withoutRetryMiddleware)
+		if err != nil {
+			return objectReadError(err)
+		}
+		size := prefetchSize
+		if n := aws.ToInt64(resp.ContentLength); n >= 0 && n < size {
+			// one more byte to read the end of the body
+			size = n + 1
+		}
+		head := make([]byte, size)
+		n, err := io.ReadFull(resp.Body, head)
+		switch {
+		case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+			_ = resp.Body.Close()
+		case err != nil:
+			_ = resp.Body.Close()
+			return err
+		default:
+			f.body = resp.Body
+		}
+		f.head = head[:n]
+		f.modified = aws.ToTime(resp.LastModified)
+		return nil
+	})
+}
+
+func (f *zipFetch) writeTo(zw *zip.Writer, name string, method uint16) error {
+	if f.err != nil {
+		return fmt.Errorf("%s: %w", f.key, f.err)
+	}
+	w, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   method,
+		Modified: f.modified,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(f.head); err != nil {
+		return err
+	}
+	if f.body != nil {
+		if _, err := io.Copy(w, f.body); err != nil {
+			return fmt.Errorf("%s: %w", f.key, err)
+		}
+	}
+	return nil
+}
+
+func (f *zipFetch) close() {
+	if f.body != nil {
+		_ = f.body.Close()
+	}
+	f.head = nil
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -27,7 +27,7 @@ func SendMessage(
 	if err != nil {
 		return nil, err
 	}
-	return (&Client{client: sdkClient}).SendMessage(ctx, queueURL, message, opts...)
+	return packageClientFromSDK(sdkClient).SendMessage(ctx, queueURL, message, opts...)
 }
 
 // SendMessageGob
@@ -43,7 +43,7 @@ func SendMessageGob(
 	if err != nil {
 		return nil, err
 	}
-	return (&Client{client: sdkClient}).SendMessageGob(ctx, queueURL, message, opts...)
+	return packageClientFromSDK(sdkClient).SendMessageGob(ctx, queueURL, message, opts...)
 }
 
 // ReceiveMessage
@@ -58,7 +58,7 @@ func ReceiveMessage(
 	if err != nil {
 		return nil, err
 	}
-	return (&Client{client: sdkClient}).ReceiveMessage(ctx, queueURL, opts...)
+	return packageClientFromSDK(sdkClient).ReceiveMessage(ctx, queueURL, opts...)
 }
 
 // ReceiveMessageGob
@@ -70,20 +70,11 @@ func ReceiveMessage(
 func ReceiveMessageGob[T any](
 	ctx context.Context, region awsconfig.Region, queueURL QueueURL, _ T, opts ...sqsreceive.ReceiveMessageOption,
 ) ([]*T, *sqs.ReceiveMessageOutput, error) {
-	c := sqsreceive.GetConf(opts...)
-	client, err := GetClient(ctx, region)
+	sdkClient, err := GetClient(ctx, region)
 	if err != nil {
 		return nil, nil, err
 	}
-
-	params := &sqs.ReceiveMessageInput{
-		QueueUrl:              queueURL.AWSString(),
-		MaxNumberOfMessages:   c.MaxNumberOfMessages,
-		WaitTimeSeconds:       c.WaitTimeSeconds,
-		VisibilityTimeout:     c.VisibilityTimeout,
-		MessageAttributeNames: []string{"All"},
-	}
-	sqsRes, err := client.ReceiveMessage(ctx, params)
+	sqsRes, err := packageClientFromSDK(sdkClient).ReceiveMessage(ctx, queueURL, opts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -118,5 +109,5 @@ func DeleteMessage(ctx context.Context, region awsconfig.Region, queueURL QueueU
 	if err != nil {
 		return err
 	}
-	return (&Client{client: sdkClient}).DeleteMessage(ctx, queueURL, message)
+	return packageClientFromSDK(sdkClient).DeleteMessage(ctx, queueURL, message)
 }
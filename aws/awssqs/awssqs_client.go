@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"encoding/gob"
 	"encoding/json"
+	"log/slog"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
@@ -19,7 +20,16 @@ import (
 // Default DelaySeconds=0.
 func (c *Client) SendMessage(
 	ctx context.Context, queueURL QueueURL, message any, opts ...sqssend.SendMessageOption,
-) (*sqs.SendMessageOutput, error) {
+) (res *sqs.SendMessageOutput, err error) {
+	done := c.logOperation(ctx, "SendMessage", slog.String("queue_url", queueURL.String()))
+	defer func() {
+		if res != nil {
+			done(err, slog.String("message_id", aws.ToString(res.MessageId)))
+			return
+		}
+		done(err)
+	}()
+
 	conf := sqssend.GetConf(opts...)
 	jsonb, err := json.Marshal(message)
 	if err != nil {
@@ -42,7 +52,16 @@ func (c *Client) SendMessage(
 // Default DelaySeconds=0.
 func (c *Client) SendMessageGob(
 	ctx context.Context, queueURL QueueURL, message any, opts ...sqssend.SendMessageOption,
-) (*sqs.SendMessageOutput, error) {
+) (res *sqs.SendMessageOutput, err error) {
+	done := c.logOperation(ctx, "SendMessageGob", slog.String("queue_url", queueURL.String()))
+	defer func() {
+		if res != nil {
+			done(err, slog.String("message_id", aws.ToString(res.MessageId)))
+			return
+		}
+		done(err)
+	}()
+
 	conf := sqssend.GetConf(opts...)
 	var buf bytes.Buffer
 	enc := gob.NewEncoder(&buf)
@@ -67,7 +86,16 @@ func (c *Client) SendMessageGob(
 // Default MaxNumberOfMessages=1, WaitTimeSeconds=20, VisibilityTimeout=30.
 func (c *Client) ReceiveMessage(
 	ctx context.Context, queueURL QueueURL, opts ...sqsreceive.ReceiveMessageOption,
-) (*sqs.ReceiveMessageOutput, error) {
+) (res *sqs.ReceiveMessageOutput, err error) {
+	done := c.logOperation(ctx, "ReceiveMessage", slog.String("queue_url", queueURL.String()))
+	defer func() {
+		if res != nil {
+			done(err, slog.Int("message_count", len(res.Messages)))
+			return
+		}
+		done(err)
+	}()
+
 	conf := sqsreceive.GetConf(opts...)
 	params := &sqs.ReceiveMessageInput{
 		QueueUrl:              queueURL.AWSString(),
@@ -84,7 +112,15 @@ func (c *Client) ReceiveMessage(
 }
 
 // DeleteMessage deletes a message from SQS.
-func (c *Client) DeleteMessage(ctx context.Context, queueURL QueueURL, message types.Message) error {
+func (c *Client) DeleteMessage(ctx context.Context, queueURL QueueURL, message types.Message) (err error) {
+	done := c.logOperation(ctx, "DeleteMessage",
+		slog.String("queue_url", queueURL.String()),
+		slog.String("message_id", aws.ToString(message.MessageId)),
+	)
+	defer func() {
+		done(err)
+	}()
+
 	params := &sqs.DeleteMessageInput{
 		QueueUrl:      queueURL.AWSString(),
 		ReceiptHandle: message.ReceiptHandle,
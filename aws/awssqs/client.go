@@ -3,7 +3,7 @@ package awssqs
 import (
 	"context"
 	"fmt"
-	"sync/atomic"
+	"log/slog"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
@@ -11,33 +11,45 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 
 	"github.com/88labs/go-utils/aws/awsconfig"
+	"github.com/88labs/go-utils/aws/awscredentials"
+	"github.com/88labs/go-utils/aws/awstransport"
 	"github.com/88labs/go-utils/aws/ctxawslocal"
+	"github.com/88labs/go-utils/aws/internal/awsmetrics"
 	"github.com/88labs/go-utils/aws/internal/awstrace"
 )
 
-var sqsClientAtomic atomic.Pointer[sqs.Client]
+// GlobalLogger is used by package-level helpers such as SendMessage and ReceiveMessage.
+// It is nil by default, which disables logging.
+var GlobalLogger *slog.Logger
 
 // Client is an SQS client that manages its own SDK client instance.
-// Unlike the package-level functions that use a singleton, each Client holds
+// Unlike the package-level functions that share the clients of DefaultRegistry, each Client holds
 // its own *sqs.Client, enabling external lifecycle management.
 type Client struct {
 	client *sqs.Client
+	logger *slog.Logger
 }
 
 // NewClient creates a new Client for the given region.
 // Using ctxawslocal.WithContext, you can make requests for local mocks.
+// Use a Registry to share the clients of several regions and options.
 func NewClient(ctx context.Context, region awsconfig.Region, opts ...ClientOption) (*Client, error) {
-	cfg := clientConfig{}
-	for _, opt := range opts {
-		if opt != nil {
-			opt.apply(&cfg)
-		}
-	}
+	return newClientWithConfig(ctx, region, newClientConfig(opts...))
+}
+
+func newClientWithConfig(ctx context.Context, region awsconfig.Region, cfg clientConfig) (*Client, error) {
 	sdkClient, err := newSQSClient(ctx, region, cfg)
 	if err != nil {
 		return nil, err
 	}
-	return &Client{client: sdkClient}, nil
+	return &Client{client: sdkClient, logger: cfg.logger}, nil
+}
+
+func packageClientFromSDK(sdkClient *sqs.Client) *Client {
+	return &Client{
+		client: sdkClient,
+		logger: GlobalLogger,
+	}
 }
 
 // SQSClient returns the underlying *sqs.Client for advanced usage.
@@ -45,41 +57,48 @@ func (c *Client) SQSClient() *sqs.Client {
 	return c.client
 }
 
-// GetClient returns the package-level singleton SQS client for aws-sdk-go v2.
+// GetClient returns the package-level SQS client of the region for aws-sdk-go v2 from DefaultRegistry.
 // Using ctxawslocal.WithContext, you can make requests for local mocks.
-// Options are used only when the singleton is initialized.
+//
+// Each region and endpoint has its own client. Without options, GetClient returns the client created first
+// for the region, so that the package-level helpers use the client initialized by GetClient.
+// With options, it returns the client of those options.
 func GetClient(ctx context.Context, region awsconfig.Region, opts ...ClientOption) (*sqs.Client, error) {
-	if v := sqsClientAtomic.Load(); v != nil {
-		return v, nil
-	}
-	cfg := clientConfig{}
-	for _, opt := range opts {
-		if opt != nil {
-			opt.apply(&cfg)
-		}
+	var (
+		c   *Client
+		err error
+	)
+	if len(opts) == 0 {
+		c, err = DefaultRegistry.first(ctx, region)
+	} else {
+		c, err = DefaultRegistry.Client(ctx, region, opts...)
 	}
-	sdkClient, err := newSQSClient(ctx, region, cfg)
 	if err != nil {
 		return nil, err
 	}
-	sqsClientAtomic.Store(sdkClient)
-	return sdkClient, nil
+	return c.client, nil
 }
 
-// newSQSClient creates a fresh *sqs.Client without touching the singleton.
+// newSQSClient creates a fresh *sqs.Client without touching the registry.
 func newSQSClient(ctx context.Context, region awsconfig.Region, cfg clientConfig) (*sqs.Client, error) {
 	if localProfile, ok := getLocalEndpoint(ctx); ok {
 		return getClientLocal(ctx, *localProfile, cfg)
 	}
 	// SQS Client
-	awsCfg, err := awsConfig.LoadDefaultConfig(ctx, awsConfig.WithRegion(region.String()))
+	awsCfg, err := awscredentials.LoadDefaultConfig(ctx, cfg.credentialsOptions, awsConfig.WithRegion(region.String()))
 	if err != nil {
 		return nil, fmt.Errorf("unable to load SDK config, %w", err)
 	}
 	return sqs.NewFromConfig(awsCfg, func(o *sqs.Options) {
+		if len(cfg.transportOptions) > 0 {
+			o.HTTPClient = awstransport.ConfigureHTTPClient(o.HTTPClient, cfg.transportOptions...)
+		}
 		if cfg.traceEnabled {
 			awstrace.AppendMiddlewares(&o.APIOptions, cfg.traceProvider)
 		}
+		if cfg.metricsEnabled {
+			awsmetrics.AppendMiddlewares(&o.APIOptions, cfg.meterProvider)
+		}
 	}), nil
 }
 
@@ -99,9 +118,15 @@ func getClientLocal(ctx context.Context, localProfile LocalProfile, cfg clientCo
 	}
 	return sqs.NewFromConfig(awsCfg, func(o *sqs.Options) {
 		o.BaseEndpoint = aws.String(localProfile.Endpoint)
+		if len(cfg.transportOptions) > 0 {
+			o.HTTPClient = awstransport.ConfigureHTTPClient(o.HTTPClient, cfg.transportOptions...)
+		}
 		if cfg.traceEnabled {
 			awstrace.AppendMiddlewares(&o.APIOptions, cfg.traceProvider)
 		}
+		if cfg.metricsEnabled {
+			awsmetrics.AppendMiddlewares(&o.APIOptions, cfg.meterProvider)
+		}
 	}), nil
 }
 
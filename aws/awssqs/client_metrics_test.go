@@ -0,0 +1,77 @@
+package awssqs_test
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/88labs/go-utils/aws/awssqs"
+	"github.com/88labs/go-utils/aws/ctxawslocal"
+)
+
+func TestNewClient_WithMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		_, _ = w.Write([]byte(`{"QueueUrls":[]}`))
+	}))
+	t.Cleanup(server.Close)
+	ctx := ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithSQSEndpoint(server.URL),
+		ctxawslocal.WithAccessKey("test"),
+		ctxawslocal.WithSecretAccessKey("test"),
+	)
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	client, err := awssqs.NewClient(ctx, TestRegion, awssqs.WithMetrics(provider))
+	require.NoError(t, err)
+	_, err = client.SQSClient().ListQueues(ctx, &sqs.ListQueuesInput{})
+	require.NoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	assert.Equal(t, "github.com/88labs/go-utils/aws", rm.ScopeMetrics[0].Scope.Name)
+
+	operation := attribute.NewSet(attribute.String("service", "SQS"), attribute.String("operation", "ListQueues"))
+	status := attribute.NewSet(
+		attribute.String("service", "SQS"), attribute.String("operation", "ListQueues"), attribute.String("status", "ok"),
+	)
+	metrics := map[string]metricdata.Aggregation{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m.Data
+	}
+	assert.ElementsMatch(t, []string{
+		"aws.client.requests", "aws.client.request.duration", "aws.client.request.size", "aws.client.response.size",
+	}, slices.Collect(maps.Keys(metrics)))
+
+	requests, ok := metrics["aws.client.requests"].(metricdata.Sum[int64])
+	if assert.True(t, ok) && assert.Len(t, requests.DataPoints, 1) {
+		assert.Equal(t, status, requests.DataPoints[0].Attributes)
+		assert.Equal(t, int64(1), requests.DataPoints[0].Value)
+	}
+	duration, ok := metrics["aws.client.request.duration"].(metricdata.Histogram[float64])
+	if assert.True(t, ok) && assert.Len(t, duration.DataPoints, 1) {
+		assert.Equal(t, status, duration.DataPoints[0].Attributes)
+		assert.Equal(t, uint64(1), duration.DataPoints[0].Count)
+	}
+	for _, name := range []string{"aws.client.request.size", "aws.client.response.size"} {
+		size, ok := metrics[name].(metricdata.Sum[int64])
+		if assert.True(t, ok, name) && assert.Len(t, size.DataPoints, 1, name) {
+			assert.Equal(t, operation, size.DataPoints[0].Attributes, name)
+			assert.Positive(t, size.DataPoints[0].Value, name)
+		}
+	}
+}
@@ -1,6 +1,16 @@
 package awssqs
 
-import oteltrace "go.opentelemetry.io/otel/trace"
+import (
+	"log/slog"
+
+	"go.opentelemetry.io/otel/metric"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/88labs/go-utils/aws/awscredentials"
+	"github.com/88labs/go-utils/aws/awstransport"
+	"github.com/88labs/go-utils/aws/internal/awslog"
+)
 
 // ClientOption configures a Client created with NewClient.
 type ClientOption interface {
@@ -8,8 +18,13 @@ type ClientOption interface {
 }
 
 type clientConfig struct {
-	traceProvider oteltrace.TracerProvider
-	traceEnabled  bool
+	logger             *slog.Logger
+	traceProvider      oteltrace.TracerProvider
+	traceEnabled       bool
+	meterProvider      metric.MeterProvider
+	metricsEnabled     bool
+	transportOptions   []awstransport.OptionTransport
+	credentialsOptions []awscredentials.OptionCredentials
 }
 
 type clientOptionFunc func(*clientConfig)
@@ -18,6 +33,39 @@ func (f clientOptionFunc) apply(cfg *clientConfig) {
 	f(cfg)
 }
 
+// newClientConfig applies opts to the default configuration.
+func newClientConfig(opts ...ClientOption) clientConfig {
+	cfg := clientConfig{
+		logger: GlobalLogger,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt.apply(&cfg)
+		}
+	}
+	return cfg
+}
+
+// WithLogger configures a Client to emit structured logs via slog.
+// When logger is nil, a no-op logger is used.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return clientOptionFunc(func(cfg *clientConfig) {
+		cfg.logger = awslog.Normalize(logger)
+	})
+}
+
+// WithZapLogger configures a Client to emit structured logs via a zap logger.
+// When logger is nil, a no-op logger is used.
+func WithZapLogger(logger *zap.Logger) ClientOption {
+	return WithLogger(NewLoggerFromZap(logger))
+}
+
+// NewLoggerFromZap bridges a zap logger into slog so it can be used with awssqs.
+// When logger is nil, a no-op logger is returned.
+func NewLoggerFromZap(logger *zap.Logger) *slog.Logger {
+	return awslog.NewLoggerFromZap(logger)
+}
+
 // WithTrace enables OpenTelemetry tracing for AWS SDK requests created by the
 // client. A nil provider uses the globally configured OpenTelemetry provider.
 // Datadog v2 spans in request contexts are also accepted as trace parents.
@@ -27,3 +75,31 @@ func WithTrace(provider oteltrace.TracerProvider) ClientOption {
 		cfg.traceEnabled = true
 	})
 }
+
+// WithMetrics records OpenTelemetry metrics of the requests of the client: the number and the latency of
+// the operations, the retries, the throttling errors and the bytes transferred, labelled by service,
+// operation and status. A nil provider uses the globally configured OpenTelemetry provider.
+func WithMetrics(provider metric.MeterProvider) ClientOption {
+	return clientOptionFunc(func(cfg *clientConfig) {
+		cfg.meterProvider = provider
+		cfg.metricsEnabled = true
+	})
+}
+
+// WithTransport configures the HTTP transport of the client: dialer, TLS, proxy, connection pool,
+// timeouts, HTTP/2 and RoundTripper hooks. Settings that are not set keep the defaults of the AWS SDK.
+func WithTransport(opts ...awstransport.OptionTransport) ClientOption {
+	return clientOptionFunc(func(cfg *clientConfig) {
+		cfg.transportOptions = append(cfg.transportOptions, opts...)
+	})
+}
+
+// WithCredentials configures the credentials of the client: STS AssumeRole with an external ID, a session name
+// and a duration, web identity token files, shared config profiles and custom credential providers.
+// Credentials are cached and refreshed before they expire. They are ignored for local endpoints, which use the
+// static credentials of ctxawslocal. It can be given more than once; later options take precedence.
+func WithCredentials(opts ...awscredentials.OptionCredentials) ClientOption {
+	return clientOptionFunc(func(cfg *clientConfig) {
+		cfg.credentialsOptions = append(cfg.credentialsOptions, opts...)
+	})
+}
@@ -0,0 +1,26 @@
+package awssqs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/88labs/go-utils/aws/awssqs"
+	"github.com/88labs/go-utils/aws/awstransport"
+)
+
+func TestNewClient_WithTransport(t *testing.T) {
+	client, err := awssqs.NewClient(context.Background(), TestRegion, awssqs.WithTransport(
+		awstransport.WithDialTimeout(3*time.Second),
+		awstransport.WithMaxIdleConnsPerHost(64),
+	))
+	assert.NoError(t, err)
+	httpClient, ok := client.SQSClient().Options().HTTPClient.(*awshttp.BuildableClient)
+	if assert.True(t, ok) {
+		assert.Equal(t, 3*time.Second, httpClient.GetDialer().Timeout)
+		assert.Equal(t, 64, httpClient.GetTransport().MaxIdleConnsPerHost)
+	}
+}
@@ -0,0 +1,151 @@
+package awssqs_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/88labs/go-utils/aws/awsconfig"
+	"github.com/88labs/go-utils/aws/awssqs"
+	"github.com/88labs/go-utils/aws/ctxawslocal"
+)
+
+// newLoggingTestContext returns a context whose SQS endpoint sends messages and fails to delete them.
+func newLoggingTestContext(t *testing.T) context.Context {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		if strings.HasSuffix(r.Header.Get("X-Amz-Target"), ".DeleteMessage") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"com.amazonaws.sqs#ReceiptHandleIsInvalid","message":"invalid receipt handle"}`))
+			return
+		}
+		var input struct{ MessageBody string }
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		_, _ = fmt.Fprintf(w, `{"MD5OfMessageBody":"%x","MessageId":"message-id"}`, md5.Sum([]byte(input.MessageBody)))
+	}))
+	t.Cleanup(server.Close)
+	return ctxawslocal.WithContext(
+		context.Background(),
+		ctxawslocal.WithSQSEndpoint(server.URL),
+		ctxawslocal.WithAccessKey("test"),
+		ctxawslocal.WithSecretAccessKey("test"),
+	)
+}
+
+func decodeLastJSONLogEntry(t *testing.T, logs string) map[string]any {
+	t.Helper()
+	lines := strings.Split(strings.TrimSpace(logs), "\n")
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	return entry
+}
+
+func TestNewClient_WithLogger_logsSendMessage(t *testing.T) {
+	ctx := newLoggingTestContext(t)
+	var buf bytes.Buffer
+	client, err := awssqs.NewClient(ctx, awsconfig.RegionTokyo, awssqs.WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	queueURL := awssqs.QueueURL("http://localhost/000000000000/queue")
+	if _, err := client.SendMessageGob(ctx, queueURL, "message"); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := decodeLastJSONLogEntry(t, buf.String())
+	for k, want := range map[string]any{
+		"level":      "INFO",
+		"msg":        "awssqs operation completed",
+		"component":  "awssqs",
+		"operation":  "SendMessageGob",
+		"queue_url":  queueURL.String(),
+		"message_id": "message-id",
+	} {
+		if entry[k] != want {
+			t.Errorf("%s = %v, want %v", k, entry[k], want)
+		}
+	}
+	if _, ok := entry["duration"]; !ok {
+		t.Error("duration is not logged")
+	}
+}
+
+func TestNewClient_WithZapLogger_logsFailure(t *testing.T) {
+	ctx := newLoggingTestContext(t)
+	core, observedLogs := observer.New(zap.InfoLevel)
+	client, err := awssqs.NewClient(ctx, awsconfig.RegionTokyo, awssqs.WithZapLogger(zap.New(core)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	queueURL := awssqs.QueueURL("http://localhost/000000000000/queue")
+	if err := client.DeleteMessage(ctx, queueURL, types.Message{
+		MessageId: aws.String("message-id"), ReceiptHandle: aws.String("invalid"),
+	}); err == nil {
+		t.Fatal("DeleteMessage with an invalid receipt handle succeeded")
+	}
+
+	entries := observedLogs.All()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(entries))
+	}
+	entry := entries[0]
+	if entry.Level != zap.ErrorLevel || entry.Message != "awssqs operation failed" {
+		t.Fatalf("logged %v %q, want error %q", entry.Level, entry.Message, "awssqs operation failed")
+	}
+	fields := entry.ContextMap()
+	if fields["operation"] != "DeleteMessage" || fields["queue_url"] != queueURL.String() {
+		t.Errorf("fields = %v", fields)
+	}
+	if _, ok := fields["error"]; !ok {
+		t.Error("error is not logged")
+	}
+}
+
+func TestGlobalLogger_logsPackageHelpers(t *testing.T) {
+	ctx := newLoggingTestContext(t)
+	var buf bytes.Buffer
+	awssqs.GlobalLogger = slog.New(slog.NewJSONHandler(&buf, nil))
+	t.Cleanup(func() { awssqs.GlobalLogger = nil })
+
+	queueURL := awssqs.QueueURL("http://localhost/000000000000/queue")
+	if _, err := awssqs.SendMessage(ctx, awsconfig.RegionTokyo, queueURL, map[string]string{"key": "value"}); err != nil {
+		t.Fatal(err)
+	}
+	entry := decodeLastJSONLogEntry(t, buf.String())
+	if entry["operation"] != "SendMessage" || entry["queue_url"] != queueURL.String() {
+		t.Fatalf("entry = %v", entry)
+	}
+}
+
+func TestNewClient_WithLogger_nil(t *testing.T) {
+	ctx := newLoggingTestContext(t)
+	var buf bytes.Buffer
+	awssqs.GlobalLogger = slog.New(slog.NewJSONHandler(&buf, nil))
+	t.Cleanup(func() { awssqs.GlobalLogger = nil })
+
+	client, err := awssqs.NewClient(ctx, awsconfig.RegionTokyo, awssqs.WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SendMessage(ctx, "http://localhost/000000000000/queue", "message"); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("a nil logger logged %q", buf.String())
+	}
+}
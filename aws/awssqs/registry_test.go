@@ -0,0 +1,28 @@
+package awssqs_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/88labs/go-utils/aws/awsconfig"
+	"github.com/88labs/go-utils/aws/awssqs"
+)
+
+func TestRegistry_Client(t *testing.T) {
+	ctx := context.Background()
+	registry := awssqs.NewRegistry()
+
+	base, err := registry.Client(ctx, awsconfig.RegionTokyo)
+	assert.NoError(t, err)
+	logger := slog.New(slog.DiscardHandler)
+	logged, err := registry.Client(ctx, awsconfig.RegionTokyo, awssqs.WithLogger(logger))
+	assert.NoError(t, err)
+	assert.NotSame(t, base, logged)
+	again, err := registry.Client(ctx, awsconfig.RegionTokyo, awssqs.WithLogger(logger))
+	assert.NoError(t, err)
+	assert.Same(t, logged, again)
+	assert.Equal(t, 2, registry.Len())
+}
@@ -0,0 +1,352 @@
+// Package awstransport configures the HTTP transport of the clients of awss3, awssqs, awsdynamo and awscognito.
+//
+// The same options are passed to the WithTransport option of each package:
+//
+//	transport := []awstransport.OptionTransport{
+//		awstransport.WithMaxIdleConnsPerHost(100),
+//		awstransport.WithResponseHeaderTimeout(10 * time.Second),
+//	}
+//	s3Client, err := awss3.NewClient(ctx, region, awss3.WithTransport(transport...))
+//	sqsClient, err := awssqs.NewClient(ctx, region, awssqs.WithTransport(transport...))
+//
+// Zero values keep the defaults of the AWS SDK.
+package awstransport
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+)
+
+type OptionTransport interface {
+	Apply(*confTransport)
+}
+
+type confTransport struct {
+	// DialTimeout is the maximum amount of time a dial waits for a connect to complete.
+	DialTimeout time.Duration
+	// DialDeadline is the absolute point in time after which dials fail.
+	DialDeadline *time.Time
+	// KeepAlive is the interval between keep-alive probes. Negative disables them.
+	KeepAlive time.Duration
+	// TLSConfig is the TLS configuration of the connections.
+	TLSConfig *tls.Config
+	// TLSHandshakeTimeout is the maximum amount of time to wait for a TLS handshake.
+	TLSHandshakeTimeout time.Duration
+	// Proxy returns the proxy of a request.
+	Proxy func(*http.Request) (*url.URL, error)
+	// MaxIdleConns is the maximum number of idle connections across all hosts.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost is the maximum number of idle connections per host.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost is the maximum number of connections per host.
+	MaxConnsPerHost int
+	// IdleConnTimeout is the time an idle connection stays in the pool.
+	IdleConnTimeout time.Duration
+	// ResponseHeaderTimeout is the time to wait for the response headers after the request is written.
+	ResponseHeaderTimeout time.Duration
+	// ExpectContinueTimeout is the time to wait for a 100-continue response.
+	ExpectContinueTimeout time.Duration
+	// Timeout is the time limit of a whole request, including reading the response body.
+	Timeout time.Duration
+	// DisableHTTP2 disables HTTP/2, which is otherwise negotiated over TLS.
+	DisableHTTP2 bool
+	// RoundTripperHooks wrap the transport, in the order they were set.
+	RoundTripperHooks []RoundTripperHook
+}
+
+// RoundTripperHook wraps the RoundTripper of a client, e.g. to add headers, record metrics or inject failures.
+type RoundTripperHook func(next http.RoundTripper) http.RoundTripper
+
+// nolint:revive
+func GetTransportConf(opts ...OptionTransport) confTransport {
+	c := confTransport{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt.Apply(&c)
+		}
+	}
+	return c
+}
+
+// NewHTTPClient returns an HTTP client for the AWS SDK configured with opts.
+// Settings that are not set keep the defaults of awshttp.NewBuildableClient.
+func NewHTTPClient(opts ...OptionTransport) aws.HTTPClient {
+	return ConfigureHTTPClient(nil, opts...)
+}
+
+// ConfigureHTTPClient applies opts on top of client, the HTTP client resolved by the AWS SDK config.
+// The settings of an *awshttp.BuildableClient, such as the root CAs of AWS_CA_BUNDLE, are kept unless opts
+// override them, and a nil client starts from the SDK defaults. RoundTripper hooks are applied to *http.Client
+// too; other HTTP clients are returned as is.
+func ConfigureHTTPClient(client aws.HTTPClient, opts ...OptionTransport) aws.HTTPClient {
+	c := GetTransportConf(opts...)
+	switch hc := client.(type) {
+	case nil:
+		client = c.configure(awshttp.NewBuildableClient())
+	case *awshttp.BuildableClient:
+		client = c.configure(hc)
+	}
+	if len(c.RoundTripperHooks) == 0 {
+		return client
+	}
+	if b, ok := client.(*awshttp.BuildableClient); ok {
+		// Freeze keeps the redirect handling of the SDK, the hooks only wrap the transport
+		client = b.Freeze()
+	}
+	hc, ok := client.(*http.Client)
+	if !ok {
+		return client
+	}
+	wrapped := *hc
+	for _, hook := range c.RoundTripperHooks {
+		wrapped.Transport = hook(wrapped.Transport)
+	}
+	return &wrapped
+}
+
+func (c confTransport) configure(b *awshttp.BuildableClient) *awshttp.BuildableClient {
+	b = b.WithDialerOptions(c.applyDialer).WithTransportOptions(c.applyTransport)
+	if c.Timeout > 0 {
+		b = b.WithTimeout(c.Timeout)
+	}
+	return b
+}
+
+func (c confTransport) applyDialer(dialer *net.Dialer) {
+	if c.DialTimeout > 0 {
+		dialer.Timeout = c.DialTimeout
+	}
+	if c.DialDeadline != nil {
+		dialer.Deadline = *c.DialDeadline
+	}
+	if c.KeepAlive != 0 {
+		dialer.KeepAlive = c.KeepAlive
+	}
+}
+
+func (c confTransport) applyTransport(tr *http.Transport) {
+	if c.TLSConfig != nil {
+		tr.TLSClientConfig = c.TLSConfig.Clone()
+	}
+	if c.TLSHandshakeTimeout > 0 {
+		tr.TLSHandshakeTimeout = c.TLSHandshakeTimeout
+	}
+	if c.Proxy != nil {
+		tr.Proxy = c.Proxy
+	}
+	if c.MaxIdleConns > 0 {
+		tr.MaxIdleConns = c.MaxIdleConns
+	}
+	if c.MaxIdleConnsPerHost > 0 {
+		tr.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+	}
+	if c.MaxConnsPerHost > 0 {
+		tr.MaxConnsPerHost = c.MaxConnsPerHost
+	}
+	if c.IdleConnTimeout > 0 {
+		tr.IdleConnTimeout = c.IdleConnTimeout
+	}
+	if c.ResponseHeaderTimeout > 0 {
+		tr.ResponseHeaderTimeout = c.ResponseHeaderTimeout
+	}
+	if c.ExpectContinueTimeout > 0 {
+		tr.ExpectContinueTimeout = c.ExpectContinueTimeout
+	}
+	if c.DisableHTTP2 {
+		tr.ForceAttemptHTTP2 = false
+		// a non-nil empty map disables the automatic HTTP/2 upgrade of net/http
+		tr.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+}
+
+type OptionDialTimeout time.Duration
+
+func (o OptionDialTimeout) Apply(c *confTransport) {
+	c.DialTimeout = time.Duration(o)
+}
+
+// WithDialTimeout
+// Sets the maximum amount of time a dial waits for a connect to complete. Default is 30 seconds.
+func WithDialTimeout(timeout time.Duration) OptionDialTimeout {
+	return OptionDialTimeout(timeout)
+}
+
+type OptionDialDeadline time.Time
+
+func (o OptionDialDeadline) Apply(c *confTransport) {
+	v := time.Time(o)
+	c.DialDeadline = &v
+}
+
+// WithDialDeadline
+// Sets the absolute point in time after which dials fail.
+func WithDialDeadline(deadline time.Time) OptionDialDeadline {
+	return OptionDialDeadline(deadline)
+}
+
+type OptionKeepAlive time.Duration
+
+func (o OptionKeepAlive) Apply(c *confTransport) {
+	c.KeepAlive = time.Duration(o)
+}
+
+// WithKeepAlive
+// Sets the interval between keep-alive probes of the connections. Default is 30 seconds. Negative disables them.
+func WithKeepAlive(keepAlive time.Duration) OptionKeepAlive {
+	return OptionKeepAlive(keepAlive)
+}
+
+type optionTLSConfig struct {
+	config *tls.Config
+}
+
+func (o optionTLSConfig) Apply(c *confTransport) {
+	c.TLSConfig = o.config
+}
+
+// WithTLSConfig
+// Sets the TLS configuration, e.g. the root CAs of a proxy or the minimum TLS version. The config is cloned.
+func WithTLSConfig(config *tls.Config) OptionTransport {
+	return optionTLSConfig{config: config}
+}
+
+type OptionTLSHandshakeTimeout time.Duration
+
+func (o OptionTLSHandshakeTimeout) Apply(c *confTransport) {
+	c.TLSHandshakeTimeout = time.Duration(o)
+}
+
+// WithTLSHandshakeTimeout
+// Sets the maximum amount of time to wait for a TLS handshake. Default is 10 seconds.
+func WithTLSHandshakeTimeout(timeout time.Duration) OptionTLSHandshakeTimeout {
+	return OptionTLSHandshakeTimeout(timeout)
+}
+
+type OptionProxy func(*http.Request) (*url.URL, error)
+
+func (o OptionProxy) Apply(c *confTransport) {
+	c.Proxy = o
+}
+
+// WithProxy
+// Sets the function that returns the proxy of a request, e.g. http.ProxyURL.
+// Default is http.ProxyFromEnvironment.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) OptionProxy {
+	return proxy
+}
+
+type OptionMaxIdleConns int
+
+func (o OptionMaxIdleConns) Apply(c *confTransport) {
+	c.MaxIdleConns = int(o)
+}
+
+// WithMaxIdleConns
+// Sets the maximum number of idle connections across all hosts. Default is 100.
+func WithMaxIdleConns(n int) OptionMaxIdleConns {
+	return OptionMaxIdleConns(n)
+}
+
+type OptionMaxIdleConnsPerHost int
+
+func (o OptionMaxIdleConnsPerHost) Apply(c *confTransport) {
+	c.MaxIdleConnsPerHost = int(o)
+}
+
+// WithMaxIdleConnsPerHost
+// Sets the maximum number of idle connections per host. Default is 10.
+// Raise it for clients that make many concurrent requests, such as parallel downloads.
+func WithMaxIdleConnsPerHost(n int) OptionMaxIdleConnsPerHost {
+	return OptionMaxIdleConnsPerHost(n)
+}
+
+type OptionMaxConnsPerHost int
+
+func (o OptionMaxConnsPerHost) Apply(c *confTransport) {
+	c.MaxConnsPerHost = int(o)
+}
+
+// WithMaxConnsPerHost
+// Sets the maximum number of connections per host, including those in use. Default is 2048.
+func WithMaxConnsPerHost(n int) OptionMaxConnsPerHost {
+	return OptionMaxConnsPerHost(n)
+}
+
+type OptionIdleConnTimeout time.Duration
+
+func (o OptionIdleConnTimeout) Apply(c *confTransport) {
+	c.IdleConnTimeout = time.Duration(o)
+}
+
+// WithIdleConnTimeout
+// Sets the time an idle connection stays in the pool. Default is 90 seconds.
+func WithIdleConnTimeout(timeout time.Duration) OptionIdleConnTimeout {
+	return OptionIdleConnTimeout(timeout)
+}
+
+type OptionResponseHeaderTimeout time.Duration
+
+func (o OptionResponseHeaderTimeout) Apply(c *confTransport) {
+	c.ResponseHeaderTimeout = time.Duration(o)
+}
+
+// WithResponseHeaderTimeout
+// Sets the time to wait for the response headers after the request is written. Default is no timeout.
+func WithResponseHeaderTimeout(timeout time.Duration) OptionResponseHeaderTimeout {
+	return OptionResponseHeaderTimeout(timeout)
+}
+
+type OptionExpectContinueTimeout time.Duration
+
+func (o OptionExpectContinueTimeout) Apply(c *confTransport) {
+	c.ExpectContinueTimeout = time.Duration(o)
+}
+
+// WithExpectContinueTimeout
+// Sets the time to wait for a 100-continue response. Default is 1 second.
+func WithExpectContinueTimeout(timeout time.Duration) OptionExpectContinueTimeout {
+	return OptionExpectContinueTimeout(timeout)
+}
+
+type OptionTimeout time.Duration
+
+func (o OptionTimeout) Apply(c *confTransport) {
+	c.Timeout = time.Duration(o)
+}
+
+// WithTimeout
+// Sets the time limit of a whole request, including reading the response body. Default is no timeout.
+// It also limits large downloads, so prefer WithResponseHeaderTimeout or a context deadline.
+func WithTimeout(timeout time.Duration) OptionTimeout {
+	return OptionTimeout(timeout)
+}
+
+type OptionDisableHTTP2 bool
+
+func (o OptionDisableHTTP2) Apply(c *confTransport) {
+	c.DisableHTTP2 = bool(o)
+}
+
+// WithDisableHTTP2
+// Disables HTTP/2, which is otherwise negotiated over TLS.
+func WithDisableHTTP2(disabled bool) OptionDisableHTTP2 {
+	return OptionDisableHTTP2(disabled)
+}
+
+type OptionRoundTripperHook RoundTripperHook
+
+func (o OptionRoundTripperHook) Apply(c *confTransport) {
+	c.RoundTripperHooks = append(c.RoundTripperHooks, RoundTripperHook(o))
+}
+
+// WithRoundTripperHook
+// Wraps the RoundTripper of the client. Hooks are applied in order, so the last one sees the requests first.
+func WithRoundTripperHook(hook RoundTripperHook) OptionRoundTripperHook {
+	return OptionRoundTripperHook(hook)
+}
@@ -0,0 +1,143 @@
+package awstransport_test
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"gotest.tools/v3/assert"
+
+	"github.com/88labs/go-utils/aws/awstransport"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no option keeps the SDK defaults", func(t *testing.T) {
+		t.Parallel()
+		client, ok := awstransport.NewHTTPClient().(*awshttp.BuildableClient)
+		assert.Assert(t, ok)
+		tr := client.GetTransport()
+		assert.Equal(t, awshttp.DefaultHTTPTransportMaxIdleConns, tr.MaxIdleConns)
+		assert.Equal(t, awshttp.DefaultHTTPTransportMaxIdleConnsPerHost, tr.MaxIdleConnsPerHost)
+		assert.Equal(t, awshttp.DefaultDialConnectTimeout, client.GetDialer().Timeout)
+	})
+	t.Run("transport and dialer", func(t *testing.T) {
+		t.Parallel()
+		proxyURL, err := url.Parse("http://proxy.example.com:3128")
+		assert.NilError(t, err)
+		deadline := time.Now().Add(time.Hour)
+		client, ok := awstransport.NewHTTPClient(
+			awstransport.WithDialTimeout(3*time.Second),
+			awstransport.WithDialDeadline(deadline),
+			awstransport.WithKeepAlive(-1),
+			awstransport.WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS13, ServerName: "s3.example.com"}),
+			awstransport.WithTLSHandshakeTimeout(4*time.Second),
+			awstransport.WithProxy(http.ProxyURL(proxyURL)),
+			awstransport.WithMaxIdleConns(200),
+			awstransport.WithMaxIdleConnsPerHost(50),
+			awstransport.WithMaxConnsPerHost(60),
+			awstransport.WithIdleConnTimeout(5*time.Second),
+			awstransport.WithResponseHeaderTimeout(6*time.Second),
+			awstransport.WithExpectContinueTimeout(7*time.Second),
+			awstransport.WithTimeout(8*time.Second),
+			awstransport.WithDisableHTTP2(true),
+		).(*awshttp.BuildableClient)
+		assert.Assert(t, ok)
+
+		dialer := client.GetDialer()
+		assert.Equal(t, 3*time.Second, dialer.Timeout)
+		assert.Equal(t, deadline, dialer.Deadline)
+		assert.Equal(t, time.Duration(-1), dialer.KeepAlive)
+
+		tr := client.GetTransport()
+		assert.Equal(t, uint16(tls.VersionTLS13), tr.TLSClientConfig.MinVersion)
+		assert.Equal(t, "s3.example.com", tr.TLSClientConfig.ServerName)
+		assert.Equal(t, 4*time.Second, tr.TLSHandshakeTimeout)
+		proxy, err := tr.Proxy(httptest.NewRequest(http.MethodGet, "https://s3.amazonaws.com/", nil))
+		assert.NilError(t, err)
+		assert.Equal(t, proxyURL.String(), proxy.String())
+		assert.Equal(t, 200, tr.MaxIdleConns)
+		assert.Equal(t, 50, tr.MaxIdleConnsPerHost)
+		assert.Equal(t, 60, tr.MaxConnsPerHost)
+		assert.Equal(t, 5*time.Second, tr.IdleConnTimeout)
+		assert.Equal(t, 6*time.Second, tr.ResponseHeaderTimeout)
+		assert.Equal(t, 7*time.Second, tr.ExpectContinueTimeout)
+		assert.Equal(t, false, tr.ForceAttemptHTTP2)
+		assert.Assert(t, tr.TLSNextProto != nil)
+		assert.Equal(t, 8*time.Second, client.GetTimeout())
+	})
+	t.Run("later options take precedence", func(t *testing.T) {
+		t.Parallel()
+		client, ok := awstransport.NewHTTPClient(
+			awstransport.WithDialTimeout(time.Second),
+			awstransport.WithDialTimeout(2*time.Second),
+		).(*awshttp.BuildableClient)
+		assert.Assert(t, ok)
+		assert.Equal(t, 2*time.Second, client.GetDialer().Timeout)
+	})
+	t.Run("RoundTripperHook", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.Header.Get("X-Order")))
+		}))
+		t.Cleanup(server.Close)
+
+		var calls atomic.Int32
+		hook := func(name string) awstransport.RoundTripperHook {
+			return func(next http.RoundTripper) http.RoundTripper {
+				return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					calls.Add(1)
+					req.Header.Set("X-Order", req.Header.Get("X-Order")+name)
+					return next.RoundTrip(req)
+				})
+			}
+		}
+		client := awstransport.NewHTTPClient(
+			awstransport.WithRoundTripperHook(hook("a")),
+			awstransport.WithRoundTripperHook(hook("b")),
+		)
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		assert.NilError(t, err)
+		res, err := client.Do(req)
+		assert.NilError(t, err)
+		t.Cleanup(func() { _ = res.Body.Close() })
+		assert.Equal(t, int32(2), calls.Load())
+		body, err := io.ReadAll(res.Body)
+		assert.NilError(t, err)
+		assert.Equal(t, "ba", string(body))
+	})
+	t.Run("RoundTripperHook keeps the redirect handling of the SDK", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/moved" {
+				http.Redirect(w, r, "/", http.StatusMovedPermanently)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		t.Cleanup(server.Close)
+
+		client := awstransport.NewHTTPClient(awstransport.WithRoundTripperHook(func(next http.RoundTripper) http.RoundTripper {
+			return next
+		}))
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/moved", nil)
+		assert.NilError(t, err)
+		res, err := client.Do(req)
+		assert.NilError(t, err)
+		t.Cleanup(func() { _ = res.Body.Close() })
+		assert.Equal(t, http.StatusMovedPermanently, res.StatusCode)
+	})
+}
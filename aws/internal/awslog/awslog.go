@@ -0,0 +1,64 @@
+// Package awslog holds the structured logging shared by the clients of awss3, awssqs, awsdynamo and awscognito.
+package awslog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/exp/zapslog"
+)
+
+// NoopLogger discards every record.
+var NoopLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// Normalize returns logger, or NoopLogger when logger is nil.
+func Normalize(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return NoopLogger
+	}
+	return logger
+}
+
+// NewLoggerFromZap bridges a zap logger into slog. When logger is nil, NoopLogger is returned.
+func NewLoggerFromZap(logger *zap.Logger) *slog.Logger {
+	if logger == nil {
+		return NoopLogger
+	}
+	return slog.New(zapslog.NewHandler(logger.Core()))
+}
+
+// LogOperation starts the log of an operation of component and returns the function that ends it:
+// an info-level "<component> operation completed" record, or an error-level "<component> operation failed"
+// record with the error, both with the operation, attrs, the extra attributes and the duration.
+// A nil logger logs nothing.
+func LogOperation(
+	ctx context.Context, logger *slog.Logger, component, operation string, attrs ...slog.Attr,
+) func(err error, extra ...slog.Attr) {
+	if logger == nil {
+		return func(error, ...slog.Attr) {}
+	}
+
+	logger = logger.With(slog.String("component", component))
+	baseAttrs := make([]slog.Attr, 0, len(attrs)+1)
+	baseAttrs = append(baseAttrs, slog.String("operation", operation))
+	baseAttrs = append(baseAttrs, attrs...)
+	startedAt := time.Now()
+
+	return func(err error, extra ...slog.Attr) {
+		logAttrs := make([]slog.Attr, 0, len(baseAttrs)+len(extra)+2)
+		logAttrs = append(logAttrs, baseAttrs...)
+		logAttrs = append(logAttrs, extra...)
+		logAttrs = append(logAttrs, slog.Duration("duration", time.Since(startedAt)))
+
+		if err != nil {
+			logAttrs = append(logAttrs, slog.Any("error", err))
+			logger.LogAttrs(ctx, slog.LevelError, component+" operation failed", logAttrs...)
+			return
+		}
+
+		logger.LogAttrs(ctx, slog.LevelInfo, component+" operation completed", logAttrs...)
+	}
+}
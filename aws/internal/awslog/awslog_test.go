@@ -0,0 +1,65 @@
+package awslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestLogOperation(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	LogOperation(ctx, logger, "component", "Operation", slog.String("resource", "name"))(nil, slog.Int("count", 2))
+	var completed map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &completed); err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range map[string]any{
+		"level":     "INFO",
+		"msg":       "component operation completed",
+		"component": "component",
+		"operation": "Operation",
+		"resource":  "name",
+		"count":     float64(2),
+	} {
+		if completed[k] != want {
+			t.Errorf("%s = %v, want %v", k, completed[k], want)
+		}
+	}
+	if _, ok := completed["duration"]; !ok {
+		t.Error("duration is not logged")
+	}
+
+	buf.Reset()
+	LogOperation(ctx, logger, "component", "Operation")(errors.New("boom"))
+	var failed map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &failed); err != nil {
+		t.Fatal(err)
+	}
+	if failed["level"] != "ERROR" || failed["msg"] != "component operation failed" || failed["error"] != "boom" {
+		t.Errorf("failed = %v", failed)
+	}
+}
+
+func TestLogOperation_nilLogger(t *testing.T) {
+	// must not panic
+	LogOperation(context.Background(), nil, "component", "Operation")(errors.New("boom"))
+}
+
+func TestNormalize(t *testing.T) {
+	if Normalize(nil) != NoopLogger {
+		t.Error("Normalize(nil) is not NoopLogger")
+	}
+	if NewLoggerFromZap(nil) != NoopLogger {
+		t.Error("NewLoggerFromZap(nil) is not NoopLogger")
+	}
+	logger := slog.Default()
+	if Normalize(logger) != logger {
+		t.Error("Normalize changed a logger")
+	}
+}
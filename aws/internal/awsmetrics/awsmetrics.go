@@ -0,0 +1,160 @@
+package awsmetrics
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ScopeName is the instrumentation scope of the meters.
+const ScopeName = "github.com/88labs/go-utils/aws"
+
+// Names of the instruments.
+const (
+	RequestsName     = "aws.client.requests"
+	DurationName     = "aws.client.request.duration"
+	RetriesName      = "aws.client.retries"
+	ThrottlesName    = "aws.client.throttles"
+	RequestSizeName  = "aws.client.request.size"
+	ResponseSizeName = "aws.client.response.size"
+)
+
+// Attributes of the measurements.
+const (
+	ServiceKey   = attribute.Key("service")
+	OperationKey = attribute.Key("operation")
+	StatusKey    = attribute.Key("status")
+)
+
+// Values of StatusKey.
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// AppendMiddlewares records the metrics of the requests of an AWS SDK v2 client with the meters of provider:
+// the number and the latency of the operations, the retries, the throttling errors and the bytes of the
+// request and response bodies declared by Content-Length, labelled by service, operation and status.
+// A nil provider uses the globally configured OpenTelemetry provider.
+func AppendMiddlewares(apiOptions *[]func(*middleware.Stack) error, provider metric.MeterProvider) {
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+	m := newInstruments(provider.Meter(ScopeName))
+	*apiOptions = append(*apiOptions, func(stack *middleware.Stack) error {
+		if err := stack.Initialize.Add(operationMetrics{instruments: m}, middleware.After); err != nil {
+			return err
+		}
+		// before the deserializers, to see the service errors such as throttling
+		return stack.Deserialize.Add(attemptMetrics{instruments: m}, middleware.Before)
+	})
+}
+
+type instruments struct {
+	requests     metric.Int64Counter
+	duration     metric.Float64Histogram
+	retries      metric.Int64Counter
+	throttles    metric.Int64Counter
+	requestSize  metric.Int64Counter
+	responseSize metric.Int64Counter
+}
+
+// newInstruments creates the instruments of meter. An instrument that cannot be created is a no-op,
+// as the OpenTelemetry API returns a usable instrument together with the error.
+func newInstruments(meter metric.Meter) *instruments {
+	m := &instruments{}
+	m.requests, _ = meter.Int64Counter(RequestsName,
+		metric.WithDescription("Number of operations."), metric.WithUnit("{request}"))
+	m.duration, _ = meter.Float64Histogram(DurationName,
+		metric.WithDescription("Duration of operations, including retries."), metric.WithUnit("s"))
+	m.retries, _ = meter.Int64Counter(RetriesName,
+		metric.WithDescription("Number of retried attempts."), metric.WithUnit("{retry}"))
+	m.throttles, _ = meter.Int64Counter(ThrottlesName,
+		metric.WithDescription("Number of attempts failed with a throttling error."), metric.WithUnit("{error}"))
+	m.requestSize, _ = meter.Int64Counter(RequestSizeName,
+		metric.WithDescription("Bytes of the request bodies sent."), metric.WithUnit("By"))
+	m.responseSize, _ = meter.Int64Counter(ResponseSizeName,
+		metric.WithDescription("Bytes of the response bodies received."), metric.WithUnit("By"))
+	return m
+}
+
+type attemptsKey struct{}
+
+// operationMetrics records the metrics of an operation, once whatever the number of attempts.
+type operationMetrics struct {
+	instruments *instruments
+}
+
+func (operationMetrics) ID() string {
+	return "go-utils/aws/OperationMetrics"
+}
+
+func (m operationMetrics) HandleInitialize(
+	ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+) (middleware.InitializeOutput, middleware.Metadata, error) {
+	attempts := new(atomic.Int64)
+	ctx = middleware.WithStackValue(ctx, attemptsKey{}, attempts)
+	start := time.Now()
+	out, metadata, err := next.HandleInitialize(ctx, in)
+
+	status := StatusOK
+	if err != nil {
+		status = StatusError
+	}
+	set := metric.WithAttributeSet(attribute.NewSet(
+		ServiceKey.String(awsmiddleware.GetServiceID(ctx)),
+		OperationKey.String(awsmiddleware.GetOperationName(ctx)),
+		StatusKey.String(status),
+	))
+	// the context of the request may be canceled, which must not drop the measurements
+	recordCtx := context.WithoutCancel(ctx)
+	m.instruments.requests.Add(recordCtx, 1, set)
+	m.instruments.duration.Record(recordCtx, time.Since(start).Seconds(), set)
+	if n := attempts.Load(); n > 1 {
+		m.instruments.retries.Add(recordCtx, n-1, set)
+	}
+	return out, metadata, err
+}
+
+// attemptMetrics records the metrics of each attempt of an operation.
+type attemptMetrics struct {
+	instruments *instruments
+}
+
+func (attemptMetrics) ID() string {
+	return "go-utils/aws/AttemptMetrics"
+}
+
+func (m attemptMetrics) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (middleware.DeserializeOutput, middleware.Metadata, error) {
+	if attempts, ok := middleware.GetStackValue(ctx, attemptsKey{}).(*atomic.Int64); ok {
+		attempts.Add(1)
+	}
+	out, metadata, err := next.HandleDeserialize(ctx, in)
+
+	set := metric.WithAttributeSet(attribute.NewSet(
+		ServiceKey.String(awsmiddleware.GetServiceID(ctx)),
+		OperationKey.String(awsmiddleware.GetOperationName(ctx)),
+	))
+	recordCtx := context.WithoutCancel(ctx)
+	if req, ok := in.Request.(*smithyhttp.Request); ok && req.ContentLength > 0 {
+		m.instruments.requestSize.Add(recordCtx, req.ContentLength, set)
+	}
+	if resp, ok := out.RawResponse.(*smithyhttp.Response); ok && resp.ContentLength > 0 {
+		m.instruments.responseSize.Add(recordCtx, resp.ContentLength, set)
+	}
+	if err != nil && retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err) == aws.TrueTernary {
+		m.instruments.throttles.Add(recordCtx, 1, set)
+	}
+	return out, metadata, err
+}
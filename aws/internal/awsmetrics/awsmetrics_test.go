@@ -0,0 +1,179 @@
+package awsmetrics
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+const notFoundBody = `<Error><Code>NoSuchKey</Code><Message>missing</Message></Error>`
+
+func TestAppendMiddlewares(t *testing.T) {
+	var puts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		switch {
+		case r.Method == http.MethodPut && puts.Add(1) == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`<Error><Code>SlowDown</Code><Message>Please reduce your request rate.</Message></Error>`))
+		case r.Method == http.MethodPut:
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/bucket/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(notFoundBody))
+		default:
+			w.Header().Set("Content-Length", "7")
+			_, _ = w.Write([]byte("content"))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	provider := newRecordingProvider()
+	client := s3.New(s3.Options{
+		Region:       "ap-northeast-1",
+		BaseEndpoint: aws.String(server.URL),
+		UsePathStyle: true,
+		Credentials:  credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		Retryer: retry.NewStandard(func(o *retry.StandardOptions) {
+			o.Backoff = retry.BackoffDelayerFunc(func(int, error) (time.Duration, error) { return 0, nil })
+		}),
+	}, func(o *s3.Options) {
+		AppendMiddlewares(&o.APIOptions, provider)
+	})
+
+	ctx := context.Background()
+	if _, err := client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String("bucket"), Key: aws.String("key"), Body: bytes.NewReader([]byte("hello")),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = out.Body.Close()
+	if _, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String("bucket"), Key: aws.String("missing"),
+	}); err == nil {
+		t.Fatal("GetObject of a missing object succeeded")
+	}
+
+	putOK := attribute.NewSet(ServiceKey.String("S3"), OperationKey.String("PutObject"), StatusKey.String(StatusOK))
+	getOK := attribute.NewSet(ServiceKey.String("S3"), OperationKey.String("GetObject"), StatusKey.String(StatusOK))
+	getError := attribute.NewSet(ServiceKey.String("S3"), OperationKey.String("GetObject"), StatusKey.String(StatusError))
+	put := attribute.NewSet(ServiceKey.String("S3"), OperationKey.String("PutObject"))
+	get := attribute.NewSet(ServiceKey.String("S3"), OperationKey.String("GetObject"))
+
+	for _, tt := range []struct {
+		name  string
+		attrs attribute.Set
+		want  float64
+	}{
+		{RequestsName, putOK, 1},
+		{RequestsName, getOK, 1},
+		{RequestsName, getError, 1},
+		{DurationName, putOK, 1},
+		{DurationName, getError, 1},
+		{RetriesName, putOK, 1},
+		{RetriesName, getOK, 0},
+		{ThrottlesName, put, 1},
+		{ThrottlesName, get, 0},
+		// the throttled attempt and the retry
+		{RequestSizeName, put, 10},
+		// the object and the error of the missing object
+		{ResponseSizeName, get, float64(len("content") + len(notFoundBody))},
+	} {
+		got := provider.value(tt.name, tt.attrs)
+		if tt.name == DurationName {
+			got = provider.count(tt.name, tt.attrs)
+		}
+		if got != tt.want {
+			t.Errorf("%s%v = %v, want %v", tt.name, tt.attrs.ToSlice(), got, tt.want)
+		}
+	}
+}
+
+// recordingProvider sums the measurements of every instrument by name and attributes.
+type recordingProvider struct {
+	noop.MeterProvider
+	mu     sync.Mutex
+	sums   map[string]float64
+	counts map[string]float64
+}
+
+func newRecordingProvider() *recordingProvider {
+	return &recordingProvider{sums: map[string]float64{}, counts: map[string]float64{}}
+}
+
+func (p *recordingProvider) Meter(string, ...metric.MeterOption) metric.Meter {
+	return recordingMeter{provider: p}
+}
+
+func (p *recordingProvider) record(name string, attrs attribute.Set, v float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := name + attrs.Encoded(attribute.DefaultEncoder())
+	p.sums[key] += v
+	p.counts[key]++
+}
+
+func (p *recordingProvider) value(name string, attrs attribute.Set) float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.sums[name+attrs.Encoded(attribute.DefaultEncoder())]
+}
+
+func (p *recordingProvider) count(name string, attrs attribute.Set) float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.counts[name+attrs.Encoded(attribute.DefaultEncoder())]
+}
+
+type recordingMeter struct {
+	noop.Meter
+	provider *recordingProvider
+}
+
+func (m recordingMeter) Int64Counter(name string, _ ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return recordingCounter{name: name, provider: m.provider}, nil
+}
+
+func (m recordingMeter) Float64Histogram(
+	name string, _ ...metric.Float64HistogramOption,
+) (metric.Float64Histogram, error) {
+	return recordingHistogram{name: name, provider: m.provider}, nil
+}
+
+type recordingCounter struct {
+	noop.Int64Counter
+	name     string
+	provider *recordingProvider
+}
+
+func (c recordingCounter) Add(_ context.Context, incr int64, opts ...metric.AddOption) {
+	c.provider.record(c.name, metric.NewAddConfig(opts).Attributes(), float64(incr))
+}
+
+type recordingHistogram struct {
+	noop.Float64Histogram
+	name     string
+	provider *recordingProvider
+}
+
+func (h recordingHistogram) Record(_ context.Context, v float64, opts ...metric.RecordOption) {
+	h.provider.record(h.name, metric.NewRecordConfig(opts).Attributes(), v)
+}
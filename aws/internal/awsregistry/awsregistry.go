@@ -0,0 +1,216 @@
+// Package awsregistry caches the SDK clients of the aws packages by region, role, endpoint and options.
+package awsregistry
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"math"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// Key identifies a client in a Registry.
+type Key struct {
+	// Region is the region of the client.
+	Region string
+	// RoleARN is the ARN of the role assumed by the client, empty for the default credentials.
+	RoleARN string
+	// Endpoint is the endpoint of the client, empty for the endpoint resolved by the AWS SDK.
+	Endpoint string
+	// Options is the hash of the other options of the client, see OptionsHash.
+	Options string
+}
+
+func (k Key) base() Key {
+	k.Options = ""
+	return k
+}
+
+// Registry lazily creates and caches clients by Key. The zero value is ready to use.
+type Registry[T any] struct {
+	mu      sync.Mutex
+	entries map[Key]*entry[T]
+	// first is the first client created for each key without its options.
+	first map[Key]*entry[T]
+}
+
+type entry[T any] struct {
+	mu      sync.Mutex
+	client  T
+	created bool
+}
+
+// Get returns the client of key, creating it with create when there is none.
+// Concurrent calls for the same key create a single client. A client is not cached when create fails.
+func (r *Registry[T]) Get(key Key, create func() (T, error)) (T, error) {
+	r.mu.Lock()
+	r.init()
+	e, ok := r.entries[key]
+	if !ok {
+		e = &entry[T]{}
+		r.entries[key] = e
+	}
+	r.mu.Unlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.created {
+		return e.client, nil
+	}
+	client, err := create()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	e.client, e.created = client, true
+
+	r.mu.Lock()
+	// the registry may have been cleared while the client was created: keep the entry out of the new generation
+	if r.entries[key] == e {
+		if _, ok := r.first[key.base()]; !ok {
+			r.first[key.base()] = e
+		}
+	}
+	r.mu.Unlock()
+	return client, nil
+}
+
+// init creates the maps of the zero value. It must be called with r.mu held.
+func (r *Registry[T]) init() {
+	if r.entries == nil {
+		r.entries = make(map[Key]*entry[T])
+		r.first = make(map[Key]*entry[T])
+	}
+}
+
+// GetFirst returns the first client created for the region, role and endpoint of key whatever its options,
+// or Get(key, create) when there is none.
+// It keeps the behavior of the package-level helpers, which use the client initialized by GetClient.
+func (r *Registry[T]) GetFirst(key Key, create func() (T, error)) (T, error) {
+	r.mu.Lock()
+	e, ok := r.first[key.base()]
+	r.mu.Unlock()
+	if ok {
+		return e.client, nil
+	}
+	return r.Get(key, create)
+}
+
+// Len returns the number of cached clients.
+func (r *Registry[T]) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, e := range r.entries {
+		e.mu.Lock()
+		if e.created {
+			n++
+		}
+		e.mu.Unlock()
+	}
+	return n
+}
+
+// Delete removes the client of key and reports whether there was one. The client keeps working for the callers
+// that already got it, and the next Get for key creates a new one.
+func (r *Registry[T]) Delete(key Key) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.entries[key]
+	if !ok {
+		return false
+	}
+	delete(r.entries, key)
+	if r.first[key.base()] == e {
+		delete(r.first, key.base())
+	}
+	return true
+}
+
+// Clear removes all the cached clients. Clients already returned keep working.
+func (r *Registry[T]) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = make(map[Key]*entry[T])
+	r.first = make(map[Key]*entry[T])
+}
+
+// OptionsHash returns a hash of values to be used as Key.Options.
+// Values are compared by value, except pointers, maps, channels and functions, which are compared by identity:
+// two options hash the same only when they share the same TracerProvider, RoundTripperHook, and so on.
+func OptionsHash(values ...any) string {
+	h := sha256.New()
+	for _, v := range values {
+		writeValue(h, reflect.ValueOf(v))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+func writeValue(h hash.Hash, v reflect.Value) {
+	if !v.IsValid() {
+		_, _ = h.Write([]byte{0})
+		return
+	}
+	_, _ = h.Write([]byte(v.Type().String()))
+	switch v.Kind() {
+	case reflect.Bool:
+		writeUint(h, boolToUint(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(h, uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(h, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeUint(h, math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		writeUint(h, math.Float64bits(real(v.Complex())))
+		writeUint(h, math.Float64bits(imag(v.Complex())))
+	case reflect.String:
+		writeUint(h, uint64(v.Len()))
+		_, _ = h.Write([]byte(v.String()))
+	case reflect.Pointer, reflect.Map, reflect.Chan, reflect.UnsafePointer:
+		writeUint(h, uint64(v.Pointer()))
+	case reflect.Func:
+		writeUint(h, uint64(funcIdentity(v)))
+	case reflect.Interface:
+		writeValue(h, v.Elem())
+	case reflect.Array, reflect.Slice:
+		writeUint(h, uint64(v.Len()))
+		for i := range v.Len() {
+			writeValue(h, v.Index(i))
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			writeValue(h, v.Field(i))
+		}
+	default:
+		// reflect.Invalid is handled above; every other kind is covered.
+	}
+}
+
+// funcIdentity returns the address of the closure of v, so that closures of the same function
+// with different captured variables are not considered equal.
+// The code pointer is used for functions read from unexported fields, whose closure cannot be read.
+func funcIdentity(v reflect.Value) uintptr {
+	if v.IsNil() || !v.CanInterface() {
+		return v.Pointer()
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	return uintptr(*(*unsafe.Pointer)(p.UnsafePointer()))
+}
+
+func writeUint(h hash.Hash, n uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], n)
+	_, _ = h.Write(b[:])
+}
+
+func boolToUint(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
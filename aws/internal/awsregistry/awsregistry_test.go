@@ -0,0 +1,234 @@
+package awsregistry
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRegistry_Get(t *testing.T) {
+	var r Registry[*int]
+	var created atomic.Int32
+	create := func() (*int, error) {
+		n := int(created.Add(1))
+		return &n, nil
+	}
+
+	tokyo := Key{Region: "ap-northeast-1"}
+	var wg sync.WaitGroup
+	clients := make([]*int, 10)
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := r.Get(tokyo, create)
+			if err != nil {
+				t.Error(err)
+			}
+			clients[i] = c
+		}()
+	}
+	wg.Wait()
+	for _, c := range clients {
+		if c != clients[0] {
+			t.Fatal("concurrent calls for the same key returned different clients")
+		}
+	}
+
+	osaka, err := r.Get(Key{Region: "ap-northeast-3"}, create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if osaka == clients[0] {
+		t.Fatal("a different region returned the same client")
+	}
+	if got := r.Len(); got != 2 {
+		t.Fatalf("Len() = %d, want 2", got)
+	}
+
+	r.Clear()
+	if got := r.Len(); got != 0 {
+		t.Fatalf("Len() after Clear = %d, want 0", got)
+	}
+}
+
+func TestRegistry_GetKey(t *testing.T) {
+	var r Registry[Key]
+	base := Key{Region: "ap-northeast-1", Options: OptionsHash()}
+	get := func(key Key) Key {
+		t.Helper()
+		got, err := r.Get(key, func() (Key, error) { return key, nil })
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	get(base)
+	for name, key := range map[string]Key{
+		"region":   {Region: "ap-northeast-3", Options: base.Options},
+		"role":     {Region: base.Region, RoleARN: "arn:aws:iam::111111111111:role/reader", Options: base.Options},
+		"endpoint": {Region: base.Region, Endpoint: "http://127.0.0.1:4566", Options: base.Options},
+		"options":  {Region: base.Region, Options: OptionsHash(true)},
+	} {
+		if got := get(key); got != key {
+			t.Fatalf("a different %s returned the client of %+v", name, got)
+		}
+		if got := get(base); got != base {
+			t.Fatalf("the client of %+v was replaced by a different %s", base, name)
+		}
+	}
+	if got := r.Len(); got != 5 {
+		t.Fatalf("Len() = %d, want 5", got)
+	}
+}
+
+func TestRegistry_Delete(t *testing.T) {
+	var r Registry[string]
+	tokyo := Key{Region: "ap-northeast-1", Options: OptionsHash()}
+	if _, err := r.Get(tokyo, func() (string, error) { return "old", nil }); err != nil {
+		t.Fatal(err)
+	}
+	if !r.Delete(tokyo) {
+		t.Fatal("Delete() = false, want true for a cached client")
+	}
+	if r.Delete(tokyo) {
+		t.Fatal("Delete() = true, want false for a deleted client")
+	}
+	if got := r.Len(); got != 0 {
+		t.Fatalf("Len() after Delete = %d, want 0", got)
+	}
+	got, err := r.GetFirst(Key{Region: "ap-northeast-1", Options: OptionsHash(true)}, func() (string, error) {
+		return "new", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "new" {
+		t.Fatalf("GetFirst() = %q, want a new client after Delete", got)
+	}
+}
+
+func TestRegistry_GetClear(t *testing.T) {
+	t.Run("Clear while creating", func(t *testing.T) {
+		var r Registry[int]
+		creating, cleared := make(chan struct{}), make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			if _, err := r.Get(Key{}, func() (int, error) {
+				close(creating)
+				<-cleared
+				return 1, nil
+			}); err != nil {
+				t.Error(err)
+			}
+		}()
+		<-creating
+		r.Clear()
+		close(cleared)
+		<-done
+		if got := r.Len(); got != 0 {
+			t.Fatalf("Len() = %d, want 0: a client created before Clear was cached", got)
+		}
+		c, err := r.GetFirst(Key{}, func() (int, error) { return 2, nil })
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c != 2 {
+			t.Fatalf("GetFirst() = %d, want the client created after Clear", c)
+		}
+	})
+	t.Run("concurrent", func(t *testing.T) {
+		var r Registry[int]
+		var wg sync.WaitGroup
+		for i := range 50 {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				key := Key{Region: "ap-northeast-1", Options: string(rune('a' + i%5))}
+				if _, err := r.Get(key, func() (int, error) { return i, nil }); err != nil {
+					t.Error(err)
+				}
+				if _, err := r.GetFirst(key, func() (int, error) { return i, nil }); err != nil {
+					t.Error(err)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				r.Clear()
+				_ = r.Len()
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestRegistry_GetDoesNotCacheErrors(t *testing.T) {
+	var r Registry[int]
+	errCreate := errors.New("create")
+	if _, err := r.Get(Key{}, func() (int, error) { return 0, errCreate }); !errors.Is(err, errCreate) {
+		t.Fatalf("err = %v, want %v", err, errCreate)
+	}
+	got, err := r.Get(Key{}, func() (int, error) { return 1, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 1 {
+		t.Fatalf("client = %d, want 1", got)
+	}
+}
+
+func TestRegistry_GetFirst(t *testing.T) {
+	var r Registry[string]
+	traced := Key{Region: "ap-northeast-1", Options: OptionsHash(true)}
+	if _, err := r.Get(traced, func() (string, error) { return "traced", nil }); err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.GetFirst(Key{Region: "ap-northeast-1", Options: OptionsHash()}, func() (string, error) {
+		return "default", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "traced" {
+		t.Fatalf("GetFirst() = %q, want the client created first for the region", got)
+	}
+	got, err = r.GetFirst(Key{Region: "us-east-1"}, func() (string, error) { return "default", nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "default" {
+		t.Fatalf("GetFirst() = %q, want a new client for another region", got)
+	}
+}
+
+func TestOptionsHash(t *testing.T) {
+	type conf struct {
+		Name    string
+		Timeout int
+		Hooks   []func() string
+	}
+	hook := func(s string) func() string { return func() string { return s } }
+	a, b := hook("a"), hook("b")
+
+	if OptionsHash(conf{Name: "x", Hooks: []func() string{a}}) != OptionsHash(conf{Name: "x", Hooks: []func() string{a}}) {
+		t.Fatal("equal options hash differently")
+	}
+	for name, other := range map[string]conf{
+		"value":   {Name: "y", Hooks: []func() string{a}},
+		"closure": {Name: "x", Hooks: []func() string{b}},
+		"length":  {Name: "x", Hooks: []func() string{a, a}},
+	} {
+		if OptionsHash(conf{Name: "x", Hooks: []func() string{a}}) == OptionsHash(other) {
+			t.Fatalf("options with a different %s hash the same", name)
+		}
+	}
+	p1, p2 := new(int), new(int)
+	if OptionsHash(p1) == OptionsHash(p2) {
+		t.Fatal("different pointers hash the same")
+	}
+	if OptionsHash(nil) == OptionsHash(0) {
+		t.Fatal("nil and zero hash the same")
+	}
+}